@@ -0,0 +1,152 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type candidateFetchFunc func(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, int, error)
+
+// emulatedCandidateCount returns the number of candidates to sample with separate requests: ModelParam.Candidates
+// when the model cannot return more than one candidate per response, else 0.
+func emulatedCandidateCount(
+	ctx context.Context,
+	p sdkutil.CompletionProvider,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (int, error) {
+	n := req.ModelParam.Candidates
+	if n < 2 {
+		return 0, nil
+	}
+	providerCaps, err := p.GetProviderCapability(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var sdkType spec.ProviderSDKType
+	if info := p.GetProviderInfo(ctx); info != nil {
+		sdkType = info.SDKType
+	}
+	caps, err := sdkutil.ResolveModelCapabilities(ctx, opts, sdkType, req.ModelParam.Name, providerCaps)
+	if err != nil {
+		return 0, err
+	}
+	if caps.SamplingCapabilities != nil && caps.SamplingCapabilities.MaxCandidates > 1 {
+		return 0, nil
+	}
+	return n, nil
+}
+
+// fetchEmulatedCandidates samples n candidates with n concurrent single candidate requests and merges them into one
+// response. Only the first request streams. Usage is summed, as every request is billed for its prompt.
+//
+// The first failure cancels the other requests and is returned with its partial response. The returned attempt count
+// is the highest of the requests.
+func fetchEmulatedCandidates(
+	ctx context.Context,
+	n int,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	fetch candidateFetchFunc,
+) (*spec.FetchCompletionResponse, int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	single := *req
+	single.ModelParam.Candidates = 0
+	quietOpts := opts
+	if opts != nil && opts.StreamHandler != nil {
+		o := *opts
+		o.StreamHandler = nil
+		quietOpts = &o
+	}
+
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		failed   = -1
+		resps    = make([]*spec.FetchCompletionResponse, n)
+		attempts = make([]int, n)
+		errs     = make([]error, n)
+	)
+	for i := range n {
+		callOpts := quietOpts
+		if i == 0 {
+			callOpts = opts
+		}
+		wg.Go(func() {
+			resps[i], attempts[i], errs[i] = fetch(ctx, &single, callOpts)
+			if errs[i] != nil {
+				failOnce.Do(func() {
+					failed = i
+					cancel()
+				})
+			}
+		})
+	}
+	wg.Wait()
+
+	maxAttempts := slices.Max(attempts)
+	if failed >= 0 {
+		return resps[failed], maxAttempts, fmt.Errorf("candidate %d of %d: %w", failed+1, n, errs[failed])
+	}
+
+	out := *resps[0]
+	out.Choices = make([]spec.Choice, 0, n)
+	out.Usage = nil
+	out.Warnings = slices.Clone(out.Warnings)
+	for i, r := range resps {
+		out.Choices = append(out.Choices, spec.Choice{Index: i, Outputs: r.Outputs, StopReason: r.StopReason})
+		if r.Usage != nil {
+			if out.Usage == nil {
+				out.Usage = &spec.Usage{}
+			}
+			addUsage(out.Usage, r.Usage)
+		}
+		if i > 0 {
+			for _, w := range r.Warnings {
+				if !slices.Contains(out.Warnings, w) {
+					out.Warnings = append(out.Warnings, w)
+				}
+			}
+		}
+	}
+	out.Warnings = append(out.Warnings, spec.Warning{
+		Code:    "candidates_emulated",
+		Message: fmt.Sprintf("%d candidates were sampled with %d separate requests; usage covers all of them.", n, n),
+	})
+	return &out, maxAttempts, nil
+}
+
+// addUsage adds the token counts of src to dst.
+func addUsage(dst, src *spec.Usage) {
+	dst.InputTokensTotal += src.InputTokensTotal
+	dst.InputTokensCached += src.InputTokensCached
+	dst.InputTokensUncached += src.InputTokensUncached
+	dst.OutputTokens += src.OutputTokens
+	dst.ReasoningTokens += src.ReasoningTokens
+	dst.InputTokensCacheWrite += src.InputTokensCacheWrite
+	dst.ToolUseInputTokens += src.ToolUseInputTokens
+	dst.WebSearchRequests += src.WebSearchRequests
+	for ttl, v := range src.InputTokensCacheWriteByTTL {
+		if dst.InputTokensCacheWriteByTTL == nil {
+			dst.InputTokensCacheWriteByTTL = map[spec.CacheControlTTL]int64{}
+		}
+		dst.InputTokensCacheWriteByTTL[ttl] += v
+	}
+	for m, v := range src.InputTokensByModality {
+		if dst.InputTokensByModality == nil {
+			dst.InputTokensByModality = map[spec.Modality]int64{}
+		}
+		dst.InputTokensByModality[m] += v
+	}
+}
@@ -0,0 +1,123 @@
+package inference
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/fakeprovider"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func newFakeProviderSet(t *testing.T, fake *fakeprovider.Provider) *ProviderSetAPI {
+	t.Helper()
+
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ps.AddCompletionProvider(t.Context(), "fake", spec.ProviderSDKTypeFake, fake.Adapter); err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+func TestFetchCompletionEmulatedCandidates(t *testing.T) {
+	t.Parallel()
+
+	usage := &spec.Usage{InputTokensTotal: 10, InputTokensUncached: 10, OutputTokens: 2}
+	fake := fakeprovider.New(fakeprovider.Config{Turns: []fakeprovider.Turn{
+		{Outputs: []spec.OutputUnion{fakeprovider.Text("red")}, Usage: usage},
+		{Outputs: []spec.OutputUnion{fakeprovider.Text("green")}, Usage: usage},
+		{Outputs: []spec.OutputUnion{fakeprovider.Text("blue")}, Usage: usage},
+	}})
+	ps := newFakeProviderSet(t, fake)
+
+	req := retryTestRequest()
+	req.ModelParam.Candidates = 3
+	req.ModelParam.Stream = true
+	var streamed strings.Builder
+	resp, err := ps.FetchCompletion(t.Context(), "fake", req, &spec.FetchCompletionOptions{
+		StreamHandler: func(ev spec.StreamEvent) error {
+			if ev.Kind == spec.StreamContentKindText {
+				streamed.WriteString(ev.Text.Text)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("FetchCompletion: %v", err)
+	}
+
+	if len(resp.Choices) != 3 {
+		t.Fatalf("choices = %+v, want 3", resp.Choices)
+	}
+	var texts []string
+	for i, c := range resp.Choices {
+		if c.Index != i {
+			t.Errorf("choice %d has index %d", i, c.Index)
+		}
+		texts = append(texts, c.Outputs[0].OutputMessage.Contents[0].TextItem.Text)
+	}
+	if first := texts[0]; resp.Outputs[0].OutputMessage.Contents[0].TextItem.Text != first ||
+		streamed.String() != first {
+		t.Errorf("outputs and stream = %q, %q; want the first choice %q",
+			resp.Outputs[0].OutputMessage.Contents[0].TextItem.Text, streamed.String(), first)
+	}
+	slices.Sort(texts)
+	if !slices.Equal(texts, []string{"blue", "green", "red"}) {
+		t.Errorf("choice texts = %v", texts)
+	}
+
+	if resp.Usage == nil || resp.Usage.InputTokensTotal != 30 || resp.Usage.OutputTokens != 6 {
+		t.Errorf("usage = %+v, want the sum of three requests", resp.Usage)
+	}
+	if !slices.ContainsFunc(resp.Warnings, func(w spec.Warning) bool { return w.Code == "candidates_emulated" }) {
+		t.Errorf("warnings = %+v, want candidates_emulated", resp.Warnings)
+	}
+	for i, r := range fake.Requests() {
+		if r.ModelParam.Candidates != 0 {
+			t.Errorf("request %d asked for %d candidates, want one", i, r.ModelParam.Candidates)
+		}
+	}
+}
+
+func TestFetchCompletionEmulatedCandidatesFailure(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	fake := fakeprovider.New(fakeprovider.Config{Turns: []fakeprovider.Turn{
+		{Outputs: []spec.OutputUnion{fakeprovider.Text("red")}},
+		{Err: boom},
+	}})
+	ps := newFakeProviderSet(t, fake)
+
+	req := retryTestRequest()
+	req.ModelParam.Candidates = 2
+	_, err := ps.FetchCompletion(t.Context(), "fake", req, nil)
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "of 2") {
+		t.Fatalf("err = %v, want the failed candidate's error", err)
+	}
+}
+
+func TestFetchCompletionNativeCandidatesNotEmulated(t *testing.T) {
+	t.Parallel()
+
+	caps := fakeprovider.DefaultCapabilities()
+	caps.SamplingCapabilities.MaxCandidates = 4
+	fake := fakeprovider.New(fakeprovider.Config{
+		Turns:        []fakeprovider.Turn{{Outputs: []spec.OutputUnion{fakeprovider.Text("red")}}},
+		Capabilities: &caps,
+	})
+	ps := newFakeProviderSet(t, fake)
+
+	req := retryTestRequest()
+	req.ModelParam.Candidates = 3
+	if _, err := ps.FetchCompletion(t.Context(), "fake", req, nil); err != nil {
+		t.Fatalf("FetchCompletion: %v", err)
+	}
+	if got := fake.Requests(); len(got) != 1 || got[0].ModelParam.Candidates != 3 {
+		t.Fatalf("requests = %d, want one request for 3 candidates", len(got))
+	}
+}
@@ -46,6 +46,7 @@ func applyModelCapabilitiesOverride(
 	applyParamDialectOverride(dst, ov.ParamDialect)
 	applyReasoningCapabilitiesOverride(dst, ov.ReasoningCapabilities)
 	applyStopSequenceCapabilitiesOverride(dst, ov.StopSequenceCapabilities)
+	applySamplingCapabilitiesOverride(dst, ov.SamplingCapabilities)
 	applyOutputCapabilitiesOverride(dst, ov.OutputCapabilities)
 	applyToolCapabilitiesOverride(dst, ov.ToolCapabilities)
 	applyCacheCapabilitiesOverride(dst, ov.CacheCapabilities)
@@ -160,6 +161,49 @@ func applyStopSequenceCapabilitiesOverride(
 	}
 }
 
+func applySamplingCapabilitiesOverride(
+	dst *spec.ModelCapabilities,
+	ov *SamplingCapabilitiesOverride,
+) {
+	if !hasSamplingCapabilitiesOverride(ov) {
+		return
+	}
+	if dst.SamplingCapabilities == nil {
+		dst.SamplingCapabilities = &spec.SamplingCapabilities{}
+	}
+
+	if ov.SupportsTopP != nil {
+		dst.SamplingCapabilities.SupportsTopP = *ov.SupportsTopP
+	}
+	if ov.SupportsTopK != nil {
+		dst.SamplingCapabilities.SupportsTopK = *ov.SupportsTopK
+	}
+	if ov.SupportsSeed != nil {
+		dst.SamplingCapabilities.SupportsSeed = *ov.SupportsSeed
+	}
+	if ov.SupportsPresencePenalty != nil {
+		dst.SamplingCapabilities.SupportsPresencePenalty = *ov.SupportsPresencePenalty
+	}
+	if ov.SupportsFrequencyPenalty != nil {
+		dst.SamplingCapabilities.SupportsFrequencyPenalty = *ov.SupportsFrequencyPenalty
+	}
+	if ov.SupportsLogitBias != nil {
+		dst.SamplingCapabilities.SupportsLogitBias = *ov.SupportsLogitBias
+	}
+	if ov.MaxCandidates != nil {
+		dst.SamplingCapabilities.MaxCandidates = *ov.MaxCandidates
+	}
+	if ov.DisallowedWithReasoning != nil {
+		dst.SamplingCapabilities.DisallowedWithReasoning = *ov.DisallowedWithReasoning
+	}
+	if ov.SupportsLogprobs != nil {
+		dst.SamplingCapabilities.SupportsLogprobs = *ov.SupportsLogprobs
+	}
+	if ov.MaxTopLogprobs != nil {
+		dst.SamplingCapabilities.MaxTopLogprobs = *ov.MaxTopLogprobs
+	}
+}
+
 func applyOutputCapabilitiesOverride(
 	dst *spec.ModelCapabilities,
 	ov *OutputCapabilitiesOverride,
@@ -302,6 +346,20 @@ func hasStopSequenceCapabilitiesOverride(ov *StopSequenceCapabilitiesOverride) b
 			ov.MaxSequences != nil)
 }
 
+func hasSamplingCapabilitiesOverride(ov *SamplingCapabilitiesOverride) bool {
+	return ov != nil &&
+		(ov.SupportsTopP != nil ||
+			ov.SupportsTopK != nil ||
+			ov.SupportsSeed != nil ||
+			ov.SupportsPresencePenalty != nil ||
+			ov.SupportsFrequencyPenalty != nil ||
+			ov.SupportsLogitBias != nil ||
+			ov.MaxCandidates != nil ||
+			ov.DisallowedWithReasoning != nil ||
+			ov.SupportsLogprobs != nil ||
+			ov.MaxTopLogprobs != nil)
+}
+
 func hasOutputCapabilitiesOverride(ov *OutputCapabilitiesOverride) bool {
 	return ov != nil &&
 		(ov.SupportedOutputFormats != nil ||
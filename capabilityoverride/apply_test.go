@@ -199,6 +199,25 @@ func TestApplyModelCapabilitiesOverrideTable(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "sampling override patches individual fields",
+			base: baseCapabilitiesForTest(),
+			override: &ModelCapabilitiesOverride{
+				SamplingCapabilities: &SamplingCapabilitiesOverride{
+					SupportsTopP:      new(false),
+					SupportsLogitBias: new(true),
+					MaxCandidates:     new(0),
+				},
+			},
+			check: func(t *testing.T, got spec.ModelCapabilities) {
+				t.Helper()
+
+				want := spec.SamplingCapabilities{SupportsSeed: true, SupportsLogitBias: true}
+				if got.SamplingCapabilities == nil || *got.SamplingCapabilities != want {
+					t.Fatalf("sampling capabilities = %#v, want %#v", got.SamplingCapabilities, want)
+				}
+			},
+		},
 		{
 			name: "output override preserves empty supported formats and false-to-true scalar",
 			base: baseCapabilitiesForTest(),
@@ -29,6 +29,11 @@ func CloneModelCapabilities(in spec.ModelCapabilities) spec.ModelCapabilities {
 		out.StopSequenceCapabilities = &c
 	}
 
+	if in.SamplingCapabilities != nil {
+		c := *in.SamplingCapabilities
+		out.SamplingCapabilities = &c
+	}
+
 	if in.OutputCapabilities != nil {
 		c := *in.OutputCapabilities
 		c.SupportedOutputFormats = slices.Clone(c.SupportedOutputFormats)
@@ -72,6 +77,7 @@ func CloneModelCapabilitiesOverride(in *ModelCapabilitiesOverride) *ModelCapabil
 		ModalitiesOut:            slices.Clone(in.ModalitiesOut),
 		ReasoningCapabilities:    cloneReasoningCapabilitiesOverride(in.ReasoningCapabilities),
 		StopSequenceCapabilities: cloneStopSequenceCapabilitiesOverride(in.StopSequenceCapabilities),
+		SamplingCapabilities:     cloneSamplingCapabilitiesOverride(in.SamplingCapabilities),
 		OutputCapabilities:       cloneOutputCapabilitiesOverride(in.OutputCapabilities),
 		ToolCapabilities:         cloneToolCapabilitiesOverride(in.ToolCapabilities),
 		CacheCapabilities:        cloneCacheCapabilitiesOverride(in.CacheCapabilities),
@@ -160,6 +166,25 @@ func cloneStopSequenceCapabilitiesOverride(
 	}
 }
 
+func cloneSamplingCapabilitiesOverride(in *SamplingCapabilitiesOverride) *SamplingCapabilitiesOverride {
+	if in == nil {
+		return nil
+	}
+
+	return &SamplingCapabilitiesOverride{
+		SupportsTopP:             sdkutil.CloneBoolPtr(in.SupportsTopP),
+		SupportsTopK:             sdkutil.CloneBoolPtr(in.SupportsTopK),
+		SupportsSeed:             sdkutil.CloneBoolPtr(in.SupportsSeed),
+		SupportsPresencePenalty:  sdkutil.CloneBoolPtr(in.SupportsPresencePenalty),
+		SupportsFrequencyPenalty: sdkutil.CloneBoolPtr(in.SupportsFrequencyPenalty),
+		SupportsLogitBias:        sdkutil.CloneBoolPtr(in.SupportsLogitBias),
+		MaxCandidates:            sdkutil.CloneIntPtr(in.MaxCandidates),
+		DisallowedWithReasoning:  sdkutil.CloneBoolPtr(in.DisallowedWithReasoning),
+		SupportsLogprobs:         sdkutil.CloneBoolPtr(in.SupportsLogprobs),
+		MaxTopLogprobs:           sdkutil.CloneIntPtr(in.MaxTopLogprobs),
+	}
+}
+
 func cloneOutputCapabilitiesOverride(in *OutputCapabilitiesOverride) *OutputCapabilitiesOverride {
 	if in == nil {
 		return nil
@@ -125,6 +125,9 @@ func TestCloneModelCapabilitiesOverrideTable(t *testing.T) {
 				if got.StopSequenceCapabilities == fullIn.StopSequenceCapabilities {
 					t.Fatal("expected different stop sequence capabilities pointer")
 				}
+				if got.SamplingCapabilities == fullIn.SamplingCapabilities {
+					t.Fatal("expected different sampling capabilities pointer")
+				}
 				if got.OutputCapabilities == fullIn.OutputCapabilities {
 					t.Fatal("expected different output capabilities pointer")
 				}
@@ -278,6 +281,8 @@ func mutateModelCapabilitiesForCloneTest(in *spec.ModelCapabilities) {
 
 	in.StopSequenceCapabilities.MaxSequences = 99
 
+	in.SamplingCapabilities.MaxCandidates = 99
+
 	in.OutputCapabilities.SupportedOutputFormats[0] = spec.OutputFormatKindJSONSchema
 
 	in.ToolCapabilities.SupportedToolTypes[0] = spec.ToolTypeCustom
@@ -308,6 +313,9 @@ func mutateCapabilitiesOverrideForCloneTest(in *ModelCapabilitiesOverride) {
 	*in.StopSequenceCapabilities.DisallowedWithReasoning = false
 	*in.StopSequenceCapabilities.MaxSequences = 99
 
+	*in.SamplingCapabilities.SupportsTopP = false
+	*in.SamplingCapabilities.MaxCandidates = 99
+
 	in.OutputCapabilities.SupportedOutputFormats[0] = spec.OutputFormatKindJSONSchema
 	*in.OutputCapabilities.SupportsVerbosity = false
 
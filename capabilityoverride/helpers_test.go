@@ -138,6 +138,11 @@ func baseCapabilitiesForTest() spec.ModelCapabilities {
 			DisallowedWithReasoning: false,
 			MaxSequences:            4,
 		},
+		SamplingCapabilities: &spec.SamplingCapabilities{
+			SupportsTopP:  true,
+			SupportsSeed:  true,
+			MaxCandidates: 4,
+		},
 		OutputCapabilities: &spec.OutputCapabilities{
 			SupportedOutputFormats: []spec.OutputFormatKind{
 				spec.OutputFormatKindText,
@@ -228,6 +233,18 @@ func fullCapabilitiesOverrideForTest() *ModelCapabilitiesOverride {
 			DisallowedWithReasoning: new(true),
 			MaxSequences:            new(4),
 		},
+		SamplingCapabilities: &SamplingCapabilitiesOverride{
+			SupportsTopP:             new(true),
+			SupportsTopK:             new(true),
+			SupportsSeed:             new(true),
+			SupportsPresencePenalty:  new(true),
+			SupportsFrequencyPenalty: new(true),
+			SupportsLogitBias:        new(true),
+			MaxCandidates:            new(8),
+			DisallowedWithReasoning:  new(true),
+			SupportsLogprobs:         new(true),
+			MaxTopLogprobs:           new(5),
+		},
 		OutputCapabilities: &OutputCapabilitiesOverride{
 			SupportedOutputFormats: []spec.OutputFormatKind{
 				spec.OutputFormatKindText,
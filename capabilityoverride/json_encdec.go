@@ -17,6 +17,9 @@ func (o ModelCapabilitiesOverride) MarshalJSON() ([]byte, error) {
 	if o.StopSequenceCapabilities != nil {
 		m["stopSequenceCapabilities"] = o.StopSequenceCapabilities
 	}
+	if o.SamplingCapabilities != nil {
+		m["samplingCapabilities"] = o.SamplingCapabilities
+	}
 	if o.OutputCapabilities != nil {
 		m["outputCapabilities"] = o.OutputCapabilities
 	}
@@ -27,6 +27,19 @@ type StopSequenceCapabilitiesOverride struct {
 	MaxSequences            *int  `json:"maxSequences,omitempty"`
 }
 
+type SamplingCapabilitiesOverride struct {
+	SupportsTopP             *bool `json:"supportsTopP,omitempty"`
+	SupportsTopK             *bool `json:"supportsTopK,omitempty"`
+	SupportsSeed             *bool `json:"supportsSeed,omitempty"`
+	SupportsPresencePenalty  *bool `json:"supportsPresencePenalty,omitempty"`
+	SupportsFrequencyPenalty *bool `json:"supportsFrequencyPenalty,omitempty"`
+	SupportsLogitBias        *bool `json:"supportsLogitBias,omitempty"`
+	MaxCandidates            *int  `json:"maxCandidates,omitempty"`
+	DisallowedWithReasoning  *bool `json:"disallowedWithReasoning,omitempty"`
+	SupportsLogprobs         *bool `json:"supportsLogprobs,omitempty"`
+	MaxTopLogprobs           *int  `json:"maxTopLogprobs,omitempty"`
+}
+
 type OutputCapabilitiesOverride struct {
 	SupportedOutputFormats []spec.OutputFormatKind `json:"supportedOutputFormats,omitempty"`
 	SupportsVerbosity      *bool                   `json:"supportsVerbosity,omitempty"`
@@ -83,6 +96,7 @@ type ModelCapabilitiesOverride struct {
 
 	ReasoningCapabilities    *ReasoningCapabilitiesOverride    `json:"reasoningCapabilities,omitempty"`
 	StopSequenceCapabilities *StopSequenceCapabilitiesOverride `json:"stopSequenceCapabilities,omitempty"`
+	SamplingCapabilities     *SamplingCapabilitiesOverride     `json:"samplingCapabilities,omitempty"`
 	OutputCapabilities       *OutputCapabilitiesOverride       `json:"outputCapabilities,omitempty"`
 	ToolCapabilities         *ToolCapabilitiesOverride         `json:"toolCapabilities,omitempty"`
 	CacheCapabilities        *CacheCapabilitiesOverride        `json:"cacheCapabilities,omitempty"`
@@ -32,6 +32,12 @@ func ValidateModelCapabilitiesOverride(o *ModelCapabilitiesOverride) error {
 		}
 	}
 
+	if o.SamplingCapabilities != nil {
+		if err := validateSamplingCapabilitiesOverride(o.SamplingCapabilities); err != nil {
+			return fmt.Errorf("samplingCapabilities: %w", err)
+		}
+	}
+
 	if o.OutputCapabilities != nil {
 		if err := validateOutputCapabilitiesOverride(o.OutputCapabilities); err != nil {
 			return fmt.Errorf("outputCapabilities: %w", err)
@@ -206,6 +212,21 @@ func validateStopSequenceCapabilitiesOverride(o *StopSequenceCapabilitiesOverrid
 	return nil
 }
 
+func validateSamplingCapabilitiesOverride(o *SamplingCapabilitiesOverride) error {
+	if o == nil {
+		return nil
+	}
+
+	if o.MaxCandidates != nil && *o.MaxCandidates < 0 {
+		return errors.New("maxCandidates must be >= 0")
+	}
+	if o.MaxTopLogprobs != nil && *o.MaxTopLogprobs < 0 {
+		return errors.New("maxTopLogprobs must be >= 0")
+	}
+
+	return nil
+}
+
 func validateOutputCapabilitiesOverride(o *OutputCapabilitiesOverride) error {
 	if o == nil {
 		return nil
@@ -218,6 +218,24 @@ func TestValidateModelCapabilitiesOverrideTable(t *testing.T) {
 			},
 			wantErr: "maxSequences must be >= 0",
 		},
+		{
+			name: "sampling negative max candidates",
+			override: &ModelCapabilitiesOverride{
+				SamplingCapabilities: &SamplingCapabilitiesOverride{
+					MaxCandidates: new(-1),
+				},
+			},
+			wantErr: "samplingCapabilities: maxCandidates must be >= 0",
+		},
+		{
+			name: "sampling negative max top logprobs",
+			override: &ModelCapabilitiesOverride{
+				SamplingCapabilities: &SamplingCapabilitiesOverride{
+					MaxTopLogprobs: new(-1),
+				},
+			},
+			wantErr: "samplingCapabilities: maxTopLogprobs must be >= 0",
+		},
 		{
 			name: "output unknown format",
 			override: &ModelCapabilitiesOverride{
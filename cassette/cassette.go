@@ -0,0 +1,245 @@
+// Package cassette records provider HTTP exchanges into cassette files and
+// replays them offline, for deterministic tests without network access or
+// API keys.
+//
+// A Recorder is a spec.CompletionDebugger whose HTTP client records or
+// replays, so it is installed with inference.WithDebugClientBuilder, alone or
+// in a debugclient.Chain. Streamed (server-sent events) responses are stored
+// event by event together with the delay before each event.
+//
+// Cassettes hold no request headers and no URL query, and only the response
+// headers in SafeResponseHeaders, so API keys never reach the file. Request
+// and response bodies are stored as sent; they hold prompts and completions.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays.
+type Mode string
+
+const (
+	// ModeReplay serves responses from the cassette file and never touches the
+	// network. It is the default.
+	ModeReplay Mode = "replay"
+
+	// ModeRecord sends requests to the provider and records the exchanges.
+	// They are written to the cassette file by Save.
+	ModeRecord Mode = "record"
+)
+
+// SafeResponseHeaders are the response headers kept in cassettes.
+var SafeResponseHeaders = []string{"Content-Type", "Retry-After", "Retry-After-Ms"}
+
+const fileVersion = 1
+
+// Cassette is the on-disk format of a cassette file.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded HTTP exchange.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request identifies a recorded request. Requests are matched on all fields.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Body is the request body normalized by NormalizeBody: JSON bodies are
+	// stored as JSON with sorted object keys, other bodies as a JSON string.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+
+	// Body holds a non-streamed body: JSON as is, anything else as a JSON
+	// string.
+	Body json.RawMessage `json:"body,omitempty"`
+
+	// Chunks holds a streamed (text/event-stream) body, one event per chunk.
+	Chunks []Chunk `json:"chunks,omitempty"`
+}
+
+// Chunk is one event of a streamed response body.
+type Chunk struct {
+	// DelayMillis is the time between the previous chunk, or the response
+	// headers for the first chunk, and this one.
+	DelayMillis int64  `json:"delayMillis"`
+	Data        string `json:"data"`
+}
+
+// Config configures a Recorder.
+type Config struct {
+	// Path is the cassette file. Required.
+	Path string
+
+	// Mode defaults to ModeReplay.
+	Mode Mode
+
+	// ReplayDelayScale scales the recorded chunk delays on replay: 0 replays
+	// without delays, 1 in real time.
+	ReplayDelayScale float64
+}
+
+// Recorder implements spec.CompletionDebugger by recording or replaying the
+// provider's HTTP traffic. It starts no spans.
+type Recorder struct {
+	cfg Config
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// New returns a Recorder for cfg. In replay mode the cassette file must exist.
+func New(cfg Config) (*Recorder, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("cassette: empty path")
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeReplay
+	}
+	if cfg.Mode != ModeReplay && cfg.Mode != ModeRecord {
+		return nil, fmt.Errorf("cassette: unknown mode %q", cfg.Mode)
+	}
+	r := &Recorder{cfg: cfg}
+	if cfg.Mode == ModeRecord {
+		return r, nil
+	}
+
+	c, err := Load(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range c.Interactions {
+		r.interactions = append(r.interactions, &c.Interactions[i])
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cassette: decode %s: %w", path, err)
+	}
+	if c.Version != fileVersion {
+		return nil, fmt.Errorf("cassette: %s has version %d, want %d", path, c.Version, fileVersion)
+	}
+	return &c, nil
+}
+
+// Mode returns the mode of the recorder.
+func (r *Recorder) Mode() Mode {
+	return r.cfg.Mode
+}
+
+// Save writes the recorded interactions to the cassette file, creating its
+// directory. It does nothing in replay mode. Exchanges whose response body
+// was not read to the end or closed are left out.
+func (r *Recorder) Save() error {
+	if r.cfg.Mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	c := Cassette{Version: fileVersion, Interactions: []Interaction{}}
+	for _, in := range r.interactions {
+		if in.Response.Status != 0 {
+			c.Interactions = append(c.Interactions, *in)
+		}
+	}
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: encode: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.WriteFile(r.cfg.Path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded requests that were not replayed. It is empty in
+// record mode.
+func (r *Recorder) Unused() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []Request
+	for i, in := range r.interactions {
+		if r.cfg.Mode == ModeReplay && !r.used[i] {
+			out = append(out, in.Request)
+		}
+	}
+	return out
+}
+
+// NormalizeBody returns the form of a request body used for matching. JSON is
+// re-encoded with sorted object keys; other non-empty bodies become a JSON
+// string.
+func NormalizeBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		if out, err := json.Marshal(v); err == nil {
+			return out
+		}
+	}
+	out, _ := json.Marshal(string(body))
+	return out
+}
+
+func safeHeader(h http.Header) http.Header {
+	var out http.Header
+	for _, k := range SafeResponseHeaders {
+		if v := h.Values(k); len(v) > 0 {
+			if out == nil {
+				out = http.Header{}
+			}
+			out[http.CanonicalHeaderKey(k)] = slices.Clone(v)
+		}
+	}
+	return out
+}
+
+func isEventStream(h http.Header) bool {
+	return strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "text/event-stream")
+}
+
+// requestKey is the matching key of req. The body is normalized again since
+// cassette files are indented.
+func requestKey(req Request) string {
+	return req.Method + " " + req.Path + " " + string(NormalizeBody(req.Body))
+}
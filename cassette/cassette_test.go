@@ -0,0 +1,199 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("X-Request-Id", "req-1")
+			for _, ev := range []string{"data: a\n\n", "data: b\n\n"} {
+				_, _ = io.WriteString(w, ev)
+				w.(http.Flusher).Flush()
+				time.Sleep(20 * time.Millisecond)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"r1","ok":true}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "nested", "cassette.json")
+	rec, err := New(Config{Path: path, Mode: ModeRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := rec.HTTPClient(nil)
+
+	gotJSON := do(t, client, srv.URL+"/json", `{"b": 1, "a": [true]}`)
+	gotStream := do(t, client, srv.URL+"/stream", "")
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 2 {
+		t.Fatalf("recorded %d interactions, want 2", len(c.Interactions))
+	}
+	stream := c.Interactions[1].Response
+	if len(stream.Chunks) != 2 || stream.Chunks[1].Data != "data: b\n\n" {
+		t.Errorf("stream chunks = %+v, want one chunk per event", stream.Chunks)
+	}
+	if stream.Chunks[1].DelayMillis < 10 {
+		t.Errorf("second chunk delay = %dms, want the recorded gap", stream.Chunks[1].DelayMillis)
+	}
+	if stream.Header.Get("X-Request-Id") != "" {
+		t.Errorf("unsafe response header recorded: %v", stream.Header)
+	}
+
+	srv.Close()
+	replay, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = replay.HTTPClient(&http.Client{})
+
+	// Bodies match regardless of key order and whitespace.
+	if got := do(t, client, "http://offline.invalid/json", `{"a":[true],"b":1}`); got != gotJSON {
+		t.Errorf("replayed body = %q, want %q", got, gotJSON)
+	}
+	if got := do(t, client, "http://offline.invalid/stream", ""); got != gotStream {
+		t.Errorf("replayed stream = %q, want %q", got, gotStream)
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("Unused() = %v, want none", unused)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	writeCassette(t, path, `{"version":1,"interactions":[
+		{"request":{"method":"POST","path":"/v1/x","body":{"a":1}},"response":{"status":200,"body":{"ok":true}}}
+	]}`)
+	rec, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "http://offline.invalid/v1/x",
+		strings.NewReader(`{"a":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rec.HTTPClient(nil).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || !strings.Contains(string(body), "POST /v1/x") {
+		t.Errorf("mismatch = %d %q, want 404 naming the request", resp.StatusCode, body)
+	}
+	if unused := rec.Unused(); len(unused) != 1 {
+		t.Errorf("Unused() = %v, want the recorded request", unused)
+	}
+}
+
+func TestReplayDelayHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	writeCassette(t, path, `{"version":1,"interactions":[
+		{"request":{"method":"GET","path":"/s"},"response":{"status":200,
+			"header":{"Content-Type":["text/event-stream"]},
+			"chunks":[{"delayMillis":0,"data":"data: a\n\n"},{"delayMillis":60000,"data":"data: b\n\n"}]}}
+	]}`)
+	rec, err := New(Config{Path: path, ReplayDelayScale: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://offline.invalid/s", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rec.HTTPClient(nil).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadAll() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeCassette(t, filepath.Join(dir, "v2.json"), `{"version":2}`)
+	for name, cfg := range map[string]Config{
+		"empty path":   {},
+		"missing file": {Path: filepath.Join(dir, "missing.json")},
+		"bad version":  {Path: filepath.Join(dir, "v2.json")},
+		"bad mode":     {Path: filepath.Join(dir, "v2.json"), Mode: "live"},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: New() error = nil", name)
+		}
+	}
+}
+
+func do(t *testing.T, client *http.Client, url, body string) string {
+	t.Helper()
+
+	method, r := http.MethodGet, io.Reader(nil)
+	if body != "" {
+		method, r = http.MethodPost, strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(t.Context(), method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("%s %s: status %d", method, url, resp.StatusCode)
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func writeCassette(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,298 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// HTTPClient implements spec.CompletionDebugger.HTTPClient. The returned client
+// records through, or replays instead of, the transport of base.
+func (r *Recorder) HTTPClient(base *http.Client) *http.Client {
+	var clone http.Client
+	if base != nil {
+		clone = *base
+	}
+	clone.Transport = r.Transport(clone.Transport)
+	return &clone
+}
+
+// StartSpan implements spec.CompletionDebugger.StartSpan. It returns no span.
+func (r *Recorder) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	return ctx, nil
+}
+
+// Transport wraps base, or http.DefaultTransport if nil, for use outside of a
+// CompletionDebugger. In replay mode base is never called.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		key, err := readRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		if r.cfg.Mode == ModeRecord {
+			return r.record(base, req, key)
+		}
+		return r.replay(req, key)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// readRequest returns the matching key of req and restores its body.
+func readRequest(req *http.Request) (Request, error) {
+	out := Request{Method: req.Method, Path: req.URL.Path}
+	if req.Body == nil || req.Body == http.NoBody {
+		return out, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return out, fmt.Errorf("cassette: read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	out.Body = NormalizeBody(body)
+	return out, nil
+}
+
+func (r *Recorder) record(base http.RoundTripper, req *http.Request, key Request) (*http.Response, error) {
+	in := &Interaction{Request: key}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	rec := &recordingBody{
+		ReadCloser: resp.Body,
+		r:          r,
+		in:         in,
+		status:     resp.StatusCode,
+		header:     safeHeader(resp.Header),
+		stream:     isEventStream(resp.Header),
+		last:       time.Now(),
+	}
+	resp.Body = rec
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, key Request) (*http.Response, error) {
+	want := requestKey(key)
+
+	r.mu.Lock()
+	var in *Interaction
+	for i, candidate := range r.interactions {
+		if !r.used[i] && requestKey(candidate.Request) == want {
+			r.used[i] = true
+			in = candidate
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if in == nil {
+		// Answer rather than fail the round trip, so SDKs do not retry.
+		msg := fmt.Sprintf("cassette: no recorded interaction for %s %s in %s", key.Method, key.Path, r.cfg.Path)
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     fmt.Sprintf("%d %s", http.StatusNotFound, http.StatusText(http.StatusNotFound)),
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(msg)),
+			Request:    req,
+		}, nil
+	}
+
+	header := in.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	var body io.ReadCloser
+	if len(in.Response.Chunks) > 0 {
+		body = &replayBody{ctx: req.Context(), chunks: in.Response.Chunks, scale: r.cfg.ReplayDelayScale}
+	} else {
+		body = io.NopCloser(bytes.NewReader(decodeBody(in.Response.Body)))
+	}
+	return &http.Response{
+		StatusCode: in.Response.Status,
+		Status:     fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+// decodeBody reverses the encoding of Response.Body, undoing the indentation
+// of the cassette file.
+func decodeBody(raw json.RawMessage) []byte {
+	var s string
+	if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return []byte(s)
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, raw) != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+// encodeBody stores JSON bodies as they are and other bodies as a JSON string.
+func encodeBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		var buf bytes.Buffer
+		if json.Compact(&buf, body) == nil {
+			return buf.Bytes()
+		}
+	}
+	out, _ := json.Marshal(string(body))
+	return out
+}
+
+// recordingBody records a response body as it is read. Streams are split into
+// events at blank lines, each with the delay since the previous event.
+type recordingBody struct {
+	io.ReadCloser
+
+	r      *Recorder
+	in     *Interaction
+	status int
+	header http.Header
+	stream bool
+
+	buf    bytes.Buffer
+	chunks []Chunk
+	last   time.Time
+	done   bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.buf.Write(p[:n])
+		if b.stream {
+			b.splitEvents(false)
+		}
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *recordingBody) splitEvents(final bool) {
+	for {
+		data := b.buf.String()
+		end := eventEnd(data)
+		if end < 0 {
+			if final && data != "" {
+				b.addChunk(data)
+				b.buf.Reset()
+			}
+			return
+		}
+		b.addChunk(data[:end])
+		b.buf.Next(end)
+	}
+}
+
+func (b *recordingBody) addChunk(data string) {
+	now := time.Now()
+	b.chunks = append(b.chunks, Chunk{DelayMillis: now.Sub(b.last).Milliseconds(), Data: data})
+	b.last = now
+}
+
+func (b *recordingBody) finish() {
+	if b.done {
+		return
+	}
+	b.done = true
+
+	resp := Response{Status: b.status, Header: b.header}
+	if b.stream {
+		b.splitEvents(true)
+		resp.Chunks = b.chunks
+	} else {
+		resp.Body = encodeBody(b.buf.Bytes())
+	}
+
+	b.r.mu.Lock()
+	b.in.Response = resp
+	b.r.mu.Unlock()
+}
+
+// eventEnd returns the end of the first server-sent event in s, including its
+// terminating blank line, or -1.
+func eventEnd(s string) int {
+	best := -1
+	for _, sep := range []string{"\n\n", "\r\n\r\n"} {
+		if i := strings.Index(s, sep); i >= 0 && (best < 0 || i+len(sep) < best) {
+			best = i + len(sep)
+		}
+	}
+	return best
+}
+
+// replayBody serves recorded chunks, waiting the scaled delay before each.
+type replayBody struct {
+	ctx    context.Context
+	chunks []Chunk
+	scale  float64
+	cur    []byte
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	for len(b.cur) == 0 {
+		if len(b.chunks) == 0 {
+			return 0, io.EOF
+		}
+		c := b.chunks[0]
+		b.chunks = b.chunks[1:]
+		if d := time.Duration(float64(c.DelayMillis) * b.scale * float64(time.Millisecond)); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-t.C:
+			case <-b.ctx.Done():
+				t.Stop()
+				return 0, b.ctx.Err()
+			}
+		}
+		b.cur = []byte(c.Data)
+	}
+	n := copy(p, b.cur)
+	b.cur = b.cur[n:]
+	return n, nil
+}
+
+func (b *replayBody) Close() error { return nil }
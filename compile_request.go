@@ -0,0 +1,64 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// CompileRequest returns the provider HTTP request FetchCompletion would send for a request, without sending it.
+//
+// The request goes through the same steps as in FetchCompletion: fitting inputs into ModelParam.MaxPromptLength,
+// normalization against the model capabilities, tool name mapping and conversion to the provider format. The
+// result holds the serialized JSON body, target URL, headers with credentials masked, warnings and the effective
+// capabilities. With ModelParam.Stream set the streaming request is compiled.
+//
+// Nothing is sent: the request goes through the provider's debugger HTTP client, so headers its transports add are
+// included, and stops where the network would begin. A provider without an API key compiles with a placeholder
+// credential, masked like a real one. opts may be nil; StreamHandler and RetryPolicy are ignored.
+func (ps *ProviderSetAPI) CompileRequest(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	if provider == "" || fetchCompletionRequest == nil || len(fetchCompletionRequest.Inputs) == 0 ||
+		fetchCompletionRequest.ModelParam.Name == "" {
+		return nil, errors.New("got empty compile request input")
+	}
+
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	compiler, ok := p.(sdkutil.RequestCompiler)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support request compilation", provider)
+	}
+	ctx = logutil.NewContext(ctx, ps.requestLogger(provider, fetchCompletionRequest.ModelParam.Name, opts))
+
+	reqCopy, contextWarns, err := ps.fitRequestInputs(ctx, fetchCompletionRequest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	callOpts := &spec.FetchCompletionOptions{}
+	if opts != nil {
+		*callOpts = *opts
+	}
+	callOpts.StreamHandler = nil
+
+	compiled, err := compiler.CompileRequest(ctx, reqCopy, callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("compile request failed for provider %s: %w", provider, err)
+	}
+	compiled.Warnings = append(compiled.Warnings, contextWarns...)
+	return compiled, nil
+}
@@ -0,0 +1,705 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/flexigpt/inference-go/capabilityoverride"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestCompileRequest(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	const apiKey = "sk-compile-secret"
+	tests := []struct {
+		name      string
+		sdkType   spec.ProviderSDKType
+		prefix    string
+		stream    bool
+		wantPath  string
+		wantInURL string
+		wantBody  []string
+	}{
+		{
+			name:     "anthropic",
+			sdkType:  spec.ProviderSDKTypeAnthropic,
+			prefix:   spec.DefaultAnthropicChatCompletionPrefix,
+			wantPath: "/v1/messages",
+			wantBody: []string{`"model":"test-model"`, `"max_tokens":8192`, `"hello there"`},
+		},
+		{
+			name:     "anthropic stream",
+			sdkType:  spec.ProviderSDKTypeAnthropic,
+			prefix:   spec.DefaultAnthropicChatCompletionPrefix,
+			stream:   true,
+			wantPath: "/v1/messages",
+			wantBody: []string{`"stream":true`},
+		},
+		{
+			name:     "openai responses",
+			sdkType:  spec.ProviderSDKTypeOpenAIResponses,
+			prefix:   spec.DefaultOpenAIResponsesPrefix,
+			wantPath: "/v1/responses",
+			wantBody: []string{`"model":"test-model"`, `"hello there"`},
+		},
+		{
+			name:     "openai chat",
+			sdkType:  spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:   spec.DefaultOpenAIChatCompletionsPrefix,
+			stream:   true,
+			wantPath: "/v1/chat/completions",
+			wantBody: []string{`"model":"test-model"`, `"stream":true`, `"hello there"`},
+		},
+		{
+			name:      "google",
+			sdkType:   spec.ProviderSDKTypeGoogleGenerateContent,
+			prefix:    spec.DefaultGoogleGenerateContentPrefix,
+			wantInURL: "models/test-model:generateContent",
+			wantBody:  []string{`"hello there"`},
+		},
+		{
+			name:      "google stream",
+			sdkType:   spec.ProviderSDKTypeGoogleGenerateContent,
+			prefix:    spec.DefaultGoogleGenerateContentPrefix,
+			stream:    true,
+			wantInURL: "models/test-model:streamGenerateContent",
+			wantBody:  []string{`"hello there"`},
+		},
+		{
+			name:     "ollama chat stream",
+			sdkType:  spec.ProviderSDKTypeOllamaChat,
+			stream:   true,
+			wantPath: spec.DefaultOllamaChatPrefix,
+			wantBody: []string{`"model":"test-model"`, `"stream":true`, `"hello there"`},
+		},
+		{
+			name:     "bedrock converse stream",
+			sdkType:  spec.ProviderSDKTypeBedrockConverse,
+			stream:   true,
+			wantPath: "/model/test-model/converse-stream",
+			wantBody: []string{`"role":"user"`, `"hello there"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := NewProviderSetAPI()
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+				SDKType:                  tt.sdkType,
+				Origin:                   srv.URL,
+				ChatCompletionPathPrefix: tt.prefix,
+			}); err != nil {
+				t.Fatalf("AddProvider: %v", err)
+			}
+			if err := ps.SetProviderAPIKey(t.Context(), "p", apiKey); err != nil {
+				t.Fatalf("SetProviderAPIKey: %v", err)
+			}
+
+			req := retryTestRequest()
+			req.ModelParam.Name = "test-model"
+			req.ModelParam.Stream = tt.stream
+			req.Inputs[0].InputMessage.Contents[0].TextItem.Text = "hello there"
+
+			got, err := ps.CompileRequest(t.Context(), "p", req, nil)
+			if err != nil {
+				t.Fatalf("CompileRequest: %v", err)
+			}
+			if hits.Load() != 0 {
+				t.Fatalf("server was called %d times", hits.Load())
+			}
+
+			if got.Method != http.MethodPost {
+				t.Errorf("method = %q", got.Method)
+			}
+			if !strings.HasPrefix(got.URL, srv.URL) {
+				t.Errorf("url = %q, want prefix %q", got.URL, srv.URL)
+			}
+			if tt.wantPath != "" && !strings.HasSuffix(strings.SplitN(got.URL, "?", 2)[0], tt.wantPath) {
+				t.Errorf("url = %q, want path %q", got.URL, tt.wantPath)
+			}
+			if tt.wantInURL != "" && !strings.Contains(got.URL, tt.wantInURL) {
+				t.Errorf("url = %q, want %q", got.URL, tt.wantInURL)
+			}
+			if !json.Valid(got.Body) {
+				t.Fatalf("body is not JSON: %s", got.Body)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(got.Body), want) {
+					t.Errorf("body %s missing %s", got.Body, want)
+				}
+			}
+			for k, vals := range got.Headers {
+				for _, v := range vals {
+					if strings.Contains(v, apiKey) {
+						t.Errorf("header %s leaks the API key: %q", k, v)
+					}
+				}
+			}
+			if strings.Contains(got.URL, apiKey) {
+				t.Errorf("url leaks the API key: %q", got.URL)
+			}
+			if got.EffectiveCapabilities == nil {
+				t.Error("EffectiveCapabilities = nil")
+			}
+		})
+	}
+}
+
+func TestCompileRequestAdditionalParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdkType  spec.ProviderSDKType
+		prefix   string
+		raw      string
+		wantBody []string
+	}{
+		{
+			name:     "anthropic",
+			sdkType:  spec.ProviderSDKTypeAnthropic,
+			prefix:   spec.DefaultAnthropicChatCompletionPrefix,
+			raw:      `{"model":"other","max_tokens":64,"top_k":5}`,
+			wantBody: []string{`"model":"test-model"`, `"max_tokens":64`, `"top_k":5`},
+		},
+		{
+			name:     "openai chat",
+			sdkType:  spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:   spec.DefaultOpenAIChatCompletionsPrefix,
+			raw:      `{"model":"other","max_completion_tokens":64,"seed":7}`,
+			wantBody: []string{`"model":"test-model"`, `"max_completion_tokens":64`, `"seed":7`},
+		},
+		{
+			name:     "openai responses",
+			sdkType:  spec.ProviderSDKTypeOpenAIResponses,
+			prefix:   spec.DefaultOpenAIResponsesPrefix,
+			raw:      `{"model":"other","max_output_tokens":64,"service_tier":"flex"}`,
+			wantBody: []string{`"model":"test-model"`, `"max_output_tokens":64`, `"service_tier":"flex"`},
+		},
+		{
+			name:    "google",
+			sdkType: spec.ProviderSDKTypeGoogleGenerateContent,
+			prefix:  spec.DefaultGoogleGenerateContentPrefix,
+			raw: `{"systemInstruction":{"parts":[{"text":"other"}]},` +
+				`"toolConfig":{"functionCallingConfig":{"mode":"NONE"}},` +
+				`"generationConfig":{"maxOutputTokens":64,"seed":7}}`,
+			wantBody: []string{`"maxOutputTokens":64`, `"seed":7`, `"hello there"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := NewProviderSetAPI()
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+				SDKType:                  tt.sdkType,
+				Origin:                   "http://127.0.0.1:0",
+				ChatCompletionPathPrefix: tt.prefix,
+			}); err != nil {
+				t.Fatalf("AddProvider: %v", err)
+			}
+			if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+				t.Fatalf("SetProviderAPIKey: %v", err)
+			}
+
+			req := retryTestRequest()
+			req.ModelParam.Name = "test-model"
+			req.ModelParam.MaxOutputLength = 100
+			req.ModelParam.AdditionalParametersRawJSON = &tt.raw
+			req.Inputs[0].InputMessage.Contents[0].TextItem.Text = "hello there"
+
+			got, err := ps.CompileRequest(t.Context(), "p", req, nil)
+			if err != nil {
+				t.Fatalf("CompileRequest: %v", err)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(got.Body), want) {
+					t.Errorf("body %s missing %s", got.Body, want)
+				}
+			}
+			if strings.Contains(string(got.Body), `"other"`) || strings.Contains(string(got.Body), `"NONE"`) {
+				t.Errorf("body %s carries an owned key", got.Body)
+			}
+
+			codes := map[string]bool{}
+			for _, w := range got.Warnings {
+				codes[w.Code] = true
+			}
+			if !codes["additional_parameter_owned_key_dropped"] || !codes["additional_parameter_overrides_value"] {
+				t.Errorf("warnings = %+v, want the dropped model and the overridden token limit", got.Warnings)
+			}
+		})
+	}
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		ps, err := NewProviderSetAPI()
+		if err != nil {
+			t.Fatalf("NewProviderSetAPI: %v", err)
+		}
+		if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+			SDKType: spec.ProviderSDKTypeGoogleGenerateContent,
+			Origin:  "http://127.0.0.1:0",
+		}); err != nil {
+			t.Fatalf("AddProvider: %v", err)
+		}
+		if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+			t.Fatalf("SetProviderAPIKey: %v", err)
+		}
+
+		req := retryTestRequest()
+		raw := `["not an object"]`
+		req.ModelParam.AdditionalParametersRawJSON = &raw
+		if _, err := ps.CompileRequest(t.Context(), "p", req, nil); err == nil {
+			t.Fatal("expected an error for a non-object additionalParametersRawJSON")
+		}
+	})
+}
+
+func TestCompileRequestSamplingParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdkType  spec.ProviderSDKType
+		prefix   string
+		wantBody []string
+		wantWarn string
+	}{
+		{
+			name:     "anthropic",
+			sdkType:  spec.ProviderSDKTypeAnthropic,
+			prefix:   spec.DefaultAnthropicChatCompletionPrefix,
+			wantBody: []string{`"top_p":0.9`, `"top_k":40`},
+			wantWarn: "seed_dropped_unsupported",
+		},
+		{
+			name:    "openai chat",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  spec.DefaultOpenAIChatCompletionsPrefix,
+			wantBody: []string{
+				`"top_p":0.9`, `"seed":7`, `"presence_penalty":0.5`, `"frequency_penalty":0.25`,
+				`"logit_bias":{"50256":-100}`, `"n":2`, `"logprobs":true`, `"top_logprobs":3`,
+			},
+			wantWarn: "topK_dropped_unsupported",
+		},
+		{
+			name:     "openai responses",
+			sdkType:  spec.ProviderSDKTypeOpenAIResponses,
+			prefix:   spec.DefaultOpenAIResponsesPrefix,
+			wantBody: []string{`"top_p":0.9`, `"message.output_text.logprobs"`, `"top_logprobs":3`},
+			wantWarn: "candidates_dropped_unsupported",
+		},
+		{
+			name:    "google",
+			sdkType: spec.ProviderSDKTypeGoogleGenerateContent,
+			prefix:  spec.DefaultGoogleGenerateContentPrefix,
+			wantBody: []string{
+				`"topP":0.9`, `"topK":40`, `"seed":7`, `"presencePenalty":0.5`, `"frequencyPenalty":0.25`,
+				`"candidateCount":2`, `"responseLogprobs":true`, `"logprobs":3`,
+			},
+			wantWarn: "logitBias_dropped_unsupported",
+		},
+		{
+			name:     "ollama chat",
+			sdkType:  spec.ProviderSDKTypeOllamaChat,
+			wantBody: []string{`"top_p":0.9`, `"top_k":40`, `"seed":7`, `"presence_penalty":0.5`},
+			wantWarn: "logitBias_dropped_unsupported",
+		},
+		{
+			name:     "bedrock converse",
+			sdkType:  spec.ProviderSDKTypeBedrockConverse,
+			wantBody: []string{`"topP":0.9`},
+			wantWarn: "topK_dropped_unsupported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := NewProviderSetAPI()
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+				SDKType:                  tt.sdkType,
+				Origin:                   "http://127.0.0.1:0",
+				ChatCompletionPathPrefix: tt.prefix,
+			}); err != nil {
+				t.Fatalf("AddProvider: %v", err)
+			}
+			if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+				t.Fatalf("SetProviderAPIKey: %v", err)
+			}
+
+			req := retryTestRequest()
+			req.ModelParam.TopP = new(0.9)
+			req.ModelParam.TopK = new(40)
+			req.ModelParam.Seed = new(int64(7))
+			req.ModelParam.PresencePenalty = new(0.5)
+			req.ModelParam.FrequencyPenalty = new(0.25)
+			req.ModelParam.LogitBias = map[string]int{"50256": -100}
+			req.ModelParam.Candidates = 2
+			req.ModelParam.Logprobs = &spec.LogprobsParam{TopAlternatives: 3}
+
+			got, err := ps.CompileRequest(t.Context(), "p", req, nil)
+			if err != nil {
+				t.Fatalf("CompileRequest: %v", err)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(got.Body), want) {
+					t.Errorf("body %s missing %s", got.Body, want)
+				}
+			}
+			var warned bool
+			for _, w := range got.Warnings {
+				warned = warned || w.Code == tt.wantWarn
+			}
+			if !warned {
+				t.Errorf("warnings = %+v, want %s", got.Warnings, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestCompileRequestMediaContent(t *testing.T) {
+	audio := spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindAudio,
+		AudioItem: &spec.ContentItemAudio{AudioMIME: "audio/wav", AudioData: "UklGRg=="},
+	}
+	video := spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindVideo,
+		VideoItem: &spec.ContentItemVideo{VideoURL: "gs://bucket/clip.mp4"},
+	}
+	audioURL := spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindAudio,
+		AudioItem: &spec.ContentItemAudio{AudioMIME: "audio/wav", AudioURL: "https://example.com/a.wav"},
+	}
+	// OpenAI SDKs leave audio to the presets of models that support it.
+	audioInOut := &capabilityoverride.ModelCapabilitiesOverride{
+		ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityAudioIn},
+		ModalitiesOut: []spec.Modality{spec.ModalityTextOut, spec.ModalityAudioOut},
+	}
+	audioIn := &capabilityoverride.ModelCapabilitiesOverride{
+		ModalitiesIn: []spec.Modality{spec.ModalityTextIn, spec.ModalityAudioIn},
+	}
+
+	tests := []struct {
+		name     string
+		sdkType  spec.ProviderSDKType
+		prefix   string
+		override *capabilityoverride.ModelCapabilitiesOverride
+		items    []spec.InputOutputContentItemUnion
+		strict   bool
+		wantBody []string
+		wantWarn string
+		wantErr  string
+	}{
+		{
+			name:     "openai chat",
+			sdkType:  spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:   spec.DefaultOpenAIChatCompletionsPrefix,
+			override: audioInOut,
+			items:    []spec.InputOutputContentItemUnion{audio},
+			wantBody: []string{
+				`"input_audio":{"data":"UklGRg==","format":"wav"}`,
+				`"modalities":["text","audio"]`,
+				`"audio":{"format":"wav","voice":"alloy"}`,
+			},
+		},
+		{
+			name:     "openai chat audio url",
+			sdkType:  spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:   spec.DefaultOpenAIChatCompletionsPrefix,
+			override: audioInOut,
+			items:    []spec.InputOutputContentItemUnion{audioURL},
+			wantWarn: "audio_input_dropped_not_inline",
+		},
+		{
+			name:     "openai chat audio url strict",
+			sdkType:  spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:   spec.DefaultOpenAIChatCompletionsPrefix,
+			override: audioInOut,
+			items:    []spec.InputOutputContentItemUnion{audioURL},
+			strict:   true,
+			wantErr:  "inline audioData",
+		},
+		{
+			name:    "openai chat without audio override",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  spec.DefaultOpenAIChatCompletionsPrefix,
+			items:   []spec.InputOutputContentItemUnion{audio},
+			wantErr: "modality",
+		},
+		{
+			name:     "openai responses",
+			sdkType:  spec.ProviderSDKTypeOpenAIResponses,
+			prefix:   spec.DefaultOpenAIResponsesPrefix,
+			override: audioIn,
+			items:    []spec.InputOutputContentItemUnion{audio},
+			wantBody: []string{`"input_audio":{"data":"UklGRg==","format":"wav"},"type":"input_audio"`},
+			wantWarn: "audio_output_dropped_unsupported",
+		},
+		{
+			name:     "openai responses audio url",
+			sdkType:  spec.ProviderSDKTypeOpenAIResponses,
+			prefix:   spec.DefaultOpenAIResponsesPrefix,
+			override: audioIn,
+			items:    []spec.InputOutputContentItemUnion{audioURL},
+			wantWarn: "audio_input_dropped_not_inline",
+		},
+		{
+			name:    "google",
+			sdkType: spec.ProviderSDKTypeGoogleGenerateContent,
+			prefix:  spec.DefaultGoogleGenerateContentPrefix,
+			items:   []spec.InputOutputContentItemUnion{audio, video},
+			wantBody: []string{
+				`"inlineData":{"data":"UklGRg==","mimeType":"audio/wav"}`,
+				`"fileData":{"fileUri":"gs://bucket/clip.mp4","mimeType":"video/mp4"}`,
+				`"responseModalities":["AUDIO"]`,
+			},
+		},
+		{
+			name:    "openai chat video",
+			sdkType: spec.ProviderSDKTypeOpenAIChatCompletions,
+			prefix:  spec.DefaultOpenAIChatCompletionsPrefix,
+			items:   []spec.InputOutputContentItemUnion{video},
+			wantErr: "modality",
+		},
+		{
+			name:    "anthropic audio",
+			sdkType: spec.ProviderSDKTypeAnthropic,
+			prefix:  spec.DefaultAnthropicChatCompletionPrefix,
+			items:   []spec.InputOutputContentItemUnion{audio},
+			wantErr: "modality",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, err := NewProviderSetAPI()
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+				SDKType:                  tt.sdkType,
+				Origin:                   "http://127.0.0.1:0",
+				ChatCompletionPathPrefix: tt.prefix,
+			}); err != nil {
+				t.Fatalf("AddProvider: %v", err)
+			}
+			if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+				t.Fatalf("SetProviderAPIKey: %v", err)
+			}
+
+			req := retryTestRequest()
+			msg := req.Inputs[0].InputMessage
+			msg.Contents = append(msg.Contents, tt.items...)
+			req.ModelParam.OutputParam = &spec.OutputParam{Audio: &spec.AudioOutputParam{}}
+
+			opts := &spec.FetchCompletionOptions{}
+			if tt.override != nil {
+				base, err := ps.GetProviderCapability(t.Context(), "p")
+				if err != nil {
+					t.Fatalf("GetProviderCapability: %v", err)
+				}
+				caps := capabilityoverride.DeriveModelCapabilities(base, tt.override)
+				opts.CapabilityResolver = capabilityoverride.NewCompletionKeyResolver("", &caps)
+			}
+			if tt.strict {
+				opts.NormalizationMode = spec.NormalizationModeStrict
+			}
+
+			got, err := ps.CompileRequest(t.Context(), "p", req, opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompileRequest: %v", err)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(string(got.Body), want) {
+					t.Errorf("body %s missing %s", got.Body, want)
+				}
+			}
+			if tt.wantWarn != "" && !slices.ContainsFunc(got.Warnings, func(w spec.Warning) bool {
+				return w.Code == tt.wantWarn
+			}) {
+				t.Errorf("warnings = %+v, want %s", got.Warnings, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestCompileRequestStrictNormalization(t *testing.T) {
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeOpenAIResponses,
+		Origin:  "http://127.0.0.1:0",
+	}); err != nil {
+		t.Fatalf("AddProvider: %v", err)
+	}
+	if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+		t.Fatalf("SetProviderAPIKey: %v", err)
+	}
+
+	req := retryTestRequest()
+	req.ModelParam.StopSequences = []string{"stop"}
+
+	_, err = ps.CompileRequest(t.Context(), "p", req, &spec.FetchCompletionOptions{
+		NormalizationMode: spec.NormalizationModeStrict,
+	})
+	var cve *spec.CapabilityValidationError
+	if !errors.As(err, &cve) {
+		t.Fatalf("err = %v, want *spec.CapabilityValidationError", err)
+	}
+
+	got, err := ps.CompileRequest(t.Context(), "p", req, nil)
+	if err != nil {
+		t.Fatalf("CompileRequest: %v", err)
+	}
+	if len(got.Warnings) == 0 || got.Warnings[0].Code != "stopSequences_dropped_unsupported" {
+		t.Fatalf("warnings = %+v", got.Warnings)
+	}
+}
+
+// systemPromptInterceptor is a debugger that only rewrites the system prompt.
+type systemPromptInterceptor struct{ prompt string }
+
+func (systemPromptInterceptor) HTTPClient(*http.Client) *http.Client { return nil }
+
+func (systemPromptInterceptor) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	return ctx, nil
+}
+
+func (i systemPromptInterceptor) InterceptRequest(
+	_ context.Context,
+	req *spec.FetchCompletionRequest,
+	_ *spec.FetchCompletionOptions,
+) error {
+	req.ModelParam.SystemPrompt = i.prompt
+	return nil
+}
+
+func TestCompileRequestRunsRequestInterceptor(t *testing.T) {
+	ps, err := NewProviderSetAPI(WithDebugClientBuilder(func(spec.ProviderParam) spec.CompletionDebugger {
+		return systemPromptInterceptor{prompt: "intercepted prompt"}
+	}))
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+		SDKType: spec.ProviderSDKTypeAnthropic,
+		Origin:  "http://127.0.0.1:0",
+	}); err != nil {
+		t.Fatalf("AddProvider: %v", err)
+	}
+	if err := ps.SetProviderAPIKey(t.Context(), "p", "key"); err != nil {
+		t.Fatalf("SetProviderAPIKey: %v", err)
+	}
+
+	got, err := ps.CompileRequest(t.Context(), "p", retryTestRequest(), nil)
+	if err != nil {
+		t.Fatalf("CompileRequest: %v", err)
+	}
+	if !strings.Contains(string(got.Body), "intercepted prompt") {
+		t.Errorf("body %s does not carry the intercepted system prompt", got.Body)
+	}
+}
+
+// headerTransportDebugger is a debugger whose HTTP client adds a header to every request.
+type headerTransportDebugger struct{}
+
+func (headerTransportDebugger) HTTPClient(base *http.Client) *http.Client {
+	clone := *base
+	next := base.Transport
+	clone.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Header.Set("X-Debug-Transport", "on")
+		return next.RoundTrip(r)
+	})
+	return &clone
+}
+
+func (headerTransportDebugger) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	return ctx, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestCompileRequestWithoutAPIKeyThroughDebugTransport(t *testing.T) {
+	for _, sdkType := range []spec.ProviderSDKType{
+		spec.ProviderSDKTypeAnthropic,
+		spec.ProviderSDKTypeOpenAIChatCompletions,
+		spec.ProviderSDKTypeOpenAIResponses,
+		spec.ProviderSDKTypeGoogleGenerateContent,
+		spec.ProviderSDKTypeBedrockConverse,
+		spec.ProviderSDKTypeOllamaChat,
+	} {
+		t.Run(string(sdkType), func(t *testing.T) {
+			ps, err := NewProviderSetAPI(WithDebugClientBuilder(func(spec.ProviderParam) spec.CompletionDebugger {
+				return headerTransportDebugger{}
+			}))
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			if _, err := ps.AddProvider(t.Context(), "p", &AddProviderConfig{
+				SDKType: sdkType,
+				Origin:  "http://127.0.0.1:0",
+			}); err != nil {
+				t.Fatalf("AddProvider: %v", err)
+			}
+
+			got, err := ps.CompileRequest(t.Context(), "p", retryTestRequest(), nil)
+			if err != nil {
+				t.Fatalf("CompileRequest without an API key: %v", err)
+			}
+			if v := got.Headers.Get("X-Debug-Transport"); v != "on" {
+				t.Errorf("X-Debug-Transport = %q, want the header added by the debugger transport", v)
+			}
+			for k, vals := range got.Headers {
+				for _, v := range vals {
+					if strings.Contains(v, sdkutil.CompileAPIKey) {
+						t.Errorf("header %s leaks the placeholder key: %q", k, v)
+					}
+				}
+			}
+			if strings.Contains(got.URL, sdkutil.CompileAPIKey) {
+				t.Errorf("url leaks the placeholder key: %q", got.URL)
+			}
+		})
+	}
+}
+
+func TestCompileRequestUnsupportedProvider(t *testing.T) {
+	ps := newScriptedProviderSet(t, &scriptedProvider{})
+	if _, err := ps.CompileRequest(t.Context(), "scripted", retryTestRequest(), nil); err == nil {
+		t.Fatal("expected error for a provider without request compilation")
+	}
+}
@@ -0,0 +1,128 @@
+package contextstrategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// wordTokenizer counts whitespace separated words.
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func budget(maxTokens int) spec.ContextBudget {
+	return spec.ContextBudget{MaxTokens: maxTokens, Tokenizer: wordTokenizer{}}
+}
+
+func userMsg(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role:     spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{textItem(text)},
+		},
+	}
+}
+
+func assistantMsg(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{
+			Role:     spec.RoleAssistant,
+			Contents: []spec.InputOutputContentItemUnion{textItem(text)},
+		},
+	}
+}
+
+func reasoning(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind:             spec.InputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{Thinking: []string{text}, Signature: "sig"},
+	}
+}
+
+func toolCall(id, args string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type:      spec.ToolTypeFunction,
+			CallID:    id,
+			Name:      "fn",
+			Arguments: args,
+		},
+	}
+}
+
+func toolOutput(id, text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolOutput,
+		FunctionToolOutput: &spec.ToolOutput{
+			Type:   spec.ToolTypeFunction,
+			CallID: id,
+			Name:   "fn",
+			Contents: []spec.ToolOutputItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}
+}
+
+func textItem(text string) spec.InputOutputContentItemUnion {
+	return spec.InputOutputContentItemUnion{
+		Kind:     spec.ContentItemKindText,
+		TextItem: &spec.ContentItemText{Text: text},
+	}
+}
+
+func sticky(in spec.InputUnion) spec.InputUnion {
+	in.Sticky = true
+	return in
+}
+
+// describe renders inputs compactly, e.g. "u:a b|a:c|call:1|out:1".
+func describe(inputs []spec.InputUnion) string {
+	parts := make([]string, 0, len(inputs))
+	for _, in := range inputs {
+		switch in.Kind {
+		case spec.InputKindInputMessage:
+			parts = append(parts, "u:"+in.InputMessage.Contents[0].TextItem.Text)
+		case spec.InputKindOutputMessage:
+			parts = append(parts, "a:"+in.OutputMessage.Contents[0].TextItem.Text)
+		case spec.InputKindReasoningMessage:
+			parts = append(parts, "r:"+in.ReasoningMessage.Thinking[0])
+		case spec.InputKindFunctionToolCall:
+			parts = append(parts, "call:"+in.FunctionToolCall.CallID)
+		case spec.InputKindFunctionToolOutput:
+			parts = append(parts, "out:"+in.FunctionToolOutput.CallID)
+		default:
+			parts = append(parts, string(in.Kind))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+func warningCodes(warns []spec.Warning) string {
+	codes := make([]string, 0, len(warns))
+	for _, w := range warns {
+		codes = append(codes, w.Code)
+	}
+	return strings.Join(codes, ",")
+}
+
+func fit(
+	t *testing.T,
+	s spec.ContextStrategy,
+	inputs []spec.InputUnion,
+	maxTokens int,
+) ([]spec.InputUnion, []spec.Warning) {
+	t.Helper()
+
+	out, warns, err := s.FitInputs(t.Context(), inputs, budget(maxTokens))
+	if err != nil {
+		t.Fatalf("FitInputs: %v", err)
+	}
+	return out, warns
+}
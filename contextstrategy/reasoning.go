@@ -0,0 +1,66 @@
+package contextstrategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DropReasoningFirst drops reasoning inputs oldest first until the inputs fit.
+//
+// Reasoning after the last input message is kept: providers such as Anthropic
+// require the signed reasoning of an in-progress tool loop to be sent back
+// unchanged, while reasoning of earlier turns is optional.
+func DropReasoningFirst() spec.ContextStrategy {
+	return Func(func(
+		_ context.Context,
+		inputs []spec.InputUnion,
+		budget spec.ContextBudget,
+	) ([]spec.InputUnion, []spec.Warning, error) {
+		total := countTokens(inputs, budget.Tokenizer)
+		if total <= budget.MaxTokens {
+			return inputs, nil, nil
+		}
+
+		protectFrom := len(inputs)
+		for i := len(inputs) - 1; i >= 0; i-- {
+			if inputs[i].Kind == spec.InputKindInputMessage {
+				protectFrom = i
+				break
+			}
+		}
+
+		drop := make([]bool, len(inputs))
+		var dropped []int
+		for i := 0; i < protectFrom && total > budget.MaxTokens; i++ {
+			in := inputs[i]
+			if in.Kind != spec.InputKindReasoningMessage || in.Sticky {
+				continue
+			}
+			drop[i] = true
+			dropped = append(dropped, i)
+			total -= sdkutil.CountInputTokens(in, budget.Tokenizer)
+		}
+		if len(dropped) == 0 {
+			return inputs, appendOverBudget(nil, inputs, budget), nil
+		}
+
+		out := make([]spec.InputUnion, 0, len(inputs)-len(dropped))
+		for i, in := range inputs {
+			if !drop[i] {
+				out = append(out, in)
+			}
+		}
+		warns := []spec.Warning{{
+			Code: WarningCodeReasoningDropped,
+			Message: fmt.Sprintf(
+				"dropped reasoning %s of %d to fit the context window",
+				formatIndexes(dropped),
+				len(inputs),
+			),
+		}}
+		return out, appendOverBudget(warns, out, budget), nil
+	})
+}
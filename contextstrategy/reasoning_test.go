@@ -0,0 +1,62 @@
+package contextstrategy
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestDropReasoningFirst(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{
+		userMsg("q1"),
+		reasoning("old thinking one"),
+		assistantMsg("a1"),
+		userMsg("q2"),
+		reasoning("older thinking two"),
+		toolCall("1", "{}"),
+		toolOutput("1", "x"),
+		userMsg("q3"),
+		reasoning("current loop thinking"),
+		toolCall("2", "{}"),
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      string
+		wantWarns string
+	}{
+		{
+			name:      "drops the oldest reasoning only as needed",
+			maxTokens: 15,
+			want:      "u:q1|a:a1|u:q2|r:older thinking two|call:1|out:1|u:q3|r:current loop thinking|call:2",
+			wantWarns: WarningCodeReasoningDropped,
+		},
+		{
+			name:      "keeps reasoning after the last input message",
+			maxTokens: 1,
+			want:      "u:q1|a:a1|u:q2|call:1|out:1|u:q3|r:current loop thinking|call:2",
+			wantWarns: WarningCodeReasoningDropped + "," + WarningCodeOverBudget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, warns := fit(t, DropReasoningFirst(), inputs, tt.maxTokens)
+			if got := describe(out); got != tt.want {
+				t.Fatalf("inputs:\n got %s\nwant %s", got, tt.want)
+			}
+			if got := warningCodes(warns); got != tt.wantWarns {
+				t.Fatalf("warnings: got %q want %q", got, tt.wantWarns)
+			}
+		})
+	}
+
+	if len(inputs) != 10 || inputs[1].Kind != spec.InputKindReasoningMessage {
+		t.Fatal("inputs were modified")
+	}
+}
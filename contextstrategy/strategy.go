@@ -0,0 +1,213 @@
+// Package contextstrategy provides spec.ContextStrategy implementations that
+// fit request inputs into ModelParam.MaxPromptLength tokens.
+//
+// Strategies work on turns rather than single inputs. A turn is either an
+// input message, or everything between two input messages: assistant output,
+// reasoning, tool calls and their tool outputs. Dropping whole turns keeps
+// tool call/output pairs and signed reasoning sequences valid. Turns holding
+// a sticky input are never dropped or summarized.
+package contextstrategy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Warning codes reported by the strategies in this package.
+const (
+	WarningCodeInputsDropped       = "context_inputs_dropped"
+	WarningCodeReasoningDropped    = "context_reasoning_dropped"
+	WarningCodeToolOutputTruncated = "context_tool_output_truncated"
+	WarningCodeInputsSummarized    = "context_inputs_summarized"
+	WarningCodeOverBudget          = "context_over_budget"
+)
+
+// Func adapts a function to spec.ContextStrategy.
+type Func func(
+	ctx context.Context,
+	inputs []spec.InputUnion,
+	budget spec.ContextBudget,
+) ([]spec.InputUnion, []spec.Warning, error)
+
+func (f Func) FitInputs(
+	ctx context.Context,
+	inputs []spec.InputUnion,
+	budget spec.ContextBudget,
+) ([]spec.InputUnion, []spec.Warning, error) {
+	return f(ctx, inputs, budget)
+}
+
+// NewestFirst keeps the newest turns that fit, always keeping at least the
+// last one. It is the default strategy.
+func NewestFirst() spec.ContextStrategy {
+	return KeepFirstLast(0, 1)
+}
+
+// KeepFirstLast drops turns oldest first until the inputs fit, but never drops
+// the first `first` or the last `last` turns.
+func KeepFirstLast(first, last int) spec.ContextStrategy {
+	first, last = max(first, 0), max(last, 0)
+	return Func(func(
+		_ context.Context,
+		inputs []spec.InputUnion,
+		budget spec.ContextBudget,
+	) ([]spec.InputUnion, []spec.Warning, error) {
+		turns := splitTurns(inputs, budget.Tokenizer)
+		total := sumTokens(turns)
+		if total <= budget.MaxTokens {
+			return inputs, nil, nil
+		}
+
+		drop := make([]bool, len(turns))
+		for i := first; i < len(turns)-last && total > budget.MaxTokens; i++ {
+			if turns[i].sticky {
+				continue
+			}
+			drop[i] = true
+			total -= turns[i].tokens
+		}
+
+		out, warns := dropTurns(inputs, turns, drop)
+		return out, appendOverBudget(warns, out, budget), nil
+	})
+}
+
+// Chain applies strategies in order until the inputs fit.
+func Chain(strategies ...spec.ContextStrategy) spec.ContextStrategy {
+	return Func(func(
+		ctx context.Context,
+		inputs []spec.InputUnion,
+		budget spec.ContextBudget,
+	) ([]spec.InputUnion, []spec.Warning, error) {
+		var warns []spec.Warning
+		for _, s := range strategies {
+			if s == nil {
+				continue
+			}
+			if countTokens(inputs, budget.Tokenizer) <= budget.MaxTokens {
+				break
+			}
+			out, w, err := s.FitInputs(ctx, inputs, budget)
+			if err != nil {
+				return nil, nil, err
+			}
+			inputs = out
+			// Only the last strategy's over budget report is meaningful.
+			warns = append(withoutOverBudget(warns), w...)
+		}
+		return inputs, warns, nil
+	})
+}
+
+// turn is the half-open range [start, end) of inputs.
+type turn struct {
+	start, end int
+	tokens     int
+	sticky     bool
+}
+
+func splitTurns(inputs []spec.InputUnion, tok spec.Tokenizer) []turn {
+	var turns []turn
+	for i, in := range inputs {
+		if in.Kind == spec.InputKindInputMessage || len(turns) == 0 ||
+			inputs[i-1].Kind == spec.InputKindInputMessage {
+			turns = append(turns, turn{start: i})
+		}
+		t := &turns[len(turns)-1]
+		t.end = i + 1
+		t.tokens += sdkutil.CountInputTokens(in, tok)
+		t.sticky = t.sticky || in.Sticky
+	}
+	return turns
+}
+
+func sumTokens(turns []turn) int {
+	total := 0
+	for _, t := range turns {
+		total += t.tokens
+	}
+	return total
+}
+
+func countTokens(inputs []spec.InputUnion, tok spec.Tokenizer) int {
+	total := 0
+	for _, in := range inputs {
+		total += sdkutil.CountInputTokens(in, tok)
+	}
+	return total
+}
+
+// dropTurns removes the marked turns, prunes tool outputs left without their
+// call and reports the removed input indexes.
+func dropTurns(inputs []spec.InputUnion, turns []turn, drop []bool) ([]spec.InputUnion, []spec.Warning) {
+	out := make([]spec.InputUnion, 0, len(inputs))
+	var dropped []int
+	for i, t := range turns {
+		if drop[i] {
+			for j := t.start; j < t.end; j++ {
+				dropped = append(dropped, j)
+			}
+			continue
+		}
+		out = append(out, inputs[t.start:t.end]...)
+	}
+	if len(dropped) == 0 {
+		return inputs, nil
+	}
+
+	kept := len(out)
+	out = sdkutil.PruneOrphanToolOutputs(out)
+	msg := fmt.Sprintf("dropped %s of %d to fit the context window", formatIndexes(dropped), len(inputs))
+	if pruned := kept - len(out); pruned > 0 {
+		msg += fmt.Sprintf(", and %d tool outputs left without their call", pruned)
+	}
+	return out, []spec.Warning{{Code: WarningCodeInputsDropped, Message: msg}}
+}
+
+func appendOverBudget(warns []spec.Warning, inputs []spec.InputUnion, budget spec.ContextBudget) []spec.Warning {
+	total := countTokens(inputs, budget.Tokenizer)
+	if total <= budget.MaxTokens {
+		return warns
+	}
+	return append(warns, spec.Warning{
+		Code:    WarningCodeOverBudget,
+		Message: fmt.Sprintf("inputs use about %d tokens, over the %d token budget", total, budget.MaxTokens),
+	})
+}
+
+func withoutOverBudget(warns []spec.Warning) []spec.Warning {
+	out := warns[:0]
+	for _, w := range warns {
+		if w.Code != WarningCodeOverBudget {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// formatIndexes renders sorted input indexes as ranges, e.g. "inputs 0-3, 7".
+func formatIndexes(idx []int) string {
+	var b strings.Builder
+	b.WriteString("inputs ")
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && idx[j+1] == idx[j]+1 {
+			j++
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Itoa(idx[i]))
+		if j > i {
+			b.WriteString("-")
+			b.WriteString(strconv.Itoa(idx[j]))
+		}
+		i = j + 1
+	}
+	return b.String()
+}
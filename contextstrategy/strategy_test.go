@@ -0,0 +1,173 @@
+package contextstrategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestKeepFirstLast(t *testing.T) {
+	t.Parallel()
+
+	// Turns: [u:one] [a:two] [u:three four] [call:1 out:1] [u:five].
+	conversation := func() []spec.InputUnion {
+		return []spec.InputUnion{
+			userMsg("one"),
+			assistantMsg("two"),
+			userMsg("three four"),
+			toolCall("1", `{}`),
+			toolOutput("1", "result words here"),
+			userMsg("five"),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		strategy  spec.ContextStrategy
+		inputs    []spec.InputUnion
+		maxTokens int
+		want      string
+		wantWarns string
+	}{
+		{
+			name:      "fits unchanged",
+			strategy:  NewestFirst(),
+			inputs:    conversation(),
+			maxTokens: 100,
+			want:      "u:one|a:two|u:three four|call:1|out:1|u:five",
+		},
+		{
+			name:      "newest first drops whole turns",
+			strategy:  NewestFirst(),
+			inputs:    conversation(),
+			maxTokens: 6,
+			want:      "call:1|out:1|u:five",
+			wantWarns: WarningCodeInputsDropped,
+		},
+		{
+			name:      "tool call and output are dropped together",
+			strategy:  NewestFirst(),
+			inputs:    conversation(),
+			maxTokens: 2,
+			want:      "u:five",
+			wantWarns: WarningCodeInputsDropped,
+		},
+		{
+			name:      "keeps the first turn",
+			strategy:  KeepFirstLast(1, 1),
+			inputs:    conversation(),
+			maxTokens: 7,
+			want:      "u:one|call:1|out:1|u:five",
+			wantWarns: WarningCodeInputsDropped,
+		},
+		{
+			name:      "reports when the kept turns do not fit",
+			strategy:  KeepFirstLast(1, 2),
+			inputs:    conversation(),
+			maxTokens: 2,
+			want:      "u:one|call:1|out:1|u:five",
+			wantWarns: WarningCodeInputsDropped + "," + WarningCodeOverBudget,
+		},
+		{
+			name:     "never drops sticky turns",
+			strategy: NewestFirst(),
+			inputs: []spec.InputUnion{
+				userMsg("a b c"),
+				sticky(userMsg("pinned")),
+				assistantMsg("d e f"),
+				userMsg("g"),
+			},
+			maxTokens: 2,
+			want:      "u:pinned|u:g",
+			wantWarns: WarningCodeInputsDropped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, warns := fit(t, tt.strategy, tt.inputs, tt.maxTokens)
+			if got := describe(out); got != tt.want {
+				t.Fatalf("inputs:\n got %s\nwant %s", got, tt.want)
+			}
+			if got := warningCodes(warns); got != tt.wantWarns {
+				t.Fatalf("warnings: got %q want %q (%+v)", got, tt.wantWarns, warns)
+			}
+		})
+	}
+}
+
+func TestDroppedWarningListsIndexes(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{userMsg("a b"), assistantMsg("c d"), userMsg("e f"), userMsg("g")}
+	_, warns := fit(t, NewestFirst(), inputs, 3)
+	if len(warns) != 1 || !strings.Contains(warns[0].Message, "inputs 0-1 of 4") {
+		t.Fatalf("warnings: got %+v", warns)
+	}
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{
+		userMsg("q"),
+		reasoning("long thinking here"),
+		assistantMsg("a"),
+		userMsg("next"),
+	}
+
+	t.Run("stops once the inputs fit", func(t *testing.T) {
+		t.Parallel()
+
+		out, warns := fit(t, Chain(DropReasoningFirst(), NewestFirst()), inputs, 3)
+		if got, want := describe(out), "u:q|a:a|u:next"; got != want {
+			t.Fatalf("inputs: got %s want %s", got, want)
+		}
+		if got := warningCodes(warns); got != WarningCodeReasoningDropped {
+			t.Fatalf("warnings: got %q", got)
+		}
+	})
+
+	t.Run("keeps only the final over budget report", func(t *testing.T) {
+		t.Parallel()
+
+		out, warns := fit(t, Chain(DropReasoningFirst(), KeepFirstLast(0, 2)), inputs, 1)
+		if got, want := describe(out), "a:a|u:next"; got != want {
+			t.Fatalf("inputs: got %s want %s", got, want)
+		}
+		want := WarningCodeReasoningDropped + "," + WarningCodeInputsDropped + "," + WarningCodeOverBudget
+		if got := warningCodes(warns); got != want {
+			t.Fatalf("warnings: got %q want %q", got, want)
+		}
+	})
+}
+
+func TestSplitTurns(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{
+		reasoning("r"),
+		toolCall("1", "{}"),
+		toolOutput("1", "x"),
+		userMsg("u"),
+		userMsg("v"),
+		assistantMsg("a"),
+	}
+	turns := splitTurns(inputs, wordTokenizer{})
+	got := make([][2]int, 0, len(turns))
+	for _, tr := range turns {
+		got = append(got, [2]int{tr.start, tr.end})
+	}
+	want := [][2]int{{0, 3}, {3, 4}, {4, 5}, {5, 6}}
+	if len(got) != len(want) {
+		t.Fatalf("turns: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("turns: got %v want %v", got, want)
+		}
+	}
+}
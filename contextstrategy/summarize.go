@@ -0,0 +1,84 @@
+package contextstrategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// Summarizer condenses inputs into a single input, typically an input message
+// holding a summary produced by another completion call.
+type Summarizer func(ctx context.Context, inputs []spec.InputUnion) (spec.InputUnion, error)
+
+// SummarizeMiddle replaces the turns between the first `first` and the last
+// `last` turns with the input returned by summarize, if the inputs do not fit.
+// Sticky turns in the middle are kept in place and are not passed to summarize.
+func SummarizeMiddle(first, last int, summarize Summarizer) spec.ContextStrategy {
+	first, last = max(first, 0), max(last, 0)
+	return Func(func(
+		ctx context.Context,
+		inputs []spec.InputUnion,
+		budget spec.ContextBudget,
+	) ([]spec.InputUnion, []spec.Warning, error) {
+		if summarize == nil {
+			return nil, nil, errors.New("contextstrategy: nil summarizer")
+		}
+		turns := splitTurns(inputs, budget.Tokenizer)
+		if sumTokens(turns) <= budget.MaxTokens {
+			return inputs, nil, nil
+		}
+
+		var (
+			middle     []spec.InputUnion
+			summarized []int
+			firstTurn  = -1
+		)
+		for i := first; i < len(turns)-last; i++ {
+			t := turns[i]
+			if t.sticky {
+				continue
+			}
+			if firstTurn < 0 {
+				firstTurn = i
+			}
+			middle = append(middle, inputs[t.start:t.end]...)
+			for j := t.start; j < t.end; j++ {
+				summarized = append(summarized, j)
+			}
+		}
+		if len(middle) == 0 {
+			return inputs, appendOverBudget(nil, inputs, budget), nil
+		}
+
+		summary, err := summarize(ctx, middle)
+		if err != nil {
+			return nil, nil, fmt.Errorf("contextstrategy: summarize inputs: %w", err)
+		}
+
+		out := make([]spec.InputUnion, 0, len(inputs)-len(middle)+1)
+		for i, t := range turns {
+			switch {
+			case i == firstTurn:
+				out = append(out, summary)
+			case i >= first && i < len(turns)-last && !t.sticky:
+				// Summarized.
+			default:
+				out = append(out, inputs[t.start:t.end]...)
+			}
+		}
+		out = sdkutil.PruneOrphanToolOutputs(out)
+
+		warns := []spec.Warning{{
+			Code: WarningCodeInputsSummarized,
+			Message: fmt.Sprintf(
+				"summarized %s of %d to fit the context window",
+				formatIndexes(summarized),
+				len(inputs),
+			),
+		}}
+		return out, appendOverBudget(warns, out, budget), nil
+	})
+}
@@ -0,0 +1,54 @@
+package contextstrategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestSummarizeMiddle(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{
+		userMsg("system like intro"),
+		assistantMsg("ok"),
+		userMsg("middle question"),
+		sticky(userMsg("pinned")),
+		toolCall("1", "{}"),
+		toolOutput("1", "middle result"),
+		userMsg("latest"),
+	}
+
+	var gotMiddle []spec.InputUnion
+	summarize := func(_ context.Context, in []spec.InputUnion) (spec.InputUnion, error) {
+		gotMiddle = in
+		return userMsg("summary"), nil
+	}
+
+	out, warns := fit(t, SummarizeMiddle(1, 1, summarize), inputs, 8)
+
+	if got, want := describe(gotMiddle), "a:ok|u:middle question|call:1|out:1"; got != want {
+		t.Fatalf("summarized inputs:\n got %s\nwant %s", got, want)
+	}
+	if got, want := describe(out), "u:system like intro|u:summary|u:pinned|u:latest"; got != want {
+		t.Fatalf("inputs:\n got %s\nwant %s", got, want)
+	}
+	if got := warningCodes(warns); got != WarningCodeInputsSummarized {
+		t.Fatalf("warnings: got %q", got)
+	}
+}
+
+func TestSummarizeMiddleError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	s := SummarizeMiddle(0, 1, func(context.Context, []spec.InputUnion) (spec.InputUnion, error) {
+		return spec.InputUnion{}, boom
+	})
+	_, _, err := s.FitInputs(t.Context(), []spec.InputUnion{userMsg("a b"), userMsg("c")}, budget(1))
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected summarizer error, got %v", err)
+	}
+}
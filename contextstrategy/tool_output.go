@@ -0,0 +1,136 @@
+package contextstrategy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// TruncatedMarker is appended to text cut by TruncateToolOutputs.
+const TruncatedMarker = "\n[truncated]"
+
+// TruncateToolOutputs cuts the text of function and custom tool outputs down
+// to maxTokens tokens each, largest output first, until the inputs fit. The
+// outputs stay in place, so their tool calls remain answered.
+func TruncateToolOutputs(maxTokens int) spec.ContextStrategy {
+	maxTokens = max(maxTokens, 0)
+	return Func(func(
+		_ context.Context,
+		inputs []spec.InputUnion,
+		budget spec.ContextBudget,
+	) ([]spec.InputUnion, []spec.Warning, error) {
+		tok := sdkutil.ResolveTokenizer(budget.Tokenizer)
+		total := countTokens(inputs, tok)
+		if total <= budget.MaxTokens {
+			return inputs, nil, nil
+		}
+
+		type candidate struct {
+			index  int
+			tokens int
+		}
+		var candidates []candidate
+		for i, in := range inputs {
+			if toolOutputOf(in) == nil || in.Sticky {
+				continue
+			}
+			if n := sdkutil.CountInputTokens(in, tok); n > maxTokens {
+				candidates = append(candidates, candidate{index: i, tokens: n})
+			}
+		}
+		slices.SortStableFunc(candidates, func(a, b candidate) int { return b.tokens - a.tokens })
+
+		var out []spec.InputUnion
+		var truncated []int
+		for _, c := range candidates {
+			if total <= budget.MaxTokens {
+				break
+			}
+			if out == nil {
+				out = slices.Clone(inputs)
+			}
+			out[c.index] = truncateToolOutputInput(inputs[c.index], maxTokens, tok)
+			total -= c.tokens - sdkutil.CountInputTokens(out[c.index], tok)
+			truncated = append(truncated, c.index)
+		}
+		if len(truncated) == 0 {
+			return inputs, appendOverBudget(nil, inputs, budget), nil
+		}
+
+		slices.Sort(truncated)
+		warns := []spec.Warning{{
+			Code: WarningCodeToolOutputTruncated,
+			Message: fmt.Sprintf(
+				"truncated tool output %s to %d tokens each to fit the context window",
+				formatIndexes(truncated),
+				maxTokens,
+			),
+		}}
+		return out, appendOverBudget(warns, out, budget), nil
+	})
+}
+
+func toolOutputOf(in spec.InputUnion) *spec.ToolOutput {
+	switch in.Kind {
+	case spec.InputKindFunctionToolOutput:
+		return in.FunctionToolOutput
+	case spec.InputKindCustomToolOutput:
+		return in.CustomToolOutput
+	default:
+		return nil
+	}
+}
+
+// truncateToolOutputInput returns a copy of in whose text contents share a
+// budget of maxTokens tokens, in order.
+func truncateToolOutputInput(in spec.InputUnion, maxTokens int, tok spec.Tokenizer) spec.InputUnion {
+	orig := toolOutputOf(in)
+	to := *orig
+	to.Contents = slices.Clone(orig.Contents)
+
+	remaining := maxTokens
+	for i, it := range to.Contents {
+		if it.Kind != spec.ContentItemKindText || it.TextItem == nil {
+			continue
+		}
+		n := tok.CountTokens(it.TextItem.Text)
+		if n <= remaining {
+			remaining -= n
+			continue
+		}
+		text := *it.TextItem
+		text.Text = truncateText(text.Text, remaining, tok) + TruncatedMarker
+		to.Contents[i].TextItem = &text
+		remaining = 0
+	}
+
+	if in.Kind == spec.InputKindCustomToolOutput {
+		in.CustomToolOutput = &to
+	} else {
+		in.FunctionToolOutput = &to
+	}
+	return in
+}
+
+// truncateText returns the longest prefix of s, cut at a rune boundary, that
+// has at most maxTokens tokens.
+func truncateText(s string, maxTokens int, tok spec.Tokenizer) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if tok.CountTokens(string(runes[:mid])) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:lo]), func(r rune) bool { return r == ' ' || r == '\n' })
+}
@@ -0,0 +1,57 @@
+package contextstrategy
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestTruncateToolOutputs(t *testing.T) {
+	t.Parallel()
+
+	inputs := []spec.InputUnion{
+		userMsg("q"),
+		toolCall("1", "{}"),
+		toolOutput("1", "one two three four five six"),
+		toolCall("2", "{}"),
+		toolOutput("2", "a b c d"),
+		userMsg("next"),
+	}
+
+	// 16 tokens; cutting the first output to "one two" plus the marker saves 3.
+	out, warns := fit(t, TruncateToolOutputs(2), inputs, 13)
+
+	got := out[2].FunctionToolOutput.Contents[0].TextItem.Text
+	if want := "one two" + TruncatedMarker; got != want {
+		t.Fatalf("first output: got %q want %q", got, want)
+	}
+	if got := out[4].FunctionToolOutput.Contents[0].TextItem.Text; got != "a b c d" {
+		t.Fatalf("second output should be untouched once the inputs fit, got %q", got)
+	}
+	if got := warningCodes(warns); got != WarningCodeToolOutputTruncated {
+		t.Fatalf("warnings: got %q", got)
+	}
+	if got := inputs[2].FunctionToolOutput.Contents[0].TextItem.Text; got != "one two three four five six" {
+		t.Fatalf("inputs were modified: %q", got)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in        string
+		maxTokens int
+		want      string
+	}{
+		{in: "alpha beta gamma", maxTokens: 2, want: "alpha beta"},
+		{in: "alpha beta", maxTokens: 5, want: "alpha beta"},
+		{in: "alpha", maxTokens: 0, want: ""},
+		{in: "héllo wörld again", maxTokens: 1, want: "héllo"},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.in, tt.maxTokens, wordTokenizer{}); got != tt.want {
+			t.Errorf("truncateText(%q, %d): got %q want %q", tt.in, tt.maxTokens, got, tt.want)
+		}
+	}
+}
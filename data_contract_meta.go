@@ -9,7 +9,7 @@ import (
 )
 
 // DataContractVersion is bumped when the *schema* of the contract types changes.
-const DataContractVersion = "v1.0.0"
+const DataContractVersion = "v1.5.0"
 
 // DataContractFiles lists files that define the data contract.
 // Paths are relative to the repo root.
@@ -32,7 +32,7 @@ var DataContractFiles = []string{
 // that they are running against the contract version they were built for.
 //
 // Format: "sha256:<hexstring>".
-const DataContractHash = "sha256:40d4d92460554cc5959464baa982f4cb6178fe77639d6c6bc01c6e682a9bd1ee"
+const DataContractHash = "sha256:8a71ae048ba2d0440f998db927f504fce049e6b8b57fb6430a04f04fd85163f4"
 
 // DataContractInfo is the public shape returned to callers who want to
 // validate they are compatible with this version of the contract.
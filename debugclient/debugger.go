@@ -1,5 +1,7 @@
 package debugclient
 
+import "github.com/flexigpt/inference-go/spec"
+
 // DebugConfig controls how HTTP debug information is captured and redacted.
 //
 // The zero value corresponds to the default behavior:
@@ -60,4 +62,8 @@ type HTTPDebugState struct {
 	// ProviderResponse holds a scrubbed form of the raw provider SDK response
 	// (e.g. *responses.Response for OpenAI), if available.
 	ProviderResponse any `json:"providerResponse,omitempty"`
+
+	// Attempt is present when the call was made under a retry policy and
+	// carries the attempt count and the errors of earlier attempts.
+	Attempt *spec.CompletionAttempt `json:"attempt,omitempty"`
 }
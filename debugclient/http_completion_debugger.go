@@ -3,6 +3,7 @@ package debugclient
 import (
 	"context"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 
@@ -18,7 +19,7 @@ type httpSpan struct {
 
 // End implements spec.CompletionSpan.End.
 func (s *httpSpan) End(end *spec.CompletionSpanEnd) any {
-	defer sdkutil.Recover("debugclient.httpSpan.End panic")
+	defer sdkutil.Recover(s.ctx, "debugclient.httpSpan.End panic")
 
 	if s.cfg.Disable {
 		return nil
@@ -41,6 +42,12 @@ func (s *httpSpan) End(end *spec.CompletionSpanEnd) any {
 		}
 	}
 
+	if s.info != nil && s.info.Attempt != nil {
+		a := *s.info.Attempt
+		a.PreviousErrors = slices.Clone(a.PreviousErrors)
+		state.Attempt = &a
+	}
+
 	// Compose error message from HTTP-level error + provider error.
 	var msgParts []string
 	if state.ErrorDetails != nil {
@@ -135,3 +142,8 @@ func (d *HTTPCompletionDebugger) GetConfig() DebugConfig {
 	defer d.mu.RUnlock()
 	return d.config
 }
+
+// DebugDetailsKey implements DebugDetailsKeyer.
+func (d *HTTPCompletionDebugger) DebugDetailsKey() string {
+	return "http"
+}
@@ -45,7 +45,7 @@ func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	state.RequestDetails = reqDetails
 
 	if cfg.LogToSlog {
-		logutil.Debug("http_debug: request", "details", getDetailsStr(reqDetails))
+		logutil.DebugContext(ctx, "http_debug: request", "details", getDetailsStr(reqDetails))
 	}
 
 	// Perform the request.
@@ -54,7 +54,7 @@ func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Capture response details (headers, status, and possibly body).
 	var respDetails *APIResponseDetails
 	if resp != nil {
-		respDetails = captureResponseDetails(resp, cfg, state)
+		respDetails = captureResponseDetails(ctx, resp, cfg, state)
 		state.ResponseDetails = respDetails
 	}
 
@@ -69,10 +69,10 @@ func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	if cfg.LogToSlog {
 		if respDetails != nil {
-			logutil.Debug("http_debug: response", "details", getDetailsStr(respDetails))
+			logutil.DebugContext(ctx, "http_debug: response", "details", getDetailsStr(respDetails))
 		}
 		if state.ErrorDetails != nil {
-			logutil.Debug("http_debug: error", "details", getDetailsStr(state.ErrorDetails))
+			logutil.DebugContext(ctx, "http_debug: error", "details", getDetailsStr(state.ErrorDetails))
 		}
 	}
 
@@ -142,6 +142,7 @@ func captureRequestDetails(req *http.Request, cfg DebugConfig) *APIRequestDetail
 }
 
 func captureResponseDetails(
+	ctx context.Context,
 	resp *http.Response,
 	cfg DebugConfig,
 	state *HTTPDebugState,
@@ -166,6 +167,7 @@ func captureResponseDetails(
 		buffer := new(bytes.Buffer)
 		resp.Body = &loggingReadCloser{
 			ReadCloser: resp.Body,
+			ctx:        ctx,
 			buf:        buffer,
 			state:      state,
 			cfg:        cfg,
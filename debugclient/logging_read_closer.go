@@ -2,6 +2,7 @@ package debugclient
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"sync"
 
@@ -11,6 +12,7 @@ import (
 type loggingReadCloser struct {
 	io.ReadCloser
 
+	ctx   context.Context // only used for logging
 	buf   *bytes.Buffer
 	state *HTTPDebugState
 	cfg   DebugConfig
@@ -65,6 +67,6 @@ func (lc *loggingReadCloser) finalize() {
 	lc.state.ResponseDetails.Data = sanitizeBodyForDebug(dataBytes, false, lc.cfg)
 
 	if lc.cfg.LogToSlog {
-		logutil.Debug("http_debug: response body raw", "body", string(dataBytes))
+		logutil.DebugContext(lc.ctx, "http_debug: response body raw", "body", string(dataBytes))
 	}
 }
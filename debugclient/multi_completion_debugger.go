@@ -0,0 +1,164 @@
+package debugclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DebugDetailsKeyer is implemented by debuggers that choose the key of their
+// debug payload in the DebugDetails map of a MultiCompletionDebugger.
+type DebugDetailsKeyer interface {
+	DebugDetailsKey() string
+}
+
+// NewMultiCompletionDebugger composes debuggers into one, e.g. the HTTP
+// debugger with tracing, auditing or header injecting layers. The first
+// debugger is the outermost:
+//
+//   - HTTPClient: each debugger wraps the client of the debugger after it, so
+//     the first one's transport sees requests first. A nil result leaves the
+//     client unchanged.
+//   - StartSpan runs in order, passing the context on; spans end in reverse.
+//   - spec.CompletionRequestInterceptor and spec.CompletionStreamObserver
+//     implementations run in order.
+//
+// Debug payloads are merged into a map[string]any keyed by DebugDetailsKey,
+// see Named, or by "debugger<index>". Nil payloads are left out, and a chain
+// with no payloads returns nil. Nil debuggers are skipped.
+func NewMultiCompletionDebugger(debuggers ...spec.CompletionDebugger) *MultiCompletionDebugger {
+	c := &MultiCompletionDebugger{}
+	for i, d := range debuggers {
+		if d == nil {
+			continue
+		}
+		key := "debugger" + strconv.Itoa(i)
+		if k, ok := d.(DebugDetailsKeyer); ok && k.DebugDetailsKey() != "" {
+			key = k.DebugDetailsKey()
+		}
+		c.links = append(c.links, chainLink{key: key, debugger: d})
+	}
+	return c
+}
+
+// Chain is shorthand for NewMultiCompletionDebugger.
+func Chain(debuggers ...spec.CompletionDebugger) *MultiCompletionDebugger {
+	return NewMultiCompletionDebugger(debuggers...)
+}
+
+// Named returns d with key as its DebugDetails key in a MultiCompletionDebugger.
+func Named(key string, d spec.CompletionDebugger) spec.CompletionDebugger {
+	return namedDebugger{CompletionDebugger: d, key: key}
+}
+
+type namedDebugger struct {
+	spec.CompletionDebugger
+
+	key string
+}
+
+func (n namedDebugger) DebugDetailsKey() string { return n.key }
+
+func (n namedDebugger) InterceptRequest(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) error {
+	if i, ok := n.CompletionDebugger.(spec.CompletionRequestInterceptor); ok {
+		return i.InterceptRequest(ctx, req, opts)
+	}
+	return nil
+}
+
+// MultiCompletionDebugger implements spec.CompletionDebugger and
+// spec.CompletionRequestInterceptor by fanning out to several debuggers.
+type MultiCompletionDebugger struct {
+	links []chainLink
+}
+
+type chainLink struct {
+	key      string
+	debugger spec.CompletionDebugger
+}
+
+// HTTPClient implements spec.CompletionDebugger.HTTPClient.
+func (c *MultiCompletionDebugger) HTTPClient(base *http.Client) *http.Client {
+	client := base
+	for i := len(c.links) - 1; i >= 0; i-- {
+		if wrapped := c.links[i].debugger.HTTPClient(client); wrapped != nil {
+			client = wrapped
+		}
+	}
+	return client
+}
+
+// InterceptRequest implements spec.CompletionRequestInterceptor. It stops at
+// the first error.
+func (c *MultiCompletionDebugger) InterceptRequest(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) error {
+	for _, l := range c.links {
+		if i, ok := l.debugger.(spec.CompletionRequestInterceptor); ok {
+			if err := i.InterceptRequest(ctx, req, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StartSpan implements spec.CompletionDebugger.StartSpan.
+func (c *MultiCompletionDebugger) StartSpan(
+	ctx context.Context,
+	info *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	var spans chainSpan
+	for _, l := range c.links {
+		var span spec.CompletionSpan
+		ctx, span = l.debugger.StartSpan(ctx, info)
+		if span != nil {
+			spans = append(spans, chainSpanLink{key: l.key, span: span})
+		}
+	}
+	if len(spans) == 0 {
+		return ctx, nil
+	}
+	return ctx, spans
+}
+
+type chainSpanLink struct {
+	key  string
+	span spec.CompletionSpan
+}
+
+type chainSpan []chainSpanLink
+
+// End implements spec.CompletionSpan.End.
+func (s chainSpan) End(end *spec.CompletionSpanEnd) any {
+	var details map[string]any
+	for i := len(s) - 1; i >= 0; i-- {
+		if d := s[i].span.End(end); d != nil {
+			if details == nil {
+				details = map[string]any{}
+			}
+			details[s[i].key] = d
+		}
+	}
+	if details == nil {
+		return nil
+	}
+	return details
+}
+
+// ObserveStreamEvent implements spec.CompletionStreamObserver.
+func (s chainSpan) ObserveStreamEvent(event spec.StreamEvent) {
+	for _, l := range s {
+		if o, ok := l.span.(spec.CompletionStreamObserver); ok {
+			o.ObserveStreamEvent(event)
+		}
+	}
+}
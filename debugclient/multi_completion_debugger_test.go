@@ -0,0 +1,164 @@
+package debugclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type recordingCtxKey string
+
+// recordingDebugger records the calls made to it into a shared log.
+type recordingDebugger struct {
+	name         string
+	log          *[]string
+	noSpan       bool
+	details      any
+	interceptErr error
+}
+
+func (d *recordingDebugger) HTTPClient(base *http.Client) *http.Client {
+	*d.log = append(*d.log, d.name+".HTTPClient")
+	rt := http.DefaultTransport
+	if base != nil && base.Transport != nil {
+		rt = base.Transport
+	}
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		*d.log = append(*d.log, d.name+".RoundTrip")
+		return rt.RoundTrip(r)
+	})}
+}
+
+func (d *recordingDebugger) InterceptRequest(
+	_ context.Context,
+	req *spec.FetchCompletionRequest,
+	_ *spec.FetchCompletionOptions,
+) error {
+	*d.log = append(*d.log, d.name+".Intercept")
+	req.ModelParam.SystemPrompt += d.name
+	return d.interceptErr
+}
+
+func (d *recordingDebugger) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	*d.log = append(*d.log, d.name+".StartSpan")
+	if parent, _ := ctx.Value(recordingCtxKey("last")).(string); parent != "" {
+		*d.log = append(*d.log, d.name+".parent="+parent)
+	}
+	ctx = context.WithValue(ctx, recordingCtxKey("last"), d.name)
+	if d.noSpan {
+		return ctx, nil
+	}
+	return ctx, &recordingSpan{d: d}
+}
+
+type recordingSpan struct{ d *recordingDebugger }
+
+func (s *recordingSpan) End(*spec.CompletionSpanEnd) any {
+	*s.d.log = append(*s.d.log, s.d.name+".End")
+	return s.d.details
+}
+
+func (s *recordingSpan) ObserveStreamEvent(spec.StreamEvent) {
+	*s.d.log = append(*s.d.log, s.d.name+".Observe")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestMultiCompletionDebugger(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	var log []string
+	a := &recordingDebugger{name: "a", log: &log, details: "a-details"}
+	b := &recordingDebugger{name: "b", log: &log, noSpan: true}
+	c := &recordingDebugger{name: "c", log: &log, details: "c-details"}
+	chain := NewMultiCompletionDebugger(Named("first", a), nil, b, c)
+
+	resp, err := chain.HTTPClient(nil).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req := &spec.FetchCompletionRequest{}
+	if err := chain.InterceptRequest(t.Context(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if req.ModelParam.SystemPrompt != "abc" {
+		t.Errorf("SystemPrompt = %q, want interceptors applied in order", req.ModelParam.SystemPrompt)
+	}
+
+	_, span := chain.StartSpan(t.Context(), &spec.CompletionSpanStart{})
+	if span == nil {
+		t.Fatal("StartSpan() span = nil")
+	}
+	observer, ok := span.(spec.CompletionStreamObserver)
+	if !ok {
+		t.Fatal("span does not implement spec.CompletionStreamObserver")
+	}
+	observer.ObserveStreamEvent(spec.StreamEvent{})
+
+	got := span.End(&spec.CompletionSpanEnd{})
+	want := map[string]any{"first": "a-details", "debugger3": "c-details"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("End() = %v, want %v", got, want)
+	}
+
+	wantLog := []string{
+		"c.HTTPClient", "b.HTTPClient", "a.HTTPClient",
+		"a.RoundTrip", "b.RoundTrip", "c.RoundTrip",
+		"a.Intercept", "b.Intercept", "c.Intercept",
+		"a.StartSpan", "b.StartSpan", "b.parent=a", "c.StartSpan", "c.parent=b",
+		"a.Observe", "c.Observe",
+		"c.End", "a.End",
+	}
+	if !slices.Equal(log, wantLog) {
+		t.Errorf("calls = %v\nwant    %v", log, wantLog)
+	}
+}
+
+func TestMultiCompletionDebuggerInterceptError(t *testing.T) {
+	t.Parallel()
+
+	var log []string
+	errStop := errors.New("stop")
+	chain := Chain(
+		&recordingDebugger{name: "a", log: &log, interceptErr: errStop},
+		&recordingDebugger{name: "b", log: &log},
+	)
+	if err := chain.InterceptRequest(t.Context(), &spec.FetchCompletionRequest{}, nil); !errors.Is(err, errStop) {
+		t.Errorf("InterceptRequest() = %v, want %v", err, errStop)
+	}
+	if want := []string{"a.Intercept"}; !slices.Equal(log, want) {
+		t.Errorf("calls = %v, want %v", log, want)
+	}
+}
+
+func TestMultiCompletionDebuggerWithoutSpans(t *testing.T) {
+	t.Parallel()
+
+	var log []string
+	chain := Chain(&recordingDebugger{name: "a", log: &log, noSpan: true})
+	if _, span := chain.StartSpan(t.Context(), &spec.CompletionSpanStart{}); span != nil {
+		t.Errorf("StartSpan() span = %v, want nil", span)
+	}
+	if got := Chain().HTTPClient(nil); got != nil {
+		t.Errorf("HTTPClient() = %v, want nil without debuggers", got)
+	}
+	if got := Chain(NewHTTPCompletionDebugger(nil)).links[0].key; got != "http" {
+		t.Errorf("HTTP debugger key = %q, want http", got)
+	}
+}
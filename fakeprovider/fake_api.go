@@ -0,0 +1,301 @@
+package fakeprovider
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// FakeAPI implements CompletionProvider on top of a scripted Provider.
+type FakeAPI struct {
+	ProviderParam *spec.ProviderParam
+	provider      *Provider
+	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
+	mu            sync.RWMutex
+}
+
+// Adapter builds the provider adapter answering from p. It is an
+// inference.CompletionProviderBuilder:
+//
+//	ps.AddCompletionProvider(ctx, "fake", spec.ProviderSDKTypeFake, fake.Adapter)
+func (p *Provider) Adapter(
+	pi spec.ProviderParam,
+	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
+) (sdkutil.CompletionProvider, error) {
+	api, err := NewFakeAPI(pi, p, debugger, logger)
+	if err != nil {
+		return nil, err
+	}
+	return api, nil
+}
+
+// NewFakeAPI creates a fake provider answering from p.
+func NewFakeAPI(
+	pi spec.ProviderParam,
+	p *Provider,
+	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
+) (*FakeAPI, error) {
+	if pi.Name == "" || p == nil {
+		return nil, errors.New("fake LLM: invalid args")
+	}
+	return &FakeAPI{
+		ProviderParam: &pi,
+		provider:      p,
+		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
+	}, nil
+}
+
+func (api *FakeAPI) InitLLM(ctx context.Context) error {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return errors.New("fake LLM: no ProviderParam found")
+	}
+	api.logger.Info("fake LLM provider initialized", "name", string(api.ProviderParam.Name))
+	return nil
+}
+
+func (api *FakeAPI) DeInitLLM(ctx context.Context) error {
+	return nil
+}
+
+func (api *FakeAPI) GetProviderInfo(ctx context.Context) *spec.ProviderParam {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return nil
+	}
+	cp := *api.ProviderParam
+	cp.DefaultHeaders = sdkutil.CloneStringMap(cp.DefaultHeaders)
+	return &cp
+}
+
+// IsConfigured reports true: the fake provider needs no API key.
+func (api *FakeAPI) IsConfigured(ctx context.Context) bool {
+	return true
+}
+
+func (api *FakeAPI) SetProviderAPIKey(ctx context.Context, apiKey string) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ProviderParam == nil {
+		return errors.New("fake LLM: no ProviderParam found")
+	}
+	api.ProviderParam.APIKey = apiKey
+	return nil
+}
+
+func (api *FakeAPI) GetProviderCapability(ctx context.Context) (spec.ModelCapabilities, error) {
+	return api.provider.capabilities, nil
+}
+
+func (api *FakeAPI) FetchCompletion(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	req, _, warns, err := sdkutil.NormalizeRequestForSDK(
+		ctx,
+		inReq,
+		opts,
+		spec.ProviderSDKTypeFake,
+		api.provider.capabilities,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := sdkutil.InterceptRequest(ctx, api.debugger, req, opts); err != nil {
+		return nil, err
+	}
+	turn, err := api.provider.take(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var resp *spec.FetchCompletionResponse
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream {
+		resp, err = api.doStreaming(ctx, pi.Name, req, opts, turn)
+	} else {
+		resp, err = api.doNonStreaming(ctx, pi.Name, req, turn)
+	}
+
+	if err != nil {
+		err = sdkutil.NewProviderError(pi.Name, err, sdkutil.ProviderErrorDetails{})
+		sdkutil.SetResponseErrorKind(resp, err)
+	}
+
+	if resp != nil && len(warns) > 0 {
+		resp.Warnings = append(resp.Warnings, warns...)
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: turn,
+			Response:         resp, // may be nil
+			Err:              err,
+		}
+		if resp != nil {
+			if dd := span.End(&end); dd != nil && resp.DebugDetails == nil {
+				resp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return resp, err
+}
+
+func (api *FakeAPI) doNonStreaming(
+	ctx context.Context,
+	providerName spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	turn Turn,
+) (*spec.FetchCompletionResponse, error) {
+	if err := sleep(ctx, turn.Delay); err != nil {
+		return nil, err
+	}
+	if turn.Err != nil {
+		return nil, withProvider(providerName, turn.Err)
+	}
+	return responseForTurn(req, turn), nil
+}
+
+func (api *FakeAPI) doStreaming(
+	ctx context.Context,
+	providerName spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	turn Turn,
+) (*spec.FetchCompletionResponse, error) {
+	resp := responseForTurn(req, turn)
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, req.ModelParam.Name, opts)
+	s := &fakeStream{
+		ctx:        ctx,
+		emitter:    emitter,
+		chunkSize:  api.provider.chunkSize,
+		chunkDelay: api.provider.chunkDelay,
+		failAfter:  turn.ErrAfterChunks,
+	}
+	if turn.Err != nil {
+		s.err = withProvider(providerName, turn.Err)
+	}
+
+	streamWriteErr := sleep(ctx, turn.Delay)
+	if streamWriteErr == nil {
+		streamWriteErr = s.writeOutputs(resp.Outputs)
+	}
+	flushErr := emitter.Close()
+	if streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(resp.Usage, resp.StopReason)
+	}
+
+	streamErr := errors.Join(streamWriteErr, flushErr)
+	if streamErr != nil {
+		logutil.ErrorContext(
+			ctx,
+			"fake stream terminated",
+			"provider", string(providerName),
+			"model", string(req.ModelParam.Name),
+			"chunks", s.sent,
+			"streamWriteErr", streamWriteErr,
+			"flushErr", flushErr,
+			"contextErr", ctx.Err(),
+		)
+		return &spec.FetchCompletionResponse{Error: &spec.Error{Message: streamErr.Error()}}, streamErr
+	}
+	return resp, nil
+}
+
+// responseForTurn returns the response of a successful turn. Tool calls
+// without a ChoiceID get the ID of the request's tool choice of the same name.
+func responseForTurn(req *spec.FetchCompletionRequest, turn Turn) *spec.FetchCompletionResponse {
+	choiceIDs := map[string]string{}
+	for _, tc := range req.ToolChoices {
+		choiceIDs[tc.Name] = tc.ID
+	}
+
+	resp := &spec.FetchCompletionResponse{
+		Outputs:    make([]spec.OutputUnion, 0, len(turn.Outputs)),
+		StopReason: turn.StopReason,
+		Usage:      turn.Usage,
+	}
+	hasToolCall := false
+	for _, o := range turn.Outputs {
+		for _, call := range []**spec.ToolCall{&o.FunctionToolCall, &o.CustomToolCall} {
+			if *call == nil {
+				continue
+			}
+			hasToolCall = true
+			cp := **call
+			if cp.ChoiceID == "" {
+				cp.ChoiceID = choiceIDs[cp.Name]
+			}
+			*call = &cp
+		}
+		resp.Outputs = append(resp.Outputs, o)
+	}
+	if resp.StopReason == nil {
+		resp.StopReason = &spec.StopReason{Kind: spec.StopReasonEndTurn}
+		if hasToolCall {
+			resp.StopReason.Kind = spec.StopReasonToolUse
+		}
+	}
+	return resp
+}
+
+// withProvider fills in the provider name of a scripted ProviderError without
+// modifying the script.
+func withProvider(providerName spec.ProviderName, err error) error {
+	pe, ok := err.(*spec.ProviderError) //nolint:errorlint // Only a bare scripted error is copied.
+	if !ok || pe.Provider != "" {
+		return err
+	}
+	cp := *pe
+	cp.Provider = providerName
+	return &cp
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
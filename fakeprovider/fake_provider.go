@@ -0,0 +1,207 @@
+// Package fakeprovider is an in-process provider for unit testing code built on
+// inference.ProviderSetAPI without calling a model.
+//
+// A Provider answers each request with the next scripted Turn, streaming it in
+// chunks when the caller streams, and records the requests it received after
+// capability normalization. Register it with AddCompletionProvider:
+//
+//	fake := fakeprovider.New(fakeprovider.Config{Turns: []fakeprovider.Turn{
+//		{Outputs: []spec.OutputUnion{fakeprovider.FunctionToolCall("call_1", "get_weather", `{"city":"Paris"}`)}},
+//		{Outputs: []spec.OutputUnion{fakeprovider.Text("It is sunny in Paris.")}},
+//	}})
+//	ps.AddCompletionProvider(ctx, "fake", spec.ProviderSDKTypeFake, fake.Adapter)
+//
+// The provider needs no API key and makes no network calls. Debuggers and
+// request interceptors run as they do for the other providers.
+package fakeprovider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// DefaultChunkSize is the number of runes per streamed text, thinking or tool
+// argument chunk when Config.ChunkSize is not set.
+const DefaultChunkSize = 16
+
+// ErrNoTurnLeft is returned when a request arrives after the last scripted turn.
+var ErrNoTurnLeft = errors.New("fakeprovider: no scripted turn left")
+
+// Turn is the scripted answer to one request.
+type Turn struct {
+	Outputs []spec.OutputUnion
+
+	// StopReason defaults to toolUse when Outputs hold a function or custom tool
+	// call and to endTurn otherwise.
+	StopReason *spec.StopReason
+	Usage      *spec.Usage
+
+	// Delay is waited before the response, or before the first streamed event.
+	Delay time.Duration
+
+	// Err fails the call. A streaming call first delivers ErrAfterChunks chunks,
+	// so errors can be injected mid-stream; a non-streaming call fails at once.
+	Err            error
+	ErrAfterChunks int
+}
+
+// Config configures a Provider.
+type Config struct {
+	Turns []Turn
+
+	// Capabilities are used to normalize requests. Nil uses
+	// DefaultCapabilities.
+	Capabilities *spec.ModelCapabilities
+
+	// ChunkSize is the number of runes per streamed chunk, DefaultChunkSize if
+	// not positive. ChunkDelay is waited before each chunk.
+	ChunkSize  int
+	ChunkDelay time.Duration
+}
+
+// Provider holds the script of a fake provider and the requests it received.
+// It is safe for concurrent use.
+type Provider struct {
+	capabilities spec.ModelCapabilities
+	chunkSize    int
+	chunkDelay   time.Duration
+
+	mu       sync.Mutex
+	turns    []Turn
+	next     int
+	requests []*spec.FetchCompletionRequest
+}
+
+// New returns a Provider answering with cfg.Turns in order.
+func New(cfg Config) *Provider {
+	p := &Provider{
+		capabilities: DefaultCapabilities(),
+		chunkSize:    cfg.ChunkSize,
+		chunkDelay:   cfg.ChunkDelay,
+		turns:        append([]Turn(nil), cfg.Turns...),
+	}
+	if cfg.Capabilities != nil {
+		p.capabilities = *cfg.Capabilities
+	}
+	if p.chunkSize <= 0 {
+		p.chunkSize = DefaultChunkSize
+	}
+	return p
+}
+
+// AddTurns appends turns to the script.
+func (p *Provider) AddTurns(turns ...Turn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.turns = append(p.turns, turns...)
+}
+
+// Requests returns the requests received so far, in order, as normalized for
+// the provider capabilities and after request interceptors ran.
+func (p *Provider) Requests() []*spec.FetchCompletionRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*spec.FetchCompletionRequest(nil), p.requests...)
+}
+
+// Remaining returns the number of scripted turns not used yet.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.turns) - p.next
+}
+
+// take records req and returns the next turn.
+func (p *Provider) take(req *spec.FetchCompletionRequest) (Turn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, req)
+	if p.next >= len(p.turns) {
+		return Turn{}, fmt.Errorf("%w for request %d", ErrNoTurnLeft, len(p.requests))
+	}
+	t := p.turns[p.next]
+	p.next++
+	return t, nil
+}
+
+// DefaultCapabilities accepts every input modality, reasoning type and level,
+// sampling parameter, output format and tool type, so requests pass
+// normalization unchanged.
+func DefaultCapabilities() spec.ModelCapabilities {
+	return spec.ModelCapabilities{
+		ModalitiesIn: []spec.Modality{
+			spec.ModalityTextIn, spec.ModalityImageIn, spec.ModalityFileIn, spec.ModalityAudioIn, spec.ModalityVideoIn,
+		},
+		ModalitiesOut: []spec.Modality{spec.ModalityTextOut, spec.ModalityAudioOut},
+		ReasoningCapabilities: &spec.ReasoningCapabilities{
+			SupportsReasoningConfig: true,
+			SupportedReasoningTypes: []spec.ReasoningType{
+				spec.ReasoningTypeHybridWithTokens,
+				spec.ReasoningTypeSingleWithLevels,
+			},
+			SupportedReasoningLevels: []spec.ReasoningLevel{
+				spec.ReasoningLevelNone,
+				spec.ReasoningLevelMinimal,
+				spec.ReasoningLevelLow,
+				spec.ReasoningLevelMedium,
+				spec.ReasoningLevelHigh,
+				spec.ReasoningLevelXHigh,
+				spec.ReasoningLevelMax,
+			},
+			SupportsSummaryStyle:            true,
+			SupportsEncryptedReasoningInput: true,
+		},
+		StopSequenceCapabilities: &spec.StopSequenceCapabilities{IsSupported: true},
+		// MaxCandidates is left at zero: each turn is one candidate, so
+		// ProviderSetAPI samples several with one request per turn.
+		SamplingCapabilities: &spec.SamplingCapabilities{
+			SupportsTopP:             true,
+			SupportsTopK:             true,
+			SupportsSeed:             true,
+			SupportsPresencePenalty:  true,
+			SupportsFrequencyPenalty: true,
+			SupportsLogitBias:        true,
+			SupportsLogprobs:         true,
+			MaxTopLogprobs:           20,
+		},
+		OutputCapabilities: &spec.OutputCapabilities{
+			SupportedOutputFormats: []spec.OutputFormatKind{
+				spec.OutputFormatKindText,
+				spec.OutputFormatKindJSONSchema,
+			},
+			SupportsVerbosity: true,
+		},
+		ToolCapabilities: &spec.ToolCapabilities{
+			SupportedToolTypes: []spec.ToolType{
+				spec.ToolTypeFunction,
+				spec.ToolTypeCustom,
+				spec.ToolTypeWebSearch,
+			},
+			SupportedToolPolicyModes: []spec.ToolPolicyMode{
+				spec.ToolPolicyModeAuto,
+				spec.ToolPolicyModeAny,
+				spec.ToolPolicyModeTool,
+				spec.ToolPolicyModeNone,
+			},
+			SupportsParallelToolCalls: true,
+			MaxForcedTools:            0,
+		},
+	}
+}
+
+// RateLimitError returns a rate limit ProviderError, as a provider reports an
+// HTTP 429 with the given Retry-After.
+func RateLimitError(retryAfter time.Duration) error {
+	return &spec.ProviderError{
+		Kind:         spec.ProviderErrorKindRateLimited,
+		HTTPStatus:   429,
+		ProviderCode: "rate_limit_error",
+		RetryAfter:   retryAfter,
+		Err:          errors.New("fakeprovider: rate limited"),
+	}
+}
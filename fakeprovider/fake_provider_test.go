@@ -0,0 +1,216 @@
+package fakeprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionScriptedTurns(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{Turns: []Turn{
+		{Outputs: []spec.OutputUnion{
+			Thinking("look up the weather"),
+			FunctionToolCall("call_1", "get_weather", `{"city":"Paris"}`),
+		}},
+		{Outputs: []spec.OutputUnion{Text("Sunny.")}, Usage: &spec.Usage{OutputTokens: 2}},
+	}})
+	api := newTestAPI(t, p)
+
+	req := testRequest("weather in Paris?")
+	req.ToolChoices = []spec.ToolChoice{{Type: spec.ToolTypeFunction, ID: "tc-weather", Name: "get_weather"}}
+	resp, err := api.FetchCompletion(t.Context(), req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Outputs) != 2 || resp.Outputs[1].FunctionToolCall == nil {
+		t.Fatalf("outputs = %+v, want reasoning and a tool call", resp.Outputs)
+	}
+	if got := resp.Outputs[1].FunctionToolCall.ChoiceID; got != "tc-weather" {
+		t.Errorf("ChoiceID = %q, want the matching tool choice", got)
+	}
+	if resp.StopReason == nil || resp.StopReason.Kind != spec.StopReasonToolUse {
+		t.Errorf("StopReason = %+v, want toolUse", resp.StopReason)
+	}
+
+	resp, err = api.FetchCompletion(t.Context(), testRequest("thanks"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StopReason.Kind != spec.StopReasonEndTurn || resp.Usage.OutputTokens != 2 {
+		t.Errorf("second turn = %+v %+v, want endTurn with scripted usage", resp.StopReason, resp.Usage)
+	}
+
+	if _, err := api.FetchCompletion(t.Context(), testRequest("more"), nil); !errors.Is(err, ErrNoTurnLeft) {
+		t.Errorf("third call error = %v, want %v", err, ErrNoTurnLeft)
+	}
+	reqs := p.Requests()
+	if len(reqs) != 3 || len(reqs[0].ToolChoices) != 1 || p.Remaining() != 0 {
+		t.Errorf("recorded %d requests, %d turns left", len(reqs), p.Remaining())
+	}
+}
+
+func TestFetchCompletionStreaming(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{
+		Turns: []Turn{{Outputs: []spec.OutputUnion{
+			WebSearchCall("ws_1", "paris weather"),
+			FunctionToolCall("call_1", "get_weather", `{"city":"Paris"}`),
+			Text("It is sunny in Paris today."),
+		}}},
+		ChunkSize: 4,
+	})
+	api := newTestAPI(t, p)
+
+	rec := &eventRecorder{}
+	req := testRequest("weather?")
+	req.ModelParam.Stream = true
+	resp, err := api.FetchCompletion(t.Context(), req, &spec.FetchCompletionOptions{StreamHandler: rec.handle})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Outputs) != 3 {
+		t.Fatalf("outputs = %+v, want the scripted outputs", resp.Outputs)
+	}
+
+	var text, args strings.Builder
+	var statuses []spec.Status
+	for _, ev := range rec.all() {
+		switch ev.Kind {
+		case spec.StreamContentKindText:
+			text.WriteString(ev.Text.Text)
+		case spec.StreamContentKindToolCallDelta:
+			args.WriteString(ev.ToolCall.ArgumentsDelta)
+		case spec.StreamContentKindWebSearchCall:
+			statuses = append(statuses, ev.WebSearchCall.Status)
+		default:
+		}
+	}
+	if text.String() != "It is sunny in Paris today." {
+		t.Errorf("streamed text = %q", text.String())
+	}
+	if args.String() != `{"city":"Paris"}` || rec.count(spec.StreamContentKindToolCallDelta) != 4 {
+		t.Errorf("streamed arguments = %q in %d deltas, want 4 chunks of 4 runes",
+			args.String(), rec.count(spec.StreamContentKindToolCallDelta))
+	}
+	if len(statuses) != 3 || statuses[2] != spec.StatusCompleted {
+		t.Errorf("web search statuses = %v", statuses)
+	}
+	if rec.count(spec.StreamContentKindOutputItemStart) != 3 || rec.count(spec.StreamContentKindCompleted) != 1 {
+		t.Errorf("events = %+v, want three output items and one completed event", rec.all())
+	}
+}
+
+func TestFetchCompletionStreamingErrorMidStream(t *testing.T) {
+	t.Parallel()
+
+	p := New(Config{
+		Turns: []Turn{{
+			Outputs:        []spec.OutputUnion{Text("abcdefghijklmnop")},
+			Err:            RateLimitError(2 * time.Second),
+			ErrAfterChunks: 2,
+		}},
+		ChunkSize: 4,
+	})
+	api := newTestAPI(t, p)
+
+	rec := &eventRecorder{}
+	req := testRequest("hi")
+	req.ModelParam.Stream = true
+	resp, err := api.FetchCompletion(t.Context(), req, &spec.FetchCompletionOptions{StreamHandler: rec.handle})
+
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) || pe.Kind != spec.ProviderErrorKindRateLimited || pe.RetryAfter != 2*time.Second {
+		t.Fatalf("error = %v, want a rate limit ProviderError", err)
+	}
+	if pe.Provider != "fake" {
+		t.Errorf("Provider = %q, want the registered name", pe.Provider)
+	}
+	if resp == nil || resp.Error == nil || resp.Error.Code != string(spec.ProviderErrorKindRateLimited) {
+		t.Errorf("resp = %+v, want the error kind", resp)
+	}
+	var text strings.Builder
+	for _, ev := range rec.all() {
+		if ev.Kind == spec.StreamContentKindText {
+			text.WriteString(ev.Text.Text)
+		}
+	}
+	if text.String() != "abcdefgh" {
+		t.Errorf("streamed text = %q, want the two chunks before the error", text.String())
+	}
+	if rec.count(spec.StreamContentKindCompleted) != 0 {
+		t.Error("completed event sent for a failed stream")
+	}
+}
+
+func TestFetchCompletionHonorsContext(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t, New(Config{Turns: []Turn{{Outputs: []spec.OutputUnion{Text("late")}, Delay: time.Minute}}}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := api.FetchCompletion(ctx, testRequest("hi"), nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func newTestAPI(t *testing.T, p *Provider) *FakeAPI {
+	t.Helper()
+	api, err := NewFakeAPI(spec.ProviderParam{Name: "fake", SDKType: spec.ProviderSDKTypeFake}, p, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func testRequest(text string) *spec.FetchCompletionRequest {
+	return &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "fake-model"},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: text},
+				}},
+			},
+		}},
+	}
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []spec.StreamEvent
+}
+
+func (r *eventRecorder) handle(ev spec.StreamEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func (r *eventRecorder) all() []spec.StreamEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]spec.StreamEvent(nil), r.events...)
+}
+
+func (r *eventRecorder) count(kind spec.StreamContentKind) int {
+	n := 0
+	for _, ev := range r.all() {
+		if ev.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package fakeprovider
+
+import "github.com/flexigpt/inference-go/spec"
+
+// Text returns an assistant output message holding text.
+func Text(text string) spec.OutputUnion {
+	return spec.OutputUnion{
+		Kind: spec.OutputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{
+			Role:   spec.RoleAssistant,
+			Status: spec.StatusCompleted,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}
+}
+
+// Thinking returns a reasoning output holding thinking text.
+func Thinking(thinking ...string) spec.OutputUnion {
+	return spec.OutputUnion{
+		Kind: spec.OutputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{
+			Role:     spec.RoleAssistant,
+			Status:   spec.StatusCompleted,
+			Thinking: thinking,
+		},
+	}
+}
+
+// FunctionToolCall returns a function tool call. Its ChoiceID is filled from
+// the request's tool choice of the same name when the call is answered.
+func FunctionToolCall(callID, name, arguments string) spec.OutputUnion {
+	return spec.OutputUnion{
+		Kind: spec.OutputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type:      spec.ToolTypeFunction,
+			ID:        callID,
+			Role:      spec.RoleAssistant,
+			Status:    spec.StatusCompleted,
+			CallID:    callID,
+			Name:      name,
+			Arguments: arguments,
+		},
+	}
+}
+
+// WebSearchCall returns a server side web search call for query.
+func WebSearchCall(callID, query string) spec.OutputUnion {
+	return spec.OutputUnion{
+		Kind: spec.OutputKindWebSearchToolCall,
+		WebSearchToolCall: &spec.ToolCall{
+			Type:   spec.ToolTypeWebSearch,
+			ID:     callID,
+			Role:   spec.RoleAssistant,
+			Status: spec.StatusCompleted,
+			CallID: callID,
+			Name:   "web_search",
+			WebSearchToolCallItems: []spec.WebSearchToolCallItemUnion{{
+				Kind:       spec.WebSearchToolCallKindSearch,
+				SearchItem: &spec.WebSearchToolCallSearch{Query: query},
+			}},
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package fakeprovider
+
+import (
+	"context"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// fakeStream replays scripted outputs as stream events, in chunks of chunkSize
+// runes. When err is set it fails once failAfter chunks were delivered, or at
+// the end of the outputs if they hold fewer chunks.
+type fakeStream struct {
+	ctx        context.Context
+	emitter    *sdkutil.StreamEmitter
+	chunkSize  int
+	chunkDelay time.Duration
+
+	err       error
+	failAfter int
+	sent      int
+}
+
+func (s *fakeStream) writeOutputs(outputs []spec.OutputUnion) error {
+	for idx, o := range outputs {
+		if err := s.writeOutput(idx, o); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func (s *fakeStream) writeOutput(idx int, o spec.OutputUnion) error {
+	id := outputID(o)
+	if err := s.emitter.OutputItemStart(idx, o.Kind, id); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case o.OutputMessage != nil:
+		err = s.writeMessage(idx, o.OutputMessage)
+	case o.ReasoningMessage != nil:
+		err = s.writeReasoning(o.ReasoningMessage)
+	case o.FunctionToolCall != nil:
+		err = s.writeToolCall(idx, o.FunctionToolCall)
+	case o.CustomToolCall != nil:
+		err = s.writeToolCall(idx, o.CustomToolCall)
+	case o.WebSearchToolCall != nil:
+		err = s.writeWebSearchCall(idx, o.WebSearchToolCall)
+	default:
+		// Web search outputs and other items are reported by their start and stop only.
+	}
+	if err != nil {
+		return err
+	}
+	return s.emitter.OutputItemStop(idx, o.Kind, id)
+}
+
+func (s *fakeStream) writeMessage(idx int, msg *spec.InputOutputContent) error {
+	for _, item := range msg.Contents {
+		if item.TextItem == nil {
+			continue
+		}
+		if err := s.writeChunks(item.TextItem.Text, s.emitter.WriteText); err != nil {
+			return err
+		}
+		for _, c := range item.TextItem.Citations {
+			if err := s.emitter.Citation(idx, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeReasoning streams summaries and thinking as thinking text. Encrypted
+// and redacted reasoning is not streamed.
+func (s *fakeStream) writeReasoning(r *spec.ReasoningContent) error {
+	for _, parts := range [][]string{r.Summary, r.Thinking} {
+		for _, t := range parts {
+			if err := s.writeChunks(t, s.emitter.WriteThinking); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeStream) writeToolCall(idx int, call *spec.ToolCall) error {
+	chunk := spec.StreamToolCallChunk{OutputIndex: idx, Type: call.Type, CallID: call.CallID, Name: call.Name}
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallStart, chunk); err != nil {
+		return err
+	}
+	err := s.writeChunks(call.Arguments, func(part string) error {
+		delta := chunk
+		delta.ArgumentsDelta = part
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, delta)
+	})
+	if err != nil {
+		return err
+	}
+	end := chunk
+	end.Arguments = call.Arguments
+	return s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, end)
+}
+
+func (s *fakeStream) writeWebSearchCall(idx int, call *spec.ToolCall) error {
+	var query string
+	for _, item := range call.WebSearchToolCallItems {
+		if item.SearchItem != nil {
+			query = item.SearchItem.Query
+			break
+		}
+	}
+	for _, status := range []spec.Status{spec.StatusInProgress, spec.StatusSearching, spec.StatusCompleted} {
+		if err := s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      call.CallID,
+			Status:      status,
+			Query:       query,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeChunks splits text into chunks and writes them, waiting chunkDelay
+// before each.
+func (s *fakeStream) writeChunks(text string, write func(string) error) error {
+	runes := []rune(text)
+	for len(runes) > 0 {
+		if s.err != nil && s.sent >= s.failAfter {
+			return s.err
+		}
+		n := min(s.chunkSize, len(runes))
+		if err := sleep(s.ctx, s.chunkDelay); err != nil {
+			return err
+		}
+		if err := write(string(runes[:n])); err != nil {
+			return err
+		}
+		runes = runes[n:]
+		s.sent++
+	}
+	return nil
+}
+
+func outputID(o spec.OutputUnion) string {
+	switch {
+	case o.OutputMessage != nil:
+		return o.OutputMessage.ID
+	case o.ReasoningMessage != nil:
+		return o.ReasoningMessage.ID
+	case o.FunctionToolCall != nil:
+		return o.FunctionToolCall.ID
+	case o.CustomToolCall != nil:
+		return o.CustomToolCall.ID
+	case o.WebSearchToolCall != nil:
+		return o.WebSearchToolCall.ID
+	case o.WebSearchToolOutput != nil:
+		return o.WebSearchToolOutput.ID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,329 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/flexigpt/inference-go/capabilityoverride"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/modelpreset"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// FallbackTarget is one entry of a FallbackRouter chain.
+type FallbackTarget struct {
+	// Provider is the runtime provider name registered in the ProviderSetAPI.
+	Provider spec.ProviderName
+
+	// ModelPreset supplies the model name and the default ModelParam for this target.
+	ModelPreset modelpreset.ModelPreset
+
+	// CapabilityResolver is used to normalize the request for this target. If nil, the provider's capabilities
+	// patched with ModelPreset.CapabilitiesOverride are used.
+	CapabilityResolver spec.ModelCapabilityResolver
+
+	// CompletionKey is passed to CapabilityResolver. Defaults to the ModelPreset ID.
+	CompletionKey string
+}
+
+// FallbackRouterOption configures optional behavior for FallbackRouter.
+type FallbackRouterOption func(*FallbackRouter)
+
+// WithFallbackCondition replaces the inbuilt decision of whether a failed target should fall over to the next one.
+// It is never consulted after a stream event has been delivered, or once the context is done.
+func WithFallbackCondition(fn func(err error) bool) FallbackRouterOption {
+	return func(r *FallbackRouter) {
+		r.shouldFallback = fn
+	}
+}
+
+// FallbackRouter sends a completion request to an ordered list of targets, moving to the next target when the
+// current one fails with a fallback-eligible error.
+//
+// Each target gets its own copy of the request with the model taken from its preset, and is normalized against that
+// target's capabilities. The target that served the response is reported in FetchCompletionResponse.Target. Once a
+// stream event has been delivered to the caller the router never falls over.
+type FallbackRouter struct {
+	ps             *ProviderSetAPI
+	targets        []FallbackTarget
+	shouldFallback func(err error) bool
+}
+
+// NewFallbackRouter creates a router over ps. Targets are tried in the given order.
+func NewFallbackRouter(
+	ps *ProviderSetAPI,
+	targets []FallbackTarget,
+	opts ...FallbackRouterOption,
+) (*FallbackRouter, error) {
+	if ps == nil {
+		return nil, errors.New("fallback router: nil provider set")
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("fallback router: no targets")
+	}
+	for i, t := range targets {
+		if t.Provider == "" {
+			return nil, fmt.Errorf("fallback router: target %d: empty provider", i)
+		}
+		if fallbackTargetModelName(t) == "" {
+			return nil, fmt.Errorf("fallback router: target %d: empty model name", i)
+		}
+		if t.ModelPreset.Pricing != nil {
+			if _, err := modelpreset.DeriveModelPricing(t.ModelPreset.Pricing); err != nil {
+				return nil, fmt.Errorf("fallback router: target %d: %w", i, err)
+			}
+		}
+	}
+
+	r := &FallbackRouter{
+		ps:      ps,
+		targets: slices.Clone(targets),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+	return r, nil
+}
+
+// FetchCompletion tries each target in order until one succeeds.
+//
+// The request's ModelParam.Name is ignored; the model always comes from the target's preset. Every other ModelParam
+// field is taken from the request when set, and from the preset otherwise. Model specific fields such as Reasoning,
+// LogitBias and AdditionalParametersRawJSON are adapted to each target by capability normalization, so use
+// NormalizationModeCoerce when a request may carry values some targets do not support.
+//
+// opts.CompletionKey and opts.CapabilityResolver are replaced per target, and opts.Pricing is replaced by the
+// pricing of the target's preset, if any. All other options are passed through.
+func (r *FallbackRouter) FetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	if req == nil || len(req.Inputs) == 0 {
+		return nil, errors.New("got empty fetch completion input")
+	}
+
+	var baseOpts spec.FetchCompletionOptions
+	if opts != nil {
+		baseOpts = *opts
+	}
+
+	// Track stream delivery and caller-side stream failures across all targets.
+	var streamed, handlerFailed atomic.Bool
+	if baseOpts.StreamHandler != nil {
+		handler := baseOpts.StreamHandler
+		baseOpts.StreamHandler = func(event spec.StreamEvent) error {
+			streamed.Store(true)
+			if err := handler(event); err != nil {
+				handlerFailed.Store(true)
+				return err
+			}
+			return nil
+		}
+	}
+
+	var (
+		warns []spec.Warning
+		errs  []error
+	)
+	for i, t := range r.targets {
+		target := spec.CompletionTarget{
+			Index:         i,
+			Provider:      t.Provider,
+			Model:         fallbackTargetModelName(t),
+			ModelPresetID: string(t.ModelPreset.ID),
+		}
+
+		resp, err := r.fetchTarget(ctx, t, req, baseOpts)
+		if resp != nil {
+			resp.Target = &target
+			resp.Warnings = slices.Concat(warns, resp.Warnings)
+		}
+		if err == nil {
+			return resp, nil
+		}
+
+		errs = append(errs, err)
+		last := i == len(r.targets)-1
+		if last || streamed.Load() || handlerFailed.Load() || ctx.Err() != nil || !r.fallbackEligible(err) {
+			if len(errs) == 1 {
+				return resp, err
+			}
+			return resp, fmt.Errorf("fallback router: %d targets failed: %w", len(errs), errors.Join(errs...))
+		}
+
+		r.ps.requestLogger("", "", opts).WarnContext(
+			ctx,
+			"fallback router: target failed, trying next",
+			"index", i,
+			"provider", target.Provider,
+			"model", target.Model,
+			"error", err,
+		)
+		warns = append(warns, spec.Warning{
+			Code: "fallback_target_failed",
+			Message: fmt.Sprintf(
+				"target %d (%s/%s) failed: %v",
+				i,
+				target.Provider,
+				target.Model,
+				err,
+			),
+		})
+	}
+
+	// Unreachable: the loop always returns on the last target.
+	return nil, errors.Join(errs...)
+}
+
+func (r *FallbackRouter) fetchTarget(
+	ctx context.Context,
+	target FallbackTarget,
+	req *spec.FetchCompletionRequest,
+	baseOpts spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	resolver := target.CapabilityResolver
+	completionKey := target.CompletionKey
+	if completionKey == "" {
+		completionKey = string(target.ModelPreset.ID)
+	}
+	if resolver == nil {
+		base, err := r.ps.GetProviderCapability(ctx, target.Provider)
+		if err != nil {
+			return nil, err
+		}
+		caps := capabilityoverride.DeriveModelCapabilities(base, target.ModelPreset.CapabilitiesOverride)
+		resolver = capabilityoverride.NewCompletionKeyResolver(completionKey, &caps)
+	}
+
+	targetOpts := baseOpts
+	targetOpts.CompletionKey = completionKey
+	targetOpts.CapabilityResolver = resolver
+	// Prices differ per target, so the caller's pricing is never applied to another model.
+	targetOpts.Pricing = nil
+	if target.ModelPreset.Pricing != nil {
+		pricing, err := modelpreset.DeriveModelPricing(target.ModelPreset.Pricing)
+		if err != nil {
+			return nil, err
+		}
+		targetOpts.Pricing = pricing
+	}
+
+	targetReq := *req
+	targetReq.ModelParam = mergeFallbackModelParam(target, req.ModelParam)
+
+	return r.ps.FetchCompletion(ctx, target.Provider, &targetReq, &targetOpts)
+}
+
+func (r *FallbackRouter) fallbackEligible(err error) bool {
+	if r.shouldFallback != nil {
+		return r.shouldFallback(err)
+	}
+	return isFallbackEligibleError(err)
+}
+
+// isFallbackEligibleError reports whether a different target may succeed where this one failed. Provider failures
+// that would repeat on any target (invalid request, content filtering, cancellation) are not eligible; local
+// failures such as an unknown or unconfigured provider or unsupported capabilities are.
+func isFallbackEligibleError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		return true
+	}
+	switch pe.Kind {
+	case spec.ProviderErrorKindInvalidRequest,
+		spec.ProviderErrorKindContentFiltered,
+		spec.ProviderErrorKindCancelled:
+		return false
+	default:
+		return true
+	}
+}
+
+func fallbackTargetModelName(t FallbackTarget) spec.ModelName {
+	if name := spec.ModelName(strings.TrimSpace(string(t.ModelPreset.Name))); name != "" {
+		return name
+	}
+	return spec.ModelName(strings.TrimSpace(string(t.ModelPreset.ModelParam.Name)))
+}
+
+func mergeFallbackModelParam(target FallbackTarget, in spec.ModelParam) spec.ModelParam {
+	out := modelpreset.CloneModelPreset(target.ModelPreset).ModelParam
+	out.Name = fallbackTargetModelName(target)
+
+	out.Stream = in.Stream
+	if in.MaxPromptLength > 0 {
+		out.MaxPromptLength = in.MaxPromptLength
+	}
+	if in.MaxOutputLength > 0 {
+		out.MaxOutputLength = in.MaxOutputLength
+	}
+	if in.Temperature != nil {
+		out.Temperature = sdkutil.CloneFloat64Ptr(in.Temperature)
+	}
+	if in.SystemPrompt != "" {
+		out.SystemPrompt = in.SystemPrompt
+	}
+	if in.Timeout > 0 {
+		out.Timeout = in.Timeout
+	}
+	if in.CacheControl != nil {
+		cc := *in.CacheControl
+		out.CacheControl = &cc
+	}
+	if in.OutputParam != nil {
+		out.OutputParam = in.OutputParam
+	}
+	if in.StopSequences != nil {
+		out.StopSequences = slices.Clone(in.StopSequences)
+	}
+	if in.TopP != nil {
+		out.TopP = sdkutil.CloneFloat64Ptr(in.TopP)
+	}
+	if in.TopK != nil {
+		out.TopK = sdkutil.CloneIntPtr(in.TopK)
+	}
+	if in.Seed != nil {
+		out.Seed = sdkutil.CloneInt64Ptr(in.Seed)
+	}
+	if in.PresencePenalty != nil {
+		out.PresencePenalty = sdkutil.CloneFloat64Ptr(in.PresencePenalty)
+	}
+	if in.FrequencyPenalty != nil {
+		out.FrequencyPenalty = sdkutil.CloneFloat64Ptr(in.FrequencyPenalty)
+	}
+	if in.Candidates > 0 {
+		out.Candidates = in.Candidates
+	}
+	if in.Logprobs != nil {
+		out.Logprobs = new(*in.Logprobs)
+	}
+	if in.Reasoning != nil {
+		reasoning := *in.Reasoning
+		if in.Reasoning.SummaryStyle != nil {
+			reasoning.SummaryStyle = new(*in.Reasoning.SummaryStyle)
+		}
+		out.Reasoning = &reasoning
+	}
+	if in.LogitBias != nil {
+		out.LogitBias = maps.Clone(in.LogitBias)
+	}
+	if in.AdditionalParametersRawJSON != nil {
+		out.AdditionalParametersRawJSON = sdkutil.CloneStringPtr(in.AdditionalParametersRawJSON)
+	}
+	return out
+}
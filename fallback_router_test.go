@@ -0,0 +1,353 @@
+package inference
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/modelpreset"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func newFallbackTestRouter(
+	t *testing.T,
+	providers map[spec.ProviderName]*scriptedProvider,
+	targets []FallbackTarget,
+	opts ...FallbackRouterOption,
+) *FallbackRouter {
+	t.Helper()
+
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	for name, p := range providers {
+		ps.providers[name] = p
+	}
+	r, err := NewFallbackRouter(ps, targets, opts...)
+	if err != nil {
+		t.Fatalf("NewFallbackRouter: %v", err)
+	}
+	return r
+}
+
+func fallbackTestTargets() []FallbackTarget {
+	return []FallbackTarget{
+		{
+			Provider: "primary",
+			ModelPreset: modelpreset.ModelPreset{
+				ID:         "primary-model",
+				Name:       "primary-model",
+				ModelParam: spec.ModelParam{MaxOutputLength: 1024},
+			},
+		},
+		{
+			Provider: "secondary",
+			ModelPreset: modelpreset.ModelPreset{
+				ID:         "secondary-model",
+				ModelParam: spec.ModelParam{Name: "secondary-model", MaxOutputLength: 2048},
+			},
+		},
+	}
+}
+
+func TestFallbackRouterFetchCompletion(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := providerStatusError(http.StatusTooManyRequests, nil)
+	badRequest := providerStatusError(http.StatusBadRequest, nil)
+
+	tests := []struct {
+		name          string
+		primaryErrs   []error
+		primaryStream []bool
+		secondaryErrs []error
+		condition     func(error) bool
+		wantPrimary   int
+		wantSecondary int
+		wantErr       string
+		wantTarget    int
+	}{
+		{
+			name:          "first target succeeds",
+			wantPrimary:   1,
+			wantSecondary: 0,
+			wantTarget:    0,
+		},
+		{
+			name:          "falls over on transient error",
+			primaryErrs:   []error{rateLimited},
+			wantPrimary:   1,
+			wantSecondary: 1,
+			wantTarget:    1,
+		},
+		{
+			name:          "does not fall over on invalid request",
+			primaryErrs:   []error{badRequest},
+			wantPrimary:   1,
+			wantSecondary: 0,
+			wantErr:       "fetch completion failed for provider primary",
+		},
+		{
+			name:          "does not fall over after stream events were delivered",
+			primaryErrs:   []error{rateLimited},
+			primaryStream: []bool{true},
+			wantPrimary:   1,
+			wantSecondary: 0,
+			wantErr:       "fetch completion failed for provider primary",
+		},
+		{
+			name:          "reports all failures when every target fails",
+			primaryErrs:   []error{rateLimited},
+			secondaryErrs: []error{rateLimited},
+			wantPrimary:   1,
+			wantSecondary: 1,
+			wantErr:       "2 targets failed",
+		},
+		{
+			name:          "custom condition is honoured",
+			primaryErrs:   []error{badRequest},
+			condition:     func(error) bool { return true },
+			wantPrimary:   1,
+			wantSecondary: 1,
+			wantTarget:    1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			primary := &scriptedProvider{errs: tc.primaryErrs, stream: tc.primaryStream}
+			secondary := &scriptedProvider{errs: tc.secondaryErrs}
+			var opts []FallbackRouterOption
+			if tc.condition != nil {
+				opts = append(opts, WithFallbackCondition(tc.condition))
+			}
+			r := newFallbackTestRouter(
+				t,
+				map[spec.ProviderName]*scriptedProvider{"primary": primary, "secondary": secondary},
+				fallbackTestTargets(),
+				opts...,
+			)
+
+			req := retryTestRequest()
+			req.ModelParam.Stream = true
+			resp, err := r.FetchCompletion(t.Context(), req, &spec.FetchCompletionOptions{
+				StreamHandler: func(spec.StreamEvent) error { return nil },
+			})
+
+			if primary.calls != tc.wantPrimary || secondary.calls != tc.wantSecondary {
+				t.Fatalf(
+					"calls: got primary=%d secondary=%d want %d/%d",
+					primary.calls,
+					secondary.calls,
+					tc.wantPrimary,
+					tc.wantSecondary,
+				)
+			}
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err: got %v want containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Target == nil || resp.Target.Index != tc.wantTarget {
+				t.Fatalf("target: got %+v want index %d", resp.Target, tc.wantTarget)
+			}
+			if tc.wantTarget > 0 {
+				if len(resp.Warnings) == 0 || resp.Warnings[0].Code != "fallback_target_failed" {
+					t.Fatalf("expected fallback_target_failed warning, got %#v", resp.Warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestFallbackRouterUsesTargetModel(t *testing.T) {
+	t.Parallel()
+
+	primary := &scriptedProvider{errs: []error{providerStatusError(http.StatusServiceUnavailable, nil)}}
+	secondary := &scriptedProvider{}
+	r := newFallbackTestRouter(
+		t,
+		map[spec.ProviderName]*scriptedProvider{"primary": primary, "secondary": secondary},
+		fallbackTestTargets(),
+	)
+
+	resp, err := r.FetchCompletion(t.Context(), retryTestRequest(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := primary.models; len(got) != 1 || got[0] != "primary-model" {
+		t.Fatalf("primary models: got %v", got)
+	}
+	if got := secondary.models; len(got) != 1 || got[0] != "secondary-model" {
+		t.Fatalf("secondary models: got %v", got)
+	}
+	want := spec.CompletionTarget{
+		Index:         1,
+		Provider:      "secondary",
+		Model:         "secondary-model",
+		ModelPresetID: "secondary-model",
+	}
+	if resp.Target == nil || *resp.Target != want {
+		t.Fatalf("target: got %+v want %+v", resp.Target, want)
+	}
+}
+
+func TestFallbackRouterModelSpecificParams(t *testing.T) {
+	t.Parallel()
+
+	presetExtra := `{"preset":true}`
+	callerExtra := `{"caller":true}`
+	targets := fallbackTestTargets()
+	targets[0].ModelPreset.ModelParam.Reasoning = &spec.ReasoningParam{
+		Type:  spec.ReasoningTypeSingleWithLevels,
+		Level: spec.ReasoningLevelLow,
+	}
+	targets[0].ModelPreset.ModelParam.AdditionalParametersRawJSON = &presetExtra
+	targets[1].ModelPreset.ModelParam.LogitBias = map[string]int{"1": 1}
+
+	primary := &scriptedProvider{errs: []error{providerStatusError(http.StatusServiceUnavailable, nil)}}
+	secondary := &scriptedProvider{}
+	r := newFallbackTestRouter(
+		t,
+		map[spec.ProviderName]*scriptedProvider{"primary": primary, "secondary": secondary},
+		targets,
+	)
+
+	req := retryTestRequest()
+	req.ModelParam.Reasoning = &spec.ReasoningParam{
+		Type:  spec.ReasoningTypeSingleWithLevels,
+		Level: spec.ReasoningLevelHigh,
+	}
+	req.ModelParam.AdditionalParametersRawJSON = &callerExtra
+	if _, err := r.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, p := range map[string]*scriptedProvider{"primary": primary, "secondary": secondary} {
+		got := p.params[0]
+		if got.Reasoning == nil || got.Reasoning.Level != spec.ReasoningLevelHigh {
+			t.Fatalf("%s: expected caller reasoning, got %+v", name, got.Reasoning)
+		}
+		if got.AdditionalParametersRawJSON == nil || *got.AdditionalParametersRawJSON != callerExtra {
+			t.Fatalf("%s: expected caller additional parameters, got %v", name, got.AdditionalParametersRawJSON)
+		}
+	}
+	if got := secondary.params[0].LogitBias; got["1"] != 1 {
+		t.Fatalf("expected preset logit bias when the caller left it unset, got %v", got)
+	}
+}
+
+func TestFallbackRouterPricesWithTargetPreset(t *testing.T) {
+	t.Parallel()
+
+	primary := &scriptedProvider{errs: []error{providerStatusError(http.StatusServiceUnavailable, nil)}}
+	secondary := &scriptedProvider{usage: &spec.Usage{InputTokensUncached: 1_000_000, OutputTokens: 1_000_000}}
+	targets := fallbackTestTargets()
+	targets[0].ModelPreset.Pricing = &modelpreset.PricingOverride{InputPerMTok: new(100.0), OutputPerMTok: new(100.0)}
+	targets[1].ModelPreset.Pricing = &modelpreset.PricingOverride{InputPerMTok: new(1.0), OutputPerMTok: new(2.0)}
+	r := newFallbackTestRouter(
+		t,
+		map[spec.ProviderName]*scriptedProvider{"primary": primary, "secondary": secondary},
+		targets,
+	)
+
+	resp, err := r.FetchCompletion(t.Context(), retryTestRequest(), &spec.FetchCompletionOptions{
+		Pricing: &spec.ModelPricing{InputPerMTok: 50, OutputPerMTok: 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Cost == nil || resp.Cost.Total != 3 {
+		t.Fatalf("expected the secondary preset's pricing, got %+v", resp.Cost)
+	}
+}
+
+func TestFallbackRouterUnknownProviderFallsOver(t *testing.T) {
+	t.Parallel()
+
+	secondary := &scriptedProvider{}
+	r := newFallbackTestRouter(
+		t,
+		map[spec.ProviderName]*scriptedProvider{"secondary": secondary},
+		fallbackTestTargets(),
+	)
+
+	resp, err := r.FetchCompletion(t.Context(), retryTestRequest(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secondary.calls != 1 || resp.Target == nil || resp.Target.Provider != "secondary" {
+		t.Fatalf("expected secondary to serve the request, got calls=%d target=%+v", secondary.calls, resp.Target)
+	}
+}
+
+func TestNewFallbackRouterValidation(t *testing.T) {
+	t.Parallel()
+
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ps      *ProviderSetAPI
+		targets []FallbackTarget
+	}{
+		{"nil provider set", nil, fallbackTestTargets()},
+		{"no targets", ps, nil},
+		{"empty provider", ps, []FallbackTarget{{ModelPreset: modelpreset.ModelPreset{Name: "m"}}}},
+		{"empty model", ps, []FallbackTarget{{Provider: "p"}}},
+		{"incomplete pricing", ps, []FallbackTarget{{
+			Provider: "p",
+			ModelPreset: modelpreset.ModelPreset{
+				Name:    "m",
+				Pricing: &modelpreset.PricingOverride{InputPerMTok: new(1.0)},
+			},
+		}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := NewFallbackRouter(tc.ps, tc.targets); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestIsFallbackEligibleError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", providerStatusError(http.StatusTooManyRequests, nil), true},
+		{"auth", providerStatusError(http.StatusUnauthorized, nil), true},
+		{"invalid request", providerStatusError(http.StatusBadRequest, nil), false},
+		{"local error", errors.New("invalid provider"), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isFallbackEligibleError(tc.err); got != tc.want {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -18,10 +20,15 @@ import (
 	"github.com/flexigpt/inference-go/spec"
 )
 
+// anthropicOwnedKeys are the request keys built from typed fields, which
+// additional parameters cannot replace.
+var anthropicOwnedKeys = []string{"model", "messages", "tools", "stream"}
+
 // AnthropicMessagesAPI implements CompletionProvider for Anthropics' Messages API.
 type AnthropicMessagesAPI struct {
 	ProviderParam *spec.ProviderParam
 	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
 	client        *anthropic.Client
 	mu            sync.RWMutex
 }
@@ -30,6 +37,7 @@ type AnthropicMessagesAPI struct {
 func NewAnthropicMessagesAPI(
 	pi spec.ProviderParam,
 	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
 ) (*AnthropicMessagesAPI, error) {
 	if pi.Name == "" {
 		return nil, errors.New("anthropic messages api LLM: invalid args")
@@ -37,6 +45,7 @@ func NewAnthropicMessagesAPI(
 	return &AnthropicMessagesAPI{
 		ProviderParam: &pi,
 		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
 	}, nil
 }
 
@@ -49,7 +58,7 @@ func (api *AnthropicMessagesAPI) InitLLM(ctx context.Context) error {
 	}
 
 	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
-		logutil.Debug(
+		api.logger.Debug(
 			string(
 				api.ProviderParam.Name,
 			) + ": No API key given. Not initializing Anthropics client",
@@ -59,12 +68,36 @@ func (api *AnthropicMessagesAPI) InitLLM(ctx context.Context) error {
 	}
 
 	pi := *api.ProviderParam // snapshot under lock
-	opts := []option.RequestOption{
+	var httpClient *http.Client
+	if api.debugger != nil {
+		httpClient = api.debugger.HTTPClient(nil)
+	}
+	opts, providerURL := anthropicClientOptions(pi, httpClient)
+
+	c := anthropic.NewClient(opts...)
+	api.client = &c
+	api.logger.Info(
+		"anthropic messages api LLM provider initialized",
+		"name", string(pi.Name),
+		"URL", providerURL,
+	)
+	return nil
+}
+
+// anthropicClientOptions returns the options of a client for pi and the URL it calls. httpClient may be nil.
+func anthropicClientOptions(
+	pi spec.ProviderParam,
+	httpClient *http.Client,
+) (opts []option.RequestOption, providerURL string) {
+	opts = []option.RequestOption{
 		// Sets x-api-key.
 		option.WithAPIKey(pi.APIKey),
+		// Retries belong to the provider set's RetryPolicy; SDK retries would multiply its attempts and hide them
+		// from the debugger.
+		option.WithMaxRetries(0),
 	}
 
-	providerURL := spec.DefaultAnthropicOrigin
+	providerURL = spec.DefaultAnthropicOrigin
 	if pi.Origin != "" {
 		baseURL := strings.TrimSuffix(pi.Origin, "/")
 		// Remove 'v1/messages' from pathPrefix if present,
@@ -98,20 +131,10 @@ func (api *AnthropicMessagesAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
-		}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
-
-	c := anthropic.NewClient(opts...)
-	api.client = &c
-	logutil.Info(
-		"anthropic messages api LLM provider initialized",
-		"name", string(pi.Name),
-		"URL", providerURL,
-	)
-	return nil
+	return opts, providerURL
 }
 
 func (api *AnthropicMessagesAPI) DeInitLLM(ctx context.Context) error {
@@ -122,7 +145,7 @@ func (api *AnthropicMessagesAPI) DeInitLLM(ctx context.Context) error {
 	}
 	api.client = nil
 	api.mu.Unlock()
-	logutil.Info(
+	api.logger.Info(
 		"anthropic messages api LLM: provider de initialized",
 		"name",
 		string(name),
@@ -180,18 +203,218 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 	if client == nil {
 		return nil, errors.New("anthropic messages api LLM: client not initialized")
 	}
+	call, err := buildAnthropicCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *anthropic.Message
+		apiErr         error
+	)
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(
+			ctx,
+			client,
+			pi.Name,
+			req.ModelParam.Name,
+			call.params,
+			opts,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			call.params,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	}
+
+	if apiErr != nil {
+		apiErr = anthropicProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CountTokens counts the input tokens of a request with the Messages count_tokens endpoint.
+func (api *AnthropicMessagesAPI) CountTokens(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.TokenCount, error) {
+	api.mu.RLock()
+	client := api.client
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("anthropic messages api LLM: client not initialized")
+	}
+	call, err := buildAnthropicCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	params := anthropic.MessageCountTokensParams{
+		Messages:     call.params.Messages,
+		Model:        call.params.Model,
+		CacheControl: call.params.CacheControl,
+		OutputConfig: call.params.OutputConfig,
+		Thinking:     call.params.Thinking,
+		ToolChoice:   call.params.ToolChoice,
+	}
+	if len(call.params.System) > 0 {
+		params.System = anthropic.MessageCountTokensParamsSystemUnion{OfTextBlockArray: call.params.System}
+	}
+	for _, t := range call.params.Tools {
+		params.Tools = append(params.Tools, anthropic.MessageCountTokensToolUnionParam(t))
+	}
+
+	res, err := client.Messages.CountTokens(ctx, params, option.WithRequestTimeout(call.timeout))
+	if err != nil {
+		return nil, anthropicProviderError(pi.Name, err)
+	}
+	return &spec.TokenCount{
+		InputTokens: int(res.InputTokens),
+		Source:      spec.TokenCountSourceProvider,
+		Exact:       true,
+		Warnings:    call.warns,
+	}, nil
+}
+
+// CompileRequest builds the Messages API request for a completion and returns it without sending it. The request
+// goes through a client built like the one InitLLM builds, including the debugger's HTTP client; without an API key
+// it carries sdkutil.CompileAPIKey.
+func (api *AnthropicMessagesAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	hasProvider := api.ProviderParam != nil
+	if hasProvider {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if !hasProvider {
+		return nil, errors.New("anthropic messages api LLM: no ProviderParam found")
+	}
+	if strings.TrimSpace(pi.APIKey) == "" {
+		pi.APIKey = sdkutil.CompileAPIKey
+	}
+	call, err := buildAnthropicCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	clientOpts, _ := anthropicClientOptions(pi, capture.HTTPClient(api.debugger))
+	client := anthropic.NewClient(clientOpts...)
+	if call.req.ModelParam.Stream {
+		stream := client.Messages.NewStreaming(captureCtx, call.params, call.requestOptions()...)
+		err = stream.Err()
+		_ = stream.Close()
+	} else {
+		_, err = client.Messages.New(captureCtx, call.params, call.requestOptions()...)
+	}
+
+	compiled, err := capture.CompiledRequest(err, pi.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// anthropicCall is a normalized request together with the Messages API
+// parameters built from it.
+type anthropicCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	params            anthropic.MessageNewParams
+	timeout           time.Duration
+	toolChoiceNameMap map[string]spec.ToolChoice
+	additionalParams  *string
+	warns             []spec.Warning
+}
+
+// requestOptions returns opts plus the middleware that merges the request's
+// additional parameters into the serialized body.
+func (c *anthropicCall) requestOptions(opts ...option.RequestOption) []option.RequestOption {
+	if mw := sdkutil.AdditionalParametersMiddleware(c.additionalParams, anthropicOwnedKeys...); mw != nil {
+		opts = append(opts, option.WithMiddleware(mw))
+	}
+	return opts
+}
+
+// buildAnthropicCall normalizes a request against the provider capabilities
+// and builds the Messages API parameters for it.
+func buildAnthropicCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+) (*anthropicCall, error) {
 	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
 		return nil, errors.New("anthropic messages api LLM: empty completion data")
 	}
-	req, _, warns, err := sdkutil.NormalizeRequestForSDK(
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
 		ctx, inReq, opts, spec.ProviderSDKTypeAnthropic, anthropicsdkCapability,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
 
 	// Decide if we must override thinking based on interleaved input history.
-	thinkingAnalysis := analyzeAnthropicThinkingBehavior(req.Inputs)
+	thinkingAnalysis := analyzeAnthropicThinkingBehavior(ctx, req.Inputs)
 
 	// Build Anthropic input messages + system blocks.
 	msgs, sysParams, err := toAnthropicMessagesInput(
@@ -218,7 +441,7 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 	applyAnthropicTopLevelCacheControl(&params, req.ModelParam.CacheControl)
 
 	// Apply thinking / temperature in a robust, policy-driven way.
-	applyAnthropicThinkingPolicy(&params, &req.ModelParam, thinkingAnalysis)
+	applyAnthropicThinkingPolicy(ctx, &params, &req.ModelParam, thinkingAnalysis)
 
 	timeout := spec.DefaultAPITimeout
 	if req.ModelParam.Timeout > 0 {
@@ -239,7 +462,7 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 
 	var toolChoiceNameMap map[string]spec.ToolChoice
 	if len(req.ToolChoices) > 0 {
-		toolDefs, nameMap, err := toolChoicesToAnthropicTools(req.ToolChoices)
+		toolDefs, nameMap, err := toolChoicesToAnthropicTools(ctx, req.ToolChoices)
 		if err != nil {
 			return nil, err
 		}
@@ -255,69 +478,33 @@ func (api *AnthropicMessagesAPI) FetchCompletion(
 		}
 	}
 
-	var span spec.CompletionSpan
-	if api.debugger != nil {
-		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
-			Provider: pi.Name,
-			Model:    req.ModelParam.Name,
-			Request:  req,
-			Options:  opts,
-		})
-	}
-
-	var (
-		normalizedResp *spec.FetchCompletionResponse
-		fullRawResp    *anthropic.Message
-		apiErr         error
-	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
-		normalizedResp, fullRawResp, apiErr = api.doStreaming(
-			ctx,
-			client,
-			pi.Name,
-			req.ModelParam.Name,
-			params,
-			opts,
-			timeout,
-			toolChoiceNameMap,
-		)
-	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
-	}
-
-	if normalizedResp != nil && len(warns) > 0 {
-		normalizedResp.Warnings = append(normalizedResp.Warnings, warns...)
-	}
-
-	if span != nil {
-		end := spec.CompletionSpanEnd{
-			ProviderResponse: fullRawResp,
-			Response:         normalizedResp, // may be nil
-			Err:              apiErr,
-		}
-		if normalizedResp != nil {
-			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
-				normalizedResp.DebugDetails = dd
-			}
-		} else {
-			_ = span.End(&end) // ignore return; nothing to attach to
-		}
+	additionalParams := req.ModelParam.AdditionalParametersRawJSON
+	extraWarns, err := sdkutil.AdditionalParametersWarnings(params, additionalParams, anthropicOwnedKeys...)
+	if err != nil {
+		return nil, err
 	}
 
-	return normalizedResp, apiErr
+	return &anthropicCall{
+		req:               req,
+		capabilities:      caps,
+		params:            params,
+		timeout:           timeout,
+		toolChoiceNameMap: toolChoiceNameMap,
+		additionalParams:  additionalParams,
+		warns:             append(warns, extraWarns...),
+	}, nil
 }
 
 func (api *AnthropicMessagesAPI) doNonStreaming(
 	ctx context.Context,
 	client *anthropic.Client,
 	params anthropic.MessageNewParams,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *anthropic.Message, error) {
 	resp := &spec.FetchCompletionResponse{}
 
-	anthropicMsg, err := client.Messages.New(ctx, params, option.WithRequestTimeout(timeout))
+	anthropicMsg, err := client.Messages.New(ctx, params, reqOpts...)
 
 	resp.Usage = usageFromAnthropicMessage(anthropicMsg)
 	if err != nil {
@@ -325,6 +512,7 @@ func (api *AnthropicMessagesAPI) doNonStreaming(
 		return resp, anthropicMsg, err
 	}
 	resp.Outputs = outputsFromAnthropicMessage(anthropicMsg, toolChoiceNameMap)
+	resp.StopReason = stopReasonFromAnthropicMessage(anthropicMsg)
 	return resp, anthropicMsg, nil
 }
 
@@ -335,58 +523,14 @@ func (api *AnthropicMessagesAPI) doStreaming(
 	modelName spec.ModelName,
 	params anthropic.MessageNewParams,
 	opts *spec.FetchCompletionOptions,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *anthropic.Message, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-
-	emitText := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:          spec.StreamContentKindText,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newAnthropicStreamEvents(emitter, toolChoiceNameMap)
 
-	emitThinking := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:          spec.StreamContentKindThinking,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Thinking:      &spec.StreamThinkingChunk{Text: chunk},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	writeTextData, flushTextData := sdkutil.NewBufferedStreamerWithError(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-	writeThinkingData, flushThinkingData := sdkutil.NewBufferedStreamerWithError(
-		emitThinking,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-
-	stream := client.Messages.NewStreaming(
-		ctx,
-		params,
-		option.WithRequestTimeout(timeout),
-	)
+	stream := client.Messages.NewStreaming(ctx, params, reqOpts...)
 	defer func() { _ = stream.Close() }()
 
 	var (
@@ -413,24 +557,19 @@ func (api *AnthropicMessagesAPI) doStreaming(
 		switch eventVariant := event.AsAny().(type) {
 		case anthropic.MessageStartEvent:
 			// Contains a Message object with empty content (metadata only).
+			events.messageID = eventVariant.Message.ID
 		case anthropic.MessageDeltaEvent:
-			// Top-level message metadata changes; nothing to stream to user.
+			// Top-level message metadata changes; reported with the completed event.
 		case anthropic.MessageStopEvent:
 			// Conversation turn complete.
 			sawMessageStop = true
 
 		case anthropic.ContentBlockStopEvent:
-			// Content block done.
+			streamWriteErr = events.handleContentBlockStop(eventVariant)
 		case anthropic.ContentBlockStartEvent:
-			streamWriteErr = handleContentBlockStartEvent(eventVariant, writeTextData, writeThinkingData)
-			if streamWriteErr != nil {
-				break
-			}
+			streamWriteErr = events.handleContentBlockStart(eventVariant)
 		case anthropic.ContentBlockDeltaEvent:
-			streamWriteErr = handleContentBlockDeltaEvent(eventVariant, writeTextData, writeThinkingData)
-			if streamWriteErr != nil {
-				break
-			}
+			streamWriteErr = events.handleContentBlockDelta(eventVariant)
 		default:
 			// No valid variant.
 		}
@@ -440,14 +579,7 @@ func (api *AnthropicMessagesAPI) doStreaming(
 		}
 	}
 
-	var flushErr error
-	if flushTextData != nil {
-		flushErr = errors.Join(flushErr, flushTextData())
-	}
-
-	if flushThinkingData != nil {
-		flushErr = errors.Join(flushErr, flushThinkingData())
-	}
+	flushErr := emitter.Close()
 
 	iteratorErr := stream.Err()
 	if !sawMessageStop &&
@@ -458,10 +590,17 @@ func (api *AnthropicMessagesAPI) doStreaming(
 			"anthropic stream ended before message_stop",
 		)
 	}
+	if iteratorErr == nil && streamAccumulateErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(
+			usageFromAnthropicMessage(&respFull),
+			stopReasonFromAnthropicMessage(&respFull),
+		)
+	}
 
 	streamErr := errors.Join(iteratorErr, streamAccumulateErr, streamWriteErr, flushErr)
 	if streamErr != nil {
-		logutil.Error(
+		logutil.ErrorContext(
+			ctx,
 			"anthropic messages stream terminated",
 			"provider", string(providerName),
 			"model", string(modelName),
@@ -482,51 +621,10 @@ func (api *AnthropicMessagesAPI) doStreaming(
 		resp.Error = &spec.Error{Message: streamErr.Error()}
 	}
 	resp.Outputs = outputsFromAnthropicMessage(&respFull, toolChoiceNameMap)
+	resp.StopReason = stopReasonFromAnthropicMessage(&respFull)
 	return resp, &respFull, streamErr
 }
 
-func handleContentBlockStartEvent(
-	event anthropic.ContentBlockStartEvent,
-	writeTextData, writeThinkingData func(string) error,
-) error {
-	switch cb := event.ContentBlock.AsAny().(type) {
-	case anthropic.TextBlock:
-		return writeTextData(cb.Text)
-
-	case anthropic.ThinkingBlock:
-		return writeThinkingData(cb.Thinking)
-
-	case anthropic.RedactedThinkingBlock:
-		// We don't stream redacted thinking to the caller.
-	case anthropic.ToolUseBlock:
-	case anthropic.ServerToolUseBlock:
-	case anthropic.WebSearchToolResultBlock:
-	default:
-		// Unknown or future content block type.
-	}
-	return nil
-}
-
-func handleContentBlockDeltaEvent(
-	event anthropic.ContentBlockDeltaEvent,
-	writeTextData, writeThinkingData func(string) error,
-) error {
-	switch delta := event.Delta.AsAny().(type) {
-	case anthropic.TextDelta:
-		return writeTextData(delta.Text)
-
-	case anthropic.ThinkingDelta:
-		return writeThinkingData(delta.Thinking)
-
-	case anthropic.InputJSONDelta:
-	case anthropic.CitationsDelta:
-	case anthropic.SignatureDelta:
-	default:
-		// Unknown or future delta variant.
-	}
-	return nil
-}
-
 func applyAnthropicOutputParam(params *anthropic.MessageNewParams, op *spec.OutputParam) error {
 	if params == nil || op == nil {
 		// Do not send anything if caller didn't request.
@@ -648,6 +746,7 @@ func applyAnthropicToolPolicy(
 }
 
 func toolChoicesToAnthropicTools(
+	ctx context.Context,
 	toolChoices []spec.ToolChoice,
 ) ([]anthropic.ToolUnionParam, map[string]spec.ToolChoice, error) {
 	if len(toolChoices) == 0 {
@@ -735,7 +834,8 @@ func toolChoicesToAnthropicTools(
 			wsTool := anthropic.WebSearchTool20250305Param{}
 
 			if len(ws.AllowedDomains) > 0 && len(ws.BlockedDomains) > 0 {
-				logutil.Warn(
+				logutil.WarnContext(
+					ctx,
 					"anthropic: web_search tool has both allowed_domains and blocked_domains; using allowed_domains only",
 					"toolID",
 					tc.ID,
@@ -1089,6 +1189,33 @@ func mapAnthropicStopReasonToStatus(stopReason anthropic.StopReason) spec.Status
 	return spec.StatusCompleted
 }
 
+func stopReasonFromAnthropicMessage(msg *anthropic.Message) *spec.StopReason {
+	if msg == nil || msg.StopReason == "" {
+		return nil
+	}
+	out := &spec.StopReason{Raw: string(msg.StopReason)}
+	switch msg.StopReason {
+	case anthropic.StopReasonEndTurn:
+		out.Kind = spec.StopReasonEndTurn
+	case anthropic.StopReasonMaxTokens:
+		out.Kind = spec.StopReasonMaxTokens
+	case anthropic.StopReasonStopSequence:
+		out.Kind = spec.StopReasonStopSequence
+		out.Sequence = msg.StopSequence
+	case anthropic.StopReasonToolUse:
+		out.Kind = spec.StopReasonToolUse
+	case anthropic.StopReasonPauseTurn:
+		out.Kind = spec.StopReasonPauseTurn
+	case anthropic.StopReasonRefusal:
+		out.Kind = spec.StopReasonRefusal
+	case anthropic.StopReasonModelContextWindowExceeded:
+		out.Kind = spec.StopReasonContextWindowExceeded
+	default:
+		out.Kind = spec.StopReasonOther
+	}
+	return out
+}
+
 // usageFromAnthropicMessage normalizes Anthropic usage into spec.Usage.
 func usageFromAnthropicMessage(msg *anthropic.Message) *spec.Usage {
 	uOut := &spec.Usage{}
@@ -1102,8 +1229,22 @@ func usageFromAnthropicMessage(msg *anthropic.Message) *spec.Usage {
 	uOut.InputTokensUncached = u.InputTokens + u.CacheCreationInputTokens
 	uOut.InputTokensTotal = u.CacheReadInputTokens + u.InputTokens + u.CacheCreationInputTokens
 	uOut.OutputTokens = u.OutputTokens
-	// Anthropic does not currently expose explicit reasoning token counts.
-	uOut.ReasoningTokens = 0
+	uOut.ReasoningTokens = u.OutputTokensDetails.ThinkingTokens
+
+	uOut.InputTokensCacheWrite = u.CacheCreationInputTokens
+	for ttl, n := range map[spec.CacheControlTTL]int64{
+		spec.CacheControlTTL5m: u.CacheCreation.Ephemeral5mInputTokens,
+		spec.CacheControlTTL1h: u.CacheCreation.Ephemeral1hInputTokens,
+	} {
+		if n == 0 {
+			continue
+		}
+		if uOut.InputTokensCacheWriteByTTL == nil {
+			uOut.InputTokensCacheWriteByTTL = map[spec.CacheControlTTL]int64{}
+		}
+		uOut.InputTokensCacheWriteByTTL[ttl] = n
+	}
+	uOut.WebSearchRequests = u.ServerToolUse.WebSearchRequests
 
 	return uOut
 }
@@ -37,6 +37,11 @@ var anthropicsdkCapability = spec.ModelCapabilities{
 		DisallowedWithReasoning: false,
 		MaxSequences:            0,
 	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:            true,
+		SupportsTopK:            true,
+		DisallowedWithReasoning: true,
+	},
 	OutputCapabilities: &spec.OutputCapabilities{
 		SupportedOutputFormats: []spec.OutputFormatKind{spec.OutputFormatKindText, spec.OutputFormatKindJSONSchema},
 		SupportsVerbosity:      true, // Maps to effort.
@@ -34,7 +34,7 @@ type anthropicTurnDraft struct {
 // toAnthropicMessagesInput converts a sequence of generic InputUnion items into
 // Anthropic MessageParam and system prompt blocks.
 func toAnthropicMessagesInput(
-	_ context.Context,
+	ctx context.Context,
 	systemPrompt string,
 	inputs []spec.InputUnion,
 ) (msgs []anthropic.MessageParam, sysPrompts []anthropic.TextBlockParam, err error) {
@@ -50,7 +50,7 @@ func toAnthropicMessagesInput(
 		if sdkutil.IsInputUnionEmpty(in) {
 			continue
 		}
-		part, partErr := inputUnionToAnthropicPart(in)
+		part, partErr := inputUnionToAnthropicPart(ctx, in)
 		if partErr != nil {
 			if errors.Is(partErr, errEmptyInputPart) {
 				continue
@@ -124,13 +124,13 @@ func appendAnthropicTurnPart(
 	return turns
 }
 
-func inputUnionToAnthropicPart(in spec.InputUnion) (*anthropicInputPart, error) {
+func inputUnionToAnthropicPart(ctx context.Context, in spec.InputUnion) (*anthropicInputPart, error) {
 	switch in.Kind {
 	case spec.InputKindInputMessage:
 		if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
 			return nil, errEmptyInputPart
 		}
-		blocks := contentItemsToAnthropicContentBlocks(in.InputMessage.Contents)
+		blocks := contentItemsToAnthropicContentBlocks(ctx, in.InputMessage.Contents)
 		blocks = applyAnthropicContentBlockCacheControl(blocks, in.InputMessage.CacheControl)
 		if len(blocks) == 0 {
 			return nil, errEmptyInputPart
@@ -144,7 +144,7 @@ func inputUnionToAnthropicPart(in spec.InputUnion) (*anthropicInputPart, error)
 		if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
 			return nil, errEmptyInputPart
 		}
-		blocks := contentItemsToAnthropicContentBlocks(in.OutputMessage.Contents)
+		blocks := contentItemsToAnthropicContentBlocks(ctx, in.OutputMessage.Contents)
 		blocks = applyAnthropicContentBlockCacheControl(blocks, in.OutputMessage.CacheControl)
 		if len(blocks) == 0 {
 			return nil, errEmptyInputPart
@@ -219,7 +219,7 @@ func inputUnionToAnthropicPart(in spec.InputUnion) (*anthropicInputPart, error)
 			}
 		}
 
-		block := toolOutputToAnthropicBlocks(output)
+		block := toolOutputToAnthropicBlocks(ctx, output)
 		if block == nil {
 			return nil, errEmptyInputPart
 		}
@@ -342,6 +342,7 @@ func validateAnthropicToolTurnOrdering(turns []anthropicTurnDraft) error {
 // contentItemsToAnthropicContentBlocks converts generic content items into Anthropic
 // content blocks (text/image/document).
 func contentItemsToAnthropicContentBlocks(
+	ctx context.Context,
 	items []spec.InputOutputContentItemUnion,
 ) []anthropic.ContentBlockParamUnion {
 	if len(items) == 0 {
@@ -364,7 +365,7 @@ func contentItemsToAnthropicContentBlocks(
 			}
 
 		case spec.ContentItemKindFile:
-			db := contentItemFileToAnthropicDocumentBlockParam(it.FileItem)
+			db := contentItemFileToAnthropicDocumentBlockParam(ctx, it.FileItem)
 			if db != nil {
 				out = append(out, anthropic.ContentBlockParamUnion{OfDocument: db})
 			}
@@ -376,7 +377,7 @@ func contentItemsToAnthropicContentBlocks(
 			continue
 
 		default:
-			logutil.Debug("anthropic: unknown content item kind for message", "kind", it.Kind)
+			logutil.DebugContext(ctx, "anthropic: unknown content item kind for message", "kind", it.Kind)
 		}
 	}
 	if len(out) == 0 {
@@ -475,6 +476,7 @@ func anthropicToolResultIDFromOutput(toolOutput *spec.ToolOutput) string {
 }
 
 func toolOutputToAnthropicBlocks(
+	ctx context.Context,
 	toolOutput *spec.ToolOutput,
 ) *anthropic.ContentBlockParamUnion {
 	if toolOutput == nil || strings.TrimSpace(toolOutput.CallID) == "" {
@@ -483,7 +485,7 @@ func toolOutputToAnthropicBlocks(
 
 	switch toolOutput.Type {
 	case spec.ToolTypeFunction, spec.ToolTypeCustom:
-		items := contentItemsToAnthropicToolResultBlocks(toolOutput.Contents)
+		items := contentItemsToAnthropicToolResultBlocks(ctx, toolOutput.Contents)
 		if len(items) == 0 {
 			return nil
 		}
@@ -518,6 +520,7 @@ func toolOutputToAnthropicBlocks(
 }
 
 func contentItemsToAnthropicToolResultBlocks(
+	ctx context.Context,
 	items []spec.ToolOutputItemUnion,
 ) []anthropic.ToolResultBlockParamContentUnion {
 	if len(items) == 0 {
@@ -540,14 +543,14 @@ func contentItemsToAnthropicToolResultBlocks(
 			}
 
 		case spec.ContentItemKindFile:
-			db := contentItemFileToAnthropicDocumentBlockParam(it.FileItem)
+			db := contentItemFileToAnthropicDocumentBlockParam(ctx, it.FileItem)
 			if db != nil {
 				out = append(out, anthropic.ToolResultBlockParamContentUnion{OfDocument: db})
 			}
 		case spec.ContentItemKindRefusal:
 			// Invalid for this.
 		default:
-			logutil.Debug("anthropic: unknown content item kind for message", "kind", it.Kind)
+			logutil.DebugContext(ctx, "anthropic: unknown content item kind for message", "kind", it.Kind)
 		}
 	}
 	if len(out) == 0 {
@@ -687,7 +690,10 @@ func contentItemImageToAnthropicImageBlockParam(imageItem *spec.ContentItemImage
 	return nil
 }
 
-func contentItemFileToAnthropicDocumentBlockParam(fileItem *spec.ContentItemFile) *anthropic.DocumentBlockParam {
+func contentItemFileToAnthropicDocumentBlockParam(
+	ctx context.Context,
+	fileItem *spec.ContentItemFile,
+) *anthropic.DocumentBlockParam {
 	if fileItem == nil {
 		return nil
 	}
@@ -717,7 +723,7 @@ func contentItemFileToAnthropicDocumentBlockParam(fileItem *spec.ContentItemFile
 	case data != "" && strings.HasPrefix(mime, "text/"):
 		// For plain text, Anthropic expects actual text, not base64. If you
 		// want to support this fully, decode base64 here. For now we skip.
-		logutil.Debug("anthropic: skipping non-pdf base64 file; plain-text decoding not implemented",
+		logutil.DebugContext(ctx, "anthropic: skipping non-pdf base64 file; plain-text decoding not implemented",
 			"id", fileItem.ID, "name", fileItem.FileName, "mime", mime)
 	default:
 		// Other file types not supported as document blocks.
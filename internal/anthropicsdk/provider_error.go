@@ -0,0 +1,35 @@
+package anthropicsdk
+
+import (
+	"errors"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// anthropicProviderError maps an Anthropic SDK / stream error into a spec.ProviderError.
+// Errors delivered inside the SSE stream carry no HTTP status and are classified from the
+// embedded error type (e.g. "overloaded_error").
+func anthropicProviderError(provider spec.ProviderName, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var details sdkutil.ProviderErrorDetails
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		details.HTTPStatus = apiErr.StatusCode
+		details.ProviderCode = string(apiErr.Type())
+		details.RequestID = apiErr.RequestID
+		if apiErr.Response != nil {
+			details.Header = apiErr.Response.Header
+			if details.RequestID == "" {
+				details.RequestID = apiErr.Response.Header.Get("request-id")
+			}
+		}
+	}
+
+	return sdkutil.NewProviderError(provider, err, details)
+}
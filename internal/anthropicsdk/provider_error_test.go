@@ -0,0 +1,47 @@
+package anthropicsdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAnthropicProviderError(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://api.anthropic.com/v1/messages")
+	h := http.Header{}
+	h.Set("retry-after", "3")
+	apiErr := &anthropic.Error{
+		StatusCode: http.StatusTooManyRequests,
+		RequestID:  "req_123",
+		Request:    &http.Request{Method: http.MethodPost, URL: u},
+		Response:   &http.Response{StatusCode: http.StatusTooManyRequests, Header: h},
+	}
+
+	err := anthropicProviderError("anthropic", fmt.Errorf("call: %w", apiErr))
+
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *spec.ProviderError, got %T", err)
+	}
+	if pe.Kind != spec.ProviderErrorKindRateLimited ||
+		pe.HTTPStatus != http.StatusTooManyRequests ||
+		pe.RequestID != "req_123" ||
+		pe.RetryAfter != 3*time.Second ||
+		pe.Provider != "anthropic" {
+		t.Fatalf("unexpected provider error: %+v", pe)
+	}
+
+	var got *anthropic.Error
+	if !errors.As(err, &got) || got != apiErr {
+		t.Fatal("expected the SDK error to remain reachable via errors.As")
+	}
+}
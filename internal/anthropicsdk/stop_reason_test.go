@@ -0,0 +1,61 @@
+package anthropicsdk
+
+import (
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStopReasonFromAnthropicMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		msg  *anthropic.Message
+		want *spec.StopReason
+	}{
+		{name: "nil message", msg: nil, want: nil},
+		{name: "no stop reason", msg: &anthropic.Message{}, want: nil},
+		{
+			name: "end turn",
+			msg:  &anthropic.Message{StopReason: anthropic.StopReasonEndTurn},
+			want: &spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "end_turn"},
+		},
+		{
+			name: "stop sequence",
+			msg:  &anthropic.Message{StopReason: anthropic.StopReasonStopSequence, StopSequence: "END"},
+			want: &spec.StopReason{Kind: spec.StopReasonStopSequence, Sequence: "END", Raw: "stop_sequence"},
+		},
+		{
+			name: "pause turn",
+			msg:  &anthropic.Message{StopReason: anthropic.StopReasonPauseTurn},
+			want: &spec.StopReason{Kind: spec.StopReasonPauseTurn, Raw: "pause_turn"},
+		},
+		{
+			name: "context window exceeded",
+			msg:  &anthropic.Message{StopReason: anthropic.StopReasonModelContextWindowExceeded},
+			want: &spec.StopReason{
+				Kind: spec.StopReasonContextWindowExceeded,
+				Raw:  string(anthropic.StopReasonModelContextWindowExceeded),
+			},
+		},
+		{
+			name: "unknown",
+			msg:  &anthropic.Message{StopReason: "something_new"},
+			want: &spec.StopReason{Kind: spec.StopReasonOther, Raw: "something_new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := stopReasonFromAnthropicMessage(tt.msg)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
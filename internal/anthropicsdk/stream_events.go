@@ -0,0 +1,223 @@
+package anthropicsdk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicSharedConstant "github.com/anthropics/anthropic-sdk-go/shared/constant"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// anthropicStreamBlock tracks one in-flight content block of a Messages stream.
+type anthropicStreamBlock struct {
+	kind     spec.OutputKind
+	id       string
+	name     string
+	toolType spec.ToolType
+	args     strings.Builder
+}
+
+// anthropicStreamEvents maps Messages stream events to normalized stream events.
+// Content block indexes are used as output indexes.
+type anthropicStreamEvents struct {
+	emitter           *sdkutil.StreamEmitter
+	toolChoiceNameMap map[string]spec.ToolChoice
+	messageID         string
+	blocks            map[int64]*anthropicStreamBlock
+}
+
+func newAnthropicStreamEvents(
+	emitter *sdkutil.StreamEmitter,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) *anthropicStreamEvents {
+	return &anthropicStreamEvents{
+		emitter:           emitter,
+		toolChoiceNameMap: toolChoiceNameMap,
+		blocks:            map[int64]*anthropicStreamBlock{},
+	}
+}
+
+func (s *anthropicStreamEvents) handleContentBlockStart(event anthropic.ContentBlockStartEvent) error {
+	idx := int(event.Index)
+	b := &anthropicStreamBlock{}
+
+	switch cb := event.ContentBlock.AsAny().(type) {
+	case anthropic.TextBlock:
+		b.kind = spec.OutputKindOutputMessage
+		b.id = s.messageID
+		s.blocks[event.Index] = b
+		if err := s.emitter.OutputItemStart(idx, b.kind, b.id); err != nil {
+			return err
+		}
+		return s.emitter.WriteText(cb.Text)
+
+	case anthropic.ThinkingBlock:
+		b.kind = spec.OutputKindReasoningMessage
+		b.id = s.messageID
+		s.blocks[event.Index] = b
+		if err := s.emitter.OutputItemStart(idx, b.kind, b.id); err != nil {
+			return err
+		}
+		return s.emitter.WriteThinking(cb.Thinking)
+
+	case anthropic.RedactedThinkingBlock:
+		// We don't stream redacted thinking to the caller, only its boundaries.
+		b.kind = spec.OutputKindReasoningMessage
+		b.id = s.messageID
+		s.blocks[event.Index] = b
+		return s.emitter.OutputItemStart(idx, b.kind, b.id)
+
+	case anthropic.ToolUseBlock:
+		b.kind = spec.OutputKindFunctionToolCall
+		b.toolType = spec.ToolTypeFunction
+		if tc, ok := s.toolChoiceNameMap[strings.TrimSpace(cb.Name)]; ok && tc.Type == spec.ToolTypeCustom {
+			b.kind = spec.OutputKindCustomToolCall
+			b.toolType = spec.ToolTypeCustom
+		}
+		b.id = cb.ID
+		b.name = cb.Name
+		s.blocks[event.Index] = b
+		if err := s.emitter.OutputItemStart(idx, b.kind, b.id); err != nil {
+			return err
+		}
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallStart, spec.StreamToolCallChunk{
+			OutputIndex: idx,
+			Type:        b.toolType,
+			CallID:      b.id,
+			Name:        b.name,
+		})
+
+	case anthropic.ServerToolUseBlock:
+		b.kind = spec.OutputKindWebSearchToolCall
+		b.id = cb.ID
+		s.blocks[event.Index] = b
+		if err := s.emitter.OutputItemStart(idx, b.kind, b.id); err != nil {
+			return err
+		}
+		return s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      b.id,
+			Status:      spec.StatusInProgress,
+		})
+
+	case anthropic.WebSearchToolResultBlock:
+		b.kind = spec.OutputKindWebSearchToolOutput
+		b.id = cb.ToolUseID
+		s.blocks[event.Index] = b
+		if err := s.emitter.OutputItemStart(idx, b.kind, b.id); err != nil {
+			return err
+		}
+		status := spec.StatusCompleted
+		if cb.Content.ErrorCode != "" {
+			status = spec.StatusFailed
+		}
+		return s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      b.id,
+			Status:      status,
+		})
+
+	default:
+		// Unknown or future content block type.
+	}
+	return nil
+}
+
+func (s *anthropicStreamEvents) handleContentBlockDelta(event anthropic.ContentBlockDeltaEvent) error {
+	switch delta := event.Delta.AsAny().(type) {
+	case anthropic.TextDelta:
+		return s.emitter.WriteText(delta.Text)
+
+	case anthropic.ThinkingDelta:
+		return s.emitter.WriteThinking(delta.Thinking)
+
+	case anthropic.InputJSONDelta:
+		b := s.blocks[event.Index]
+		if b == nil || delta.PartialJSON == "" {
+			return nil
+		}
+		b.args.WriteString(delta.PartialJSON)
+		if b.kind == spec.OutputKindWebSearchToolCall {
+			// Server tool input is surfaced once complete, as the search query.
+			return nil
+		}
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, spec.StreamToolCallChunk{
+			OutputIndex:    int(event.Index),
+			Type:           b.toolType,
+			CallID:         b.id,
+			Name:           b.name,
+			ArgumentsDelta: delta.PartialJSON,
+		})
+
+	case anthropic.CitationsDelta:
+		c, ok := anthropicCitationDeltaToSpec(delta.Citation)
+		if !ok {
+			return nil
+		}
+		return s.emitter.Citation(int(event.Index), c)
+
+	case anthropic.SignatureDelta:
+	default:
+		// Unknown or future delta variant.
+	}
+	return nil
+}
+
+func (s *anthropicStreamEvents) handleContentBlockStop(event anthropic.ContentBlockStopEvent) error {
+	b := s.blocks[event.Index]
+	if b == nil {
+		return nil
+	}
+	delete(s.blocks, event.Index)
+	idx := int(event.Index)
+
+	switch b.kind {
+	case spec.OutputKindFunctionToolCall, spec.OutputKindCustomToolCall:
+		if err := s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, spec.StreamToolCallChunk{
+			OutputIndex: idx,
+			Type:        b.toolType,
+			CallID:      b.id,
+			Name:        b.name,
+			Arguments:   strings.TrimSpace(b.args.String()),
+		}); err != nil {
+			return err
+		}
+
+	case spec.OutputKindWebSearchToolCall:
+		var input struct {
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal([]byte(b.args.String()), &input)
+		if err := s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      b.id,
+			Status:      spec.StatusSearching,
+			Query:       input.Query,
+		}); err != nil {
+			return err
+		}
+
+	default:
+		// Nothing beyond the item boundary.
+	}
+	return s.emitter.OutputItemStop(idx, b.kind, b.id)
+}
+
+func anthropicCitationDeltaToSpec(c anthropic.CitationsDeltaCitationUnion) (spec.Citation, bool) {
+	// Only web_search_result_location is currently supported, as for non-streamed citations.
+	if c.Type != string(anthropicSharedConstant.WebSearchResultLocation("").Default()) {
+		return spec.Citation{}, false
+	}
+	return spec.Citation{
+		Kind: spec.CitationKindURL,
+		URLCitation: &spec.URLCitation{
+			URL:            c.URL,
+			Title:          c.Title,
+			CitedText:      c.CitedText,
+			EncryptedIndex: c.EncryptedIndex,
+		},
+	}, true
+}
@@ -0,0 +1,103 @@
+package anthropicsdk
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestAnthropicStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	rawEvents := []string{
+		`{"type":"content_block_start","index":0,"content_block":{"type":"server_tool_use","id":"srv_1",` +
+			`"name":"web_search","input":{}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"query\":\"go\"}"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Go is great."}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"citations_delta","citation":` +
+			`{"type":"web_search_result_location","url":"https://go.dev","title":"Go","cited_text":"Go",` +
+			`"encrypted_index":"x"}}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"tu_1",` +
+			`"name":"lookup","input":{}}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"1}"}}`,
+		`{"type":"content_block_stop","index":2}`,
+	}
+
+	var events []spec.StreamEvent
+	emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+		StreamHandler: func(ev spec.StreamEvent) error {
+			events = append(events, ev)
+			return nil
+		},
+		StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+	})
+	s := newAnthropicStreamEvents(emitter, nil)
+
+	for i, raw := range rawEvents {
+		var ev anthropic.MessageStreamEventUnion
+		if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		var err error
+		switch v := ev.AsAny().(type) {
+		case anthropic.ContentBlockStartEvent:
+			err = s.handleContentBlockStart(v)
+		case anthropic.ContentBlockDeltaEvent:
+			err = s.handleContentBlockDelta(v)
+		case anthropic.ContentBlockStopEvent:
+			err = s.handleContentBlockStop(v)
+		default:
+			t.Fatalf("event %d: unexpected type %T", i, v)
+		}
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	got := make([]spec.StreamContentKind, 0, len(events))
+	for _, ev := range events {
+		got = append(got, ev.Kind)
+	}
+	want := []spec.StreamContentKind{
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindWebSearchCall,
+		spec.StreamContentKindWebSearchCall,
+		spec.StreamContentKindOutputItemStop,
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindText,
+		spec.StreamContentKindCitation,
+		spec.StreamContentKindOutputItemStop,
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindToolCallStart,
+		spec.StreamContentKindToolCallDelta,
+		spec.StreamContentKindToolCallDelta,
+		spec.StreamContentKindToolCallEnd,
+		spec.StreamContentKindOutputItemStop,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("kinds:\n got %v\nwant %v", got, want)
+	}
+
+	if ws := events[2].WebSearchCall; ws.Status != spec.StatusSearching || ws.Query != "go" || ws.CallID != "srv_1" {
+		t.Fatalf("web search call: got %+v", ws)
+	}
+	if c := events[6].Citation; c.OutputIndex != 1 || c.Citation.URLCitation == nil ||
+		c.Citation.URLCitation.URL != "https://go.dev" {
+		t.Fatalf("citation: got %+v", c)
+	}
+	if end := events[12].ToolCall; end.Arguments != `{"q":1}` || end.CallID != "tu_1" || end.Name != "lookup" {
+		t.Fatalf("tool call end: got %+v", end)
+	}
+}
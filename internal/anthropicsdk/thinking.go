@@ -1,6 +1,7 @@
 package anthropicsdk
 
 import (
+	"context"
 	"slices"
 	"strings"
 
@@ -36,7 +37,7 @@ type anthropicThinkingAnalysis struct {
 //
 // Additionally, we treat "signed/redacted thinking present in input" as a fail-safe requirement:
 // if we will send a ThinkingBlock/RedactedThinkingBlock, we ensure thinking is enabled unless explicitly forced off.
-func analyzeAnthropicThinkingBehavior(inputs []spec.InputUnion) anthropicThinkingAnalysis {
+func analyzeAnthropicThinkingBehavior(ctx context.Context, inputs []spec.InputUnion) anthropicThinkingAnalysis {
 	var a anthropicThinkingAnalysis
 	if len(inputs) == 0 {
 		return a
@@ -84,7 +85,8 @@ func analyzeAnthropicThinkingBehavior(inputs []spec.InputUnion) anthropicThinkin
 	}
 
 	if a.Override != thinkingOverrideNone {
-		logutil.Debug(
+		logutil.DebugContext(
+			ctx,
 			"anthropic: thinking override applied",
 			"override", a.Override.String(),
 			"reasoningTotal", a.TotalReasoningMessages,
@@ -217,6 +219,7 @@ func isAnthropicSignedOrRedactedReasoning(r *spec.ReasoningContent) bool {
 }
 
 func applyAnthropicThinkingPolicy(
+	ctx context.Context,
 	params *anthropic.MessageNewParams,
 	mp *spec.ModelParam,
 	a anthropicThinkingAnalysis,
@@ -252,7 +255,8 @@ func applyAnthropicThinkingPolicy(
 	// Fail-safe: if we're going to send signed/redacted thinking blocks as part of the prompt,
 	// ensure thinking is enabled (unless explicitly forced off).
 	if a.Override != thinkingOverrideForceDisabled && !effectiveEnabled && a.SignedOrRedactedReasoning > 0 {
-		logutil.Warn(
+		logutil.WarnContext(
+			ctx,
 			"anthropic: signed/redacted reasoning present in input but thinking is disabled; enabling thinking as a fail-safe",
 			"provider",
 			"anthropic",
@@ -290,14 +294,20 @@ func applyAnthropicThinkingPolicy(
 			// Existing behavior: fixed-budget enabled thinking.
 			params.Thinking = anthropic.ThinkingConfigParamOfEnabled(effectiveBudget)
 		}
-		// Do not set temperature when thinking is enabled (Anthropic requirement).
+		// Do not set temperature, top_p or top_k when thinking is enabled (Anthropic requirement).
 		return
 	}
 
-	// Thinking disabled => temperature is allowed.
+	// Thinking disabled => sampling parameters are allowed.
 	if mp.Temperature != nil {
 		params.Temperature = anthropic.Float(*mp.Temperature)
 	}
+	if mp.TopP != nil {
+		params.TopP = anthropic.Float(*mp.TopP)
+	}
+	if mp.TopK != nil {
+		params.TopK = anthropic.Int(int64(*mp.TopK))
+	}
 }
 
 func requestedAnthropicThinking(mp *spec.ModelParam, derivedMaxToken int64) (enabled, adaptive bool, budget int64) {
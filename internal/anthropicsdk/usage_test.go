@@ -0,0 +1,47 @@
+package anthropicsdk
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestUsageFromAnthropicMessage(t *testing.T) {
+	t.Parallel()
+
+	msg := &anthropic.Message{
+		Usage: anthropic.Usage{
+			InputTokens:              100,
+			CacheReadInputTokens:     200,
+			CacheCreationInputTokens: 300,
+			CacheCreation: anthropic.CacheCreation{
+				Ephemeral5mInputTokens: 100,
+				Ephemeral1hInputTokens: 200,
+			},
+			OutputTokens:        50,
+			OutputTokensDetails: anthropic.OutputTokensDetails{ThinkingTokens: 20},
+			ServerToolUse:       anthropic.ServerToolUsage{WebSearchRequests: 3},
+		},
+	}
+
+	got := usageFromAnthropicMessage(msg)
+	want := spec.Usage{
+		InputTokensTotal:      600,
+		InputTokensCached:     200,
+		InputTokensUncached:   400,
+		OutputTokens:          50,
+		ReasoningTokens:       20,
+		InputTokensCacheWrite: 300,
+		InputTokensCacheWriteByTTL: map[spec.CacheControlTTL]int64{
+			spec.CacheControlTTL5m: 100,
+			spec.CacheControlTTL1h: 200,
+		},
+		WebSearchRequests: 3,
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("usage:\n got %+v\nwant %+v", *got, want)
+	}
+}
@@ -0,0 +1,686 @@
+package bedrockconversesdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// bedrockOwnedKeys are the request keys built from typed fields, which
+// additional parameters cannot replace.
+var bedrockOwnedKeys = []string{"messages", "system", "toolConfig"}
+
+// BedrockConverseAPI implements CompletionProvider for the AWS Bedrock
+// Converse and ConverseStream operations.
+type BedrockConverseAPI struct {
+	ProviderParam *spec.ProviderParam
+	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
+	client        *bedrockClient
+	mu            sync.RWMutex
+}
+
+// NewBedrockConverseAPI creates a new instance of the Bedrock Converse provider.
+func NewBedrockConverseAPI(
+	pi spec.ProviderParam,
+	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
+) (*BedrockConverseAPI, error) {
+	if pi.Name == "" {
+		return nil, errors.New("bedrock converse api LLM: invalid args")
+	}
+	return &BedrockConverseAPI{
+		ProviderParam: &pi,
+		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
+	}, nil
+}
+
+// InitLLM builds the client. The API key is either static AWS credentials,
+// spec.BedrockEnvironmentCredentials or a Bedrock API key; see newBedrockAuth.
+// The signing region is taken from the origin.
+func (api *BedrockConverseAPI) InitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ProviderParam == nil {
+		api.client = nil
+		return errors.New("bedrock converse api LLM: no ProviderParam found")
+	}
+
+	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
+		api.logger.Debug(
+			string(api.ProviderParam.Name) + ": No API key given. Not initializing Bedrock client",
+		)
+		api.client = nil
+		return nil
+	}
+
+	pi := *api.ProviderParam // snapshot under lock
+
+	httpClient := &http.Client{}
+	if api.debugger != nil {
+		if c := api.debugger.HTTPClient(httpClient); c != nil {
+			httpClient = c
+		}
+	}
+	client, region, err := newBedrockClient(pi, httpClient)
+	if err != nil {
+		api.client = nil
+		return err
+	}
+
+	api.client = client
+	api.logger.Info(
+		"bedrock converse api LLM provider initialized",
+		"name", string(pi.Name),
+		"URL", client.baseURL,
+		"region", region,
+	)
+	return nil
+}
+
+// newBedrockClient returns a client for pi that sends through httpClient, and the region it signs for.
+func newBedrockClient(pi spec.ProviderParam, httpClient *http.Client) (*bedrockClient, string, error) {
+	origin := spec.DefaultBedrockRuntimeOrigin
+	if pi.Origin != "" {
+		origin = pi.Origin
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil || originURL.Host == "" {
+		return nil, "", errors.New("bedrock converse api LLM: invalid origin")
+	}
+	pathPrefix := strings.TrimSpace(pi.ChatCompletionPathPrefix)
+	if pathPrefix == "" {
+		pathPrefix = spec.DefaultBedrockConversePrefix
+	}
+	baseURL := strings.TrimSuffix(origin, "/") + "/" + strings.Trim(pathPrefix, "/")
+	region := bedrockRegion(originURL.Hostname())
+
+	header := http.Header{}
+	for k, v := range pi.DefaultHeaders {
+		header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	return &bedrockClient{
+		httpClient: httpClient,
+		baseURL:    baseURL,
+		header:     header,
+		auth:       newBedrockAuth(pi.APIKey, region),
+	}, region, nil
+}
+
+func (api *BedrockConverseAPI) DeInitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	var name spec.ProviderName
+	if api.ProviderParam != nil {
+		name = api.ProviderParam.Name
+	}
+	api.client = nil
+	api.mu.Unlock()
+	api.logger.Info(
+		"bedrock converse api LLM: provider de initialized",
+		"name",
+		string(name),
+	)
+	return nil
+}
+
+func (api *BedrockConverseAPI) GetProviderInfo(ctx context.Context) *spec.ProviderParam {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return nil
+	}
+	cp := *api.ProviderParam
+	cp.DefaultHeaders = sdkutil.CloneStringMap(cp.DefaultHeaders)
+	return &cp
+}
+
+func (api *BedrockConverseAPI) IsConfigured(ctx context.Context) bool {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.ProviderParam != nil && strings.TrimSpace(api.ProviderParam.APIKey) != ""
+}
+
+func (api *BedrockConverseAPI) SetProviderAPIKey(ctx context.Context, apiKey string) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.ProviderParam == nil {
+		return errors.New("bedrock converse api LLM: no ProviderParam found")
+	}
+	// Allow empty to clear.
+	api.ProviderParam.APIKey = strings.TrimSpace(apiKey)
+
+	return nil
+}
+
+func (api *BedrockConverseAPI) GetProviderCapability(ctx context.Context) (spec.ModelCapabilities, error) {
+	return bedrockconversesdkCapability, nil
+}
+
+func (api *BedrockConverseAPI) FetchCompletion(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	api.mu.RLock()
+	client := api.client
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("bedrock converse api LLM: client not initialized")
+	}
+	call, err := buildBedrockCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *converseResponse
+		apiErr         error
+	)
+	if call.stream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(ctx, client, pi.Name, call, opts)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, call)
+	}
+
+	if apiErr != nil {
+		apiErr = bedrockProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		// The raw response carries the latency metric and additionalModelResponseFields.
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CompileRequest builds the Converse request for a completion and returns it, signed, without sending it. The
+// request goes through a client built like the one InitLLM builds, including the debugger's HTTP client; without an
+// API key it carries sdkutil.CompileAPIKey as a Bedrock API key.
+func (api *BedrockConverseAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	hasProvider := api.ProviderParam != nil
+	if hasProvider {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if !hasProvider {
+		return nil, errors.New("bedrock converse api LLM: no ProviderParam found")
+	}
+	if strings.TrimSpace(pi.APIKey) == "" {
+		pi.APIKey = sdkutil.CompileAPIKey
+	}
+	call, err := buildBedrockCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compile what a streaming caller would send, as the other adapters do.
+	stream := call.stream || call.req.ModelParam.Stream
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	client, _, err := newBedrockClient(pi, capture.HTTPClient(api.debugger))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.post(captureCtx, nil, string(call.req.ModelParam.Name), stream, call.body)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	secrets := []string{pi.APIKey}
+	if a := client.auth.static; a != nil {
+		secrets = append(secrets, a.SecretAccessKey, a.SessionToken)
+	}
+	compiled, err := capture.CompiledRequest(err, secrets...)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// bedrockCall is a normalized request together with the Converse body built
+// from it. The body is the same for Converse and ConverseStream.
+type bedrockCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	body              []byte
+	stream            bool
+	timeout           time.Duration
+	toolChoiceNameMap map[string]spec.ToolChoice
+	warns             []spec.Warning
+}
+
+// buildBedrockCall normalizes a request against the provider capabilities and
+// builds the Converse body for it.
+func buildBedrockCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+) (*bedrockCall, error) {
+	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
+		return nil, errors.New("bedrock converse api LLM: empty completion data")
+	}
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
+		ctx, inReq, opts, spec.ProviderSDKTypeBedrockConverse, bedrockconversesdkCapability,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
+
+	msgs, system, err := toBedrockMessages(ctx, req.ModelParam.SystemPrompt, req.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, errors.New("bedrock converse api LLM: no messages to send")
+	}
+
+	params := converseRequest{Messages: msgs, System: system}
+
+	ic := bedrockInferenceConfig{
+		MaxTokens:     req.ModelParam.MaxOutputLength,
+		Temperature:   req.ModelParam.Temperature,
+		TopP:          req.ModelParam.TopP,
+		StopSequences: req.ModelParam.StopSequences,
+	}
+	if ic.Temperature != nil || ic.TopP != nil || ic.MaxTokens > 0 || len(ic.StopSequences) > 0 {
+		params.InferenceConfig = &ic
+	}
+	applyBedrockThinking(ctx, &params, &req.ModelParam, msgs)
+
+	// The top level cache point caches the whole prompt: it goes after the
+	// last message.
+	if cp := bedrockCachePointFor(req.ModelParam.CacheControl); cp != nil {
+		last := &params.Messages[len(params.Messages)-1]
+		if n := len(last.Content); n == 0 || last.Content[n-1].CachePoint == nil {
+			last.Content = append(last.Content, bedrockContentBlock{CachePoint: cp})
+		}
+	}
+
+	tools, toolChoiceNameMap := toolChoicesToBedrockTools(req.ToolChoices)
+	if len(tools) > 0 {
+		params.ToolConfig = &bedrockToolConfig{Tools: tools}
+		if err := applyBedrockToolPolicy(params.ToolConfig, req.ToolPolicy, toolChoiceNameMap); err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := spec.DefaultAPITimeout
+	if req.ModelParam.Timeout > 0 {
+		timeout = time.Duration(req.ModelParam.Timeout) * time.Second
+	}
+
+	// Guardrails, performance config and model specific fields such as
+	// additionalModelRequestFields.top_k come in as additional parameters.
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	body, extraWarns, err := sdkutil.MergeAdditionalParameters(
+		body, req.ModelParam.AdditionalParametersRawJSON, bedrockOwnedKeys...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bedrockCall{
+		req:               req,
+		capabilities:      caps,
+		body:              body,
+		stream:            req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil,
+		timeout:           timeout,
+		toolChoiceNameMap: toolChoiceNameMap,
+		warns:             append(warns, extraWarns...),
+	}, nil
+}
+
+func (api *BedrockConverseAPI) doNonStreaming(
+	ctx context.Context,
+	client *bedrockClient,
+	call *bedrockCall,
+) (*spec.FetchCompletionResponse, *converseResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	ctx, cancel := context.WithTimeout(ctx, call.timeout)
+	defer cancel()
+
+	httpResp, err := client.post(ctx, nil, string(call.req.ModelParam.Name), false, call.body)
+	if err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	var raw converseResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&raw); err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+
+	resp.Usage = usageFromConverseResponse(&raw)
+	resp.Outputs = outputsFromConverseResponse(&raw, call.toolChoiceNameMap)
+	resp.StopReason = stopReasonFromConverseResponse(&raw)
+	return resp, &raw, nil
+}
+
+func (api *BedrockConverseAPI) doStreaming(
+	ctx context.Context,
+	client *bedrockClient,
+	providerName spec.ProviderName,
+	call *bedrockCall,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, *converseResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	modelName := call.req.ModelParam.Name
+	ctx, cancel := context.WithTimeout(ctx, call.timeout)
+	defer cancel()
+
+	httpResp, err := client.post(ctx, nil, string(modelName), true, call.body)
+	if err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newBedrockStreamEvents(emitter, call.toolChoiceNameMap)
+	reader := newConverseStreamReader(httpResp.Body)
+
+	var (
+		readErr        error
+		streamWriteErr error
+	)
+	streamStartedAt := time.Now()
+	lastEventAt := streamStartedAt
+	eventCount := 0
+	lastEventType := ""
+
+	for {
+		eventType, ev, err := reader.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
+		eventCount++
+		lastEventAt = time.Now()
+		lastEventType = eventType
+
+		// If downstream write failed (client disconnect, etc.), stop consuming the stream.
+		if streamWriteErr = events.handle(eventType, ev); streamWriteErr != nil {
+			break
+		}
+	}
+
+	flushErr := emitter.Close()
+
+	full := events.response()
+	if !events.stopped && readErr == nil && streamWriteErr == nil {
+		streamWriteErr = errors.New("bedrock stream ended before messageStop")
+	}
+	if readErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(usageFromConverseResponse(full), stopReasonFromConverseResponse(full))
+	}
+
+	streamErr := errors.Join(readErr, streamWriteErr, flushErr)
+	if streamErr != nil {
+		logutil.ErrorContext(
+			ctx,
+			"bedrock converse stream terminated",
+			"provider", string(providerName),
+			"model", string(modelName),
+			"duration", time.Since(streamStartedAt),
+			"lastEventAgo", time.Since(lastEventAt),
+			"eventCount", eventCount,
+			"lastEventType", lastEventType,
+			"sawMessageStop", events.stopped,
+			"readErr", readErr,
+			"streamWriteErr", streamWriteErr,
+			"flushErr", flushErr,
+			"contextErr", ctx.Err(),
+		)
+	}
+
+	resp.Usage = usageFromConverseResponse(full)
+	if streamErr != nil {
+		resp.Error = &spec.Error{Message: streamErr.Error()}
+	}
+	resp.Outputs = outputsFromConverseResponse(full, call.toolChoiceNameMap)
+	resp.StopReason = stopReasonFromConverseResponse(full)
+	return resp, full, streamErr
+}
+
+func outputsFromConverseResponse(
+	r *converseResponse,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
+	if r == nil || r.Output.Message == nil {
+		return nil
+	}
+
+	var outs []spec.OutputUnion
+	status := bedrockStopReasonStatus(r.StopReason)
+
+	for _, block := range r.Output.Message.Content {
+		switch {
+		case block.Text != "" || block.CitationsContent != nil:
+			textItem := bedrockTextToSpec(block)
+			if strings.TrimSpace(textItem.Text) == "" {
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{
+				Kind: spec.OutputKindOutputMessage,
+				OutputMessage: &spec.InputOutputContent{
+					Role:   spec.RoleAssistant,
+					Status: status,
+					Contents: []spec.InputOutputContentItemUnion{{
+						Kind:     spec.ContentItemKindText,
+						TextItem: &textItem,
+					}},
+				},
+			})
+
+		case block.ReasoningContent != nil:
+			rc := block.ReasoningContent
+			r := spec.ReasoningContent{Role: spec.RoleAssistant, Status: status}
+			switch {
+			case rc.RedactedContent != "":
+				r.RedactedThinking = []string{rc.RedactedContent}
+			case rc.ReasoningText != nil:
+				r.Thinking = []string{rc.ReasoningText.Text}
+				r.Signature = rc.ReasoningText.Signature
+			default:
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindReasoningMessage, ReasoningMessage: &r})
+
+		case block.ToolUse != nil:
+			name := strings.TrimSpace(block.ToolUse.Name)
+			choice, ok := toolChoiceNameMap[name]
+			if name == "" || !ok || block.ToolUse.ToolUseID == "" {
+				continue
+			}
+			call := spec.ToolCall{
+				ChoiceID:  choice.ID,
+				Type:      choice.Type,
+				Role:      spec.RoleAssistant,
+				ID:        block.ToolUse.ToolUseID,
+				CallID:    block.ToolUse.ToolUseID,
+				Name:      name,
+				Arguments: bedrockToolInput(string(block.ToolUse.Input)),
+				Status:    spec.StatusCompleted,
+			}
+			if choice.Type == spec.ToolTypeCustom {
+				outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindCustomToolCall, CustomToolCall: &call})
+			} else {
+				call.Type = spec.ToolTypeFunction
+				outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindFunctionToolCall, FunctionToolCall: &call})
+			}
+
+		default:
+			// Future content block types.
+		}
+	}
+
+	return outs
+}
+
+// bedrockTextToSpec returns the text of a text or citations content block
+// with its URL citations.
+func bedrockTextToSpec(block bedrockContentBlock) spec.ContentItemText {
+	if block.CitationsContent == nil {
+		return spec.ContentItemText{Text: block.Text}
+	}
+	var text strings.Builder
+	for _, c := range block.CitationsContent.Content {
+		text.WriteString(c.Text)
+	}
+	out := spec.ContentItemText{Text: text.String()}
+	for _, c := range block.CitationsContent.Citations {
+		if sc, ok := bedrockCitationToSpec(c); ok {
+			out.Citations = append(out.Citations, sc)
+		}
+	}
+	return out
+}
+
+// bedrockCitationToSpec converts a web citation into a URL citation. Document
+// locations have no spec counterpart and are skipped.
+func bedrockCitationToSpec(c bedrockCitation) (spec.Citation, bool) {
+	if c.Location.Web == nil || c.Location.Web.URL == "" {
+		return spec.Citation{}, false
+	}
+	var cited strings.Builder
+	for _, s := range c.SourceContent {
+		cited.WriteString(s.Text)
+	}
+	return spec.Citation{
+		Kind: spec.CitationKindURL,
+		URLCitation: &spec.URLCitation{
+			URL:       c.Location.Web.URL,
+			Title:     c.Title,
+			CitedText: cited.String(),
+		},
+	}, true
+}
+
+func bedrockStopReasonStatus(stopReason string) spec.Status {
+	switch stopReason {
+	case "max_tokens", "model_context_window_exceeded":
+		return spec.StatusIncomplete
+	case "guardrail_intervened", "content_filtered":
+		return spec.StatusFailed
+	default:
+		return spec.StatusCompleted
+	}
+}
+
+func stopReasonFromConverseResponse(r *converseResponse) *spec.StopReason {
+	if r == nil || r.StopReason == "" {
+		return nil
+	}
+	out := &spec.StopReason{Raw: r.StopReason}
+	switch r.StopReason {
+	case "end_turn":
+		out.Kind = spec.StopReasonEndTurn
+	case "tool_use":
+		out.Kind = spec.StopReasonToolUse
+	case "max_tokens":
+		out.Kind = spec.StopReasonMaxTokens
+	case "stop_sequence":
+		out.Kind = spec.StopReasonStopSequence
+	case "guardrail_intervened", "content_filtered":
+		out.Kind = spec.StopReasonContentFilter
+	case "model_context_window_exceeded":
+		out.Kind = spec.StopReasonContextWindowExceeded
+	default:
+		out.Kind = spec.StopReasonOther
+	}
+	return out
+}
+
+func usageFromConverseResponse(r *converseResponse) *spec.Usage {
+	uOut := &spec.Usage{}
+	if r == nil || r.Usage == nil {
+		return uOut
+	}
+	u := r.Usage
+	// Converse does not report reasoning tokens separately.
+	uOut.InputTokensCached = u.CacheReadInputTokens
+	uOut.InputTokensUncached = u.InputTokens + u.CacheWriteInputTokens
+	uOut.InputTokensTotal = u.CacheReadInputTokens + u.InputTokens + u.CacheWriteInputTokens
+	uOut.InputTokensCacheWrite = u.CacheWriteInputTokens
+	uOut.OutputTokens = u.OutputTokens
+	return uOut
+}
+
+// bedrockToolInput returns tool input as a JSON string.
+func bedrockToolInput(args string) string {
+	args = strings.TrimSpace(args)
+	if args == "" || args == "null" {
+		return "{}"
+	}
+	return args
+}
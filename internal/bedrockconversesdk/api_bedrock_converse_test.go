@@ -0,0 +1,172 @@
+package bedrockconversesdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestBuildBedrockCallBody(t *testing.T) {
+	t.Parallel()
+
+	extra := `{"additionalModelRequestFields":{"top_k":40},"performanceConfig":{"latency":"optimized"},"messages":[]}`
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{
+			Name:                        "us.meta.llama3-3-70b-instruct-v1:0",
+			SystemPrompt:                "Be brief.",
+			MaxOutputLength:             512,
+			Temperature:                 new(0.3),
+			StopSequences:               []string{"END"},
+			CacheControl:                &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+			AdditionalParametersRawJSON: &extra,
+		},
+		Inputs: []spec.InputUnion{userText("hi")},
+		ToolChoices: []spec.ToolChoice{{
+			Type:         spec.ToolTypeFunction,
+			ID:           "tc-1",
+			Name:         "lookup",
+			CacheControl: &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+		}},
+		ToolPolicy: &spec.ToolPolicy{
+			Mode:         spec.ToolPolicyModeTool,
+			AllowedTools: []spec.AllowedTool{{ToolChoiceName: "lookup"}},
+		},
+	}
+
+	call, err := buildBedrockCall(t.Context(), req, nil, nil)
+	if err != nil {
+		t.Fatalf("buildBedrockCall: %v", err)
+	}
+	if call.stream {
+		t.Fatal("call streams without a stream handler")
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(call.body, &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	want := map[string]any{
+		"system": []any{map[string]any{"text": "Be brief."}},
+		"messages": []any{map[string]any{
+			"role": "user",
+			"content": []any{
+				map[string]any{"text": "hi"},
+				map[string]any{"cachePoint": map[string]any{"type": "default"}},
+			},
+		}},
+		"inferenceConfig": map[string]any{
+			"maxTokens":     float64(512),
+			"temperature":   0.3,
+			"stopSequences": []any{"END"},
+		},
+		"additionalModelRequestFields": map[string]any{"top_k": float64(40)},
+		"performanceConfig":            map[string]any{"latency": "optimized"},
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(body[k], v) {
+			t.Errorf("body[%q] = %#v, want %#v", k, body[k], v)
+		}
+	}
+
+	toolConfig, _ := body["toolConfig"].(map[string]any)
+	tools, _ := toolConfig["tools"].([]any)
+	if len(tools) != 2 || tools[1].(map[string]any)["cachePoint"] == nil {
+		t.Fatalf("tools = %#v, want a tool spec and a cache point", toolConfig["tools"])
+	}
+	wantChoice := map[string]any{"tool": map[string]any{"name": "lookup"}}
+	if !reflect.DeepEqual(toolConfig["toolChoice"], wantChoice) {
+		t.Errorf("toolChoice = %#v, want %#v", toolConfig["toolChoice"], wantChoice)
+	}
+
+	var dropped bool
+	for _, w := range call.warns {
+		if w.Code == "additional_parameter_owned_key_dropped" {
+			dropped = true
+		}
+	}
+	if !dropped {
+		t.Errorf("warnings = %+v, want the owned messages key reported", call.warns)
+	}
+}
+
+func TestOutputsFromConverseResponse(t *testing.T) {
+	t.Parallel()
+
+	raw := `{
+		"output": {"message": {"role": "assistant", "content": [
+			{"reasoningContent": {"reasoningText": {"text": "think", "signature": "sig"}}},
+			{"citationsContent": {
+				"content": [{"text": "It is sunny."}],
+				"citations": [{"title": "Forecast", "sourceContent": [{"text": "sunny"}],
+					"location": {"web": {"url": "https://example.com/w"}}}]
+			}},
+			{"toolUse": {"toolUseId": "tu_1", "name": "lookup", "input": {"q": "x"}}}
+		]}},
+		"stopReason": "tool_use",
+		"usage": {"inputTokens": 10, "outputTokens": 5, "totalTokens": 35,
+			"cacheReadInputTokens": 15, "cacheWriteInputTokens": 5}
+	}`
+	var resp converseResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+	nameMap := map[string]spec.ToolChoice{"lookup": {ID: "tc-1", Type: spec.ToolTypeFunction, Name: "lookup"}}
+
+	outs := outputsFromConverseResponse(&resp, nameMap)
+	if len(outs) != 3 {
+		t.Fatalf("outputs = %+v", outs)
+	}
+	if r := outs[0].ReasoningMessage; r == nil || r.Signature != "sig" || r.Thinking[0] != "think" {
+		t.Errorf("reasoning = %+v", outs[0].ReasoningMessage)
+	}
+	text := outs[1].OutputMessage.Contents[0].TextItem
+	if text.Text != "It is sunny." || len(text.Citations) != 1 ||
+		text.Citations[0].URLCitation.URL != "https://example.com/w" {
+		t.Errorf("text = %+v", text)
+	}
+	call := outs[2].FunctionToolCall
+	if call == nil || call.CallID != "tu_1" || call.ChoiceID != "tc-1" || call.Arguments != `{"q": "x"}` {
+		t.Errorf("tool call = %+v", call)
+	}
+
+	u := usageFromConverseResponse(&resp)
+	wantUsage := spec.Usage{
+		InputTokensTotal:      30,
+		InputTokensCached:     15,
+		InputTokensUncached:   15,
+		InputTokensCacheWrite: 5,
+		OutputTokens:          5,
+	}
+	if !reflect.DeepEqual(*u, wantUsage) {
+		t.Errorf("usage = %+v, want %+v", *u, wantUsage)
+	}
+}
+
+func TestStopReasonFromConverseResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw  string
+		want spec.StopReasonKind
+	}{
+		{raw: "end_turn", want: spec.StopReasonEndTurn},
+		{raw: "tool_use", want: spec.StopReasonToolUse},
+		{raw: "max_tokens", want: spec.StopReasonMaxTokens},
+		{raw: "stop_sequence", want: spec.StopReasonStopSequence},
+		{raw: "guardrail_intervened", want: spec.StopReasonContentFilter},
+		{raw: "content_filtered", want: spec.StopReasonContentFilter},
+		{raw: "model_context_window_exceeded", want: spec.StopReasonContextWindowExceeded},
+		{raw: "something_new", want: spec.StopReasonOther},
+	}
+	for _, tt := range tests {
+		got := stopReasonFromConverseResponse(&converseResponse{StopReason: tt.raw})
+		if got == nil || got.Kind != tt.want || got.Raw != tt.raw {
+			t.Errorf("%s: got %+v, want %s", tt.raw, got, tt.want)
+		}
+	}
+	if got := stopReasonFromConverseResponse(&converseResponse{}); got != nil {
+		t.Errorf("empty stop reason: got %+v, want nil", got)
+	}
+}
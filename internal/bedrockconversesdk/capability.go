@@ -0,0 +1,80 @@
+package bedrockconversesdk
+
+import "github.com/flexigpt/inference-go/spec"
+
+// bedrockCachePointCapability describes Converse cache points, which have no
+// TTL or key.
+var bedrockCachePointCapability = &spec.CacheControlCapabilities{
+	SupportsTTL:    false,
+	SupportedKinds: []spec.CacheControlKind{spec.CacheControlKindEphemeral},
+	SupportsKey:    false,
+}
+
+var bedrockconversesdkCapability = spec.ModelCapabilities{
+	ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityImageIn, spec.ModalityFileIn},
+	ModalitiesOut: []spec.Modality{spec.ModalityTextOut},
+
+	// Reasoning is configured through additionalModelRequestFields in the
+	// shape of the Anthropic thinking config; other models reason on their own.
+	ReasoningCapabilities: &spec.ReasoningCapabilities{
+		SupportsReasoningConfig: true,
+		SupportedReasoningTypes: []spec.ReasoningType{
+			spec.ReasoningTypeHybridWithTokens,
+			spec.ReasoningTypeSingleWithLevels,
+		},
+		SupportedReasoningLevels: []spec.ReasoningLevel{
+			spec.ReasoningLevelNone,
+			spec.ReasoningLevelLow,
+			spec.ReasoningLevelMedium,
+			spec.ReasoningLevelHigh,
+		},
+		HybridTokenBudgetCapabilities: &spec.ReasoningTokenBudgetCapabilities{
+			MinAllowed:      1024,
+			ZeroAllowed:     false,
+			MinusOneAllowed: false,
+		},
+		SupportsSummaryStyle: false,
+
+		SupportsEncryptedReasoningInput:  false,
+		TemperatureDisallowedWhenEnabled: true,
+	},
+
+	StopSequenceCapabilities: &spec.StopSequenceCapabilities{
+		IsSupported:             true,
+		DisallowedWithReasoning: false,
+	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:            true,
+		DisallowedWithReasoning: true,
+	},
+	OutputCapabilities: &spec.OutputCapabilities{
+		SupportedOutputFormats: []spec.OutputFormatKind{spec.OutputFormatKindText},
+		SupportsVerbosity:      false,
+	},
+
+	ToolCapabilities: &spec.ToolCapabilities{
+		SupportedToolTypes: []spec.ToolType{spec.ToolTypeFunction, spec.ToolTypeCustom},
+
+		// toolChoice has no "none"; leaving tools out is not possible once the
+		// history holds tool use.
+		SupportedToolPolicyModes: []spec.ToolPolicyMode{
+			spec.ToolPolicyModeAuto,
+			spec.ToolPolicyModeAny,
+			spec.ToolPolicyModeTool,
+		},
+		SupportsParallelToolCalls: true,
+		MaxForcedTools:            1,
+		SupportedClientToolOutputFormats: []spec.ToolOutputFormatKind{
+			spec.ToolOutputFormatKindContentItemList,
+		},
+	},
+
+	CacheCapabilities: &spec.CacheCapabilities{
+		SupportsAutomaticCaching: false,
+		TopLevel:                 bedrockCachePointCapability,
+		InputOutputContent:       bedrockCachePointCapability,
+		ToolChoice:               bedrockCachePointCapability,
+		ToolCall:                 bedrockCachePointCapability,
+		ToolOutput:               bedrockCachePointCapability,
+	},
+}
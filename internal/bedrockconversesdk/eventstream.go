@@ -0,0 +1,137 @@
+package bedrockconversesdk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	eventStreamPreludeLen = 12
+	eventStreamCRCLen     = 4
+	// eventStreamMaxMessageLen is the largest message the AWS event stream
+	// encoding allows.
+	eventStreamMaxMessageLen = 16 << 20
+)
+
+// Event stream header value types.
+const (
+	eventStreamBoolTrue byte = iota
+	eventStreamBoolFalse
+	eventStreamByte
+	eventStreamInt16
+	eventStreamInt32
+	eventStreamInt64
+	eventStreamBytes
+	eventStreamString
+	eventStreamTimestamp
+	eventStreamUUID
+)
+
+// eventStreamMessage is one message of an application/vnd.amazon.eventstream
+// response. Only string header values are kept; the headers Converse uses
+// (:message-type, :event-type, :exception-type) are all strings.
+type eventStreamMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// eventStreamDecoder reads the binary framing of an AWS event stream:
+//
+//	total length (4) | headers length (4) | prelude CRC (4) | headers | payload | message CRC (4)
+//
+// All integers are big endian and both checksums are CRC32 (IEEE).
+type eventStreamDecoder struct {
+	r *bufio.Reader
+}
+
+func newEventStreamDecoder(r io.Reader) *eventStreamDecoder {
+	return &eventStreamDecoder{r: bufio.NewReader(r)}
+}
+
+// next returns the next message, or io.EOF at a clean end of the stream.
+func (d *eventStreamDecoder) next() (*eventStreamMessage, error) {
+	prelude := make([]byte, eventStreamPreludeLen)
+	if _, err := io.ReadFull(d.r, prelude); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("bedrock event stream: truncated prelude")
+		}
+		return nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, errors.New("bedrock event stream: prelude checksum mismatch")
+	}
+	if totalLen > eventStreamMaxMessageLen ||
+		uint64(totalLen) < uint64(eventStreamPreludeLen)+uint64(headersLen)+eventStreamCRCLen {
+		return nil, fmt.Errorf("bedrock event stream: invalid message length %d", totalLen)
+	}
+
+	rest := make([]byte, totalLen-eventStreamPreludeLen)
+	if _, err := io.ReadFull(d.r, rest); err != nil {
+		return nil, fmt.Errorf("bedrock event stream: truncated message: %w", err)
+	}
+	body := rest[:len(rest)-eventStreamCRCLen]
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(prelude)
+	_, _ = crc.Write(body)
+	if crc.Sum32() != binary.BigEndian.Uint32(rest[len(rest)-eventStreamCRCLen:]) {
+		return nil, errors.New("bedrock event stream: message checksum mismatch")
+	}
+
+	headers, err := decodeEventStreamHeaders(body[:headersLen])
+	if err != nil {
+		return nil, err
+	}
+	return &eventStreamMessage{Headers: headers, Payload: body[headersLen:]}, nil
+}
+
+func decodeEventStreamHeaders(b []byte) (map[string]string, error) {
+	errShort := errors.New("bedrock event stream: truncated header")
+	headers := map[string]string{}
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, errShort
+		}
+		name := string(b[1 : 1+nameLen])
+		typ := b[1+nameLen]
+		b = b[2+nameLen:]
+
+		var size int
+		switch typ {
+		case eventStreamBoolTrue, eventStreamBoolFalse:
+			size = 0
+		case eventStreamByte:
+			size = 1
+		case eventStreamInt16:
+			size = 2
+		case eventStreamInt32:
+			size = 4
+		case eventStreamInt64, eventStreamTimestamp:
+			size = 8
+		case eventStreamUUID:
+			size = 16
+		case eventStreamBytes, eventStreamString:
+			if len(b) < 2 {
+				return nil, errShort
+			}
+			size = int(binary.BigEndian.Uint16(b))
+			b = b[2:]
+		default:
+			return nil, fmt.Errorf("bedrock event stream: unknown header type %d", typ)
+		}
+		if len(b) < size {
+			return nil, errShort
+		}
+		if typ == eventStreamString {
+			headers[name] = string(b[:size])
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
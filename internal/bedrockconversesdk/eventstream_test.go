@@ -0,0 +1,94 @@
+package bedrockconversesdk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+func TestConverseStreamReader(t *testing.T) {
+	t.Parallel()
+
+	var stream bytes.Buffer
+	stream.Write(encodeEventStreamMessage(map[string]string{
+		":message-type": "event",
+		":event-type":   "contentBlockDelta",
+		":content-type": "application/json",
+	}, []byte(`{"contentBlockIndex":1,"delta":{"text":"Hello"}}`)))
+	stream.Write(encodeEventStreamMessage(map[string]string{
+		":message-type":   "exception",
+		":exception-type": "throttlingException",
+	}, []byte(`{"message":"slow down"}`)))
+
+	r := newConverseStreamReader(&stream)
+	eventType, ev, err := r.next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if eventType != "contentBlockDelta" || ev.ContentBlockIndex != 1 || ev.Delta == nil ||
+		ev.Delta.Text == nil || *ev.Delta.Text != "Hello" {
+		t.Fatalf("event = %q %+v", eventType, ev)
+	}
+
+	_, _, err = r.next()
+	var streamErr *bedrockStreamError
+	if !errors.As(err, &streamErr) || streamErr.Type != "throttlingException" || streamErr.Message != "slow down" {
+		t.Fatalf("err = %v, want the throttling exception", err)
+	}
+
+	if _, _, err := r.next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+}
+
+func TestEventStreamDecoderChecksums(t *testing.T) {
+	t.Parallel()
+
+	msg := encodeEventStreamMessage(map[string]string{":event-type": "messageStop"}, []byte(`{}`))
+
+	badPrelude := bytes.Clone(msg)
+	badPrelude[8] ^= 0xff
+	badMessage := bytes.Clone(msg)
+	badMessage[len(badMessage)-5] ^= 0xff
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "prelude", data: badPrelude},
+		{name: "message", data: badMessage},
+		{name: "truncated", data: msg[:len(msg)-3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := newEventStreamDecoder(bytes.NewReader(tt.data)).next(); err == nil || errors.Is(err, io.EOF) {
+				t.Fatalf("err = %v, want a framing error", err)
+			}
+		})
+	}
+}
+
+// encodeEventStreamMessage frames payload with string headers.
+func encodeEventStreamMessage(headers map[string]string, payload []byte) []byte {
+	var hb bytes.Buffer
+	for name, value := range headers {
+		hb.WriteByte(byte(len(name)))
+		hb.WriteString(name)
+		hb.WriteByte(eventStreamString)
+		_ = binary.Write(&hb, binary.BigEndian, uint16(len(value)))
+		hb.WriteString(value)
+	}
+
+	total := eventStreamPreludeLen + hb.Len() + len(payload) + eventStreamCRCLen
+	out := make([]byte, 8, total)
+	binary.BigEndian.PutUint32(out[0:4], uint32(total))
+	binary.BigEndian.PutUint32(out[4:8], uint32(hb.Len()))
+	out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+	out = append(out, hb.Bytes()...)
+	out = append(out, payload...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+}
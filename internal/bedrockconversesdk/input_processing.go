@@ -0,0 +1,527 @@
+package bedrockconversesdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	bedrockRoleUser      = "user"
+	bedrockRoleAssistant = "assistant"
+
+	bedrockCachePointDefault = "default"
+)
+
+var errEmptyInputPart = errors.New("empty input part")
+
+// toBedrockMessages converts a system prompt and a slice of spec InputUnion
+// items into Converse system blocks and messages.
+//
+// Converse needs user and assistant messages to alternate, with tool results
+// in user messages, so adjacent items of the same role are merged into one
+// message.
+func toBedrockMessages(
+	ctx context.Context,
+	systemPrompt string,
+	inputs []spec.InputUnion,
+) ([]bedrockMessage, []bedrockSystemBlock, error) {
+	var system []bedrockSystemBlock
+	if s := strings.TrimSpace(systemPrompt); s != "" {
+		system = append(system, bedrockSystemBlock{Text: s})
+	}
+
+	var out []bedrockMessage
+	for _, in := range inputs {
+		if sdkutil.IsInputUnionEmpty(in) {
+			continue
+		}
+
+		role, blocks, err := inputUnionToBedrockBlocks(ctx, in)
+		if err != nil {
+			if errors.Is(err, errEmptyInputPart) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if len(out) > 0 && out[len(out)-1].Role == role {
+			out[len(out)-1].Content = append(out[len(out)-1].Content, blocks...)
+			continue
+		}
+		out = append(out, bedrockMessage{Role: role, Content: blocks})
+	}
+	return out, system, nil
+}
+
+// inputUnionToBedrockBlocks converts a single InputUnion to the content
+// blocks of a message with the returned role.
+func inputUnionToBedrockBlocks(ctx context.Context, in spec.InputUnion) (string, []bedrockContentBlock, error) {
+	switch in.Kind {
+	case spec.InputKindInputMessage:
+		if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
+			return "", nil, errEmptyInputPart
+		}
+		blocks := contentItemsToBedrockBlocks(ctx, in.InputMessage.Contents, true)
+		if len(blocks) == 0 {
+			return "", nil, errEmptyInputPart
+		}
+		return bedrockRoleUser, withBedrockCachePoint(blocks, in.InputMessage.CacheControl), nil
+
+	case spec.InputKindOutputMessage:
+		if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
+			return "", nil, errEmptyInputPart
+		}
+		// Converse takes images and documents in user messages only.
+		blocks := contentItemsToBedrockBlocks(ctx, in.OutputMessage.Contents, false)
+		if len(blocks) == 0 {
+			return "", nil, errEmptyInputPart
+		}
+		return bedrockRoleAssistant, withBedrockCachePoint(blocks, in.OutputMessage.CacheControl), nil
+
+	case spec.InputKindReasoningMessage:
+		blocks := reasoningContentToBedrockBlocks(in.ReasoningMessage)
+		if len(blocks) == 0 {
+			return "", nil, errEmptyInputPart
+		}
+		return bedrockRoleAssistant, blocks, nil
+
+	case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
+		var call *spec.ToolCall
+		switch {
+		case in.FunctionToolCall != nil:
+			call = in.FunctionToolCall
+		case in.CustomToolCall != nil:
+			call = in.CustomToolCall
+		}
+		block, err := toolCallToBedrockToolUse(call)
+		if err != nil {
+			return "", nil, err
+		}
+		return bedrockRoleAssistant, withBedrockCachePoint([]bedrockContentBlock{block}, call.CacheControl), nil
+
+	case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput:
+		var output *spec.ToolOutput
+		switch {
+		case in.FunctionToolOutput != nil:
+			output = in.FunctionToolOutput
+		case in.CustomToolOutput != nil:
+			output = in.CustomToolOutput
+		}
+		if output == nil {
+			return "", nil, errEmptyInputPart
+		}
+		if strings.TrimSpace(output.CallID) == "" {
+			return "", nil, errors.New("bedrock: tool output is missing callID")
+		}
+		block := toolOutputToBedrockToolResult(ctx, output)
+		return bedrockRoleUser, withBedrockCachePoint([]bedrockContentBlock{block}, output.CacheControl), nil
+
+	case spec.InputKindWebSearchToolCall, spec.InputKindWebSearchToolOutput:
+		// Converse has no server-side web search.
+		return "", nil, errEmptyInputPart
+
+	default:
+		return "", nil, errEmptyInputPart
+	}
+}
+
+// contentItemsToBedrockBlocks converts message content items. Images and
+// documents are kept only when withMedia is set.
+func contentItemsToBedrockBlocks(
+	ctx context.Context,
+	items []spec.InputOutputContentItemUnion,
+	withMedia bool,
+) []bedrockContentBlock {
+	var out []bedrockContentBlock
+	for _, it := range items {
+		switch it.Kind {
+		case spec.ContentItemKindText:
+			if block, ok := contentItemTextToBedrock(it.TextItem); ok {
+				out = append(out, block)
+			}
+
+		case spec.ContentItemKindImage:
+			if !withMedia {
+				continue
+			}
+			if img := contentItemImageToBedrock(ctx, it.ImageItem); img != nil {
+				out = append(out, bedrockContentBlock{Image: img})
+			}
+
+		case spec.ContentItemKindFile:
+			if !withMedia {
+				continue
+			}
+			if doc := contentItemFileToBedrockDocument(ctx, it.FileItem); doc != nil {
+				out = append(out, bedrockContentBlock{Document: doc})
+			}
+
+		case spec.ContentItemKindRefusal:
+			// Refusals are model outputs; not a meaningful input representation.
+
+		default:
+			logutil.DebugContext(ctx, "bedrock: unsupported content item kind for message", "kind", it.Kind)
+		}
+	}
+	return out
+}
+
+// contentItemTextToBedrock returns a text block, or a citations content block
+// when the text carries URL citations.
+func contentItemTextToBedrock(textItem *spec.ContentItemText) (bedrockContentBlock, bool) {
+	if textItem == nil || strings.TrimSpace(textItem.Text) == "" {
+		return bedrockContentBlock{}, false
+	}
+	var citations []bedrockCitation
+	for _, c := range textItem.Citations {
+		if c.Kind != spec.CitationKindURL || c.URLCitation == nil || c.URLCitation.URL == "" {
+			continue
+		}
+		bc := bedrockCitation{
+			Title:    c.URLCitation.Title,
+			Location: bedrockCitationLocation{Web: &bedrockWebLocation{URL: c.URLCitation.URL}},
+		}
+		if c.URLCitation.CitedText != "" {
+			bc.SourceContent = []bedrockCitationText{{Text: c.URLCitation.CitedText}}
+		}
+		citations = append(citations, bc)
+	}
+	if len(citations) == 0 {
+		return bedrockContentBlock{Text: textItem.Text}, true
+	}
+	return bedrockContentBlock{CitationsContent: &bedrockCitationsContent{
+		Content:   []bedrockCitationText{{Text: textItem.Text}},
+		Citations: citations,
+	}}, true
+}
+
+// reasoningContentToBedrockBlocks replays signed and redacted reasoning.
+// Unsigned reasoning cannot be verified by the model and is dropped.
+func reasoningContentToBedrockBlocks(r *spec.ReasoningContent) []bedrockContentBlock {
+	if r == nil {
+		return nil
+	}
+	var out []bedrockContentBlock
+	thinking := strings.Join(r.Thinking, "\n")
+	if strings.TrimSpace(r.Signature) != "" && strings.TrimSpace(thinking) != "" {
+		out = append(out, bedrockContentBlock{ReasoningContent: &bedrockReasoningBlock{
+			ReasoningText: &bedrockReasoningText{Text: thinking, Signature: r.Signature},
+		}})
+	}
+	for _, redacted := range r.RedactedThinking {
+		if strings.TrimSpace(redacted) != "" {
+			out = append(out, bedrockContentBlock{ReasoningContent: &bedrockReasoningBlock{RedactedContent: redacted}})
+		}
+	}
+	return out
+}
+
+// toolCallToBedrockToolUse converts a ToolCall from the conversation history.
+// Converse expects the input as a JSON object.
+func toolCallToBedrockToolUse(call *spec.ToolCall) (bedrockContentBlock, error) {
+	if call == nil {
+		return bedrockContentBlock{}, errEmptyInputPart
+	}
+	name := strings.TrimSpace(call.Name)
+	if name == "" {
+		return bedrockContentBlock{}, errors.New("bedrock: tool call is missing name")
+	}
+	id := strings.TrimSpace(call.CallID)
+	if id == "" {
+		id = strings.TrimSpace(call.ID)
+	}
+	if id == "" {
+		return bedrockContentBlock{}, errors.New("bedrock: tool call is missing callID")
+	}
+
+	input := json.RawMessage("{}")
+	if a := strings.TrimSpace(call.Arguments); a != "" {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(a), &obj); err != nil {
+			return bedrockContentBlock{}, fmt.Errorf("bedrock: tool call %q has invalid JSON arguments: %w", name, err)
+		}
+		input = json.RawMessage(a)
+	}
+	return bedrockContentBlock{ToolUse: &bedrockToolUseBlock{ToolUseID: id, Name: name, Input: input}}, nil
+}
+
+// toolOutputToBedrockToolResult converts a ToolOutput to a toolResult block.
+func toolOutputToBedrockToolResult(ctx context.Context, output *spec.ToolOutput) bedrockContentBlock {
+	result := &bedrockToolResultBlock{ToolUseID: strings.TrimSpace(output.CallID)}
+	for _, c := range output.Contents {
+		switch c.Kind {
+		case spec.ContentItemKindText:
+			if c.TextItem != nil && strings.TrimSpace(c.TextItem.Text) != "" {
+				result.Content = append(result.Content, bedrockToolResultContent{Text: c.TextItem.Text})
+			}
+		case spec.ContentItemKindImage:
+			if img := contentItemImageToBedrock(ctx, c.ImageItem); img != nil {
+				result.Content = append(result.Content, bedrockToolResultContent{Image: img})
+			}
+		case spec.ContentItemKindFile:
+			if doc := contentItemFileToBedrockDocument(ctx, c.FileItem); doc != nil {
+				result.Content = append(result.Content, bedrockToolResultContent{Document: doc})
+			}
+		default:
+			logutil.DebugContext(ctx, "bedrock: unsupported tool output item kind", "kind", c.Kind)
+		}
+	}
+	if output.IsError {
+		result.Status = "error"
+		if len(result.Content) == 0 {
+			result.Content = []bedrockToolResultContent{{Text: "tool call failed"}}
+		}
+	}
+	if len(result.Content) == 0 {
+		// Converse rejects a tool result without content.
+		result.Content = []bedrockToolResultContent{{Text: "(empty)"}}
+	}
+	return bedrockContentBlock{ToolResult: result}
+}
+
+// contentItemImageToBedrock returns an image block from base64 data. Converse
+// does not fetch images, so URL-only images are skipped.
+func contentItemImageToBedrock(ctx context.Context, imageItem *spec.ContentItemImage) *bedrockImageBlock {
+	if imageItem == nil {
+		return nil
+	}
+	mime, data := splitDataURL(strings.TrimSpace(imageItem.ImageData))
+	if data == "" {
+		if imageItem.ImageURL != "" {
+			logutil.DebugContext(ctx, "bedrock: image URLs are not supported, skipping image", "id", imageItem.ID)
+		}
+		return nil
+	}
+	if !isBase64(data) {
+		logutil.DebugContext(ctx, "bedrock: failed to decode base64 image data", "id", imageItem.ID)
+		return nil
+	}
+	if mime == "" {
+		mime = strings.TrimSpace(imageItem.ImageMIME)
+	}
+	if mime == "" {
+		mime = spec.DefaultImageDataMIME
+	}
+	format, ok := bedrockImageFormat(mime)
+	if !ok {
+		logutil.DebugContext(ctx, "bedrock: unsupported image type, skipping image", "id", imageItem.ID, "mime", mime)
+		return nil
+	}
+	return &bedrockImageBlock{Format: format, Source: bedrockSource{Bytes: data}}
+}
+
+// contentItemFileToBedrockDocument returns a document block from base64 data.
+// URL-only files are skipped.
+func contentItemFileToBedrockDocument(ctx context.Context, fileItem *spec.ContentItemFile) *bedrockDocumentBlock {
+	if fileItem == nil {
+		return nil
+	}
+	mime, data := splitDataURL(strings.TrimSpace(fileItem.FileData))
+	if data == "" {
+		if fileItem.FileURL != "" {
+			logutil.DebugContext(ctx, "bedrock: file URLs are not supported, skipping file", "id", fileItem.ID)
+		}
+		return nil
+	}
+	if !isBase64(data) {
+		logutil.DebugContext(ctx, "bedrock: failed to decode base64 file data", "id", fileItem.ID)
+		return nil
+	}
+	if m := strings.TrimSpace(fileItem.FileMIME); m != "" {
+		mime = m
+	}
+	format, ok := bedrockDocumentFormat(mime)
+	if !ok {
+		logutil.DebugContext(ctx, "bedrock: unsupported document type, skipping file", "id", fileItem.ID, "mime", mime)
+		return nil
+	}
+	doc := &bedrockDocumentBlock{
+		Format: format,
+		Name:   bedrockDocumentName(fileItem.FileName),
+		Source: bedrockSource{Bytes: data},
+	}
+	if fileItem.CitationConfig != nil && fileItem.CitationConfig.Enabled {
+		doc.Citations = &bedrockCitationsConfig{Enabled: true}
+	}
+	return doc
+}
+
+// splitDataURL returns the MIME type and payload of a data URL, or data as is.
+func splitDataURL(data string) (mime, payload string) {
+	rest, ok := strings.CutPrefix(data, "data:")
+	if !ok {
+		return "", data
+	}
+	meta, payload, found := strings.Cut(rest, ",")
+	if !found {
+		return "", data
+	}
+	mime, _, _ = strings.Cut(meta, ";")
+	return mime, payload
+}
+
+func bedrockImageFormat(mime string) (string, bool) {
+	switch strings.ToLower(mime) {
+	case "image/png":
+		return "png", true
+	case "image/jpeg", "image/jpg":
+		return "jpeg", true
+	case "image/gif":
+		return "gif", true
+	case "image/webp":
+		return "webp", true
+	default:
+		return "", false
+	}
+}
+
+func bedrockDocumentFormat(mime string) (string, bool) {
+	mime, _, _ = strings.Cut(strings.ToLower(mime), ";")
+	switch strings.TrimSpace(mime) {
+	case "application/pdf":
+		return "pdf", true
+	case "text/csv":
+		return "csv", true
+	case "application/msword":
+		return "doc", true
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return "docx", true
+	case "application/vnd.ms-excel":
+		return "xls", true
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return "xlsx", true
+	case "text/html":
+		return "html", true
+	case "text/plain":
+		return "txt", true
+	case "text/markdown":
+		return "md", true
+	default:
+		return "", false
+	}
+}
+
+// bedrockDocumentName reduces a file name to the characters Converse allows
+// in document names: letters, digits, single spaces, hyphens, parentheses and
+// square brackets.
+func bedrockDocumentName(fileName string) string {
+	var b strings.Builder
+	for _, r := range fileName {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '-', r == '(', r == ')', r == '[', r == ']':
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	name := strings.Join(strings.Fields(b.String()), " ")
+	if name == "" {
+		return "document"
+	}
+	return name
+}
+
+// withBedrockCachePoint appends a cache point after blocks when cc asks for
+// caching.
+func withBedrockCachePoint(blocks []bedrockContentBlock, cc *spec.CacheControl) []bedrockContentBlock {
+	if cp := bedrockCachePointFor(cc); cp != nil {
+		return append(blocks, bedrockContentBlock{CachePoint: cp})
+	}
+	return blocks
+}
+
+func bedrockCachePointFor(cc *spec.CacheControl) *bedrockCachePoint {
+	if cc == nil || (cc.Kind != "" && cc.Kind != spec.CacheControlKindEphemeral) {
+		return nil
+	}
+	return &bedrockCachePoint{Type: bedrockCachePointDefault}
+}
+
+// toolChoicesToBedrockTools converts function and custom tool choices into
+// Converse tool specs, each followed by a cache point when it asks for one.
+func toolChoicesToBedrockTools(toolChoices []spec.ToolChoice) ([]bedrockTool, map[string]spec.ToolChoice) {
+	if len(toolChoices) == 0 {
+		return nil, nil
+	}
+
+	ordered, nameMap := sdkutil.BuildToolChoiceNameMapping(toolChoices)
+	out := make([]bedrockTool, 0, len(ordered))
+	for _, tw := range ordered {
+		tc := tw.Choice
+		switch tc.Type {
+		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			if tw.Name == "" {
+				continue
+			}
+			srcArgs := tc.Arguments
+			if srcArgs == nil {
+				srcArgs = sdkutil.EmptyJSONArgs
+			}
+			schema := make(map[string]any, len(srcArgs))
+			maps.Copy(schema, srcArgs)
+			out = append(out, bedrockTool{ToolSpec: &bedrockToolSpec{
+				Name:        tw.Name,
+				Description: sdkutil.ToolDescription(tc),
+				InputSchema: bedrockInputSchema{JSON: schema},
+			}})
+			if cp := bedrockCachePointFor(tc.CacheControl); cp != nil {
+				out = append(out, bedrockTool{CachePoint: cp})
+			}
+		default:
+			// Web search is not available through Converse.
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nameMap
+}
+
+// applyBedrockToolPolicy sets the tool choice of config.
+func applyBedrockToolPolicy(
+	config *bedrockToolConfig,
+	policy *spec.ToolPolicy,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) error {
+	if config == nil || policy == nil {
+		return nil
+	}
+	switch policy.Mode {
+	case spec.ToolPolicyModeAuto:
+		config.ToolChoice = &bedrockToolChoice{Auto: &struct{}{}}
+	case spec.ToolPolicyModeAny:
+		config.ToolChoice = &bedrockToolChoice{Any: &struct{}{}}
+	case spec.ToolPolicyModeTool:
+		resolved, err := sdkutil.ResolveAllowedTools(policy.AllowedTools, toolChoiceNameMap)
+		if err != nil || len(resolved) == 0 {
+			return errors.New(
+				"bedrock: toolPolicy=tool requires allowedTools with a resolvable toolChoiceName/toolChoiceID",
+			)
+		}
+		config.ToolChoice = &bedrockToolChoice{Tool: &bedrockSpecificTool{Name: resolved[0].Name}}
+	case spec.ToolPolicyModeNone:
+		// Not expressible; normalization drops it first.
+	default:
+		return fmt.Errorf("bedrock: unknown toolPolicy.mode %q", policy.Mode)
+	}
+	return nil
+}
+
+// isBase64 reports whether s is standard base64, padded or not.
+func isBase64(s string) bool {
+	if _, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return true
+	}
+	_, err := base64.RawStdEncoding.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,324 @@
+package bedrockconversesdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestToBedrockMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		systemPrompt string
+		inputs       []spec.InputUnion
+		want         []bedrockMessage
+		wantSystem   []bedrockSystemBlock
+		wantErr      bool
+	}{
+		{
+			name:         "merges an assistant turn and answers tools in a user message",
+			systemPrompt: "You are helpful.",
+			inputs: []spec.InputUnion{
+				userText("Weather in SF?"),
+				signedReasoning("Need the weather tool.", "sig"),
+				assistantText("Let me check."),
+				functionToolCall("call_1", "get_weather", `{"city":"SF"}`),
+				functionToolOutput("call_1", "72F and sunny"),
+				userText("Thanks."),
+			},
+			wantSystem: []bedrockSystemBlock{{Text: "You are helpful."}},
+			want: []bedrockMessage{
+				{Role: bedrockRoleUser, Content: []bedrockContentBlock{{Text: "Weather in SF?"}}},
+				{
+					Role: bedrockRoleAssistant,
+					Content: []bedrockContentBlock{
+						{ReasoningContent: &bedrockReasoningBlock{
+							ReasoningText: &bedrockReasoningText{Text: "Need the weather tool.", Signature: "sig"},
+						}},
+						{Text: "Let me check."},
+						{ToolUse: &bedrockToolUseBlock{
+							ToolUseID: "call_1",
+							Name:      "get_weather",
+							Input:     json.RawMessage(`{"city":"SF"}`),
+						}},
+					},
+				},
+				{
+					Role: bedrockRoleUser,
+					Content: []bedrockContentBlock{
+						{ToolResult: &bedrockToolResultBlock{
+							ToolUseID: "call_1",
+							Content:   []bedrockToolResultContent{{Text: "72F and sunny"}},
+						}},
+						{Text: "Thanks."},
+					},
+				},
+			},
+		},
+		{
+			name:   "unsigned reasoning is dropped",
+			inputs: []spec.InputUnion{signedReasoning("thinking", ""), assistantText("Hi.")},
+			want: []bedrockMessage{
+				{Role: bedrockRoleAssistant, Content: []bedrockContentBlock{{Text: "Hi."}}},
+			},
+		},
+		{
+			name: "images from data URLs, URL images skipped, cache point appended",
+			inputs: []spec.InputUnion{{
+				Kind: spec.InputKindInputMessage,
+				InputMessage: &spec.InputOutputContent{
+					Role: spec.RoleUser,
+					Contents: []spec.InputOutputContentItemUnion{
+						{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: "What is this?"}},
+						{
+							Kind:      spec.ContentItemKindImage,
+							ImageItem: &spec.ContentItemImage{ImageData: "data:image/jpeg;base64,aGVsbG8="},
+						},
+						{
+							Kind:      spec.ContentItemKindImage,
+							ImageItem: &spec.ContentItemImage{ImageURL: "https://example.com/a.png"},
+						},
+						{
+							Kind: spec.ContentItemKindFile,
+							FileItem: &spec.ContentItemFile{
+								FileName:       "report_2024.pdf",
+								FileMIME:       "application/pdf",
+								FileData:       "aGVsbG8=",
+								CitationConfig: &spec.CitationConfig{Enabled: true},
+							},
+						},
+					},
+					CacheControl: &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+				},
+			}},
+			want: []bedrockMessage{{
+				Role: bedrockRoleUser,
+				Content: []bedrockContentBlock{
+					{Text: "What is this?"},
+					{Image: &bedrockImageBlock{Format: "jpeg", Source: bedrockSource{Bytes: "aGVsbG8="}}},
+					{Document: &bedrockDocumentBlock{
+						Format:    "pdf",
+						Name:      "report 2024 pdf",
+						Source:    bedrockSource{Bytes: "aGVsbG8="},
+						Citations: &bedrockCitationsConfig{Enabled: true},
+					}},
+					{CachePoint: &bedrockCachePoint{Type: bedrockCachePointDefault}},
+				},
+			}},
+		},
+		{
+			name:   "empty tool arguments become an object",
+			inputs: []spec.InputUnion{functionToolCall("call_1", "now", "")},
+			want: []bedrockMessage{{
+				Role: bedrockRoleAssistant,
+				Content: []bedrockContentBlock{{ToolUse: &bedrockToolUseBlock{
+					ToolUseID: "call_1",
+					Name:      "now",
+					Input:     json.RawMessage(`{}`),
+				}}},
+			}},
+		},
+		{
+			name:    "tool output without call ID",
+			inputs:  []spec.InputUnion{functionToolOutput("", "x")},
+			wantErr: true,
+		},
+		{
+			name:    "tool call with non JSON arguments",
+			inputs:  []spec.InputUnion{functionToolCall("call_1", "f", "not json")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, system, err := toBedrockMessages(t.Context(), tt.systemPrompt, tt.inputs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("toBedrockMessages: %v", err)
+			}
+			if !reflect.DeepEqual(system, tt.wantSystem) {
+				t.Errorf("system = %+v, want %+v", system, tt.wantSystem)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Fatalf("messages\n got: %s\nwant: %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestApplyBedrockThinking(t *testing.T) {
+	t.Parallel()
+
+	level := func(l spec.ReasoningLevel) *spec.ReasoningParam {
+		return &spec.ReasoningParam{Type: spec.ReasoningTypeSingleWithLevels, Level: l}
+	}
+	user := bedrockMessage{Role: bedrockRoleUser, Content: []bedrockContentBlock{{Text: "hi"}}}
+	tests := []struct {
+		name          string
+		model         spec.ModelName
+		reasoning     *spec.ReasoningParam
+		maxTokens     int
+		messages      []bedrockMessage
+		wantBudget    int
+		wantMaxTokens int
+	}{
+		{
+			name:          "level on an Anthropic inference profile",
+			model:         "us.anthropic.claude-sonnet-4-5-20250929-v1:0",
+			reasoning:     level(spec.ReasoningLevelMedium),
+			messages:      []bedrockMessage{user},
+			wantBudget:    2048,
+			wantMaxTokens: bedrockDefaultMaxTokens,
+		},
+		{
+			name:          "budget above max tokens raises max tokens",
+			model:         "anthropic.claude-3-7-sonnet-20250219-v1:0",
+			reasoning:     &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 4096},
+			maxTokens:     1024,
+			messages:      []bedrockMessage{user},
+			wantBudget:    1024,
+			wantMaxTokens: 1025,
+		},
+		{
+			name:      "other models take no thinking config",
+			model:     "us.meta.llama3-3-70b-instruct-v1:0",
+			reasoning: level(spec.ReasoningLevelHigh),
+			messages:  []bedrockMessage{user},
+		},
+		{
+			name:      "tool result of a turn without reasoning",
+			model:     "anthropic.claude-sonnet-4-20250514-v1:0",
+			reasoning: level(spec.ReasoningLevelHigh),
+			messages: []bedrockMessage{
+				user,
+				{Role: bedrockRoleAssistant, Content: []bedrockContentBlock{
+					{ToolUse: &bedrockToolUseBlock{ToolUseID: "c1", Name: "f", Input: json.RawMessage(`{}`)}},
+				}},
+				{Role: bedrockRoleUser, Content: []bedrockContentBlock{
+					{ToolResult: &bedrockToolResultBlock{ToolUseID: "c1"}},
+				}},
+			},
+		},
+		{
+			name:  "replayed reasoning enables thinking",
+			model: "anthropic.claude-sonnet-4-20250514-v1:0",
+			messages: []bedrockMessage{
+				user,
+				{Role: bedrockRoleAssistant, Content: []bedrockContentBlock{
+					{ReasoningContent: &bedrockReasoningBlock{RedactedContent: "x"}},
+					{Text: "hello"},
+				}},
+				user,
+			},
+			wantBudget:    bedrockDefaultThinkingBudget,
+			wantMaxTokens: bedrockDefaultMaxTokens,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			params := &converseRequest{}
+			if tt.maxTokens > 0 {
+				params.InferenceConfig = &bedrockInferenceConfig{MaxTokens: tt.maxTokens, Temperature: new(0.5)}
+			}
+			mp := &spec.ModelParam{Name: tt.model, Reasoning: tt.reasoning}
+			applyBedrockThinking(t.Context(), params, mp, tt.messages)
+
+			thinking, _ := params.AdditionalModelRequestFields["thinking"].(map[string]any)
+			if tt.wantBudget == 0 {
+				if thinking != nil {
+					t.Fatalf("thinking = %+v, want none", thinking)
+				}
+				return
+			}
+			if thinking == nil || thinking["budget_tokens"] != tt.wantBudget {
+				t.Fatalf("thinking = %+v, want budget %d", thinking, tt.wantBudget)
+			}
+			if params.InferenceConfig.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("maxTokens = %d, want %d", params.InferenceConfig.MaxTokens, tt.wantMaxTokens)
+			}
+			if params.InferenceConfig.Temperature != nil {
+				t.Error("temperature kept with thinking enabled")
+			}
+		})
+	}
+}
+
+func userText(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
+
+func assistantText(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{
+			Role: spec.RoleAssistant,
+			Contents: []spec.InputOutputContentItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
+
+func signedReasoning(text, signature string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{
+			Role:      spec.RoleAssistant,
+			Thinking:  []string{text},
+			Signature: signature,
+		},
+	}
+}
+
+func functionToolCall(callID, name, args string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type:      spec.ToolTypeFunction,
+			Role:      spec.RoleAssistant,
+			CallID:    callID,
+			Name:      name,
+			Arguments: args,
+		},
+	}
+}
+
+func functionToolOutput(callID, text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolOutput,
+		FunctionToolOutput: &spec.ToolOutput{
+			Type:   spec.ToolTypeFunction,
+			Role:   spec.RoleTool,
+			CallID: callID,
+			Name:   "tool",
+			Contents: []spec.ToolOutputItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package bedrockconversesdk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// bedrockProviderError maps a Bedrock API / stream error into a spec.ProviderError.
+// Exceptions inside an event stream carry no HTTP status, so the status their
+// exception type stands for is used for classification.
+func bedrockProviderError(provider spec.ProviderName, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var details sdkutil.ProviderErrorDetails
+	var apiErr *bedrockAPIError
+	var streamErr *bedrockStreamError
+	switch {
+	case errors.As(err, &apiErr):
+		details.HTTPStatus = apiErr.StatusCode
+		details.ProviderCode = apiErr.Code
+		details.RequestID = apiErr.RequestID
+		details.Header = apiErr.Header
+	case errors.As(err, &streamErr):
+		details.ProviderCode = streamErr.Type
+		details.HTTPStatus = bedrockExceptionStatus(streamErr.Type)
+	default:
+	}
+
+	return sdkutil.NewProviderError(provider, err, details)
+}
+
+// bedrockExceptionStatus returns the HTTP status of a Bedrock runtime
+// exception type, or 0 if it is unknown.
+func bedrockExceptionStatus(exceptionType string) int {
+	switch strings.ToLower(exceptionType) {
+	case "validationexception":
+		return http.StatusBadRequest
+	case "accessdeniedexception":
+		return http.StatusForbidden
+	case "resourcenotfoundexception":
+		return http.StatusNotFound
+	case "modeltimeoutexception":
+		return http.StatusRequestTimeout
+	case "throttlingexception":
+		return http.StatusTooManyRequests
+	case "internalserverexception", "modelstreamerrorexception":
+		return http.StatusInternalServerError
+	case "serviceunavailableexception", "modelnotreadyexception":
+		return http.StatusServiceUnavailable
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,233 @@
+package bedrockconversesdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	sigV4Algorithm       = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat      = "20060102T150405Z"
+	sigV4DateFormat      = "20060102"
+	bedrockSigV4Service  = "bedrock"
+	bedrockDefaultRegion = "us-east-1"
+)
+
+// awsCredentials are the credentials requests are signed with.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// bedrockAuth authenticates Bedrock requests in one of three ways: a Bedrock
+// API key sent as a bearer token, static SigV4 credentials, or SigV4
+// credentials read from the environment on every request.
+type bedrockAuth struct {
+	bearerToken string
+	static      *awsCredentials
+	fromEnv     bool
+	region      string
+}
+
+// newBedrockAuth parses the provider API key. It is either
+// spec.BedrockEnvironmentCredentials, "ACCESS_KEY_ID:SECRET_ACCESS_KEY" with an
+// optional ":SESSION_TOKEN", or a Bedrock API key.
+func newBedrockAuth(apiKey, region string) bedrockAuth {
+	a := bedrockAuth{region: region}
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == spec.BedrockEnvironmentCredentials {
+		a.fromEnv = true
+		return a
+	}
+	parts := strings.Split(apiKey, ":")
+	if len(parts) == 2 || len(parts) == 3 {
+		creds := awsCredentials{AccessKeyID: parts[0], SecretAccessKey: parts[1]}
+		if len(parts) == 3 {
+			creds.SessionToken = parts[2]
+		}
+		if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+			a.static = &creds
+			return a
+		}
+	}
+	a.bearerToken = apiKey
+	return a
+}
+
+// authorize sets the authentication headers of req, whose body hashes to
+// payloadHash.
+func (a bedrockAuth) authorize(req *http.Request, payloadHash string, now time.Time) error {
+	if a.bearerToken != "" {
+		req.Header.Set(spec.DefaultAuthorizationHeaderKey, "Bearer "+a.bearerToken)
+		return nil
+	}
+	creds := a.static
+	if a.fromEnv {
+		creds = &awsCredentials{
+			AccessKeyID:     strings.TrimSpace(os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: strings.TrimSpace(os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    strings.TrimSpace(os.Getenv("AWS_SESSION_TOKEN")),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return errors.New("bedrock: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+		}
+	}
+	if creds == nil {
+		return errors.New("bedrock: no credentials")
+	}
+	signSigV4(req, *creds, a.region, bedrockSigV4Service, payloadHash, now)
+	return nil
+}
+
+// bedrockRegion returns the region of a bedrock-runtime origin, such as
+// https://bedrock-runtime.eu-west-1.amazonaws.com or a VPC endpoint under it.
+// Other origins, like a local stand-in, use AWS_REGION or AWS_DEFAULT_REGION.
+func bedrockRegion(host string) string {
+	labels := strings.Split(strings.ToLower(host), ".")
+	for i, l := range labels {
+		if strings.HasPrefix(l, "bedrock-runtime") && i+1 < len(labels) {
+			return labels[i+1]
+		}
+	}
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if r := strings.TrimSpace(os.Getenv(env)); r != "" {
+			return r
+		}
+	}
+	return bedrockDefaultRegion
+}
+
+// signSigV4 signs req with AWS Signature Version 4. The host, content type,
+// date and session token headers are signed. req.URL must carry the escaped
+// path in RawPath when it differs from the default encoding.
+func signSigV4(req *http.Request, creds awsCredentials, region, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	scope := now.Format(sigV4DateFormat) + "/" + region + "/" + service + "/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		vals := make([]string, len(v))
+		for i, s := range v {
+			vals[i] = strings.Join(strings.Fields(s), " ")
+		}
+		headers[name] = strings.Join(vals, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4CanonicalURI(req.URL.EscapedPath()),
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format(sigV4DateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set(spec.DefaultAuthorizationHeaderKey, sigV4Algorithm+
+		" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+}
+
+// sigV4CanonicalURI encodes each segment of an already escaped path once more,
+// as SigV4 requires for every service but S3.
+func sigV4CanonicalURI(escapedPath string) string {
+	if escapedPath == "" {
+		return "/"
+	}
+	segments := strings.Split(escapedPath, "/")
+	for i, s := range segments {
+		segments[i] = awsURIEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sigV4CanonicalQuery(q map[string][]string) string {
+	if len(q) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(q))
+	for k, vals := range q {
+		for _, v := range vals {
+			pairs = append(pairs, awsURIEncode(k)+"="+awsURIEncode(v))
+		}
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte but the RFC 3986 unreserved
+// characters, with upper case hex digits.
+func awsURIEncode(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := range len(s) {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
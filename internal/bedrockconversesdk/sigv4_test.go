@@ -0,0 +1,137 @@
+package bedrockconversesdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The expected signatures are the get-vanilla and post-vanilla cases of the
+// AWS SigV4 test suite.
+func TestSignSigV4(t *testing.T) {
+	t.Parallel()
+
+	creds := awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		method        string
+		wantSignature string
+	}{
+		{method: http.MethodGet, wantSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{method: http.MethodPost, wantSignature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.method, "https://example.amazonaws.com/", http.NoBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signSigV4(req, creds, "us-east-1", "service", sha256Hex(nil), now)
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.wantSignature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Fatalf("Authorization\n got: %s\nwant: %s", got, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+		})
+	}
+}
+
+func TestBedrockAuthorize(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	newReq := func() *http.Request {
+		req, _ := http.NewRequest(
+			http.MethodPost,
+			"https://bedrock-runtime.eu-west-1.amazonaws.com/model/a%3Ab/converse",
+			http.NoBody,
+		)
+		return req
+	}
+
+	t.Run("bearer token", func(t *testing.T) {
+		req := newReq()
+		if err := newBedrockAuth("ABSK-token", "eu-west-1").authorize(req, sha256Hex(nil), now); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer ABSK-token" {
+			t.Fatalf("Authorization = %q", got)
+		}
+	})
+
+	t.Run("static credentials with session token", func(t *testing.T) {
+		req := newReq()
+		auth := newBedrockAuth("AKID:SECRET:TOKEN", "eu-west-1")
+		if err := auth.authorize(req, sha256Hex(nil), now); err != nil {
+			t.Fatal(err)
+		}
+		got := req.Header.Get("Authorization")
+		if !strings.HasPrefix(got, "AWS4-HMAC-SHA256 Credential=AKID/20250102/eu-west-1/bedrock/aws4_request, ") ||
+			!strings.Contains(got, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+			t.Fatalf("Authorization = %q", got)
+		}
+		if req.Header.Get("X-Amz-Security-Token") != "TOKEN" {
+			t.Errorf("session token header = %q", req.Header.Get("X-Amz-Security-Token"))
+		}
+	})
+
+	t.Run("environment credentials", func(t *testing.T) {
+		auth := newBedrockAuth("aws:env", "eu-west-1")
+		t.Setenv("AWS_ACCESS_KEY_ID", "")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+		if err := auth.authorize(newReq(), sha256Hex(nil), now); err == nil {
+			t.Fatal("expected an error without environment credentials")
+		}
+
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+		t.Setenv("AWS_SESSION_TOKEN", "")
+		req := newReq()
+		if err := auth.authorize(req, sha256Hex(nil), now); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); !strings.Contains(got, "Credential=AKIDENV/") {
+			t.Fatalf("Authorization = %q", got)
+		}
+	})
+}
+
+func TestBedrockRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "bedrock-runtime.eu-central-1.amazonaws.com", want: "eu-central-1"},
+		{host: "bedrock-runtime-fips.us-west-2.amazonaws.com", want: "us-west-2"},
+		{host: "vpce-0abc.bedrock-runtime.us-east-2.vpce.amazonaws.com", want: "us-east-2"},
+		{host: "127.0.0.1", want: "ap-south-1"},
+	}
+	for _, tt := range tests {
+		if got := bedrockRegion(tt.host); got != tt.want {
+			t.Errorf("bedrockRegion(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSigV4CanonicalURI(t *testing.T) {
+	t.Parallel()
+
+	model := "us.anthropic.claude-sonnet-4-5-20250929-v1:0"
+	got := sigV4CanonicalURI("/model/" + awsURIEncode(model) + "/converse")
+	want := "/model/us.anthropic.claude-sonnet-4-5-20250929-v1%253A0/converse"
+	if got != want {
+		t.Fatalf("canonical URI = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package bedrockconversesdk
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// bedrockStreamBlock tracks one content block of a ConverseStream response.
+type bedrockStreamBlock struct {
+	kind     spec.OutputKind
+	toolType spec.ToolType
+	id       string
+	name     string
+	open     bool
+
+	text      strings.Builder
+	thinking  strings.Builder
+	signature string
+	redacted  string
+	citations []bedrockCitation
+	args      strings.Builder
+}
+
+// bedrockStreamEvents maps ConverseStream events to normalized stream events
+// and accumulates them into the full response. Content block indexes are
+// used as output indexes.
+//
+// Only tool use blocks are announced by contentBlockStart; text and reasoning
+// blocks start with their first delta.
+type bedrockStreamEvents struct {
+	emitter           *sdkutil.StreamEmitter
+	toolChoiceNameMap map[string]spec.ToolChoice
+
+	blocks  map[int]*bedrockStreamBlock
+	full    converseResponse
+	stopped bool
+}
+
+func newBedrockStreamEvents(
+	emitter *sdkutil.StreamEmitter,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) *bedrockStreamEvents {
+	return &bedrockStreamEvents{
+		emitter:           emitter,
+		toolChoiceNameMap: toolChoiceNameMap,
+		blocks:            map[int]*bedrockStreamBlock{},
+	}
+}
+
+func (s *bedrockStreamEvents) handle(eventType string, ev *converseStreamEvent) error {
+	switch eventType {
+	case "messageStart":
+		return nil
+
+	case "contentBlockStart":
+		if ev.Start == nil || ev.Start.ToolUse == nil {
+			return nil
+		}
+		b := s.block(ev.ContentBlockIndex, spec.OutputKindFunctionToolCall)
+		b.toolType = spec.ToolTypeFunction
+		b.name = strings.TrimSpace(ev.Start.ToolUse.Name)
+		b.id = ev.Start.ToolUse.ToolUseID
+		if tc, ok := s.toolChoiceNameMap[b.name]; ok && tc.Type == spec.ToolTypeCustom {
+			b.kind = spec.OutputKindCustomToolCall
+			b.toolType = spec.ToolTypeCustom
+		}
+		if err := s.start(ev.ContentBlockIndex, b); err != nil {
+			return err
+		}
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallStart, spec.StreamToolCallChunk{
+			OutputIndex: ev.ContentBlockIndex,
+			Type:        b.toolType,
+			CallID:      b.id,
+			Name:        b.name,
+		})
+
+	case "contentBlockDelta":
+		if ev.Delta == nil {
+			return nil
+		}
+		return s.handleDelta(ev.ContentBlockIndex, ev.Delta)
+
+	case "contentBlockStop":
+		return s.stop(ev.ContentBlockIndex)
+
+	case "messageStop":
+		s.stopped = true
+		s.full.StopReason = ev.StopReason
+		s.full.AdditionalModelResponseFields = ev.AdditionalModelResponseFields
+		return s.closeAll()
+
+	case "metadata":
+		s.full.Usage = ev.Usage
+		s.full.Metrics = ev.Metrics
+		return nil
+
+	default:
+		// Unknown or future event type.
+		return nil
+	}
+}
+
+func (s *bedrockStreamEvents) handleDelta(idx int, d *converseStreamDelta) error {
+	switch {
+	case d.Text != nil:
+		b := s.block(idx, spec.OutputKindOutputMessage)
+		if err := s.start(idx, b); err != nil {
+			return err
+		}
+		b.text.WriteString(*d.Text)
+		return s.emitter.WriteText(*d.Text)
+
+	case d.ReasoningContent != nil:
+		b := s.block(idx, spec.OutputKindReasoningMessage)
+		if err := s.start(idx, b); err != nil {
+			return err
+		}
+		rc := d.ReasoningContent
+		if rc.Signature != "" {
+			b.signature += rc.Signature
+		}
+		// Redacted reasoning is kept for replay but not streamed.
+		b.redacted += rc.RedactedContent
+		if rc.Text == "" {
+			return nil
+		}
+		b.thinking.WriteString(rc.Text)
+		return s.emitter.WriteThinking(rc.Text)
+
+	case d.ToolUse != nil:
+		b := s.blocks[idx]
+		if b == nil || d.ToolUse.Input == "" {
+			return nil
+		}
+		b.args.WriteString(d.ToolUse.Input)
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, spec.StreamToolCallChunk{
+			OutputIndex:    idx,
+			Type:           b.toolType,
+			CallID:         b.id,
+			Name:           b.name,
+			ArgumentsDelta: d.ToolUse.Input,
+		})
+
+	case d.Citation != nil:
+		b := s.block(idx, spec.OutputKindOutputMessage)
+		if err := s.start(idx, b); err != nil {
+			return err
+		}
+		b.citations = append(b.citations, *d.Citation)
+		c, ok := bedrockCitationToSpec(*d.Citation)
+		if !ok {
+			return nil
+		}
+		return s.emitter.Citation(idx, c)
+
+	default:
+		return nil
+	}
+}
+
+// block returns the block at idx, creating it with kind if needed.
+func (s *bedrockStreamEvents) block(idx int, kind spec.OutputKind) *bedrockStreamBlock {
+	b := s.blocks[idx]
+	if b == nil {
+		b = &bedrockStreamBlock{kind: kind}
+		s.blocks[idx] = b
+	}
+	return b
+}
+
+// start emits the start of b unless it was started already.
+func (s *bedrockStreamEvents) start(idx int, b *bedrockStreamBlock) error {
+	if b.open {
+		return nil
+	}
+	b.open = true
+	return s.emitter.OutputItemStart(idx, b.kind, b.id)
+}
+
+// stop emits the end of the block at idx, if it is open.
+func (s *bedrockStreamEvents) stop(idx int) error {
+	b := s.blocks[idx]
+	if b == nil || !b.open {
+		return nil
+	}
+	b.open = false
+	if b.kind == spec.OutputKindFunctionToolCall || b.kind == spec.OutputKindCustomToolCall {
+		if err := s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, spec.StreamToolCallChunk{
+			OutputIndex: idx,
+			Type:        b.toolType,
+			CallID:      b.id,
+			Name:        b.name,
+			Arguments:   bedrockToolInput(b.args.String()),
+		}); err != nil {
+			return err
+		}
+	}
+	return s.emitter.OutputItemStop(idx, b.kind, b.id)
+}
+
+// closeAll ends all open blocks in index order. It is idempotent.
+func (s *bedrockStreamEvents) closeAll() error {
+	for _, idx := range s.indexes() {
+		if err := s.stop(idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *bedrockStreamEvents) indexes() []int {
+	idxs := make([]int, 0, len(s.blocks))
+	for idx := range s.blocks {
+		idxs = append(idxs, idx)
+	}
+	slices.Sort(idxs)
+	return idxs
+}
+
+// response returns the response accumulated so far.
+func (s *bedrockStreamEvents) response() *converseResponse {
+	out := s.full
+	msg := &bedrockMessage{Role: bedrockRoleAssistant}
+	for _, idx := range s.indexes() {
+		b := s.blocks[idx]
+		switch b.kind {
+		case spec.OutputKindOutputMessage:
+			text := b.text.String()
+			if len(b.citations) == 0 {
+				msg.Content = append(msg.Content, bedrockContentBlock{Text: text})
+				continue
+			}
+			msg.Content = append(msg.Content, bedrockContentBlock{CitationsContent: &bedrockCitationsContent{
+				Content:   []bedrockCitationText{{Text: text}},
+				Citations: b.citations,
+			}})
+
+		case spec.OutputKindReasoningMessage:
+			rb := &bedrockReasoningBlock{RedactedContent: b.redacted}
+			if b.redacted == "" {
+				rb.ReasoningText = &bedrockReasoningText{Text: b.thinking.String(), Signature: b.signature}
+			}
+			msg.Content = append(msg.Content, bedrockContentBlock{ReasoningContent: rb})
+
+		case spec.OutputKindFunctionToolCall, spec.OutputKindCustomToolCall:
+			msg.Content = append(msg.Content, bedrockContentBlock{ToolUse: &bedrockToolUseBlock{
+				ToolUseID: b.id,
+				Name:      b.name,
+				Input:     bedrockRawToolInput(b.args.String()),
+			}})
+
+		default:
+		}
+	}
+	out.Output.Message = msg
+	return &out
+}
+
+// bedrockRawToolInput returns streamed tool input as raw JSON for the
+// accumulated response, quoting it when the stream ended mid-object.
+func bedrockRawToolInput(args string) json.RawMessage {
+	input := bedrockToolInput(args)
+	if json.Valid([]byte(input)) {
+		return json.RawMessage(input)
+	}
+	quoted, _ := json.Marshal(input)
+	return quoted
+}
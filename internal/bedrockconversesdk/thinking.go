@@ -0,0 +1,142 @@
+package bedrockconversesdk
+
+import (
+	"context"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	bedrockDefaultThinkingBudget = 1024
+	bedrockDefaultMaxTokens      = 8192
+)
+
+// isBedrockAnthropicModel reports whether model is an Anthropic model ID,
+// inference profile ID or ARN, e.g. "us.anthropic.claude-sonnet-4-5-20250929-v1:0".
+func isBedrockAnthropicModel(model spec.ModelName) bool {
+	m := strings.ToLower(string(model))
+	return strings.Contains(m, "anthropic.") || strings.Contains(m, "claude")
+}
+
+// applyBedrockThinking sets the Anthropic thinking config of params from the
+// reasoning param and the conversation history. Other models take no
+// reasoning config through Converse.
+//
+// As for Anthropic Messages, thinking is switched off when the last tool
+// result answers an assistant turn that did not start with reasoning, and on
+// when signed or redacted reasoning is replayed.
+func applyBedrockThinking(
+	ctx context.Context,
+	params *converseRequest,
+	mp *spec.ModelParam,
+	messages []bedrockMessage,
+) {
+	if !isBedrockAnthropicModel(mp.Name) {
+		if mp.Reasoning != nil {
+			logutil.DebugContext(
+				ctx,
+				"bedrock: reasoning config is only sent to Anthropic models, ignoring it",
+				"model", string(mp.Name),
+			)
+		}
+		return
+	}
+
+	enabled, budget := requestedBedrockThinking(mp.Reasoning)
+	replaysReasoning, lastToolResultWithoutReasoning := analyzeBedrockReasoningHistory(messages)
+	switch {
+	case enabled && lastToolResultWithoutReasoning:
+		logutil.DebugContext(ctx, "bedrock: thinking disabled for a tool result of a turn without reasoning")
+		enabled = false
+	case !enabled && replaysReasoning && !lastToolResultWithoutReasoning:
+		logutil.DebugContext(ctx, "bedrock: reasoning present in input, enabling thinking as a fail-safe")
+		enabled, budget = true, bedrockDefaultThinkingBudget
+	default:
+	}
+	if !enabled {
+		return
+	}
+
+	if params.InferenceConfig == nil {
+		params.InferenceConfig = &bedrockInferenceConfig{}
+	}
+	ic := params.InferenceConfig
+	if ic.MaxTokens <= 0 {
+		ic.MaxTokens = bedrockDefaultMaxTokens
+	}
+	budget = max(min(budget, ic.MaxTokens-1), bedrockDefaultThinkingBudget)
+	if ic.MaxTokens <= budget {
+		ic.MaxTokens = budget + 1
+	}
+	// Temperature and top-p are not allowed with thinking.
+	ic.Temperature = nil
+	ic.TopP = nil
+
+	if params.AdditionalModelRequestFields == nil {
+		params.AdditionalModelRequestFields = map[string]any{}
+	}
+	params.AdditionalModelRequestFields["thinking"] = map[string]any{
+		"type":          "enabled",
+		"budget_tokens": budget,
+	}
+}
+
+// requestedBedrockThinking maps the reasoning param to a thinking budget.
+func requestedBedrockThinking(rp *spec.ReasoningParam) (enabled bool, budget int) {
+	if rp == nil {
+		return false, 0
+	}
+	switch rp.Type {
+	case spec.ReasoningTypeHybridWithTokens:
+		return true, max(rp.Tokens, bedrockDefaultThinkingBudget)
+	case spec.ReasoningTypeSingleWithLevels:
+		switch rp.Level {
+		case spec.ReasoningLevelNone:
+			return false, 0
+		case spec.ReasoningLevelMinimal, spec.ReasoningLevelLow:
+			return true, 1024
+		case spec.ReasoningLevelMedium:
+			return true, 2048
+		case spec.ReasoningLevelHigh:
+			return true, 8192
+		case spec.ReasoningLevelXHigh, spec.ReasoningLevelMax:
+			return true, 16384
+		default:
+			return false, 0
+		}
+	default:
+		return false, 0
+	}
+}
+
+// analyzeBedrockReasoningHistory reports whether messages replay reasoning,
+// and whether the last message holds tool results for an assistant turn that
+// does not start with reasoning.
+func analyzeBedrockReasoningHistory(messages []bedrockMessage) (replaysReasoning, lastToolResultWithoutReasoning bool) {
+	for _, m := range messages {
+		for _, b := range m.Content {
+			if b.ReasoningContent != nil {
+				replaysReasoning = true
+			}
+		}
+	}
+
+	n := len(messages)
+	if n < 2 || messages[n-1].Role != bedrockRoleUser || messages[n-2].Role != bedrockRoleAssistant {
+		return replaysReasoning, false
+	}
+	hasToolResult := false
+	for _, b := range messages[n-1].Content {
+		if b.ToolResult != nil {
+			hasToolResult = true
+		}
+	}
+	if !hasToolResult {
+		return replaysReasoning, false
+	}
+	prev := messages[n-2].Content
+	startsWithReasoning := len(prev) > 0 && prev[0].ReasoningContent != nil
+	return replaysReasoning, !startsWithReasoning
+}
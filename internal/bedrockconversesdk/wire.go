@@ -0,0 +1,390 @@
+package bedrockconversesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	bedrockConverseSuffix       = "/converse"
+	bedrockConverseStreamSuffix = "/converse-stream"
+	bedrockEventStreamMIME      = "application/vnd.amazon.eventstream"
+)
+
+// converseRequest is the body of POST /model/{modelId}/converse and
+// /converse-stream. The model is part of the path.
+type converseRequest struct {
+	Messages        []bedrockMessage        `json:"messages"`
+	System          []bedrockSystemBlock    `json:"system,omitempty"`
+	InferenceConfig *bedrockInferenceConfig `json:"inferenceConfig,omitempty"`
+	ToolConfig      *bedrockToolConfig      `json:"toolConfig,omitempty"`
+
+	// AdditionalModelRequestFields carries model specific fields, such as the
+	// thinking config of Anthropic models.
+	AdditionalModelRequestFields map[string]any `json:"additionalModelRequestFields,omitempty"`
+}
+
+type bedrockInferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+type bedrockSystemBlock struct {
+	Text       string             `json:"text,omitempty"`
+	CachePoint *bedrockCachePoint `json:"cachePoint,omitempty"`
+}
+
+type bedrockMessage struct {
+	Role    string                `json:"role"`
+	Content []bedrockContentBlock `json:"content"`
+}
+
+// bedrockContentBlock is a union; exactly one field is set.
+type bedrockContentBlock struct {
+	Text             string                   `json:"text,omitempty"`
+	Image            *bedrockImageBlock       `json:"image,omitempty"`
+	Document         *bedrockDocumentBlock    `json:"document,omitempty"`
+	ToolUse          *bedrockToolUseBlock     `json:"toolUse,omitempty"`
+	ToolResult       *bedrockToolResultBlock  `json:"toolResult,omitempty"`
+	ReasoningContent *bedrockReasoningBlock   `json:"reasoningContent,omitempty"`
+	CitationsContent *bedrockCitationsContent `json:"citationsContent,omitempty"`
+	CachePoint       *bedrockCachePoint       `json:"cachePoint,omitempty"`
+}
+
+// bedrockSource holds base64 encoded bytes.
+type bedrockSource struct {
+	Bytes string `json:"bytes"`
+}
+
+type bedrockImageBlock struct {
+	Format string        `json:"format"`
+	Source bedrockSource `json:"source"`
+}
+
+type bedrockDocumentBlock struct {
+	Format    string                  `json:"format"`
+	Name      string                  `json:"name"`
+	Source    bedrockSource           `json:"source"`
+	Citations *bedrockCitationsConfig `json:"citations,omitempty"`
+}
+
+type bedrockCitationsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+type bedrockToolUseBlock struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+type bedrockToolResultBlock struct {
+	ToolUseID string                     `json:"toolUseId"`
+	Content   []bedrockToolResultContent `json:"content"`
+	Status    string                     `json:"status,omitempty"`
+}
+
+type bedrockToolResultContent struct {
+	Text     string                `json:"text,omitempty"`
+	Image    *bedrockImageBlock    `json:"image,omitempty"`
+	Document *bedrockDocumentBlock `json:"document,omitempty"`
+}
+
+// bedrockReasoningBlock holds either signed reasoning text or redacted
+// reasoning, which is base64 encoded.
+type bedrockReasoningBlock struct {
+	ReasoningText   *bedrockReasoningText `json:"reasoningText,omitempty"`
+	RedactedContent string                `json:"redactedContent,omitempty"`
+}
+
+type bedrockReasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type bedrockCitationsContent struct {
+	Content   []bedrockCitationText `json:"content"`
+	Citations []bedrockCitation     `json:"citations"`
+}
+
+type bedrockCitationText struct {
+	Text string `json:"text"`
+}
+
+type bedrockCitation struct {
+	Title         string                  `json:"title,omitempty"`
+	SourceContent []bedrockCitationText   `json:"sourceContent,omitempty"`
+	Location      bedrockCitationLocation `json:"location"`
+}
+
+// bedrockCitationLocation is a union of document and web locations. Only web
+// locations map to spec citations.
+type bedrockCitationLocation struct {
+	Web *bedrockWebLocation `json:"web,omitempty"`
+}
+
+type bedrockWebLocation struct {
+	URL    string `json:"url"`
+	Domain string `json:"domain,omitempty"`
+}
+
+type bedrockCachePoint struct {
+	Type string `json:"type"`
+}
+
+type bedrockToolConfig struct {
+	Tools      []bedrockTool      `json:"tools"`
+	ToolChoice *bedrockToolChoice `json:"toolChoice,omitempty"`
+}
+
+type bedrockTool struct {
+	ToolSpec   *bedrockToolSpec   `json:"toolSpec,omitempty"`
+	CachePoint *bedrockCachePoint `json:"cachePoint,omitempty"`
+}
+
+type bedrockToolSpec struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	InputSchema bedrockInputSchema `json:"inputSchema"`
+}
+
+type bedrockInputSchema struct {
+	JSON map[string]any `json:"json"`
+}
+
+// bedrockToolChoice is a union; exactly one field is set.
+type bedrockToolChoice struct {
+	Auto *struct{}            `json:"auto,omitempty"`
+	Any  *struct{}            `json:"any,omitempty"`
+	Tool *bedrockSpecificTool `json:"tool,omitempty"`
+}
+
+type bedrockSpecificTool struct {
+	Name string `json:"name"`
+}
+
+// converseResponse is the body of a non-streaming Converse response, and the
+// response accumulated from a stream.
+type converseResponse struct {
+	Output struct {
+		Message *bedrockMessage `json:"message,omitempty"`
+	} `json:"output"`
+	StopReason                    string          `json:"stopReason"`
+	Usage                         *bedrockUsage   `json:"usage,omitempty"`
+	Metrics                       *bedrockMetrics `json:"metrics,omitempty"`
+	AdditionalModelResponseFields json.RawMessage `json:"additionalModelResponseFields,omitempty"`
+}
+
+// bedrockUsage counts tokens. InputTokens excludes cache reads and writes.
+type bedrockUsage struct {
+	InputTokens           int64 `json:"inputTokens"`
+	OutputTokens          int64 `json:"outputTokens"`
+	TotalTokens           int64 `json:"totalTokens"`
+	CacheReadInputTokens  int64 `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int64 `json:"cacheWriteInputTokens,omitempty"`
+}
+
+type bedrockMetrics struct {
+	LatencyMs int64 `json:"latencyMs"`
+}
+
+// converseStreamEvent is the payload of any ConverseStream event; which
+// fields are set depends on the :event-type header.
+type converseStreamEvent struct {
+	// messageStart.
+	Role string `json:"role,omitempty"`
+
+	// contentBlockStart, contentBlockDelta and contentBlockStop.
+	ContentBlockIndex int                  `json:"contentBlockIndex"`
+	Start             *converseBlockStart  `json:"start,omitempty"`
+	Delta             *converseStreamDelta `json:"delta,omitempty"`
+
+	// messageStop.
+	StopReason                    string          `json:"stopReason,omitempty"`
+	AdditionalModelResponseFields json.RawMessage `json:"additionalModelResponseFields,omitempty"`
+
+	// metadata.
+	Usage   *bedrockUsage   `json:"usage,omitempty"`
+	Metrics *bedrockMetrics `json:"metrics,omitempty"`
+}
+
+type converseBlockStart struct {
+	ToolUse *struct {
+		ToolUseID string `json:"toolUseId"`
+		Name      string `json:"name"`
+	} `json:"toolUse,omitempty"`
+}
+
+type converseStreamDelta struct {
+	Text    *string `json:"text,omitempty"`
+	ToolUse *struct {
+		Input string `json:"input"`
+	} `json:"toolUse,omitempty"`
+	ReasoningContent *struct {
+		Text            string `json:"text,omitempty"`
+		Signature       string `json:"signature,omitempty"`
+		RedactedContent string `json:"redactedContent,omitempty"`
+	} `json:"reasoningContent,omitempty"`
+	Citation *bedrockCitation `json:"citation,omitempty"`
+}
+
+// bedrockAPIError is a non-2xx response of the Bedrock runtime API.
+type bedrockAPIError struct {
+	StatusCode int
+	// Code is the exception name, e.g. ThrottlingException.
+	Code      string
+	Message   string
+	RequestID string
+	Header    http.Header
+}
+
+func (e *bedrockAPIError) Error() string {
+	code := e.Code
+	if code == "" {
+		code = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("bedrock: %d %s: %s", e.StatusCode, code, e.Message)
+}
+
+// bedrockStreamError is an exception or error message inside an event stream.
+type bedrockStreamError struct {
+	Type    string
+	Message string
+}
+
+func (e *bedrockStreamError) Error() string {
+	return "bedrock stream error: " + e.Type + ": " + e.Message
+}
+
+// bedrockClient sends Converse requests to one Bedrock runtime endpoint.
+type bedrockClient struct {
+	httpClient *http.Client
+	// baseURL is the origin and path prefix; the model and operation are
+	// appended per request.
+	baseURL string
+	header  http.Header
+	auth    bedrockAuth
+}
+
+// post sends body to the Converse or ConverseStream operation of model and
+// returns the response once its status is known. Non-2xx responses are
+// consumed and returned as *bedrockAPIError.
+func (c *bedrockClient) post(
+	ctx context.Context,
+	httpClient *http.Client,
+	model string,
+	stream bool,
+	body []byte,
+) (*http.Response, error) {
+	suffix, accept := bedrockConverseSuffix, "application/json"
+	if stream {
+		suffix, accept = bedrockConverseStreamSuffix, bedrockEventStreamMIME
+	}
+	// Model IDs and ARNs contain ':' and '/', which must be escaped within the
+	// path segment.
+	u := c.baseURL + "/" + awsURIEncode(model) + suffix
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if err := c.auth.authorize(req, sha256Hex(body), time.Now()); err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		httpClient = c.httpClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	apiErr := &bedrockAPIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Amzn-Requestid"),
+		Header:     resp.Header,
+	}
+	// The exception name comes as "ThrottlingException:http://internal.amazon.com/..."
+	// or in the __type field of the body.
+	apiErr.Code, _, _ = strings.Cut(resp.Header.Get("X-Amzn-Errortype"), ":")
+	var payload struct {
+		Message      string `json:"message"`
+		UpperMessage string `json:"Message"`
+		Type         string `json:"__type"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		apiErr.Message = payload.Message
+		if apiErr.Message == "" {
+			apiErr.Message = payload.UpperMessage
+		}
+		if apiErr.Code == "" && payload.Type != "" {
+			t := payload.Type
+			if i := strings.LastIndex(t, "#"); i >= 0 {
+				t = t[i+1:]
+			}
+			apiErr.Code = t
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return nil, apiErr
+}
+
+// converseStreamReader reads ConverseStream events from an event stream.
+type converseStreamReader struct {
+	dec *eventStreamDecoder
+}
+
+func newConverseStreamReader(r io.Reader) *converseStreamReader {
+	return &converseStreamReader{dec: newEventStreamDecoder(r)}
+}
+
+// next returns the type and payload of the next event, or io.EOF at the end
+// of the stream. Exception and error messages are returned as
+// *bedrockStreamError.
+func (s *converseStreamReader) next() (string, *converseStreamEvent, error) {
+	msg, err := s.dec.next()
+	if err != nil {
+		return "", nil, err
+	}
+	switch msg.Headers[":message-type"] {
+	case "exception":
+		var payload struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(msg.Payload, &payload)
+		if payload.Message == "" {
+			payload.Message = strings.TrimSpace(string(msg.Payload))
+		}
+		return "", nil, &bedrockStreamError{Type: msg.Headers[":exception-type"], Message: payload.Message}
+	case "error":
+		return "", nil, &bedrockStreamError{
+			Type:    msg.Headers[":error-code"],
+			Message: msg.Headers[":error-message"],
+		}
+	default:
+	}
+
+	var ev converseStreamEvent
+	if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+		return "", nil, fmt.Errorf("bedrock stream: invalid %s event: %w", msg.Headers[":event-type"], err)
+	}
+	return msg.Headers[":event-type"], &ev, nil
+}
@@ -0,0 +1,414 @@
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/mockserver"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	testModel      spec.ModelName = "mock-model"
+	weatherTool                   = "get_weather"
+	weatherCall                   = "call_1"
+	weatherArgs                   = `{"city":"Paris"}`
+	abortedText                   = "The quick brown fox jumps over the lazy dog."
+	citedURL                      = "https://example.com/paris"
+	scenarioPrompt                = "What is the weather in Paris?"
+)
+
+var sdkTypes = []spec.ProviderSDKType{
+	spec.ProviderSDKTypeAnthropic,
+	spec.ProviderSDKTypeOpenAIChatCompletions,
+	spec.ProviderSDKTypeOpenAIResponses,
+	spec.ProviderSDKTypeGoogleGenerateContent,
+	spec.ProviderSDKTypeOllamaChat,
+	spec.ProviderSDKTypeBedrockConverse,
+}
+
+// outcome is the provider independent projection of a completion compared
+// across adapters.
+type outcome struct {
+	Text      string
+	Thinking  string
+	ToolCalls []toolCall
+	Citations []string
+	Stop      spec.StopReasonKind
+
+	InputTokens     int64
+	OutputTokens    int64
+	ReasoningTokens int64
+
+	ErrKind    spec.ProviderErrorKind
+	HTTPStatus int
+}
+
+type toolCall struct {
+	Name      string
+	CallID    string
+	Arguments string
+}
+
+type scenario struct {
+	name  string
+	turn  mockserver.Turn
+	tools bool
+	want  outcome
+
+	// unsupported returns why the provider cannot express the scenario, or "".
+	unsupported func(sdk spec.ProviderSDKType, stream bool) string
+}
+
+var scenarios = []scenario{
+	{
+		name: "text",
+		turn: mockserver.Turn{
+			Blocks: []mockserver.Block{mockserver.Text("It is sunny in Paris today.")},
+			Usage:  mockserver.Usage{InputTokens: 12, OutputTokens: 7},
+		},
+		want: outcome{
+			Text:         "It is sunny in Paris today.",
+			Stop:         spec.StopReasonEndTurn,
+			InputTokens:  12,
+			OutputTokens: 7,
+		},
+	},
+	{
+		name: "tools",
+		turn: mockserver.Turn{
+			Blocks: []mockserver.Block{mockserver.ToolCall(weatherCall, weatherTool, weatherArgs)},
+			Usage:  mockserver.Usage{InputTokens: 30, OutputTokens: 9},
+		},
+		tools: true,
+		want: outcome{
+			ToolCalls:    []toolCall{{Name: weatherTool, CallID: weatherCall, Arguments: weatherArgs}},
+			Stop:         spec.StopReasonToolUse,
+			InputTokens:  30,
+			OutputTokens: 9,
+		},
+	},
+	{
+		name: "reasoning",
+		turn: mockserver.Turn{
+			Blocks: []mockserver.Block{
+				mockserver.Reasoning("The user wants the weather."),
+				mockserver.Text("It is sunny."),
+			},
+			Usage: mockserver.Usage{InputTokens: 12, OutputTokens: 20, ReasoningTokens: 16},
+		},
+		want: outcome{
+			Text:            "It is sunny.",
+			Thinking:        "The user wants the weather.",
+			Stop:            spec.StopReasonEndTurn,
+			InputTokens:     12,
+			OutputTokens:    20,
+			ReasoningTokens: 16,
+		},
+		unsupported: func(sdk spec.ProviderSDKType, stream bool) string {
+			if sdk == spec.ProviderSDKTypeOpenAIChatCompletions {
+				return "chat completions return no reasoning"
+			}
+			return ""
+		},
+	},
+	{
+		name: "citations",
+		turn: mockserver.Turn{
+			Blocks: []mockserver.Block{mockserver.Text(
+				"Paris is sunny today.",
+				mockserver.Citation{URL: citedURL, Title: "Paris weather", CitedText: "sunny"},
+			)},
+			Usage: mockserver.Usage{InputTokens: 40, OutputTokens: 6},
+		},
+		want: outcome{
+			Text:         "Paris is sunny today.",
+			Citations:    []string{citedURL},
+			Stop:         spec.StopReasonEndTurn,
+			InputTokens:  40,
+			OutputTokens: 6,
+		},
+		unsupported: func(sdk spec.ProviderSDKType, stream bool) string {
+			switch {
+			case sdk == spec.ProviderSDKTypeGoogleGenerateContent:
+				return "gemini text carries no citations"
+			case sdk == spec.ProviderSDKTypeOllamaChat:
+				return "ollama text carries no citations"
+			case sdk == spec.ProviderSDKTypeOpenAIChatCompletions && stream:
+				return "chat completion chunks carry no annotations"
+			default:
+				return ""
+			}
+		},
+	},
+	{
+		name: "rateLimited",
+		turn: mockserver.Turn{Error: &mockserver.Error{
+			Status:     http.StatusTooManyRequests,
+			Message:    "rate limit exceeded",
+			RetryAfter: 2 * time.Second,
+		}},
+		want: outcome{ErrKind: spec.ProviderErrorKindRateLimited, HTTPStatus: http.StatusTooManyRequests},
+	},
+	{
+		name: "invalidRequest",
+		turn: mockserver.Turn{Error: &mockserver.Error{
+			Status:  http.StatusBadRequest,
+			Message: "invalid request",
+		}},
+		want: outcome{ErrKind: spec.ProviderErrorKindInvalidRequest, HTTPStatus: http.StatusBadRequest},
+	},
+}
+
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	for _, sc := range scenarios {
+		for _, sdk := range sdkTypes {
+			for _, stream := range []bool{false, true} {
+				t.Run(fmt.Sprintf("%s/%s/stream=%t", sc.name, sdk, stream), func(t *testing.T) {
+					t.Parallel()
+					if sc.unsupported != nil {
+						if reason := sc.unsupported(sdk, stream); reason != "" {
+							t.Skip(reason)
+						}
+					}
+
+					srv := mockserver.New(mockserver.Config{Turns: []mockserver.Turn{sc.turn}})
+					defer srv.Close()
+
+					rec := &eventRecorder{}
+					resp, err := fetch(t, srv, sdk, sc.tools, stream, rec)
+					got, want := project(resp, err), sc.wantFor(sdk)
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("outcome mismatch\n got: %+v\nwant: %+v\n err: %v", got, want, err)
+					}
+					if reqs := srv.Requests(); len(reqs) != 1 || reqs[0].Stream != stream {
+						t.Errorf("server got %d requests, want one with stream=%t", len(reqs), stream)
+					}
+					if stream && err == nil {
+						if text, thinking := rec.text(); text != sc.want.Text || thinking != sc.want.Thinking {
+							t.Errorf("streamed text %q and thinking %q, want %q and %q",
+								text, thinking, sc.want.Text, sc.want.Thinking)
+						}
+						if rec.count(spec.StreamContentKindCompleted) != 1 {
+							t.Error("stream did not end with one completed event")
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestConformanceStreamAbort(t *testing.T) {
+	t.Parallel()
+
+	for _, sdk := range sdkTypes {
+		t.Run(string(sdk), func(t *testing.T) {
+			t.Parallel()
+
+			srv := mockserver.New(mockserver.Config{
+				Turns: []mockserver.Turn{{
+					Blocks:     []mockserver.Block{mockserver.Text(abortedText)},
+					AbortAfter: 2,
+				}},
+				ChunkSize: 8,
+			})
+			defer srv.Close()
+
+			rec := &eventRecorder{}
+			_, err := fetch(t, srv, sdk, false, true, rec)
+			if err == nil {
+				t.Fatal("aborted stream returned no error")
+			}
+			if text, _ := rec.text(); text != abortedText[:16] {
+				t.Errorf("streamed text = %q, want the two deltas before the abort", text)
+			}
+			if rec.count(spec.StreamContentKindCompleted) != 0 {
+				t.Error("completed event sent for an aborted stream")
+			}
+		})
+	}
+}
+
+// wantFor returns the expected outcome for sdk. Ollama and Bedrock report
+// reasoning tokens only as part of the output tokens.
+func (sc scenario) wantFor(sdk spec.ProviderSDKType) outcome {
+	want := sc.want
+	if sdk == spec.ProviderSDKTypeOllamaChat || sdk == spec.ProviderSDKTypeBedrockConverse {
+		want.ReasoningTokens = 0
+	}
+	return want
+}
+
+func fetch(
+	t *testing.T,
+	srv *mockserver.Server,
+	sdk spec.ProviderSDKType,
+	tools, stream bool,
+	rec *eventRecorder,
+) (*spec.FetchCompletionResponse, error) {
+	t.Helper()
+
+	ps, err := inference.NewProviderSetAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := spec.ProviderName(sdk)
+	if _, err := ps.AddProvider(t.Context(), name, &inference.AddProviderConfig{
+		SDKType: sdk,
+		Origin:  srv.URL,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.SetProviderAPIKey(t.Context(), name, "mock-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: testModel, Stream: stream, MaxOutputLength: 1024},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: scenarioPrompt},
+				}},
+			},
+		}},
+	}
+	if tools {
+		req.ToolChoices = []spec.ToolChoice{{
+			Type:        spec.ToolTypeFunction,
+			ID:          "tc-weather",
+			Name:        weatherTool,
+			Description: "Get the current weather for a city.",
+			Arguments: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				"required":   []any{"city"},
+			},
+		}}
+	}
+	var opts *spec.FetchCompletionOptions
+	if stream {
+		opts = &spec.FetchCompletionOptions{StreamHandler: rec.handle}
+	}
+	return ps.FetchCompletion(t.Context(), name, req, opts)
+}
+
+func project(resp *spec.FetchCompletionResponse, err error) outcome {
+	var out outcome
+	if err != nil {
+		var pe *spec.ProviderError
+		if errors.As(err, &pe) {
+			out.ErrKind, out.HTTPStatus = pe.Kind, pe.HTTPStatus
+		} else {
+			out.ErrKind = "untyped: " + spec.ProviderErrorKind(err.Error())
+		}
+		return out
+	}
+	if resp == nil {
+		return out
+	}
+
+	var text, thinking strings.Builder
+	for _, o := range resp.Outputs {
+		switch {
+		case o.OutputMessage != nil:
+			for _, item := range o.OutputMessage.Contents {
+				if item.TextItem == nil {
+					continue
+				}
+				text.WriteString(item.TextItem.Text)
+				for _, c := range item.TextItem.Citations {
+					if c.URLCitation != nil {
+						out.Citations = append(out.Citations, c.URLCitation.URL)
+					}
+				}
+			}
+		case o.ReasoningMessage != nil:
+			thinking.WriteString(strings.Join(o.ReasoningMessage.Summary, ""))
+			thinking.WriteString(strings.Join(o.ReasoningMessage.Thinking, ""))
+		case o.FunctionToolCall != nil:
+			out.ToolCalls = append(out.ToolCalls, toolCall{
+				Name:      o.FunctionToolCall.Name,
+				CallID:    o.FunctionToolCall.CallID,
+				Arguments: compactJSON(o.FunctionToolCall.Arguments),
+			})
+		default:
+		}
+	}
+	out.Text, out.Thinking = text.String(), thinking.String()
+	if resp.StopReason != nil {
+		out.Stop = resp.StopReason.Kind
+	}
+	if u := resp.Usage; u != nil {
+		out.InputTokens, out.OutputTokens, out.ReasoningTokens = u.InputTokensTotal, u.OutputTokens, u.ReasoningTokens
+	}
+	return out
+}
+
+// compactJSON strips insignificant whitespace, which adapters re-encoding
+// arguments may change.
+func compactJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(b)
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []spec.StreamEvent
+}
+
+func (r *eventRecorder) handle(ev spec.StreamEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func (r *eventRecorder) text() (text, thinking string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var t, th strings.Builder
+	for _, ev := range r.events {
+		switch {
+		case ev.Kind == spec.StreamContentKindText && ev.Text != nil:
+			t.WriteString(ev.Text.Text)
+		case ev.Kind == spec.StreamContentKindThinking && ev.Thinking != nil:
+			th.WriteString(ev.Thinking.Text)
+		default:
+		}
+	}
+	return t.String(), th.String()
+}
+
+func (r *eventRecorder) count(kind spec.StreamContentKind) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, ev := range r.events {
+		if ev.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,14 @@
+// Package conformance checks that the provider adapters normalize equivalent
+// provider responses the same way.
+//
+// Each scenario scripts one turn on a mockserver.Server and runs it through
+// every adapter, streaming and not, via ProviderSetAPI. The normalized
+// responses must match the scenario's expected outcome, so a difference in
+// text, tool calls, reasoning, citations, stop reasons, usage or error kinds
+// between adapters fails the suite. The tests run offline:
+//
+//	go test ./internal/conformance
+//
+// A provider whose wire format cannot express a scenario, e.g. reasoning over
+// OpenAI Chat Completions, skips it.
+package conformance
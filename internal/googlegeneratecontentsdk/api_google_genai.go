@@ -2,11 +2,14 @@ package googlegeneratecontentsdk
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -18,10 +21,15 @@ import (
 	"github.com/flexigpt/inference-go/spec"
 )
 
+// googleGenerateContentOwnedKeys are the request keys built from typed fields,
+// which additional parameters cannot replace.
+var googleGenerateContentOwnedKeys = []string{"contents", "systemInstruction", "tools", "toolConfig"}
+
 // GoogleGenerateContentAPI implements CompletionProvider for Google's Generative AI API.
 type GoogleGenerateContentAPI struct {
 	ProviderParam *spec.ProviderParam
 	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
 	client        *genai.Client
 	mu            sync.RWMutex
 }
@@ -30,6 +38,7 @@ type GoogleGenerateContentAPI struct {
 func NewGoogleGenerateContentAPI(
 	pi spec.ProviderParam,
 	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
 ) (*GoogleGenerateContentAPI, error) {
 	if pi.Name == "" {
 		return nil, errors.New("google genai api LLM: invalid args")
@@ -37,6 +46,7 @@ func NewGoogleGenerateContentAPI(
 	return &GoogleGenerateContentAPI{
 		ProviderParam: &pi,
 		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
 	}, nil
 }
 
@@ -50,7 +60,7 @@ func (api *GoogleGenerateContentAPI) InitLLM(ctx context.Context) error {
 	}
 
 	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
-		logutil.Debug(
+		api.logger.Debug(
 			string(api.ProviderParam.Name) + ": No API key given. Not initializing Google GenAI client",
 		)
 		api.client = nil
@@ -58,8 +68,29 @@ func (api *GoogleGenerateContentAPI) InitLLM(ctx context.Context) error {
 	}
 
 	pi := *api.ProviderParam // snapshot under lock
+	var httpClient *http.Client
+	if api.debugger != nil {
+		httpClient = api.debugger.HTTPClient(nil)
+	}
+	cc, baseURL := googleGenAIClientConfig(pi, httpClient)
+
+	client, err := genai.NewClient(ctx, cc)
+	if err != nil {
+		return fmt.Errorf("google genai api LLM: failed to create client: %w", err)
+	}
+	api.client = client
 
-	cc := &genai.ClientConfig{
+	api.logger.Info(
+		"google genai api LLM provider initialized",
+		"name", string(pi.Name),
+		"URL", baseURL,
+	)
+	return nil
+}
+
+// googleGenAIClientConfig returns the configuration of a client for pi and the URL it calls. httpClient may be nil.
+func googleGenAIClientConfig(pi spec.ProviderParam, httpClient *http.Client) (cc *genai.ClientConfig, baseURL string) {
+	cc = &genai.ClientConfig{
 		APIKey:  pi.APIKey,
 		Backend: genai.BackendGeminiAPI,
 	}
@@ -67,7 +98,7 @@ func (api *GoogleGenerateContentAPI) InitLLM(ctx context.Context) error {
 	httpOpts := genai.HTTPOptions{}
 
 	// Custom base URL / path prefix (optional).
-	baseURL := spec.DefaultGoogleGenerateContentOrigin
+	baseURL = spec.DefaultGoogleGenerateContentOrigin
 	if pi.Origin != "" || strings.TrimSpace(pi.ChatCompletionPathPrefix) != "" {
 		baseURL = strings.TrimSuffix(pi.Origin, "/")
 		if prefix := strings.Trim(strings.TrimSpace(pi.ChatCompletionPathPrefix), "/"); prefix != "" {
@@ -93,24 +124,10 @@ func (api *GoogleGenerateContentAPI) InitLLM(ctx context.Context) error {
 	cc.HTTPOptions = httpOpts
 
 	// Debugger HTTP client (optional).
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			cc.HTTPClient = httpClient
-		}
-	}
-
-	client, err := genai.NewClient(ctx, cc)
-	if err != nil {
-		return fmt.Errorf("google genai api LLM: failed to create client: %w", err)
+	if httpClient != nil {
+		cc.HTTPClient = httpClient
 	}
-	api.client = client
-
-	logutil.Info(
-		"google genai api LLM provider initialized",
-		"name", string(pi.Name),
-		"URL", baseURL,
-	)
-	return nil
+	return cc, baseURL
 }
 
 func (api *GoogleGenerateContentAPI) DeInitLLM(ctx context.Context) error {
@@ -121,7 +138,7 @@ func (api *GoogleGenerateContentAPI) DeInitLLM(ctx context.Context) error {
 	}
 	api.client = nil
 	api.mu.Unlock()
-	logutil.Info(
+	api.logger.Info(
 		"google genai api LLM: provider de initialized",
 		"name", string(name),
 	)
@@ -177,19 +194,230 @@ func (api *GoogleGenerateContentAPI) FetchCompletion(
 	if client == nil {
 		return nil, errors.New("google genai api LLM: client not initialized")
 	}
+	call, err := buildGoogleGenerateContentCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	// Debug span.
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		rawResp        *genai.GenerateContentResponse
+		apiErr         error
+	)
+
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream {
+		normalizedResp, rawResp, apiErr = api.doStreaming(
+			ctx, client, pi.Name, req.ModelParam.Name,
+			call.contents, call.config, opts, call.toolChoiceNameMap, call.webSearchChoiceID,
+		)
+	} else {
+		normalizedResp, rawResp, apiErr = api.doNonStreaming(
+			ctx, client, req.ModelParam.Name,
+			call.contents, call.config, call.toolChoiceNameMap, call.webSearchChoiceID,
+		)
+	}
+
+	if apiErr != nil {
+		apiErr = googleGenerateContentProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: rawResp,
+			Response:         normalizedResp,
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end)
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CountTokens counts the input tokens of a request with the countTokens endpoint.
+//
+// The Gemini Developer API only counts contents, so the system instruction is
+// counted as a leading user turn and tool declarations are estimated with
+// opts.Tokenizer; such counts are not exact.
+func (api *GoogleGenerateContentAPI) CountTokens(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.TokenCount, error) {
+	api.mu.RLock()
+	client := api.client
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("google genai api LLM: client not initialized")
+	}
+	call, err := buildGoogleGenerateContentCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := call.contents
+	if sys := call.config.SystemInstruction; sys != nil {
+		contents = append([]*genai.Content{{Role: genai.RoleUser, Parts: sys.Parts}}, contents...)
+	}
+	// Additional parameters target GenerateContent and do not belong in a
+	// CountTokens body.
+	httpOpts := *call.config.HTTPOptions
+	httpOpts.ExtrasRequestProvider = nil
+	res, err := client.Models.CountTokens(
+		ctx,
+		string(call.req.ModelParam.Name),
+		contents,
+		&genai.CountTokensConfig{HTTPOptions: &httpOpts},
+	)
+	if err != nil {
+		return nil, googleGenerateContentProviderError(pi.Name, err)
+	}
+
+	count := &spec.TokenCount{
+		InputTokens: int(res.TotalTokens),
+		Source:      spec.TokenCountSourceProvider,
+		Exact:       call.config.SystemInstruction == nil,
+		Warnings:    call.warns,
+	}
+	if len(call.config.Tools) > 0 {
+		var tokenizer spec.Tokenizer
+		if opts != nil {
+			tokenizer = opts.Tokenizer
+		}
+		count.InputTokens += sdkutil.CountRequestTokens(
+			&spec.FetchCompletionRequest{ToolChoices: call.req.ToolChoices},
+			tokenizer,
+		)
+		count.Exact = false
+		count.Warnings = append(count.Warnings, spec.Warning{
+			Code:    "token_count_estimated",
+			Message: "tool declarations are not counted by the provider and were estimated locally",
+		})
+	}
+	return count, nil
+}
+
+// CompileRequest builds the GenerateContent request for a completion and returns it without sending it. The request
+// goes through a client built like the one InitLLM builds, including the debugger's HTTP client; without an API key
+// it carries sdkutil.CompileAPIKey.
+func (api *GoogleGenerateContentAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	hasProvider := api.ProviderParam != nil
+	if hasProvider {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if !hasProvider {
+		return nil, errors.New("google genai api LLM: no ProviderParam found")
+	}
+	if strings.TrimSpace(pi.APIKey) == "" {
+		pi.APIKey = sdkutil.CompileAPIKey
+	}
+	call, err := buildGoogleGenerateContentCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	cc, _ := googleGenAIClientConfig(pi, capture.HTTPClient(api.debugger))
+	client, err := genai.NewClient(captureCtx, cc)
+	if err != nil {
+		return nil, fmt.Errorf("google genai api LLM: failed to create client: %w", err)
+	}
+
+	modelName := string(call.req.ModelParam.Name)
+	if call.req.ModelParam.Stream {
+		for _, streamErr := range client.Models.GenerateContentStream(
+			captureCtx, modelName, call.contents, call.config,
+		) {
+			err = streamErr
+			break
+		}
+	} else {
+		_, err = client.Models.GenerateContent(captureCtx, modelName, call.contents, call.config)
+	}
+
+	compiled, err := capture.CompiledRequest(err, pi.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// googleGenerateContentCall is a normalized request together with the
+// GenerateContent contents and config built from it.
+type googleGenerateContentCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	contents          []*genai.Content
+	config            *genai.GenerateContentConfig
+	toolChoiceNameMap map[string]spec.ToolChoice
+	webSearchChoiceID string
+	warns             []spec.Warning
+}
+
+// buildGoogleGenerateContentCall normalizes a request against the provider
+// capabilities and builds the GenerateContent contents and config for it.
+func buildGoogleGenerateContentCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+) (*googleGenerateContentCall, error) {
 	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
 		return nil, errors.New("google genai api LLM: empty completion data")
 	}
 
-	req, _, warns, err := sdkutil.NormalizeRequestForSDK(
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
 		ctx, inReq, opts, spec.ProviderSDKTypeGoogleGenerateContent, googleGenerateContentSDKCapability,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
 
 	// Sanitize reasoning inputs: keep only Google-native signed thoughts.
-	req.Inputs = sanitizeGoogleGenerateContentReasoningInputs(req.Inputs)
+	req.Inputs = sanitizeGoogleGenerateContentReasoningInputs(ctx, req.Inputs)
 
 	// Build genai contents + system instruction.
 	contents, sysInstruction, err := toGoogleGenerateContentContents(ctx, req.ModelParam.SystemPrompt, req.Inputs)
@@ -218,6 +446,7 @@ func (api *GoogleGenerateContentAPI) FetchCompletion(
 		v := float32(*t)
 		config.Temperature = &v
 	}
+	warns = append(warns, applyGoogleGenerateContentSamplingParams(config, &req.ModelParam)...)
 	if len(req.ModelParam.StopSequences) > 0 {
 		config.StopSequences = req.ModelParam.StopSequences
 	}
@@ -285,56 +514,35 @@ func (api *GoogleGenerateContentAPI) FetchCompletion(
 		}
 	}
 
-	// Debug span.
-	var span spec.CompletionSpan
-	if api.debugger != nil {
-		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
-			Provider: pi.Name,
-			Model:    req.ModelParam.Name,
-			Request:  req,
-			Options:  opts,
-		})
-	}
-
-	var (
-		normalizedResp *spec.FetchCompletionResponse
-		rawResp        *genai.GenerateContentResponse
-		apiErr         error
-	)
-
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
-		normalizedResp, rawResp, apiErr = api.doStreaming(
-			ctx, client, pi.Name, req.ModelParam.Name,
-			contents, config, opts, toolChoiceNameMap, webSearchChoiceID,
-		)
-	} else {
-		normalizedResp, rawResp, apiErr = api.doNonStreaming(
-			ctx, client, req.ModelParam.Name,
-			contents, config, toolChoiceNameMap, webSearchChoiceID,
-		)
-	}
-
-	if normalizedResp != nil && len(warns) > 0 {
-		normalizedResp.Warnings = append(normalizedResp.Warnings, warns...)
+	call := &googleGenerateContentCall{
+		req:               req,
+		capabilities:      caps,
+		contents:          contents,
+		config:            config,
+		toolChoiceNameMap: toolChoiceNameMap,
+		webSearchChoiceID: webSearchChoiceID,
+		warns:             warns,
 	}
 
-	if span != nil {
-		end := spec.CompletionSpanEnd{
-			ProviderResponse: rawResp,
-			Response:         normalizedResp,
-			Err:              apiErr,
+	// genai builds the wire body itself, so additional parameters are merged
+	// into it when the request is sent. Collisions are only known then, and
+	// each attempt replaces the warnings of the previous one.
+	if raw := req.ModelParam.AdditionalParametersRawJSON; raw != nil && strings.TrimSpace(*raw) != "" {
+		if _, err := sdkutil.MergeAdditionalParametersMap(
+			map[string]any{}, raw, googleGenerateContentOwnedKeys...,
+		); err != nil {
+			return nil, err
 		}
-		if normalizedResp != nil {
-			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
-				normalizedResp.DebugDetails = dd
+		config.HTTPOptions.ExtrasRequestProvider = func(body map[string]any) map[string]any {
+			if body == nil {
+				body = map[string]any{}
 			}
-		} else {
-			_ = span.End(&end)
+			extraWarns, _ := sdkutil.MergeAdditionalParametersMap(body, raw, googleGenerateContentOwnedKeys...)
+			call.warns = slices.Concat(warns, extraWarns)
+			return body
 		}
 	}
-
-	return normalizedResp, apiErr
+	return call, nil
 }
 
 func (api *GoogleGenerateContentAPI) doNonStreaming(
@@ -355,7 +563,9 @@ func (api *GoogleGenerateContentAPI) doNonStreaming(
 		resp.Error = &spec.Error{Message: err.Error()}
 		return resp, genResp, err
 	}
-	resp.Outputs = outputsFromGenAIResponse(genResp, toolChoiceNameMap, webSearchChoiceID)
+	resp.Outputs = outputsFromGenAIResponse(ctx, genResp, toolChoiceNameMap, webSearchChoiceID)
+	resp.StopReason = stopReasonFromGenAIResponse(genResp)
+	resp.Choices = choicesFromGenAIResponse(ctx, genResp, toolChoiceNameMap, webSearchChoiceID)
 	return resp, genResp, nil
 }
 
@@ -371,7 +581,6 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 	webSearchChoiceID string,
 ) (*spec.FetchCompletionResponse, *genai.GenerateContentResponse, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
 	// Work around a Google GenAI SDK streaming timeout lifecycle bug:
 	// when HTTPOptions.Timeout is set, the SDK may create a derived request
 	// context inside sendStreamRequest() and defer cancel() there, even though
@@ -385,51 +594,15 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 		defer streamCancel()
 	}
 
-	emitText := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, spec.StreamEvent{
-			Kind:          spec.StreamContentKindText,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Text:          &spec.StreamTextChunk{Text: chunk},
-		})
-	}
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newGoogleGenerateContentStreamEvents(emitter, toolChoiceNameMap)
 
-	emitThinking := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, spec.StreamEvent{
-			Kind:          spec.StreamContentKindThinking,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Thinking:      &spec.StreamThinkingChunk{Text: chunk},
-		})
-	}
-
-	// Flush calls are for end cleaning not for continuation.
-	writeText, flushText := sdkutil.NewBufferedStreamerWithError(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-	writeThinking, flushThinking := sdkutil.NewBufferedStreamerWithError(
-		emitThinking,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-
-	// Accumulated state across all stream chunks.
+	// Accumulated state across all stream chunks. Only the first candidate is
+	// streamed as events; the others are collected for the final response.
 	var (
 		accUsage       *genai.GenerateContentResponseUsageMetadata
-		accFinish      genai.FinishReason
 		accResponseID  string
-		accParts       []*genai.Part
-		accGrounding   *genai.GroundingMetadata
+		accCands       = map[int32]*googleGenerateContentStreamCandidate{}
 		streamWriteErr error
 		streamErr      error
 	)
@@ -455,43 +628,59 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 		}
 		if chunkResp.ResponseID != "" {
 			accResponseID = chunkResp.ResponseID
+			events.responseID = accResponseID
 		}
 
-		if len(chunkResp.Candidates) == 0 || chunkResp.Candidates[0] == nil {
-			continue
-		}
-		cand := chunkResp.Candidates[0]
-		if cand.FinishReason != "" {
-			accFinish = cand.FinishReason
-			sawFinishReason = true
-		}
-		if cand.GroundingMetadata != nil {
-			accGrounding = mergeGoogleGenerateContentGroundingMetadata(accGrounding, cand.GroundingMetadata)
-		}
-		if cand.Content == nil {
-			continue
-		}
+		for _, cand := range chunkResp.Candidates {
+			if cand == nil {
+				continue
+			}
+			acc := accCands[cand.Index]
+			if acc == nil {
+				acc = &googleGenerateContentStreamCandidate{}
+				accCands[cand.Index] = acc
+			}
+			first := cand.Index == 0
 
-		for _, part := range cand.Content.Parts {
-			if part == nil {
+			if cand.FinishReason != "" {
+				acc.finish = cand.FinishReason
+				sawFinishReason = sawFinishReason || first
+			}
+			if cand.GroundingMetadata != nil {
+				acc.grounding = mergeGoogleGenerateContentGroundingMetadata(acc.grounding, cand.GroundingMetadata)
+				if first && webSearchChoiceID != "" {
+					streamWriteErr = events.handleGrounding(cand.GroundingMetadata)
+					if streamWriteErr != nil {
+						break
+					}
+				}
+			}
+			if lr := cand.LogprobsResult; lr != nil {
+				if acc.logprobs == nil {
+					acc.logprobs = &genai.LogprobsResult{}
+				}
+				acc.logprobs.ChosenCandidates = append(acc.logprobs.ChosenCandidates, lr.ChosenCandidates...)
+				acc.logprobs.TopCandidates = append(acc.logprobs.TopCandidates, lr.TopCandidates...)
+				if first {
+					events.pendingLogprobs = tokenLogprobsFromGenAI(lr)
+				}
+			}
+			if cand.Content == nil {
 				continue
 			}
-			accParts = append(accParts, part)
 
-			switch {
-			case part.Thought:
-				if part.Text == "" {
+			for _, part := range cand.Content.Parts {
+				if part == nil {
 					continue
 				}
-				streamWriteErr = writeThinking(part.Text)
+				acc.parts = append(acc.parts, part)
 
-			case part.FunctionCall != nil:
-				// We dont stream function calls.
-			case part.Text != "":
-				if streamWriteErr != nil {
-					break
+				if first {
+					streamWriteErr = events.handlePart(part)
+					if streamWriteErr != nil {
+						break
+					}
 				}
-				streamWriteErr = writeText(part.Text)
 			}
 			if streamWriteErr != nil {
 				break
@@ -502,13 +691,10 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 		}
 	}
 
-	var flushErr error
-	if flushText != nil {
-		flushErr = errors.Join(flushErr, flushText())
-	}
-	if flushThinking != nil {
-		flushErr = errors.Join(flushErr, flushThinking())
+	if streamErr == nil && streamWriteErr == nil {
+		streamWriteErr = events.closeOpen()
 	}
+	flushErr := emitter.Close()
 
 	if !sawFinishReason && streamErr == nil && streamWriteErr == nil {
 		streamErr = errors.New(
@@ -516,9 +702,44 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 		)
 	}
 
+	// Build a synthetic GenerateContentResponse from all accumulated state so
+	// the shared outputsFromGenAIResponse path can process it uniformly.
+	synthResp := &genai.GenerateContentResponse{
+		ResponseID:    accResponseID,
+		UsageMetadata: accUsage,
+	}
+	for _, idx := range slices.Sorted(maps.Keys(accCands)) {
+		acc := accCands[idx]
+		if len(acc.parts) == 0 && acc.grounding == nil && acc.finish == "" {
+			continue
+		}
+		synthResp.Candidates = append(synthResp.Candidates, &genai.Candidate{
+			Index: idx,
+			Content: &genai.Content{
+				Role: genai.RoleModel,
+				// Consolidate raw per-chunk stream parts into the canonical single-part-per-kind
+				// form that GenerateContent (non-streaming) returns: adjacent thought fragments are
+				// merged into one thought part and adjacent text fragments into one text part, with
+				// the last non-empty ThoughtSignature winning (signatures arrive on the final chunk).
+				// Without this, outputsFromGenAIResponse produces many tiny, mostly-unsigned
+				// ReasoningMessage / OutputMessage entries instead of the single signed entries that
+				// callers and the multi-turn round-trip path expect.
+				Parts: consolidateGoogleGenerateContentStreamParts(acc.parts),
+			},
+			FinishReason:      acc.finish,
+			GroundingMetadata: acc.grounding,
+			LogprobsResult:    acc.logprobs,
+		})
+	}
+
+	if streamErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(usageFromGenAIResponse(synthResp), stopReasonFromGenAIResponse(synthResp))
+	}
+
 	combinedErr := errors.Join(streamErr, streamWriteErr, flushErr)
 	if combinedErr != nil {
-		logutil.Error(
+		logutil.ErrorContext(
+			ctx,
 			"google GenerateContent stream terminated",
 			"provider", string(providerName),
 			"model", string(modelName),
@@ -533,41 +754,25 @@ func (api *GoogleGenerateContentAPI) doStreaming(
 		)
 	}
 
-	// Consolidate raw per-chunk stream parts into the canonical single-part-per-kind
-	// form that GenerateContent (non-streaming) returns: adjacent thought fragments are
-	// merged into one thought part and adjacent text fragments into one text part, with
-	// the last non-empty ThoughtSignature winning (signatures arrive on the final chunk).
-	// Without this, outputsFromGenAIResponse produces many tiny, mostly-unsigned
-	// ReasoningMessage / OutputMessage entries instead of the single signed entries that
-	// callers and the multi-turn round-trip path expect.
-	accParts = consolidateGoogleGenerateContentStreamParts(accParts)
-
-	// Build a synthetic GenerateContentResponse from all accumulated state so
-	// the shared outputsFromGenAIResponse path can process it uniformly.
-	synthResp := &genai.GenerateContentResponse{
-		ResponseID:    accResponseID,
-		UsageMetadata: accUsage,
-	}
-	if len(accParts) > 0 || accGrounding != nil || accFinish != "" {
-		synthResp.Candidates = []*genai.Candidate{{
-			Content: &genai.Content{
-				Role:  genai.RoleModel,
-				Parts: accParts,
-			},
-			FinishReason:      accFinish,
-			GroundingMetadata: accGrounding,
-		}}
-	}
-
 	resp.Usage = usageFromGenAIResponse(synthResp)
 	if combinedErr != nil {
 		resp.Error = &spec.Error{Message: combinedErr.Error()}
 	}
-	resp.Outputs = outputsFromGenAIResponse(synthResp, toolChoiceNameMap, webSearchChoiceID)
+	resp.Outputs = outputsFromGenAIResponse(ctx, synthResp, toolChoiceNameMap, webSearchChoiceID)
+	resp.StopReason = stopReasonFromGenAIResponse(synthResp)
+	resp.Choices = choicesFromGenAIResponse(ctx, synthResp, toolChoiceNameMap, webSearchChoiceID)
 
 	return resp, synthResp, combinedErr
 }
 
+// googleGenerateContentStreamCandidate accumulates the chunks of one streamed candidate.
+type googleGenerateContentStreamCandidate struct {
+	parts     []*genai.Part
+	finish    genai.FinishReason
+	grounding *genai.GroundingMetadata
+	logprobs  *genai.LogprobsResult
+}
+
 func mergeGoogleGenerateContentGroundingMetadata(
 	dst *genai.GroundingMetadata,
 	src *genai.GroundingMetadata,
@@ -668,6 +873,8 @@ func cloneGoogleGenerateContentStreamConfigWithoutTimeout(
 //   - Adjacent plain-text (answer) parts are merged the same way.
 //   - FunctionCall parts are kept verbatim and act as group boundaries: a new
 //     text/thought accumulation starts after each function call.
+//   - Adjacent inline data parts of the same MIME type, i.e. streamed speech, are
+//     joined into one part.
 //
 // This makes resp.Outputs from the streaming path structurally identical to the
 // non-streaming path so that callers and the multi-turn signature round-trip work
@@ -682,12 +889,16 @@ func consolidateGoogleGenerateContentStreamParts(parts []*genai.Part) []*genai.P
 		kindThought  partKind = iota // Thought == true, FunctionCall == nil
 		kindText                     // Thought == false, FunctionCall == nil
 		kindFuncCall                 // FunctionCall != nil
+		kindInline                   // InlineData != nil, e.g. generated speech
 	)
 
 	classOf := func(p *genai.Part) partKind {
 		if p.FunctionCall != nil {
 			return kindFuncCall
 		}
+		if p.InlineData != nil {
+			return kindInline
+		}
 		if p.Thought {
 			return kindThought
 		}
@@ -699,6 +910,7 @@ func consolidateGoogleGenerateContentStreamParts(parts []*genai.Part) []*genai.P
 		textBuf  strings.Builder
 		sig      []byte
 		funcCall *genai.FunctionCall
+		blob     *genai.Blob
 	}
 
 	var groups []*mergeGroup
@@ -720,6 +932,19 @@ func consolidateGoogleGenerateContentStreamParts(parts []*genai.Part) []*genai.P
 			continue
 		}
 
+		// Streamed audio arrives as consecutive chunks of one PCM stream; join chunks of the same MIME type.
+		if k == kindInline {
+			if n := len(groups); n > 0 && groups[n-1].kind == kindInline &&
+				groups[n-1].blob.MIMEType == p.InlineData.MIMEType {
+				groups[n-1].blob.Data = append(groups[n-1].blob.Data, p.InlineData.Data...)
+				continue
+			}
+			b := *p.InlineData
+			b.Data = slices.Clone(b.Data)
+			groups = append(groups, &mergeGroup{kind: kindInline, blob: &b})
+			continue
+		}
+
 		// Merge into the immediately preceding same-kind group when present.
 		if n := len(groups); n > 0 {
 			if last := groups[n-1]; last.kind == k {
@@ -757,6 +982,8 @@ func consolidateGoogleGenerateContentStreamParts(parts []*genai.Part) []*genai.P
 				FunctionCall:     g.funcCall,
 				ThoughtSignature: g.sig,
 			}
+		case kindInline:
+			p = &genai.Part{InlineData: g.blob}
 		case kindThought:
 			p = &genai.Part{
 				Thought:          true,
@@ -778,6 +1005,7 @@ func consolidateGoogleGenerateContentStreamParts(parts []*genai.Part) []*genai.P
 // spec.OutputUnion entries, preserving the natural ordering of the model's
 // response parts (thinking before text when the model emits them in that order).
 func outputsFromGenAIResponse(
+	ctx context.Context,
 	genResp *genai.GenerateContentResponse,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 	webSearchChoiceID string,
@@ -785,14 +1013,57 @@ func outputsFromGenAIResponse(
 	if genResp == nil || len(genResp.Candidates) == 0 {
 		return nil
 	}
+	return outputsFromGenAICandidate(
+		ctx,
+		genResp.ResponseID,
+		genResp.Candidates[0],
+		toolChoiceNameMap,
+		webSearchChoiceID,
+	)
+}
+
+// choicesFromGenAIResponse returns every candidate of a response sampled with candidateCount > 1, and nil for a
+// single candidate.
+func choicesFromGenAIResponse(
+	ctx context.Context,
+	genResp *genai.GenerateContentResponse,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+	webSearchChoiceID string,
+) []spec.Choice {
+	if genResp == nil || len(genResp.Candidates) < 2 {
+		return nil
+	}
+	out := make([]spec.Choice, 0, len(genResp.Candidates))
+	for i, cand := range genResp.Candidates {
+		if cand == nil {
+			continue
+		}
+		// Index is omitted from the wire for the first candidate.
+		idx := int(cand.Index)
+		if idx == 0 {
+			idx = i
+		}
+		out = append(out, spec.Choice{
+			Index:      idx,
+			Outputs:    outputsFromGenAICandidate(ctx, genResp.ResponseID, cand, toolChoiceNameMap, webSearchChoiceID),
+			StopReason: stopReasonFromGenAICandidate(cand),
+		})
+	}
+	return out
+}
 
-	cand := genResp.Candidates[0]
+func outputsFromGenAICandidate(
+	ctx context.Context,
+	respID string,
+	cand *genai.Candidate,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+	webSearchChoiceID string,
+) []spec.OutputUnion {
 	if cand == nil || cand.Content == nil {
 		return nil
 	}
 
 	status := mapGenAIFinishReasonToStatus(cand.FinishReason)
-	respID := genResp.ResponseID
 
 	outs := make([]spec.OutputUnion, 0, len(cand.Content.Parts)+2)
 
@@ -813,7 +1084,12 @@ func outputsFromGenAIResponse(
 
 			tc, ok := toolChoiceNameMap[name]
 			if !ok || tc.ID == "" {
-				logutil.Debug("googleGenerateContent: received unknown function call in response", "name", name)
+				logutil.DebugContext(
+					ctx,
+					"googleGenerateContent: received unknown function call in response",
+					"name",
+					name,
+				)
 				continue
 			}
 
@@ -854,6 +1130,22 @@ func outputsFromGenAIResponse(
 				out.FunctionToolCall = &call
 			}
 			outs = append(outs, out)
+		case part.InlineData != nil && len(part.InlineData.Data) > 0:
+			item, ok := contentItemFromGenAIBlob(part.InlineData)
+			if !ok {
+				logutil.DebugContext(ctx, "googleGenerateContent: skipping inline data of unsupported type",
+					"mime", part.InlineData.MIMEType)
+				continue
+			}
+			outs = append(outs, spec.OutputUnion{
+				Kind: spec.OutputKindOutputMessage,
+				OutputMessage: &spec.InputOutputContent{
+					ID:       respID,
+					Role:     spec.RoleAssistant,
+					Status:   status,
+					Contents: []spec.InputOutputContentItemUnion{item},
+				},
+			})
 		case part.Text != "" || len(part.ThoughtSignature) > 0:
 			if part.Thought {
 				msg := &spec.ReasoningContent{
@@ -890,6 +1182,11 @@ func outputsFromGenAIResponse(
 		}
 	}
 
+	// Gemini reports logprobs per candidate, not per part; attach them to the first text item.
+	if logprobs := tokenLogprobsFromGenAI(cand.LogprobsResult); logprobs != nil {
+		attachGenAILogprobs(outs, logprobs)
+	}
+
 	// Grounding metadata → WebSearchToolCall + WebSearchToolOutput.
 	if webSearchChoiceID != "" && cand.GroundingMetadata != nil {
 		outs = append(outs, groundingToWebSearchOutputs(cand.GroundingMetadata, webSearchChoiceID)...)
@@ -901,6 +1198,27 @@ func outputsFromGenAIResponse(
 	return outs
 }
 
+// contentItemFromGenAIBlob converts generated audio or video to a content item. Gemini speech is raw PCM, reported
+// as e.g. "audio/L16;codec=pcm;rate=24000", and is passed on with that MIME type.
+func contentItemFromGenAIBlob(b *genai.Blob) (spec.InputOutputContentItemUnion, bool) {
+	data := base64.StdEncoding.EncodeToString(b.Data)
+	mime := strings.ToLower(b.MIMEType)
+	switch {
+	case strings.HasPrefix(mime, "audio/"):
+		return spec.InputOutputContentItemUnion{
+			Kind:      spec.ContentItemKindAudio,
+			AudioItem: &spec.ContentItemAudio{AudioName: b.DisplayName, AudioMIME: b.MIMEType, AudioData: data},
+		}, true
+	case strings.HasPrefix(mime, "video/"):
+		return spec.InputOutputContentItemUnion{
+			Kind:      spec.ContentItemKindVideo,
+			VideoItem: &spec.ContentItemVideo{VideoName: b.DisplayName, VideoMIME: b.MIMEType, VideoData: data},
+		}, true
+	default:
+		return spec.InputOutputContentItemUnion{}, false
+	}
+}
+
 // groundingToWebSearchOutputs converts Google grounding metadata to spec
 // WebSearchToolCall (one per query) and a single WebSearchToolOutput (all
 // grounding chunks).
@@ -982,14 +1300,57 @@ func usageFromGenAIResponse(genResp *genai.GenerateContentResponse) *spec.Usage
 	}
 	u := genResp.UsageMetadata
 
-	uOut.InputTokensTotal = int64(u.PromptTokenCount)
+	// Tool use prompt tokens (e.g. search results) are billed as input but not included in the prompt count.
+	uOut.ToolUseInputTokens = int64(u.ToolUsePromptTokenCount)
+	uOut.InputTokensTotal = int64(u.PromptTokenCount) + uOut.ToolUseInputTokens
 	uOut.InputTokensCached = int64(u.CachedContentTokenCount)
-	uOut.InputTokensUncached = int64(max(int(u.PromptTokenCount)-int(u.CachedContentTokenCount), 0))
-	uOut.OutputTokens = int64(u.CandidatesTokenCount)
+	uOut.InputTokensUncached = max(uOut.InputTokensTotal-uOut.InputTokensCached, 0)
+	// Thoughts are billed as output but not included in the candidates count, unlike the other providers' output
+	// token counts.
+	uOut.OutputTokens = int64(u.CandidatesTokenCount) + int64(u.ThoughtsTokenCount)
 	uOut.ReasoningTokens = int64(u.ThoughtsTokenCount)
+
+	for _, details := range [][]*genai.ModalityTokenCount{u.PromptTokensDetails, u.ToolUsePromptTokensDetails} {
+		for _, d := range details {
+			m, ok := modalityFromGenAIMediaModality(d)
+			if !ok {
+				continue
+			}
+			if uOut.InputTokensByModality == nil {
+				uOut.InputTokensByModality = map[spec.Modality]int64{}
+			}
+			uOut.InputTokensByModality[m] += int64(d.TokenCount)
+		}
+	}
+
+	for _, cand := range genResp.Candidates {
+		if cand != nil && cand.GroundingMetadata != nil {
+			uOut.WebSearchRequests += int64(len(cand.GroundingMetadata.WebSearchQueries))
+		}
+	}
 	return uOut
 }
 
+func modalityFromGenAIMediaModality(d *genai.ModalityTokenCount) (spec.Modality, bool) {
+	if d == nil || d.TokenCount == 0 {
+		return "", false
+	}
+	switch d.Modality {
+	case genai.MediaModalityText:
+		return spec.ModalityTextIn, true
+	case genai.MediaModalityImage:
+		return spec.ModalityImageIn, true
+	case genai.MediaModalityAudio:
+		return spec.ModalityAudioIn, true
+	case genai.MediaModalityVideo:
+		return spec.ModalityVideoIn, true
+	case genai.MediaModalityDocument:
+		return spec.ModalityFileIn, true
+	default:
+		return "", false
+	}
+}
+
 // buildGoogleGenerateContentTools converts spec ToolChoices to a slice of *genai.Tool.
 // Function/custom tools go into one Tool{FunctionDeclarations: [...]};
 // the web-search tool goes into a separate Tool{GoogleSearch: ...}.
@@ -1171,11 +1532,111 @@ func filterGoogleGenerateContentCallableToolChoices(toolChoices []spec.ToolChoic
 	return out
 }
 
+// applyGoogleGenerateContentSamplingParams maps the sampling fields left by
+// normalization. Gemini seeds are 32-bit, so larger seeds are clamped with a
+// warning.
+func applyGoogleGenerateContentSamplingParams(
+	config *genai.GenerateContentConfig,
+	mp *spec.ModelParam,
+) []spec.Warning {
+	var warns []spec.Warning
+	if mp.TopP != nil {
+		config.TopP = new(float32(*mp.TopP))
+	}
+	if mp.TopK != nil {
+		config.TopK = new(float32(*mp.TopK))
+	}
+	if mp.Seed != nil {
+		seed := sdkutil.ClampIntToInt32(int(*mp.Seed))
+		if int64(seed) != *mp.Seed {
+			warns = append(warns, spec.Warning{
+				Code: "seed_clamped",
+				Message: fmt.Sprintf(
+					"modelParam.seed %d is outside Gemini's 32-bit range and was clamped to %d.",
+					*mp.Seed,
+					seed,
+				),
+			})
+		}
+		config.Seed = &seed
+	}
+	if mp.PresencePenalty != nil {
+		config.PresencePenalty = new(float32(*mp.PresencePenalty))
+	}
+	if mp.FrequencyPenalty != nil {
+		config.FrequencyPenalty = new(float32(*mp.FrequencyPenalty))
+	}
+	if mp.Candidates > 1 {
+		config.CandidateCount = sdkutil.ClampIntToInt32(mp.Candidates)
+	}
+	if mp.Logprobs != nil {
+		config.ResponseLogprobs = true
+		if mp.Logprobs.TopAlternatives > 0 {
+			config.Logprobs = new(sdkutil.ClampIntToInt32(mp.Logprobs.TopAlternatives))
+		}
+	}
+	return warns
+}
+
+// attachGenAILogprobs sets logprobs on the first text item of outs.
+func attachGenAILogprobs(outs []spec.OutputUnion, logprobs []spec.TokenLogprob) {
+	for _, o := range outs {
+		if o.OutputMessage == nil {
+			continue
+		}
+		for _, c := range o.OutputMessage.Contents {
+			if c.TextItem != nil {
+				c.TextItem.Logprobs = logprobs
+				return
+			}
+		}
+	}
+}
+
+// tokenLogprobsFromGenAI pairs each chosen token with the top candidates of its decoding step.
+func tokenLogprobsFromGenAI(lr *genai.LogprobsResult) []spec.TokenLogprob {
+	if lr == nil || len(lr.ChosenCandidates) == 0 {
+		return nil
+	}
+	out := make([]spec.TokenLogprob, 0, len(lr.ChosenCandidates))
+	for i, c := range lr.ChosenCandidates {
+		if c == nil {
+			continue
+		}
+		tl := spec.TokenLogprob{Token: c.Token, Logprob: float64(c.LogProbability)}
+		if i < len(lr.TopCandidates) && lr.TopCandidates[i] != nil {
+			for _, alt := range lr.TopCandidates[i].Candidates {
+				if alt != nil {
+					tl.TopAlternatives = append(
+						tl.TopAlternatives,
+						spec.TokenAlternative{Token: alt.Token, Logprob: float64(alt.LogProbability)},
+					)
+				}
+			}
+		}
+		out = append(out, tl)
+	}
+	return out
+}
+
 func applyGoogleGenerateContentOutputParam(
 	config *genai.GenerateContentConfig,
 	op *spec.OutputParam,
 ) error {
-	if config == nil || op == nil || op.Format == nil {
+	if config == nil || op == nil {
+		return nil
+	}
+
+	if a := op.Audio; a != nil {
+		config.ResponseModalities = []string{string(genai.ModalityAudio)}
+		if v := strings.TrimSpace(a.Voice); v != "" {
+			config.SpeechConfig = &genai.SpeechConfig{
+				VoiceConfig: &genai.VoiceConfig{PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: v}},
+			}
+		}
+	}
+
+	if op.Format == nil {
 		return nil
 	}
 
@@ -1199,6 +1660,53 @@ func applyGoogleGenerateContentOutputParam(
 	}
 }
 
+// stopReasonFromGenAIResponse normalizes the first candidate's finish reason, or the prompt block reason when the
+// prompt was blocked. Gemini finishes tool calling turns and stop sequence hits with STOP: the former is told apart by
+// its function call parts, the latter cannot be.
+func stopReasonFromGenAIResponse(genResp *genai.GenerateContentResponse) *spec.StopReason {
+	if genResp == nil {
+		return nil
+	}
+	if len(genResp.Candidates) == 0 || genResp.Candidates[0] == nil {
+		if pf := genResp.PromptFeedback; pf != nil && pf.BlockReason != "" {
+			return &spec.StopReason{Kind: spec.StopReasonContentFilter, Raw: string(pf.BlockReason)}
+		}
+		return nil
+	}
+	return stopReasonFromGenAICandidate(genResp.Candidates[0])
+}
+
+// stopReasonFromGenAICandidate normalizes a candidate's finish reason.
+func stopReasonFromGenAICandidate(cand *genai.Candidate) *spec.StopReason {
+	if cand == nil || cand.FinishReason == "" {
+		return nil
+	}
+	out := &spec.StopReason{Raw: string(cand.FinishReason)}
+	switch cand.FinishReason {
+	case genai.FinishReasonStop:
+		out.Kind = spec.StopReasonEndTurn
+		if cand.Content != nil && slices.ContainsFunc(cand.Content.Parts, func(p *genai.Part) bool {
+			return p != nil && p.FunctionCall != nil
+		}) {
+			out.Kind = spec.StopReasonToolUse
+		}
+	case genai.FinishReasonMaxTokens:
+		out.Kind = spec.StopReasonMaxTokens
+	case genai.FinishReasonSafety,
+		genai.FinishReasonRecitation,
+		genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent,
+		genai.FinishReasonSPII,
+		genai.FinishReasonImageSafety,
+		genai.FinishReasonImageProhibitedContent,
+		genai.FinishReasonImageRecitation:
+		out.Kind = spec.StopReasonContentFilter
+	default:
+		out.Kind = spec.StopReasonOther
+	}
+	return out
+}
+
 func mapGenAIFinishReasonToStatus(reason genai.FinishReason) spec.Status {
 	switch reason {
 	case genai.FinishReasonStop,
@@ -2,6 +2,7 @@ package googlegeneratecontentsdk
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"google.golang.org/genai"
@@ -43,7 +44,7 @@ func TestOutputsFromGenAIResponse_PreservesPartBoundariesAndSignatures(t *testin
 		}},
 	}
 
-	outs := outputsFromGenAIResponse(resp, map[string]spec.ToolChoice{
+	outs := outputsFromGenAIResponse(t.Context(), resp, map[string]spec.ToolChoice{
 		testCallNameValue: {
 			ID:   "echo-tool",
 			Type: spec.ToolTypeFunction,
@@ -77,6 +78,107 @@ func TestOutputsFromGenAIResponse_PreservesPartBoundariesAndSignatures(t *testin
 	}
 }
 
+func TestChoicesFromGenAIResponse(t *testing.T) {
+	t.Parallel()
+
+	candidate := func(idx int32, text string, reason genai.FinishReason) *genai.Candidate {
+		return &genai.Candidate{
+			Index:        idx,
+			FinishReason: reason,
+			Content:      &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: text}}},
+		}
+	}
+	resp := &genai.GenerateContentResponse{
+		ResponseID: "resp-1",
+		Candidates: []*genai.Candidate{
+			candidate(0, "first", genai.FinishReasonStop),
+			candidate(1, "second", genai.FinishReasonMaxTokens),
+		},
+	}
+
+	choices := choicesFromGenAIResponse(t.Context(), resp, nil, "")
+	if len(choices) != 2 {
+		t.Fatalf("choices = %+v, want 2", choices)
+	}
+	if c := choices[0]; c.Index != 0 || c.Outputs[0].OutputMessage.Contents[0].TextItem.Text != "first" ||
+		c.StopReason.Kind != spec.StopReasonEndTurn {
+		t.Errorf("choice 0 = %+v", c)
+	}
+	if c := choices[1]; c.Index != 1 || c.Outputs[0].OutputMessage.ID != "resp-1" ||
+		c.Outputs[0].OutputMessage.Contents[0].TextItem.Text != "second" || c.StopReason.Kind != spec.StopReasonMaxTokens {
+		t.Errorf("choice 1 = %+v", c)
+	}
+
+	resp.Candidates = resp.Candidates[:1]
+	if got := choicesFromGenAIResponse(t.Context(), resp, nil, ""); got != nil {
+		t.Errorf("single candidate: got %+v, want nil", got)
+	}
+}
+
+func TestOutputsFromGenAIResponseLogprobs(t *testing.T) {
+	t.Parallel()
+
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		FinishReason: genai.FinishReasonStop,
+		Content: &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{
+			{Text: "plan", Thought: true},
+			{Text: "Yes"},
+		}},
+		LogprobsResult: &genai.LogprobsResult{
+			ChosenCandidates: []*genai.LogprobsResultCandidate{{Token: "Yes", LogProbability: -0.5}},
+			TopCandidates: []*genai.LogprobsResultTopCandidates{{Candidates: []*genai.LogprobsResultCandidate{
+				{Token: "Yes", LogProbability: -0.5},
+				{Token: "No", LogProbability: -1},
+			}}},
+		},
+	}}}
+
+	outs := outputsFromGenAIResponse(t.Context(), resp, nil, "")
+	if len(outs) != 2 || outs[1].OutputMessage == nil {
+		t.Fatalf("outputs = %+v, want reasoning and text", outs)
+	}
+	want := []spec.TokenLogprob{{
+		Token:   "Yes",
+		Logprob: -0.5,
+		TopAlternatives: []spec.TokenAlternative{
+			{Token: "Yes", Logprob: -0.5},
+			{Token: "No", Logprob: -1},
+		},
+	}}
+	if got := outs[1].OutputMessage.Contents[0].TextItem.Logprobs; !reflect.DeepEqual(got, want) {
+		t.Errorf("logprobs = %+v, want %+v", got, want)
+	}
+}
+
+func TestOutputsFromGenAIResponseAudio(t *testing.T) {
+	t.Parallel()
+
+	const pcm = "audio/L16;codec=pcm;rate=24000"
+	// Streamed speech arrives in chunks that consolidate into one part.
+	parts := consolidateGoogleGenerateContentStreamParts([]*genai.Part{
+		{InlineData: &genai.Blob{MIMEType: pcm, Data: []byte{1, 2}}},
+		{InlineData: &genai.Blob{MIMEType: pcm, Data: []byte{3}}},
+		{InlineData: &genai.Blob{MIMEType: "image/png", Data: []byte{4}}},
+	})
+	if len(parts) != 2 {
+		t.Fatalf("parts = %+v, want the audio chunks joined", parts)
+	}
+
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		FinishReason: genai.FinishReasonStop,
+		Content:      &genai.Content{Role: genai.RoleModel, Parts: parts},
+	}}}
+	outs := outputsFromGenAIResponse(t.Context(), resp, nil, "")
+	if len(outs) != 1 || outs[0].OutputMessage == nil {
+		t.Fatalf("outputs = %+v, want one audio message", outs)
+	}
+	want := &spec.ContentItemAudio{AudioMIME: pcm, AudioData: "AQID"}
+	if got := outs[0].OutputMessage.Contents[0]; got.Kind != spec.ContentItemKindAudio ||
+		!reflect.DeepEqual(got.AudioItem, want) {
+		t.Errorf("content = %+v, want %+v", got, want)
+	}
+}
+
 func TestConsolidateGoogleGenerateContentStreamParts(t *testing.T) {
 	t.Parallel()
 
@@ -238,3 +340,139 @@ func TestConsolidateGoogleGenerateContentStreamParts(t *testing.T) {
 		})
 	}
 }
+
+func TestUsageFromGenAIResponse(t *testing.T) {
+	t.Parallel()
+
+	resp := &genai.GenerateContentResponse{
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        100,
+			CachedContentTokenCount: 40,
+			CandidatesTokenCount:    20,
+			ThoughtsTokenCount:      30,
+			ToolUsePromptTokenCount: 50,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 70},
+				{Modality: genai.MediaModalityImage, TokenCount: 30},
+			},
+			ToolUsePromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 50},
+			},
+		},
+		Candidates: []*genai.Candidate{{
+			GroundingMetadata: &genai.GroundingMetadata{WebSearchQueries: []string{"a", "b"}},
+		}},
+	}
+
+	got := usageFromGenAIResponse(resp)
+	want := spec.Usage{
+		InputTokensTotal:    150,
+		InputTokensCached:   40,
+		InputTokensUncached: 110,
+		OutputTokens:        50,
+		ReasoningTokens:     30,
+		InputTokensByModality: map[spec.Modality]int64{
+			spec.ModalityTextIn:  120,
+			spec.ModalityImageIn: 30,
+		},
+		ToolUseInputTokens: 50,
+		WebSearchRequests:  2,
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("usage:\n got %+v\nwant %+v", *got, want)
+	}
+}
+
+func TestStopReasonFromGenAIResponse(t *testing.T) {
+	t.Parallel()
+
+	candidate := func(reason genai.FinishReason, parts ...*genai.Part) *genai.GenerateContentResponse {
+		return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+			FinishReason: reason,
+			Content:      &genai.Content{Role: genai.RoleModel, Parts: parts},
+		}}}
+	}
+
+	tests := []struct {
+		name string
+		resp *genai.GenerateContentResponse
+		want *spec.StopReason
+	}{
+		{name: "nil", resp: nil, want: nil},
+		{
+			name: "stop",
+			resp: candidate(genai.FinishReasonStop, &genai.Part{Text: testHello}),
+			want: &spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "STOP"},
+		},
+		{
+			name: "function call",
+			resp: candidate(
+				genai.FinishReasonStop,
+				&genai.Part{FunctionCall: &genai.FunctionCall{Name: testCallNameValue}},
+			),
+			want: &spec.StopReason{Kind: spec.StopReasonToolUse, Raw: "STOP"},
+		},
+		{
+			name: "max tokens",
+			resp: candidate(genai.FinishReasonMaxTokens),
+			want: &spec.StopReason{Kind: spec.StopReasonMaxTokens, Raw: "MAX_TOKENS"},
+		},
+		{
+			name: "safety",
+			resp: candidate(genai.FinishReasonSafety),
+			want: &spec.StopReason{Kind: spec.StopReasonContentFilter, Raw: "SAFETY"},
+		},
+		{
+			name: "malformed function call",
+			resp: candidate(genai.FinishReasonMalformedFunctionCall),
+			want: &spec.StopReason{Kind: spec.StopReasonOther, Raw: "MALFORMED_FUNCTION_CALL"},
+		},
+		{
+			name: "blocked prompt",
+			resp: &genai.GenerateContentResponse{
+				PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety},
+			},
+			want: &spec.StopReason{Kind: spec.StopReasonContentFilter, Raw: "SAFETY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := stopReasonFromGenAIResponse(tt.resp)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyGoogleGenerateContentSamplingParamsSeed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		seed     int64
+		wantSeed int32
+		wantWarn bool
+	}{
+		{name: "in range", seed: 42, wantSeed: 42},
+		{name: "above int32", seed: 1 << 40, wantSeed: 1<<31 - 1, wantWarn: true},
+		{name: "below int32", seed: -(1 << 40), wantSeed: -1 << 31, wantWarn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := &genai.GenerateContentConfig{}
+			warns := applyGoogleGenerateContentSamplingParams(config, &spec.ModelParam{Seed: &tt.seed})
+			if config.Seed == nil || *config.Seed != tt.wantSeed {
+				t.Fatalf("seed = %v, want %d", config.Seed, tt.wantSeed)
+			}
+			if gotWarn := len(warns) == 1 && warns[0].Code == "seed_clamped"; gotWarn != tt.wantWarn || len(warns) > 1 {
+				t.Fatalf("warnings = %+v, want seed_clamped %v", warns, tt.wantWarn)
+			}
+		})
+	}
+}
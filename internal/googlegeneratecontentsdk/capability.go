@@ -3,8 +3,14 @@ package googlegeneratecontentsdk
 import "github.com/flexigpt/inference-go/spec"
 
 var googleGenerateContentSDKCapability = spec.ModelCapabilities{
-	ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityImageIn, spec.ModalityFileIn},
-	ModalitiesOut: []spec.Modality{spec.ModalityTextOut},
+	ModalitiesIn: []spec.Modality{
+		spec.ModalityTextIn,
+		spec.ModalityImageIn,
+		spec.ModalityFileIn,
+		spec.ModalityAudioIn,
+		spec.ModalityVideoIn,
+	},
+	ModalitiesOut: []spec.Modality{spec.ModalityTextOut, spec.ModalityAudioOut},
 
 	ReasoningCapabilities: &spec.ReasoningCapabilities{
 		SupportsReasoningConfig: true,
@@ -37,6 +43,16 @@ var googleGenerateContentSDKCapability = spec.ModelCapabilities{
 		DisallowedWithReasoning: false,
 		MaxSequences:            5,
 	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:             true,
+		SupportsTopK:             true,
+		SupportsSeed:             true,
+		SupportsPresencePenalty:  true,
+		SupportsFrequencyPenalty: true,
+		MaxCandidates:            8,
+		SupportsLogprobs:         true,
+		MaxTopLogprobs:           20,
+	},
 
 	OutputCapabilities: &spec.OutputCapabilities{
 		SupportedOutputFormats: []spec.OutputFormatKind{
@@ -24,7 +24,7 @@ var errEmptyInputPart = errors.New("empty input part")
 // Adjacent turns that share the same role are merged into a single Content so
 // the conversation conforms to the expected user/model alternation pattern.
 func toGoogleGenerateContentContents(
-	_ context.Context,
+	ctx context.Context,
 	systemPrompt string,
 	inputs []spec.InputUnion,
 ) (contents []*genai.Content, sysInstruction *genai.Content, err error) {
@@ -46,7 +46,7 @@ func toGoogleGenerateContentContents(
 			continue
 		}
 
-		role, parts, convErr := inputUnionToGenAIParts(in)
+		role, parts, convErr := inputUnionToGenAIParts(ctx, in)
 		if convErr != nil {
 			if errors.Is(convErr, errEmptyInputPart) {
 				continue
@@ -77,14 +77,14 @@ func toGoogleGenerateContentContents(
 }
 
 // inputUnionToGenAIParts converts a single InputUnion to a (role, []*genai.Part) pair.
-func inputUnionToGenAIParts(in spec.InputUnion) (role string, parts []*genai.Part, err error) {
+func inputUnionToGenAIParts(ctx context.Context, in spec.InputUnion) (role string, parts []*genai.Part, err error) {
 	switch in.Kind {
 
 	case spec.InputKindInputMessage:
 		if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
 			return "", nil, errEmptyInputPart
 		}
-		ps := contentItemsToGenAIParts(in.InputMessage.Contents)
+		ps := contentItemsToGenAIParts(ctx, in.InputMessage.Contents)
 		if len(ps) == 0 {
 			return "", nil, errEmptyInputPart
 		}
@@ -94,7 +94,7 @@ func inputUnionToGenAIParts(in spec.InputUnion) (role string, parts []*genai.Par
 		if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
 			return "", nil, errEmptyInputPart
 		}
-		ps := contentItemsToGenAIParts(in.OutputMessage.Contents)
+		ps := contentItemsToGenAIParts(ctx, in.OutputMessage.Contents)
 		if len(ps) == 0 {
 			return "", nil, errEmptyInputPart
 		}
@@ -162,7 +162,7 @@ func inputUnionToGenAIParts(in spec.InputUnion) (role string, parts []*genai.Par
 
 // contentItemsToGenAIParts converts a slice of InputOutputContentItemUnion to
 // genai.Part pointers, skipping unsupported types.
-func contentItemsToGenAIParts(items []spec.InputOutputContentItemUnion) []*genai.Part {
+func contentItemsToGenAIParts(ctx context.Context, items []spec.InputOutputContentItemUnion) []*genai.Part {
 	if len(items) == 0 {
 		return nil
 	}
@@ -184,26 +184,42 @@ func contentItemsToGenAIParts(items []spec.InputOutputContentItemUnion) []*genai
 			}
 
 		case spec.ContentItemKindImage:
-			if p := contentItemImageToGenAIPart(it.ImageItem); p != nil {
+			if p := contentItemImageToGenAIPart(ctx, it.ImageItem); p != nil {
 				out = append(out, p)
 			}
 
 		case spec.ContentItemKindFile:
-			if p := contentItemFileToGenAIPart(it.FileItem); p != nil {
+			if p := contentItemFileToGenAIPart(ctx, it.FileItem); p != nil {
 				out = append(out, p)
 			}
 
+		case spec.ContentItemKindAudio:
+			if a := it.AudioItem; a != nil {
+				if p := genAIMediaPart(ctx, a.ID, a.AudioMIME, spec.DefaultAudioDataMIME, a.AudioData, a.AudioURL,
+					a.FileID); p != nil {
+					out = append(out, p)
+				}
+			}
+
+		case spec.ContentItemKindVideo:
+			if v := it.VideoItem; v != nil {
+				if p := genAIMediaPart(ctx, v.ID, v.VideoMIME, spec.DefaultVideoDataMIME, v.VideoData, v.VideoURL,
+					v.FileID); p != nil {
+					out = append(out, p)
+				}
+			}
+
 		case spec.ContentItemKindRefusal:
 			// Refusals are model outputs; not a meaningful input representation.
 
 		default:
-			logutil.Debug("googleGenerateContent: unknown content item kind for message", "kind", it.Kind)
+			logutil.DebugContext(ctx, "googleGenerateContent: unknown content item kind for message", "kind", it.Kind)
 		}
 	}
 	return out
 }
 
-func contentItemImageToGenAIPart(imageItem *spec.ContentItemImage) *genai.Part {
+func contentItemImageToGenAIPart(ctx context.Context, imageItem *spec.ContentItemImage) *genai.Part {
 	if imageItem == nil {
 		return nil
 	}
@@ -218,7 +234,7 @@ func contentItemImageToGenAIPart(imageItem *spec.ContentItemImage) *genai.Part {
 		if err != nil {
 			raw, err = base64.RawStdEncoding.DecodeString(data)
 			if err != nil {
-				logutil.Debug("googleGenerateContent: failed to decode base64 image data",
+				logutil.DebugContext(ctx, "googleGenerateContent: failed to decode base64 image data",
 					"id", imageItem.ID, "err", err)
 				return nil
 			}
@@ -232,7 +248,7 @@ func contentItemImageToGenAIPart(imageItem *spec.ContentItemImage) *genai.Part {
 	return nil
 }
 
-func contentItemFileToGenAIPart(fileItem *spec.ContentItemFile) *genai.Part {
+func contentItemFileToGenAIPart(ctx context.Context, fileItem *spec.ContentItemFile) *genai.Part {
 	if fileItem == nil {
 		return nil
 	}
@@ -247,7 +263,7 @@ func contentItemFileToGenAIPart(fileItem *spec.ContentItemFile) *genai.Part {
 		if err != nil {
 			raw, err = base64.RawStdEncoding.DecodeString(data)
 			if err != nil {
-				logutil.Debug("googleGenerateContent: failed to decode base64 file data",
+				logutil.DebugContext(ctx, "googleGenerateContent: failed to decode base64 file data",
 					"id", fileItem.ID, "name", fileItem.FileName, "err", err)
 				return nil
 			}
@@ -261,6 +277,36 @@ func contentItemFileToGenAIPart(fileItem *spec.ContentItemFile) *genai.Part {
 	return nil
 }
 
+// genAIMediaPart converts audio or video to inline data, or to a file reference when only a URL or an uploaded
+// file's URI is known.
+func genAIMediaPart(ctx context.Context, id, mime, defaultMIME, data, url, fileURI string) *genai.Part {
+	mime = strings.TrimSpace(mime)
+	if mime == "" {
+		mime = defaultMIME
+	}
+
+	if data = strings.TrimSpace(data); data != "" {
+		raw, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			raw, err = base64.RawStdEncoding.DecodeString(data)
+			if err != nil {
+				logutil.DebugContext(ctx, "googleGenerateContent: failed to decode base64 media data",
+					"id", id, "mime", mime, "err", err)
+				return nil
+			}
+		}
+		return genai.NewPartFromBytes(raw, mime)
+	}
+
+	if u := strings.TrimSpace(url); u != "" {
+		return genai.NewPartFromURI(u, mime)
+	}
+	if u := strings.TrimSpace(fileURI); u != "" {
+		return genai.NewPartFromURI(u, mime)
+	}
+	return nil
+}
+
 // reasoningContentToGenAIPart converts a spec.ReasoningContent to a genai
 // thought Part for history pass-back.
 // Google-native signed thoughts are replayed even when the visible thought
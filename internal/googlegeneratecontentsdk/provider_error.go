@@ -0,0 +1,27 @@
+package googlegeneratecontentsdk
+
+import (
+	"errors"
+
+	"google.golang.org/genai"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// googleGenerateContentProviderError maps a GenAI SDK error into a spec.ProviderError.
+// GenAI errors do not expose response headers, so RequestID and RetryAfter stay empty.
+func googleGenerateContentProviderError(provider spec.ProviderName, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var details sdkutil.ProviderErrorDetails
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		details.HTTPStatus = apiErr.Code
+		details.ProviderCode = apiErr.Status
+	}
+
+	return sdkutil.NewProviderError(provider, err, details)
+}
@@ -0,0 +1,167 @@
+package googlegeneratecontentsdk
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// googleGenerateContentStreamEvents maps GenerateContent stream parts to
+// normalized stream events.
+//
+// GenerateContent has no notion of output items, so items are derived: each
+// run of adjacent text or thought parts is one item and every function call is
+// another, numbered in stream order. Function calls arrive complete in a single
+// part, so their start, arguments and end are emitted together.
+type googleGenerateContentStreamEvents struct {
+	emitter           *sdkutil.StreamEmitter
+	toolChoiceNameMap map[string]spec.ToolChoice
+	responseID        string
+
+	nextOutputIndex int
+	openKind        spec.OutputKind
+	openIndex       int
+
+	seenQueries map[string]bool
+
+	// pendingLogprobs are those of the current chunk, delivered with its first
+	// text part.
+	pendingLogprobs []spec.TokenLogprob
+}
+
+func newGoogleGenerateContentStreamEvents(
+	emitter *sdkutil.StreamEmitter,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) *googleGenerateContentStreamEvents {
+	return &googleGenerateContentStreamEvents{
+		emitter:           emitter,
+		toolChoiceNameMap: toolChoiceNameMap,
+		seenQueries:       map[string]bool{},
+	}
+}
+
+func (s *googleGenerateContentStreamEvents) handlePart(part *genai.Part) error {
+	switch {
+	case part.Thought:
+		if part.Text == "" {
+			return nil
+		}
+		if err := s.open(spec.OutputKindReasoningMessage); err != nil {
+			return err
+		}
+		return s.emitter.WriteThinking(part.Text)
+
+	case part.FunctionCall != nil:
+		return s.handleFunctionCall(part.FunctionCall)
+
+	case part.Text != "":
+		if err := s.open(spec.OutputKindOutputMessage); err != nil {
+			return err
+		}
+		logprobs := s.pendingLogprobs
+		s.pendingLogprobs = nil
+		return s.emitter.WriteTextLogprobs(part.Text, logprobs)
+
+	default:
+		return nil
+	}
+}
+
+func (s *googleGenerateContentStreamEvents) handleFunctionCall(fc *genai.FunctionCall) error {
+	if err := s.closeOpen(); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(fc.Name)
+	if name == "" {
+		return nil
+	}
+
+	tc := spec.StreamToolCallChunk{
+		OutputIndex: s.nextOutputIndex,
+		Type:        spec.ToolTypeFunction,
+		CallID:      strings.TrimSpace(fc.ID),
+		Name:        name,
+	}
+	kind := spec.OutputKindFunctionToolCall
+	if choice, ok := s.toolChoiceNameMap[name]; ok && choice.Type == spec.ToolTypeCustom {
+		tc.Type = spec.ToolTypeCustom
+		kind = spec.OutputKindCustomToolCall
+	}
+	s.nextOutputIndex++
+
+	args := "{}"
+	if fc.Args != nil {
+		if b, err := json.Marshal(fc.Args); err == nil {
+			args = string(b)
+		}
+	}
+
+	if err := s.emitter.OutputItemStart(tc.OutputIndex, kind, tc.CallID); err != nil {
+		return err
+	}
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallStart, tc); err != nil {
+		return err
+	}
+	delta := tc
+	delta.ArgumentsDelta = args
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, delta); err != nil {
+		return err
+	}
+	end := tc
+	end.Arguments = args
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, end); err != nil {
+		return err
+	}
+	return s.emitter.OutputItemStop(tc.OutputIndex, kind, tc.CallID)
+}
+
+// handleGrounding reports each new web search query of the grounding metadata as
+// a completed web search call; GenerateContent only discloses searches once they
+// have run.
+func (s *googleGenerateContentStreamEvents) handleGrounding(gm *genai.GroundingMetadata) error {
+	if gm == nil {
+		return nil
+	}
+	for _, q := range gm.WebSearchQueries {
+		q = strings.TrimSpace(q)
+		if q == "" || s.seenQueries[q] {
+			continue
+		}
+		s.seenQueries[q] = true
+		if err := s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: s.nextOutputIndex,
+			Status:      spec.StatusCompleted,
+			Query:       q,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *googleGenerateContentStreamEvents) open(kind spec.OutputKind) error {
+	if s.openKind == kind {
+		return nil
+	}
+	if err := s.closeOpen(); err != nil {
+		return err
+	}
+	s.openKind = kind
+	s.openIndex = s.nextOutputIndex
+	s.nextOutputIndex++
+	return s.emitter.OutputItemStart(s.openIndex, kind, s.responseID)
+}
+
+// closeOpen ends the current text or thought item, if any. It is idempotent.
+func (s *googleGenerateContentStreamEvents) closeOpen() error {
+	if s.openKind == "" {
+		return nil
+	}
+	kind := s.openKind
+	s.openKind = ""
+	return s.emitter.OutputItemStop(s.openIndex, kind, s.responseID)
+}
@@ -0,0 +1,196 @@
+package googlegeneratecontentsdk
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// googleGenerateContentStreamStep is one input to the stream translator: a part of the first candidate or its
+// grounding metadata, optionally preceded by the logprobs of its chunk.
+type googleGenerateContentStreamStep struct {
+	part      *genai.Part
+	grounding *genai.GroundingMetadata
+	logprobs  []spec.TokenLogprob
+}
+
+func TestGoogleGenerateContentStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	logprobs := []spec.TokenLogprob{{Token: "Hi", Logprob: -0.1}}
+
+	tests := []struct {
+		name   string
+		steps  []googleGenerateContentStreamStep
+		want   []string
+		verify func(t *testing.T, events []spec.StreamEvent)
+	}{
+		{
+			name: "thought text and tool calls",
+			steps: []googleGenerateContentStreamStep{
+				{part: &genai.Part{Thought: true, Text: "plan"}},
+				{part: &genai.Part{Thought: true, Text: " more"}},
+				{part: &genai.Part{Text: "Hi"}, logprobs: logprobs},
+				{part: &genai.Part{FunctionCall: &genai.FunctionCall{
+					ID:   testCallID,
+					Name: "get_weather",
+					Args: map[string]any{"city": "Paris"},
+				}}},
+				{part: &genai.Part{FunctionCall: &genai.FunctionCall{Name: "run_sql"}}},
+				{part: &genai.Part{Text: "Done"}},
+			},
+			want: []string{
+				"outputItemStart@0",
+				"thinking",
+				"outputItemStop@0",
+				"outputItemStart@1",
+				"text",
+				"outputItemStop@1",
+				"outputItemStart@2",
+				"toolCallStart@2",
+				"toolCallDelta@2",
+				"toolCallEnd@2",
+				"outputItemStop@2",
+				"outputItemStart@3",
+				"toolCallStart@3",
+				"toolCallDelta@3",
+				"toolCallEnd@3",
+				"outputItemStop@3",
+				"outputItemStart@4",
+				"text",
+				"outputItemStop@4",
+			},
+			verify: func(t *testing.T, events []spec.StreamEvent) {
+				t.Helper()
+				if item := events[0].OutputItem; item.Kind != spec.OutputKindReasoningMessage || item.ID != "resp-1" {
+					t.Errorf("reasoning item start: got %+v", item)
+				}
+				if th := events[1].Thinking; th.Text != "plan more" {
+					t.Errorf("thinking: got %+v", th)
+				}
+				if tx := events[4].Text; tx.Text != "Hi" || !reflect.DeepEqual(tx.Logprobs, logprobs) {
+					t.Errorf("text: got %+v", tx)
+				}
+				if item := events[6].OutputItem; item.Kind != spec.OutputKindFunctionToolCall || item.ID != testCallID {
+					t.Errorf("function call item start: got %+v", item)
+				}
+				if end := events[9].ToolCall; end.Arguments != `{"city":"Paris"}` || end.CallID != testCallID ||
+					end.Name != "get_weather" || end.Type != spec.ToolTypeFunction {
+					t.Errorf("function call end: got %+v", end)
+				}
+				if item := events[11].OutputItem; item.Kind != spec.OutputKindCustomToolCall {
+					t.Errorf("custom call item start: got %+v", item)
+				}
+				if end := events[14].ToolCall; end.Arguments != "{}" || end.Type != spec.ToolTypeCustom {
+					t.Errorf("custom call end: got %+v", end)
+				}
+			},
+		},
+		{
+			name: "grounding queries",
+			steps: []googleGenerateContentStreamStep{
+				{grounding: &genai.GroundingMetadata{WebSearchQueries: []string{"go", "go", " "}}},
+				{part: &genai.Part{Text: "Go is great."}},
+				{grounding: &genai.GroundingMetadata{WebSearchQueries: []string{"go", " rust "}}},
+				{part: &genai.Part{Text: " So is Rust."}},
+			},
+			want: []string{
+				"webSearchCall@0",
+				"outputItemStart@0",
+				"text",
+				"webSearchCall@1",
+				"text",
+				"outputItemStop@0",
+			},
+			verify: func(t *testing.T, events []spec.StreamEvent) {
+				t.Helper()
+				if ws := events[0].WebSearchCall; ws.Query != "go" || ws.Status != spec.StatusCompleted {
+					t.Errorf("first web search: got %+v", ws)
+				}
+				if ws := events[3].WebSearchCall; ws.Query != "rust" {
+					t.Errorf("second web search: got %+v", ws)
+				}
+			},
+		},
+		{
+			name: "empty parts",
+			steps: []googleGenerateContentStreamStep{
+				{part: &genai.Part{Thought: true}},
+				{part: &genai.Part{FunctionCall: &genai.FunctionCall{Name: " "}}},
+				{part: &genai.Part{}},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var events []spec.StreamEvent
+			emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+				StreamHandler: func(ev spec.StreamEvent) error {
+					events = append(events, ev)
+					return nil
+				},
+				StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+			})
+			s := newGoogleGenerateContentStreamEvents(emitter, map[string]spec.ToolChoice{
+				"run_sql": {Type: spec.ToolTypeCustom, Name: "run_sql"},
+			})
+			s.responseID = "resp-1"
+
+			for i, step := range tt.steps {
+				var err error
+				if step.grounding != nil {
+					err = s.handleGrounding(step.grounding)
+				} else {
+					s.pendingLogprobs = step.logprobs
+					err = s.handlePart(step.part)
+				}
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+			}
+			if err := s.closeOpen(); err != nil {
+				t.Fatalf("close open item: %v", err)
+			}
+			if err := emitter.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			got := make([]string, 0, len(events))
+			for _, ev := range events {
+				got = append(got, googleGenerateContentStreamEventLabel(ev))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("events:\n got %v\nwant %v", got, tt.want)
+			}
+			if tt.verify != nil {
+				tt.verify(t, events)
+			}
+		})
+	}
+}
+
+// googleGenerateContentStreamEventLabel renders an event as its kind and, where it has one, its output index.
+func googleGenerateContentStreamEventLabel(ev spec.StreamEvent) string {
+	switch {
+	case ev.OutputItem != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.OutputItem.OutputIndex)
+	case ev.ToolCall != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.ToolCall.OutputIndex)
+	case ev.WebSearchCall != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.WebSearchCall.OutputIndex)
+	case ev.Citation != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.Citation.OutputIndex)
+	default:
+		return string(ev.Kind)
+	}
+}
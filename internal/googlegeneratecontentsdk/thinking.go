@@ -28,7 +28,8 @@ func resolveGoogleGenerateContentReasoningCapabilities(
 		CompletionKey:   opts.CompletionKey,
 	})
 	if err != nil {
-		logutil.Debug(
+		logutil.DebugContext(
+			ctx,
 			"googleGenerateContent: failed to resolve model capabilities for reasoning policy",
 			"model", modelName,
 			"err", err,
@@ -168,7 +169,7 @@ func googleThinkingLevelFromSpec(level spec.ReasoningLevel) (genai.ThinkingLevel
 //   - Drop everything else: Anthropic's RedactedThinking, OpenAI's
 //     EncryptedContent, or any unsigned/plain-text reasoning content from a
 //     different provider.
-func sanitizeGoogleGenerateContentReasoningInputs(inputs []spec.InputUnion) []spec.InputUnion {
+func sanitizeGoogleGenerateContentReasoningInputs(ctx context.Context, inputs []spec.InputUnion) []spec.InputUnion {
 	if len(inputs) == 0 {
 		return nil
 	}
@@ -196,7 +197,8 @@ func sanitizeGoogleGenerateContentReasoningInputs(inputs []spec.InputUnion) []sp
 	}
 
 	if dropped > 0 {
-		logutil.Debug(
+		logutil.DebugContext(
+			ctx,
 			"googleGenerateContent: sanitized non-native reasoning messages from input history",
 			"dropped", dropped,
 		)
@@ -68,7 +68,7 @@ func TestSanitizeGoogleGenerateContentReasoningInputs(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			got := sanitizeGoogleGenerateContentReasoningInputs(tc.in)
+			got := sanitizeGoogleGenerateContentReasoningInputs(t.Context(), tc.in)
 			if len(got) != tc.want {
 				t.Fatalf("len(...) = %d, want %d", len(got), tc.want)
 			}
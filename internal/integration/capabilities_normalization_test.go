@@ -27,7 +27,7 @@ func TestNormalizeRequestForSDK_OpenAIChat_PreservesWebSearchToolChoice(t *testi
 	api, err := openaichatsdk.NewOpenAIChatCompletionsAPI(spec.ProviderParam{
 		Name:    modelpreset.ProviderOpenAIChat,
 		SDKType: spec.ProviderSDKTypeOpenAIChatCompletions,
-	}, nil)
+	}, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -74,7 +74,7 @@ func TestNormalizeRequestForSDK_ResolverRestrictsReasoningLevels(t *testing.T) {
 	api, err := openairesponsessdk.NewOpenAIResponsesAPI(spec.ProviderParam{
 		Name:    modelpreset.ProviderOpenAIResponses,
 		SDKType: spec.ProviderSDKTypeOpenAIResponses,
-	}, nil)
+	}, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -6,10 +6,26 @@
 //
 //	go test ./internal/integration -run Example
 //
-// All examples are best-effort: they only attempt live API calls when the
-// relevant environment variables are set, e.g.:
+// By default every example replays its cassette from testdata/cassettes, so
+// the examples run offline, e.g. in CI, even where provider keys are set. Live
+// calls need both the provider key and an explicit mode:
 //
 //	ANTHROPIC_API_KEY=<your key>
 //	OPENAI_API_KEY=<your key>
 //	GEMINI_API_KEY=<your key>   (or GOOGLE_API_KEY=<your key>)
+//
+// To call the providers whose keys are set without touching the cassettes:
+//
+//	INFERENCE_CASSETTE_MODE=live go test ./internal/integration -run Example
+//
+// To re-record the cassettes of the providers whose keys are set:
+//
+//	INFERENCE_CASSETTE_MODE=record go test ./internal/integration -run 'Example|Test'
+//
+// A replayed example fails if it no longer makes every request its cassette
+// recorded, so re-record the cassette whenever an example changes.
+//
+// The cassettes shipped in the repository are synthetic: they were recorded
+// against a scripted stand-in that answers in each provider's wire format, not
+// against the live APIs. Re-record them to pin real provider responses.
 package integration
@@ -15,11 +15,12 @@ func Example_anthropic_basicConversation() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(slog.LevelDebug, "anthropic_basic_conversation", "ANTHROPIC_API_KEY")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -32,7 +33,7 @@ func Example_anthropic_basicConversation() {
 		return
 	}
 
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "ANTHROPIC_API_KEY not set; skipping live Anthropic call")
 		fmt.Println("OK")
@@ -35,11 +35,16 @@ func Example_anthropic_toolsAndThinkingStreaming() {
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(
+		slog.LevelDebug,
+		"anthropic_tools_and_thinking_streaming",
+		"ANTHROPIC_API_KEY",
+	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -52,7 +57,7 @@ func Example_anthropic_toolsAndThinkingStreaming() {
 		return
 	}
 
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "ANTHROPIC_API_KEY not set; skipping live Anthropic call")
 		fmt.Println("OK")
@@ -177,11 +182,12 @@ func Example_anthropic_functionToolRoundTrip() {
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(slog.LevelDebug, "anthropic_function_tool_round_trip", "ANTHROPIC_API_KEY")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -194,7 +200,7 @@ func Example_anthropic_functionToolRoundTrip() {
 		return
 	}
 
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "ANTHROPIC_API_KEY not set; skipping live Anthropic call")
 		fmt.Println("OK")
@@ -12,7 +12,7 @@ import (
 func TestCapabilityOverride_GetProviderCapsThenOverride(t *testing.T) {
 	ctx := t.Context()
 
-	ps, err := newProviderSetWithDebug(0)
+	ps, _, err := newProviderSetWithDebug(0, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -15,11 +15,17 @@ func Example_googleGenerateContent_basicConversation() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(
+		slog.LevelDebug,
+		"google_basic_conversation",
+		"GEMINI_API_KEY",
+		"GOOGLE_API_KEY",
+	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -32,10 +38,7 @@ func Example_googleGenerateContent_basicConversation() {
 		return
 	}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("GOOGLE_API_KEY")
-	}
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "GEMINI_API_KEY/GOOGLE_API_KEY not set; skipping live Google Gemini call")
 		fmt.Println("OK")
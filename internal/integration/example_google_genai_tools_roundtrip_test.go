@@ -32,11 +32,17 @@ func Example_googleGenerateContent_functionToolRoundTrip() {
 	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(
+		slog.LevelDebug,
+		"google_function_tool_round_trip",
+		"GEMINI_API_KEY",
+		"GOOGLE_API_KEY",
+	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -49,10 +55,7 @@ func Example_googleGenerateContent_functionToolRoundTrip() {
 		return
 	}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("GOOGLE_API_KEY")
-	}
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "GEMINI_API_KEY/GOOGLE_API_KEY not set; skipping live Google Gemini tool example")
 		fmt.Println("OK")
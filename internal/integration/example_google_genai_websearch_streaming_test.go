@@ -30,11 +30,17 @@ func Example_googleGenerateContent_webSearchAndThinkingStreaming() {
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelInfo)
+	ps, conn, err := newProviderSetWithDebug(
+		slog.LevelInfo,
+		"google_web_search_and_thinking_streaming",
+		"GEMINI_API_KEY",
+		"GOOGLE_API_KEY",
+	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -47,10 +53,7 @@ func Example_googleGenerateContent_webSearchAndThinkingStreaming() {
 		return
 	}
 
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("GOOGLE_API_KEY")
-	}
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "GEMINI_API_KEY/GOOGLE_API_KEY not set; skipping live Google Gemini web-search example")
 		fmt.Println("OK")
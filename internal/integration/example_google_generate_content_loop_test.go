@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"testing"
 	"time"
 
@@ -20,21 +19,28 @@ const (
 )
 
 func TestGoogleGenerateContent_FunctionToolRoundTripLoop(t *testing.T) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("GOOGLE_API_KEY")
-	}
-	if apiKey == "" {
-		t.Skip("GEMINI_API_KEY/GOOGLE_API_KEY not set")
-	}
-
 	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Minute)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(
+		slog.LevelDebug,
+		"google_function_tool_round_trip_loop",
+		"GEMINI_API_KEY",
+		"GOOGLE_API_KEY",
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer func() {
+		if err := conn.finish(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	apiKey := conn.apiKey
+	if apiKey == "" {
+		t.Skip("GEMINI_API_KEY/GOOGLE_API_KEY not set")
+	}
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
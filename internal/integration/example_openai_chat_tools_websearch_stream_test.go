@@ -29,11 +29,12 @@ func Example_openAIChat_toolsAndJSONSchema() {
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(slog.LevelDebug, "openai_chat_tools_and_json_schema", "OPENAI_API_KEY")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -46,7 +47,7 @@ func Example_openAIChat_toolsAndJSONSchema() {
 		return
 	}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "OPENAI_API_KEY not set; skipping live OpenAI Chat call")
 		fmt.Println("OK")
@@ -15,11 +15,12 @@ func Example_openAIResponses_basicConversation() {
 	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
 	defer cancel()
 
-	ps, err := newProviderSetWithDebug(slog.LevelDebug)
+	ps, conn, err := newProviderSetWithDebug(slog.LevelDebug, "openai_responses_basic_conversation", "OPENAI_API_KEY")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "error creating ProviderSetAPI:", err)
 		return
 	}
+	defer conn.close()
 
 	pp, mp, err := addCatalogModelProvider(
 		ctx,
@@ -32,7 +33,7 @@ func Example_openAIResponses_basicConversation() {
 		return
 	}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	apiKey := conn.apiKey
 	if apiKey == "" {
 		fmt.Fprintln(os.Stderr, "OPENAI_API_KEY not set; skipping live OpenAI Responses call")
 		fmt.Println("OK")
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/flexigpt/inference-go"
+	"github.com/flexigpt/inference-go/cassette"
 	"github.com/flexigpt/inference-go/debugclient"
 	"github.com/flexigpt/inference-go/modelpreset"
 	"github.com/flexigpt/inference-go/spec"
@@ -29,19 +31,81 @@ const (
 	toolJSONValueBoolean            = "boolean"
 )
 
+// cassetteModeEnv selects how examples reach their provider. Unset, or
+// "replay", replays the example's cassette. With the provider API key set,
+// INFERENCE_CASSETTE_MODE=record calls the provider and rewrites the cassette,
+// and INFERENCE_CASSETTE_MODE=live calls the provider without a cassette.
+const cassetteModeEnv = "INFERENCE_CASSETTE_MODE"
+
+// cassetteModeLive is the cassetteModeEnv value for live calls without a
+// cassette; the other values are cassette modes.
+const cassetteModeLive = "live"
+
+// replayAPIKey is the API key used when replaying; cassettes hold no keys.
+const replayAPIKey = "cassette-replay"
+
+// exampleConn describes how an example reaches its provider.
+type exampleConn struct {
+	// apiKey is the key to set on the provider. It is empty when the example
+	// cannot run: no key in the environment and no cassette to replay.
+	apiKey string
+
+	rec *cassette.Recorder
+}
+
+// close finishes the cassette; see finish. An example that defers it fails
+// on a finish error, since the error is printed to stdout after the expected
+// output.
+func (c *exampleConn) close() {
+	if err := c.finish(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// finish writes the cassette when recording, and when replaying fails if the
+// example no longer makes some of the recorded requests.
+func (c *exampleConn) finish() error {
+	if c.rec == nil {
+		return nil
+	}
+	if err := c.rec.Save(); err != nil {
+		return fmt.Errorf("error saving cassette: %w", err)
+	}
+	var errs []error
+	for _, req := range c.rec.Unused() {
+		errs = append(errs, fmt.Errorf("unused cassette interaction: %s %s", req.Method, req.Path))
+	}
+	return errors.Join(errs...)
+}
+
 // newProviderSetWithDebug constructs a ProviderSetAPI with:
 //
 //   - a text slog.Logger writing to stdout at debug level
 //   - an HTTPCompletionDebugger that logs HTTP request/response metadata
+//   - the cassette testdata/cassettes/<cassetteName>.json, if cassetteName is
+//     set; see exampleConn
+//
+// The first of keyEnvs that is set is the live API key. It is only used when
+// cassetteModeEnv asks for recording or live calls; otherwise the examples
+// replay their cassette, so they run offline in CI even where keys are set.
 //
 // The examples reuse this helper to keep them short.
-func newProviderSetWithDebug(level slog.Level) (*inference.ProviderSetAPI, error) {
+func newProviderSetWithDebug(
+	level slog.Level,
+	cassetteName string,
+	keyEnvs ...string,
+) (*inference.ProviderSetAPI, *exampleConn, error) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: level,
 	}))
 	slog.SetDefault(logger)
 
-	return inference.NewProviderSetAPI(
+	conn, err := newExampleConn(cassetteName, keyEnvs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ps, err := inference.NewProviderSetAPI(
 		inference.WithLogger(logger),
 		inference.WithDebugClientBuilder(func(p spec.ProviderParam) spec.CompletionDebugger {
 			cfg := &debugclient.DebugConfig{
@@ -49,9 +113,59 @@ func newProviderSetWithDebug(level slog.Level) (*inference.ProviderSetAPI, error
 				// status codes, etc.). Bodies are scrubbed by default.
 				LogToSlog: true,
 			}
-			return debugclient.NewHTTPCompletionDebugger(cfg)
+			if conn.rec == nil {
+				return debugclient.NewHTTPCompletionDebugger(cfg)
+			}
+			return debugclient.Chain(debugclient.NewHTTPCompletionDebugger(cfg), conn.rec)
 		}),
 	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ps, conn, nil
+}
+
+func newExampleConn(cassetteName string, keyEnvs ...string) (*exampleConn, error) {
+	if cassetteName == "" {
+		return &exampleConn{}, nil
+	}
+	path := filepath.Join("testdata", "cassettes", cassetteName+".json")
+
+	var apiKey string
+	for _, env := range keyEnvs {
+		if apiKey = os.Getenv(env); apiKey != "" {
+			break
+		}
+	}
+
+	// A key alone never turns replay off: live calls cost money and need the
+	// mode to ask for them.
+	mode := cassette.ModeReplay
+	switch envMode := os.Getenv(cassetteModeEnv); envMode {
+	case "", string(cassette.ModeReplay):
+	case string(cassette.ModeRecord):
+		if apiKey != "" {
+			mode = cassette.ModeRecord
+		}
+	case cassetteModeLive:
+		if apiKey != "" {
+			return &exampleConn{apiKey: apiKey}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown %s %q", cassetteModeEnv, envMode)
+	}
+	if mode == cassette.ModeReplay {
+		if _, err := os.Stat(path); err != nil {
+			return &exampleConn{}, nil
+		}
+		apiKey = replayAPIKey
+	}
+
+	rec, err := cassette.New(cassette.Config{Path: path, Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+	return &exampleConn{apiKey: apiKey, rec: rec}, nil
 }
 
 func addCatalogModelProvider(
@@ -11,6 +11,8 @@ var (
 	globalLogger *slog.Logger
 )
 
+type loggerKey struct{}
+
 func init() {
 	// Default to a no-op logger so the library is silent unless a logger
 	// is explicitly installed by the caller.
@@ -26,9 +28,10 @@ func Debug(msg string, args ...any) {
 	Default().Debug(msg, args...)
 }
 
-// DebugContext logs at LevelDebug with context using the process-wide logger.
+// DebugContext logs at LevelDebug using the logger carried by ctx, or the
+// process-wide logger if there is none.
 func DebugContext(ctx context.Context, msg string, args ...any) {
-	Default().DebugContext(ctx, msg, args...)
+	FromContext(ctx).DebugContext(ctx, msg, args...)
 }
 
 // Info logs at LevelInfo using the process-wide logger.
@@ -36,9 +39,10 @@ func Info(msg string, args ...any) {
 	Default().Info(msg, args...)
 }
 
-// InfoContext logs at LevelInfo with context using the process-wide logger.
+// InfoContext logs at LevelInfo using the logger carried by ctx, or the
+// process-wide logger if there is none.
 func InfoContext(ctx context.Context, msg string, args ...any) {
-	Default().InfoContext(ctx, msg, args...)
+	FromContext(ctx).InfoContext(ctx, msg, args...)
 }
 
 // Warn logs at LevelWarn using the process-wide logger.
@@ -46,9 +50,10 @@ func Warn(msg string, args ...any) {
 	Default().Warn(msg, args...)
 }
 
-// WarnContext logs at LevelWarn with context using the process-wide logger.
+// WarnContext logs at LevelWarn using the logger carried by ctx, or the
+// process-wide logger if there is none.
 func WarnContext(ctx context.Context, msg string, args ...any) {
-	Default().WarnContext(ctx, msg, args...)
+	FromContext(ctx).WarnContext(ctx, msg, args...)
 }
 
 // Error logs at LevelError using the process-wide logger.
@@ -56,21 +61,23 @@ func Error(msg string, args ...any) {
 	Default().Error(msg, args...)
 }
 
-// ErrorContext logs at LevelError with context using the process-wide logger.
+// ErrorContext logs at LevelError using the logger carried by ctx, or the
+// process-wide logger if there is none.
 func ErrorContext(ctx context.Context, msg string, args ...any) {
-	Default().ErrorContext(ctx, msg, args...)
+	FromContext(ctx).ErrorContext(ctx, msg, args...)
 }
 
-// Log logs at the given level using the process-wide logger.
-// Signature is identical to slog.Log.
+// Log logs at the given level using the logger carried by ctx, or the
+// process-wide logger if there is none. Signature is identical to slog.Log.
 func Log(ctx context.Context, level slog.Level, msg string, args ...any) {
-	Default().Log(ctx, level, msg, args...)
+	FromContext(ctx).Log(ctx, level, msg, args...)
 }
 
-// LogAttrs logs at the given level with pre-built attributes using the
-// process-wide logger. Signature is identical to slog.LogAttrs.
+// LogAttrs logs at the given level with pre-built attributes using the logger
+// carried by ctx, or the process-wide logger if there is none. Signature is
+// identical to slog.LogAttrs.
 func LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
-	Default().LogAttrs(ctx, level, msg, attrs...)
+	FromContext(ctx).LogAttrs(ctx, level, msg, attrs...)
 }
 
 // With returns a logger that includes the supplied key/value pairs as
@@ -87,6 +94,34 @@ func Default() *slog.Logger {
 	return globalLogger
 }
 
+// NewContext returns a copy of ctx carrying logger. The *Context functions of
+// this package log through it. A nil logger leaves ctx unchanged.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	if logger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the process-wide logger if
+// there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return Default()
+}
+
+// OrDiscard returns logger, or a logger discarding everything if it is nil.
+func OrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return logger
+}
+
 // SetDefault sets the process-wide logger, analogous to slog.SetDefault.
 func SetDefault(logger *slog.Logger) {
 	mu.Lock()
@@ -0,0 +1,652 @@
+package ollamachatsdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ollamaOwnedKeys are the request keys built from typed fields, which
+// additional parameters cannot replace.
+var ollamaOwnedKeys = []string{"model", "messages", "tools", "stream"}
+
+// OllamaChatAPI implements CompletionProvider for Ollama's native /api/chat endpoint.
+type OllamaChatAPI struct {
+	ProviderParam *spec.ProviderParam
+	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
+	client        *ollamaClient
+	mu            sync.RWMutex
+}
+
+// NewOllamaChatAPI creates a new instance of the Ollama chat provider.
+func NewOllamaChatAPI(
+	pi spec.ProviderParam,
+	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
+) (*OllamaChatAPI, error) {
+	if pi.Name == "" {
+		return nil, errors.New("ollama chat api LLM: invalid args")
+	}
+	return &OllamaChatAPI{
+		ProviderParam: &pi,
+		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
+	}, nil
+}
+
+func (api *OllamaChatAPI) InitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ProviderParam == nil {
+		api.client = nil
+		return errors.New("ollama chat api LLM: no ProviderParam found")
+	}
+
+	pi := *api.ProviderParam // snapshot under lock
+
+	origin := spec.DefaultOllamaOrigin
+	if pi.Origin != "" {
+		origin = pi.Origin
+	}
+	pathPrefix := strings.TrimSpace(pi.ChatCompletionPathPrefix)
+	if pathPrefix == "" {
+		pathPrefix = spec.DefaultOllamaChatPrefix
+	}
+	providerURL := strings.TrimSuffix(origin, "/") + "/" + strings.TrimPrefix(pathPrefix, "/")
+
+	header := http.Header{}
+	for k, v := range pi.DefaultHeaders {
+		header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	// Local Ollama needs no key; ollama.com and proxies expect a bearer token.
+	if apiKey := strings.TrimSpace(pi.APIKey); apiKey != "" {
+		keyHeader := pi.APIKeyHeaderKey
+		if keyHeader == "" {
+			keyHeader = spec.DefaultAuthorizationHeaderKey
+		}
+		if strings.EqualFold(keyHeader, spec.DefaultAuthorizationHeaderKey) {
+			header.Set(keyHeader, "Bearer "+apiKey)
+		} else {
+			header.Set(keyHeader, apiKey)
+		}
+	}
+
+	httpClient := &http.Client{}
+	if api.debugger != nil {
+		if c := api.debugger.HTTPClient(httpClient); c != nil {
+			httpClient = c
+		}
+	}
+
+	api.client = &ollamaClient{httpClient: httpClient, url: providerURL, header: header}
+	api.logger.Info(
+		"ollama chat api LLM provider initialized",
+		"name", string(pi.Name),
+		"URL", providerURL,
+	)
+	return nil
+}
+
+func (api *OllamaChatAPI) DeInitLLM(ctx context.Context) error {
+	api.mu.Lock()
+	var name spec.ProviderName
+	if api.ProviderParam != nil {
+		name = api.ProviderParam.Name
+	}
+	api.client = nil
+	api.mu.Unlock()
+	api.logger.Info(
+		"ollama chat api LLM: provider de initialized",
+		"name",
+		string(name),
+	)
+	return nil
+}
+
+func (api *OllamaChatAPI) GetProviderInfo(ctx context.Context) *spec.ProviderParam {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.ProviderParam == nil {
+		return nil
+	}
+	cp := *api.ProviderParam
+	cp.DefaultHeaders = sdkutil.CloneStringMap(cp.DefaultHeaders)
+	return &cp
+}
+
+// IsConfigured reports true once the provider has a ProviderParam: a local Ollama server needs no API key.
+func (api *OllamaChatAPI) IsConfigured(ctx context.Context) bool {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.ProviderParam != nil
+}
+
+func (api *OllamaChatAPI) SetProviderAPIKey(ctx context.Context, apiKey string) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.ProviderParam == nil {
+		return errors.New("ollama chat api LLM: no ProviderParam found")
+	}
+	// Allow empty to clear.
+	api.ProviderParam.APIKey = strings.TrimSpace(apiKey)
+
+	return nil
+}
+
+func (api *OllamaChatAPI) GetProviderCapability(ctx context.Context) (spec.ModelCapabilities, error) {
+	return ollamachatsdkCapability, nil
+}
+
+// clientSnapshot returns the client and a copy of the ProviderParam. The client is initialized on first use, so a
+// provider that never had an API key set works against a local server.
+func (api *OllamaChatAPI) clientSnapshot(ctx context.Context) (*ollamaClient, spec.ProviderParam, error) {
+	api.mu.RLock()
+	client := api.client
+	api.mu.RUnlock()
+	if client == nil {
+		if err := api.InitLLM(ctx); err != nil {
+			return nil, spec.ProviderParam{}, err
+		}
+	}
+
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	if api.client == nil || api.ProviderParam == nil {
+		return nil, spec.ProviderParam{}, errors.New("ollama chat api LLM: client not initialized")
+	}
+	return api.client, *api.ProviderParam, nil
+}
+
+func (api *OllamaChatAPI) FetchCompletion(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	client, pi, err := api.clientSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	call, err := buildOllamaCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *ollamaChatResponse
+		apiErr         error
+	)
+	if call.stream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(ctx, client, pi.Name, req.ModelParam.Name, call, opts)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, call)
+	}
+
+	if apiErr != nil {
+		apiErr = ollamaProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		// The raw final response carries the model load status: done_reason and load_duration.
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CompileRequest builds the /api/chat request for a completion and returns it without sending it. The request goes
+// through the debugger's HTTP client, as in FetchCompletion.
+func (api *OllamaChatAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	client, pi, err := api.clientSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	call, err := buildOllamaCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compile what a streaming caller would send, as the other adapters do.
+	body := call.body
+	if call.req.ModelParam.Stream && !call.stream {
+		if body, _, err = call.encode(true); err != nil {
+			return nil, err
+		}
+	}
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	resp, err := client.post(captureCtx, capture.HTTPClient(api.debugger), body)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+
+	compiled, err := capture.CompiledRequest(err, pi.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// ollamaCall is a normalized request together with the /api/chat body built
+// from it.
+type ollamaCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	params            ollamaChatRequest
+	body              []byte
+	stream            bool
+	timeout           time.Duration
+	toolChoiceNameMap map[string]spec.ToolChoice
+	warns             []spec.Warning
+}
+
+// buildOllamaCall normalizes a request against the provider capabilities and
+// builds the /api/chat body for it.
+func buildOllamaCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+) (*ollamaCall, error) {
+	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
+		return nil, errors.New("ollama chat api LLM: empty completion data")
+	}
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
+		ctx, inReq, opts, spec.ProviderSDKTypeOllamaChat, ollamachatsdkCapability,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
+
+	msgs, err := toOllamaMessages(ctx, req.ModelParam.SystemPrompt, req.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	params := ollamaChatRequest{
+		Model:    string(req.ModelParam.Name),
+		Messages: msgs,
+		Stream:   stream,
+		Think:    ollamaThink(req.ModelParam.Name, req.ModelParam.Reasoning),
+	}
+
+	options := ollamaOptions{
+		Temperature:      req.ModelParam.Temperature,
+		TopP:             req.ModelParam.TopP,
+		TopK:             req.ModelParam.TopK,
+		Seed:             req.ModelParam.Seed,
+		PresencePenalty:  req.ModelParam.PresencePenalty,
+		FrequencyPenalty: req.ModelParam.FrequencyPenalty,
+		NumPredict:       req.ModelParam.MaxOutputLength,
+		Stop:             req.ModelParam.StopSequences,
+	}
+	if options.NumPredict > 0 || len(options.Stop) > 0 || options.Temperature != nil || options.TopP != nil ||
+		options.TopK != nil || options.Seed != nil || options.PresencePenalty != nil ||
+		options.FrequencyPenalty != nil {
+		params.Options = &options
+	}
+
+	if op := req.ModelParam.OutputParam; op != nil && op.Format != nil &&
+		op.Format.Kind == spec.OutputFormatKindJSONSchema {
+		if op.Format.JSONSchemaParam == nil || len(op.Format.JSONSchemaParam.Schema) == 0 {
+			return nil, errors.New("ollama: outputParam.format=jsonSchema requires jsonSchemaParam.schema")
+		}
+		params.Format = op.Format.JSONSchemaParam.Schema
+	}
+
+	tools, toolChoiceNameMap := toolChoicesToOllamaTools(req.ToolChoices)
+	params.Tools = tools
+
+	timeout := spec.DefaultAPITimeout
+	if req.ModelParam.Timeout > 0 {
+		timeout = time.Duration(req.ModelParam.Timeout) * time.Second
+	}
+
+	call := &ollamaCall{
+		req:               req,
+		capabilities:      caps,
+		params:            params,
+		stream:            stream,
+		timeout:           timeout,
+		toolChoiceNameMap: toolChoiceNameMap,
+	}
+	body, extraWarns, err := call.encode(stream)
+	if err != nil {
+		return nil, err
+	}
+	call.body = body
+	call.warns = append(warns, extraWarns...)
+	return call, nil
+}
+
+// encode returns the request body for the given stream mode. Ollama-native
+// knobs such as keep_alive and options.num_ctx come in as additional
+// parameters and are merged over the typed fields.
+func (c *ollamaCall) encode(stream bool) ([]byte, []spec.Warning, error) {
+	params := c.params
+	params.Stream = stream
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sdkutil.MergeAdditionalParameters(body, c.req.ModelParam.AdditionalParametersRawJSON, ollamaOwnedKeys...)
+}
+
+// ollamaThink maps reasoning to the think field. gpt-oss models take a level;
+// other thinking models only switch thinking on or off.
+func ollamaThink(model spec.ModelName, r *spec.ReasoningParam) any {
+	if r == nil {
+		return nil
+	}
+	switch r.Type {
+	case spec.ReasoningTypeSingleWithLevels:
+		switch r.Level {
+		case spec.ReasoningLevelNone:
+			return false
+		case spec.ReasoningLevelLow, spec.ReasoningLevelMedium, spec.ReasoningLevelHigh:
+			if strings.HasPrefix(strings.ToLower(string(model)), "gpt-oss") {
+				return string(r.Level)
+			}
+			return true
+		default:
+			return nil
+		}
+	case spec.ReasoningTypeHybridWithTokens:
+		return r.Tokens > 0
+	default:
+		return nil
+	}
+}
+
+func (api *OllamaChatAPI) doNonStreaming(
+	ctx context.Context,
+	client *ollamaClient,
+	call *ollamaCall,
+) (*spec.FetchCompletionResponse, *ollamaChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	ctx, cancel := context.WithTimeout(ctx, call.timeout)
+	defer cancel()
+
+	httpResp, err := client.post(ctx, nil, call.body)
+	if err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	var raw ollamaChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&raw); err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+	if raw.Error != "" {
+		err := &ollamaStreamError{Message: raw.Error}
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, &raw, err
+	}
+	ensureOllamaToolCallIDs(raw.Message.ToolCalls)
+
+	resp.Usage = usageFromOllamaResponse(&raw)
+	resp.Outputs = outputsFromOllamaResponse(&raw, call.toolChoiceNameMap)
+	resp.StopReason = stopReasonFromOllamaResponse(&raw)
+	return resp, &raw, nil
+}
+
+func (api *OllamaChatAPI) doStreaming(
+	ctx context.Context,
+	client *ollamaClient,
+	providerName spec.ProviderName,
+	modelName spec.ModelName,
+	call *ollamaCall,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, *ollamaChatResponse, error) {
+	resp := &spec.FetchCompletionResponse{}
+	ctx, cancel := context.WithTimeout(ctx, call.timeout)
+	defer cancel()
+
+	httpResp, err := client.post(ctx, nil, call.body)
+	if err != nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+		return resp, nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newOllamaStreamEvents(emitter, call.toolChoiceNameMap)
+	reader := newNDJSONReader(httpResp.Body)
+
+	var (
+		readErr        error
+		streamWriteErr error
+	)
+	streamStartedAt := time.Now()
+	lastEventAt := streamStartedAt
+	eventCount := 0
+	sawDone := false
+
+	for !sawDone {
+		chunk, err := reader.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				readErr = err
+			}
+			break
+		}
+		eventCount++
+		lastEventAt = time.Now()
+		sawDone = chunk.Done
+
+		// If downstream write failed (client disconnect, etc.), stop consuming the stream.
+		if streamWriteErr = events.handle(chunk); streamWriteErr != nil {
+			break
+		}
+	}
+
+	flushErr := emitter.Close()
+
+	full := events.response()
+	if !sawDone && readErr == nil && streamWriteErr == nil {
+		streamWriteErr = errors.New("ollama stream ended before the done line")
+	}
+	if readErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(usageFromOllamaResponse(full), stopReasonFromOllamaResponse(full))
+	}
+
+	streamErr := errors.Join(readErr, streamWriteErr, flushErr)
+	if streamErr != nil {
+		logutil.ErrorContext(
+			ctx,
+			"ollama chat stream terminated",
+			"provider", string(providerName),
+			"model", string(modelName),
+			"duration", time.Since(streamStartedAt),
+			"lastEventAgo", time.Since(lastEventAt),
+			"eventCount", eventCount,
+			"sawDone", sawDone,
+			"readErr", readErr,
+			"streamWriteErr", streamWriteErr,
+			"flushErr", flushErr,
+			"contextErr", ctx.Err(),
+		)
+	}
+
+	resp.Usage = usageFromOllamaResponse(full)
+	if streamErr != nil {
+		resp.Error = &spec.Error{Message: streamErr.Error()}
+	}
+	resp.Outputs = outputsFromOllamaResponse(full, call.toolChoiceNameMap)
+	resp.StopReason = stopReasonFromOllamaResponse(full)
+	return resp, full, streamErr
+}
+
+func outputsFromOllamaResponse(
+	r *ollamaChatResponse,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
+	if r == nil {
+		return nil
+	}
+
+	var outs []spec.OutputUnion
+	status := spec.StatusCompleted
+	if r.DoneReason == "length" {
+		status = spec.StatusIncomplete
+	}
+
+	if strings.TrimSpace(r.Message.Thinking) != "" {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindReasoningMessage,
+			ReasoningMessage: &spec.ReasoningContent{
+				Role:     spec.RoleAssistant,
+				Status:   status,
+				Thinking: []string{r.Message.Thinking},
+			},
+		})
+	}
+
+	if strings.TrimSpace(r.Message.Content) != "" {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				Role:   spec.RoleAssistant,
+				Status: status,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: r.Message.Content},
+				}},
+			},
+		})
+	}
+
+	for _, tc := range r.Message.ToolCalls {
+		name := strings.TrimSpace(tc.Function.Name)
+		choice, ok := toolChoiceNameMap[name]
+		if name == "" || !ok {
+			continue
+		}
+
+		call := spec.ToolCall{
+			ChoiceID:  choice.ID,
+			Type:      choice.Type,
+			Role:      spec.RoleAssistant,
+			ID:        tc.ID,
+			CallID:    tc.ID,
+			Name:      name,
+			Arguments: ollamaToolCallArguments(tc),
+			Status:    spec.StatusCompleted,
+		}
+		if choice.Type == spec.ToolTypeCustom {
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindCustomToolCall, CustomToolCall: &call})
+		} else {
+			call.Type = spec.ToolTypeFunction
+			outs = append(outs, spec.OutputUnion{Kind: spec.OutputKindFunctionToolCall, FunctionToolCall: &call})
+		}
+	}
+
+	return outs
+}
+
+// stopReasonFromOllamaResponse normalizes done_reason. Ollama reports "stop"
+// for tool calls and stop sequences alike, so tool use is told apart by the
+// calls in the message. "load" and "unload" mean no generation happened.
+func stopReasonFromOllamaResponse(r *ollamaChatResponse) *spec.StopReason {
+	if r == nil || !r.Done {
+		return nil
+	}
+	out := &spec.StopReason{Raw: r.DoneReason}
+	switch r.DoneReason {
+	case "stop", "":
+		out.Kind = spec.StopReasonEndTurn
+		if len(r.Message.ToolCalls) > 0 {
+			out.Kind = spec.StopReasonToolUse
+		}
+	case "length":
+		out.Kind = spec.StopReasonMaxTokens
+	default:
+		out.Kind = spec.StopReasonOther
+	}
+	return out
+}
+
+func usageFromOllamaResponse(r *ollamaChatResponse) *spec.Usage {
+	uOut := &spec.Usage{}
+	if r == nil {
+		return uOut
+	}
+	// Ollama does not report cached prompt tokens or a separate reasoning count.
+	uOut.InputTokensTotal = r.PromptEvalCount
+	uOut.InputTokensUncached = r.PromptEvalCount
+	uOut.OutputTokens = r.EvalCount
+	return uOut
+}
+
+// ollamaToolCallArguments returns the arguments of a call as a JSON string.
+func ollamaToolCallArguments(tc ollamaToolCall) string {
+	args := strings.TrimSpace(string(tc.Function.Arguments))
+	if args == "" || args == "null" {
+		return "{}"
+	}
+	return args
+}
+
+// ensureOllamaToolCallIDs assigns IDs to tool calls that have none. Older
+// Ollama versions do not return call IDs, but spec tool calls need one.
+func ensureOllamaToolCallIDs(calls []ollamaToolCall) {
+	for i := range calls {
+		if strings.TrimSpace(calls[i].ID) == "" {
+			calls[i].ID = "call_" + strings.ToLower(rand.Text())
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package ollamachatsdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestBuildOllamaCallBody(t *testing.T) {
+	t.Parallel()
+
+	extra := `{"keep_alive":"10m","options":{"num_ctx":16384,"seed":7},"model":"other"}`
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{
+			Name:            "gpt-oss:20b",
+			MaxOutputLength: 512,
+			Temperature:     new(0.3),
+			StopSequences:   []string{"END"},
+			Reasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelMedium,
+			},
+			OutputParam: &spec.OutputParam{Format: &spec.OutputFormat{
+				Kind: spec.OutputFormatKindJSONSchema,
+				JSONSchemaParam: &spec.JSONSchemaParam{
+					Name:   "answer",
+					Schema: map[string]any{"type": "object"},
+				},
+			}},
+			AdditionalParametersRawJSON: &extra,
+		},
+		Inputs: []spec.InputUnion{userText("hi")},
+		ToolChoices: []spec.ToolChoice{{
+			Type: spec.ToolTypeFunction,
+			ID:   "tc-1",
+			Name: "lookup",
+		}},
+	}
+
+	call, err := buildOllamaCall(t.Context(), req, nil, nil)
+	if err != nil {
+		t.Fatalf("buildOllamaCall: %v", err)
+	}
+	if call.stream {
+		t.Fatal("call streams without a stream handler")
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(call.body, &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	want := map[string]any{
+		"model":      "gpt-oss:20b",
+		"stream":     false,
+		"think":      "medium",
+		"keep_alive": "10m",
+		"format":     map[string]any{"type": "object"},
+		"options": map[string]any{
+			"temperature": 0.3,
+			"num_predict": float64(512),
+			"stop":        []any{"END"},
+			"num_ctx":     float64(16384),
+			"seed":        float64(7),
+		},
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(body[k], v) {
+			t.Errorf("body[%q] = %#v, want %#v", k, body[k], v)
+		}
+	}
+	tools, _ := body["tools"].([]any)
+	if len(tools) != 1 {
+		t.Fatalf("tools = %#v", body["tools"])
+	}
+
+	var dropped bool
+	for _, w := range call.warns {
+		if w.Code == "additional_parameter_owned_key_dropped" {
+			dropped = true
+		}
+	}
+	if !dropped {
+		t.Errorf("warnings = %+v, want the owned model key reported", call.warns)
+	}
+}
+
+func TestStopReasonFromOllamaResponse(t *testing.T) {
+	t.Parallel()
+
+	toolCall := []ollamaToolCall{{ID: "call_1", Function: ollamaToolFunction{Name: "f"}}}
+	tests := []struct {
+		name string
+		resp *ollamaChatResponse
+		want *spec.StopReason
+	}{
+		{name: "not done", resp: &ollamaChatResponse{}, want: nil},
+		{
+			name: "stop",
+			resp: &ollamaChatResponse{Done: true, DoneReason: "stop"},
+			want: &spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "stop"},
+		},
+		{
+			name: "stop with tool calls",
+			resp: &ollamaChatResponse{Done: true, DoneReason: "stop", Message: ollamaMessage{ToolCalls: toolCall}},
+			want: &spec.StopReason{Kind: spec.StopReasonToolUse, Raw: "stop"},
+		},
+		{
+			name: "length",
+			resp: &ollamaChatResponse{Done: true, DoneReason: "length"},
+			want: &spec.StopReason{Kind: spec.StopReasonMaxTokens, Raw: "length"},
+		},
+		{
+			name: "load",
+			resp: &ollamaChatResponse{Done: true, DoneReason: "load"},
+			want: &spec.StopReason{Kind: spec.StopReasonOther, Raw: "load"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := stopReasonFromOllamaResponse(tt.resp)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaChatAPIWithoutAPIKey(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(
+			w,
+			`{"model":"m","message":{"role":"assistant","content":"hi"},"done":true,"done_reason":"stop"}`,
+		)
+	}))
+	defer srv.Close()
+
+	api, err := NewOllamaChatAPI(spec.ProviderParam{Name: "ollama", Origin: srv.URL}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !api.IsConfigured(t.Context()) {
+		t.Fatal("IsConfigured() = false without an API key")
+	}
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs:     []spec.InputUnion{userText("hi")},
+	}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() without an API key: %v", err)
+	}
+
+	if err := api.SetProviderAPIKey(t.Context(), "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.InitLLM(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.FetchCompletion(t.Context(), req, nil); err != nil {
+		t.Fatalf("FetchCompletion() with an API key: %v", err)
+	}
+
+	if want := []string{"", "Bearer secret"}; !reflect.DeepEqual(gotAuth, want) {
+		t.Fatalf("Authorization headers = %q, want %q", gotAuth, want)
+	}
+}
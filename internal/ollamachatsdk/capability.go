@@ -0,0 +1,51 @@
+package ollamachatsdk
+
+import "github.com/flexigpt/inference-go/spec"
+
+var ollamachatsdkCapability = spec.ModelCapabilities{
+	ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityImageIn},
+	ModalitiesOut: []spec.Modality{spec.ModalityTextOut},
+
+	ReasoningCapabilities: &spec.ReasoningCapabilities{
+		SupportsReasoningConfig: true,
+		SupportedReasoningTypes: []spec.ReasoningType{spec.ReasoningTypeSingleWithLevels},
+		SupportedReasoningLevels: []spec.ReasoningLevel{
+			spec.ReasoningLevelNone,
+			spec.ReasoningLevelLow,
+			spec.ReasoningLevelMedium,
+			spec.ReasoningLevelHigh,
+		},
+		SupportsSummaryStyle: false,
+
+		SupportsEncryptedReasoningInput:  false,
+		TemperatureDisallowedWhenEnabled: false,
+	},
+
+	StopSequenceCapabilities: &spec.StopSequenceCapabilities{
+		IsSupported:             true,
+		DisallowedWithReasoning: false,
+	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:             true,
+		SupportsTopK:             true,
+		SupportsSeed:             true,
+		SupportsPresencePenalty:  true,
+		SupportsFrequencyPenalty: true,
+	},
+	OutputCapabilities: &spec.OutputCapabilities{
+		SupportedOutputFormats: []spec.OutputFormatKind{spec.OutputFormatKindText, spec.OutputFormatKindJSONSchema},
+		SupportsVerbosity:      false,
+	},
+
+	ToolCapabilities: &spec.ToolCapabilities{
+		SupportedToolTypes: []spec.ToolType{spec.ToolTypeFunction, spec.ToolTypeCustom},
+
+		// /api/chat has no tool_choice; the model always decides.
+		SupportedToolPolicyModes:  []spec.ToolPolicyMode{spec.ToolPolicyModeAuto},
+		SupportsParallelToolCalls: false,
+		MaxForcedTools:            0,
+		SupportedClientToolOutputFormats: []spec.ToolOutputFormatKind{
+			spec.ToolOutputFormatKindString,
+		},
+	},
+}
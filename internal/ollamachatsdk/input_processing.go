@@ -0,0 +1,308 @@
+package ollamachatsdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	ollamaRoleSystem    = "system"
+	ollamaRoleUser      = "user"
+	ollamaRoleAssistant = "assistant"
+	ollamaRoleTool      = "tool"
+)
+
+var errEmptyInputPart = errors.New("empty input part")
+
+// toOllamaMessages converts a system prompt and a slice of spec InputUnion
+// items into /api/chat messages.
+//
+// An assistant turn may carry thinking, text and tool calls at once, so
+// adjacent assistant items are merged into a single message. User and tool
+// messages are kept as they are.
+func toOllamaMessages(
+	ctx context.Context,
+	systemPrompt string,
+	inputs []spec.InputUnion,
+) ([]ollamaMessage, error) {
+	var out []ollamaMessage
+	if s := strings.TrimSpace(systemPrompt); s != "" {
+		out = append(out, ollamaMessage{Role: ollamaRoleSystem, Content: s})
+	}
+
+	for _, in := range inputs {
+		if sdkutil.IsInputUnionEmpty(in) {
+			continue
+		}
+
+		msg, err := inputUnionToOllamaMessage(ctx, in)
+		if err != nil {
+			if errors.Is(err, errEmptyInputPart) {
+				continue
+			}
+			return nil, err
+		}
+
+		if msg.Role == ollamaRoleAssistant && len(out) > 0 && out[len(out)-1].Role == ollamaRoleAssistant {
+			mergeOllamaAssistantMessage(&out[len(out)-1], msg)
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+// inputUnionToOllamaMessage converts a single InputUnion to a chat message.
+func inputUnionToOllamaMessage(ctx context.Context, in spec.InputUnion) (ollamaMessage, error) {
+	switch in.Kind {
+	case spec.InputKindInputMessage:
+		if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		msg := contentItemsToOllamaMessage(ctx, ollamaRoleUser, in.InputMessage.Contents)
+		if msg.Content == "" && len(msg.Images) == 0 {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		return msg, nil
+
+	case spec.InputKindOutputMessage:
+		if in.OutputMessage == nil || in.OutputMessage.Role != spec.RoleAssistant {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		msg := contentItemsToOllamaMessage(ctx, ollamaRoleAssistant, in.OutputMessage.Contents)
+		if msg.Content == "" {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		// Ollama does not read images back from assistant turns.
+		msg.Images = nil
+		return msg, nil
+
+	case spec.InputKindReasoningMessage:
+		if in.ReasoningMessage == nil {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		thinking := strings.TrimSpace(strings.Join(in.ReasoningMessage.Thinking, "\n"))
+		if thinking == "" {
+			// Redacted or encrypted reasoning cannot be replayed to a local model.
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		return ollamaMessage{Role: ollamaRoleAssistant, Thinking: thinking}, nil
+
+	case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
+		var call *spec.ToolCall
+		switch {
+		case in.FunctionToolCall != nil:
+			call = in.FunctionToolCall
+		case in.CustomToolCall != nil:
+			call = in.CustomToolCall
+		}
+		tc, err := toolCallToOllama(call)
+		if err != nil {
+			return ollamaMessage{}, err
+		}
+		return ollamaMessage{Role: ollamaRoleAssistant, ToolCalls: []ollamaToolCall{tc}}, nil
+
+	case spec.InputKindFunctionToolOutput, spec.InputKindCustomToolOutput:
+		var output *spec.ToolOutput
+		switch {
+		case in.FunctionToolOutput != nil:
+			output = in.FunctionToolOutput
+		case in.CustomToolOutput != nil:
+			output = in.CustomToolOutput
+		}
+		if output == nil {
+			return ollamaMessage{}, errEmptyInputPart
+		}
+		if strings.TrimSpace(output.CallID) == "" {
+			return ollamaMessage{}, errors.New("ollama: tool output is missing callID")
+		}
+		if strings.TrimSpace(output.Name) == "" {
+			return ollamaMessage{}, errors.New("ollama: tool output is missing name")
+		}
+		return toolOutputToOllamaMessage(output), nil
+
+	case spec.InputKindWebSearchToolCall, spec.InputKindWebSearchToolOutput:
+		// Ollama has no server-side web search.
+		return ollamaMessage{}, errEmptyInputPart
+
+	default:
+		return ollamaMessage{}, errEmptyInputPart
+	}
+}
+
+// contentItemsToOllamaMessage joins the text items of a message and collects
+// its images as base64 data.
+func contentItemsToOllamaMessage(
+	ctx context.Context,
+	role string,
+	items []spec.InputOutputContentItemUnion,
+) ollamaMessage {
+	msg := ollamaMessage{Role: role}
+	var texts []string
+	for _, it := range items {
+		switch it.Kind {
+		case spec.ContentItemKindText:
+			if it.TextItem != nil {
+				if s := strings.TrimSpace(it.TextItem.Text); s != "" {
+					texts = append(texts, s)
+				}
+			}
+
+		case spec.ContentItemKindImage:
+			if img := contentItemImageToOllama(ctx, it.ImageItem); img != "" {
+				msg.Images = append(msg.Images, img)
+			}
+
+		case spec.ContentItemKindRefusal:
+			// Refusals are model outputs; not a meaningful input representation.
+
+		default:
+			logutil.DebugContext(ctx, "ollama: unsupported content item kind for message", "kind", it.Kind)
+		}
+	}
+	msg.Content = strings.Join(texts, "\n\n")
+	return msg
+}
+
+// contentItemImageToOllama returns the base64 data of an image. Ollama does
+// not fetch images, so URL-only images are skipped.
+func contentItemImageToOllama(ctx context.Context, imageItem *spec.ContentItemImage) string {
+	if imageItem == nil {
+		return ""
+	}
+	data := strings.TrimSpace(imageItem.ImageData)
+	if data == "" {
+		if imageItem.ImageURL != "" {
+			logutil.DebugContext(ctx, "ollama: image URLs are not supported, skipping image", "id", imageItem.ID)
+		}
+		return ""
+	}
+	// Tolerate data URLs; Ollama wants the bare payload.
+	if rest, ok := strings.CutPrefix(data, "data:"); ok {
+		if _, payload, found := strings.Cut(rest, ","); found {
+			data = payload
+		}
+	}
+	if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+		if _, err := base64.RawStdEncoding.DecodeString(data); err != nil {
+			logutil.DebugContext(ctx, "ollama: failed to decode base64 image data", "id", imageItem.ID, "err", err)
+			return ""
+		}
+	}
+	return data
+}
+
+// toolCallToOllama converts a ToolCall from the conversation history. Ollama
+// expects the arguments as a JSON object.
+func toolCallToOllama(call *spec.ToolCall) (ollamaToolCall, error) {
+	if call == nil {
+		return ollamaToolCall{}, errEmptyInputPart
+	}
+	name := strings.TrimSpace(call.Name)
+	if name == "" {
+		return ollamaToolCall{}, errors.New("ollama: tool call is missing function name")
+	}
+
+	args := json.RawMessage("{}")
+	if a := strings.TrimSpace(call.Arguments); a != "" {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(a), &obj); err != nil {
+			return ollamaToolCall{}, fmt.Errorf("ollama: tool call %q has invalid JSON arguments: %w", name, err)
+		}
+		args = json.RawMessage(a)
+	}
+
+	id := strings.TrimSpace(call.CallID)
+	if id == "" {
+		id = strings.TrimSpace(call.ID)
+	}
+	return ollamaToolCall{ID: id, Function: ollamaToolFunction{Name: name, Arguments: args}}, nil
+}
+
+// toolOutputToOllamaMessage converts a ToolOutput to a tool message. Outputs
+// are already collapsed to text by normalization; Ollama matches them to calls
+// by tool name.
+func toolOutputToOllamaMessage(output *spec.ToolOutput) ollamaMessage {
+	var texts []string
+	for _, c := range output.Contents {
+		if c.Kind == spec.ContentItemKindText && c.TextItem != nil {
+			if t := strings.TrimSpace(c.TextItem.Text); t != "" {
+				texts = append(texts, t)
+			}
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if output.IsError && text == "" {
+		text = "tool call failed"
+	}
+	return ollamaMessage{
+		Role:     ollamaRoleTool,
+		Content:  text,
+		ToolName: strings.TrimSpace(output.Name),
+	}
+}
+
+func mergeOllamaAssistantMessage(dst *ollamaMessage, src ollamaMessage) {
+	if src.Thinking != "" {
+		if dst.Thinking != "" {
+			dst.Thinking += "\n"
+		}
+		dst.Thinking += src.Thinking
+	}
+	if src.Content != "" {
+		if dst.Content != "" {
+			dst.Content += "\n\n"
+		}
+		dst.Content += src.Content
+	}
+	dst.ToolCalls = append(dst.ToolCalls, src.ToolCalls...)
+}
+
+// toolChoicesToOllamaTools converts function and custom tool choices into
+// /api/chat tool definitions.
+func toolChoicesToOllamaTools(toolChoices []spec.ToolChoice) ([]ollamaTool, map[string]spec.ToolChoice) {
+	if len(toolChoices) == 0 {
+		return nil, nil
+	}
+
+	ordered, nameMap := sdkutil.BuildToolChoiceNameMapping(toolChoices)
+	out := make([]ollamaTool, 0, len(ordered))
+	for _, tw := range ordered {
+		tc := tw.Choice
+		switch tc.Type {
+		case spec.ToolTypeFunction, spec.ToolTypeCustom:
+			if tw.Name == "" {
+				continue
+			}
+			srcArgs := tc.Arguments
+			if srcArgs == nil {
+				srcArgs = sdkutil.EmptyJSONArgs
+			}
+			params := make(map[string]any, len(srcArgs))
+			maps.Copy(params, srcArgs)
+			out = append(out, ollamaTool{
+				Type: "function",
+				Function: ollamaToolDef{
+					Name:        tw.Name,
+					Description: sdkutil.ToolDescription(tc),
+					Parameters:  params,
+				},
+			})
+		default:
+			// Web search is not available through /api/chat.
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nameMap
+}
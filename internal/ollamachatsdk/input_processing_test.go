@@ -0,0 +1,201 @@
+package ollamachatsdk
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestToOllamaMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		systemPrompt string
+		inputs       []spec.InputUnion
+		want         []ollamaMessage
+		wantErr      bool
+	}{
+		{
+			name:         "merges an assistant turn and answers tools by name",
+			systemPrompt: "You are helpful.",
+			inputs: []spec.InputUnion{
+				userText("Weather in SF?"),
+				reasoning("Need the weather tool."),
+				assistantText("Let me check."),
+				functionToolCall("call_1", "get_weather", `{"city":"SF"}`),
+				functionToolOutput("call_1", "get_weather", "72F and sunny"),
+			},
+			want: []ollamaMessage{
+				{Role: ollamaRoleSystem, Content: "You are helpful."},
+				{Role: ollamaRoleUser, Content: "Weather in SF?"},
+				{
+					Role:     ollamaRoleAssistant,
+					Content:  "Let me check.",
+					Thinking: "Need the weather tool.",
+					ToolCalls: []ollamaToolCall{{
+						ID:       "call_1",
+						Function: ollamaToolFunction{Name: "get_weather", Arguments: []byte(`{"city":"SF"}`)},
+					}},
+				},
+				{Role: ollamaRoleTool, Content: "72F and sunny", ToolName: "get_weather"},
+			},
+		},
+		{
+			name: "images as bare base64 and URL images skipped",
+			inputs: []spec.InputUnion{{
+				Kind: spec.InputKindInputMessage,
+				InputMessage: &spec.InputOutputContent{
+					Role: spec.RoleUser,
+					Contents: []spec.InputOutputContentItemUnion{
+						{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: "What is this?"}},
+						{Kind: spec.ContentItemKindImage, ImageItem: &spec.ContentItemImage{ImageData: "aGVsbG8="}},
+						{
+							Kind:      spec.ContentItemKindImage,
+							ImageItem: &spec.ContentItemImage{ImageData: "data:image/png;base64,d29ybGQ="},
+						},
+						{
+							Kind:      spec.ContentItemKindImage,
+							ImageItem: &spec.ContentItemImage{ImageURL: "https://example.com/a.png"},
+						},
+					},
+				},
+			}},
+			want: []ollamaMessage{
+				{Role: ollamaRoleUser, Content: "What is this?", Images: []string{"aGVsbG8=", "d29ybGQ="}},
+			},
+		},
+		{
+			name: "empty tool arguments become an object",
+			inputs: []spec.InputUnion{
+				functionToolCall("call_1", "now", ""),
+			},
+			want: []ollamaMessage{{
+				Role: ollamaRoleAssistant,
+				ToolCalls: []ollamaToolCall{{
+					ID:       "call_1",
+					Function: ollamaToolFunction{Name: "now", Arguments: []byte(`{}`)},
+				}},
+			}},
+		},
+		{
+			name:    "tool output without name",
+			inputs:  []spec.InputUnion{functionToolOutput("call_1", "", "x")},
+			wantErr: true,
+		},
+		{
+			name:    "tool call with non JSON arguments",
+			inputs:  []spec.InputUnion{functionToolCall("call_1", "f", "not json")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := toOllamaMessages(t.Context(), tt.systemPrompt, tt.inputs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("toOllamaMessages: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("messages\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOllamaThink(t *testing.T) {
+	t.Parallel()
+
+	level := func(l spec.ReasoningLevel) *spec.ReasoningParam {
+		return &spec.ReasoningParam{Type: spec.ReasoningTypeSingleWithLevels, Level: l}
+	}
+	tests := []struct {
+		name  string
+		model spec.ModelName
+		r     *spec.ReasoningParam
+		want  any
+	}{
+		{name: "unset", model: "qwen3:8b", r: nil, want: nil},
+		{name: "none", model: "qwen3:8b", r: level(spec.ReasoningLevelNone), want: false},
+		{name: "toggle model", model: "qwen3:8b", r: level(spec.ReasoningLevelHigh), want: true},
+		{name: "gpt-oss level", model: "gpt-oss:20b", r: level(spec.ReasoningLevelLow), want: "low"},
+		{name: "gpt-oss none", model: "gpt-oss:20b", r: level(spec.ReasoningLevelNone), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ollamaThink(tt.model, tt.r); got != tt.want {
+				t.Fatalf("think = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func userText(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
+
+func assistantText(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindOutputMessage,
+		OutputMessage: &spec.InputOutputContent{
+			Role: spec.RoleAssistant,
+			Contents: []spec.InputOutputContentItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
+
+func reasoning(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind:             spec.InputKindReasoningMessage,
+		ReasoningMessage: &spec.ReasoningContent{Role: spec.RoleAssistant, Thinking: []string{text}},
+	}
+}
+
+func functionToolCall(callID, name, args string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolCall,
+		FunctionToolCall: &spec.ToolCall{
+			Type:      spec.ToolTypeFunction,
+			Role:      spec.RoleAssistant,
+			CallID:    callID,
+			Name:      name,
+			Arguments: args,
+		},
+	}
+}
+
+func functionToolOutput(callID, name, text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindFunctionToolOutput,
+		FunctionToolOutput: &spec.ToolOutput{
+			Type:   spec.ToolTypeFunction,
+			Role:   spec.RoleTool,
+			CallID: callID,
+			Name:   name,
+			Contents: []spec.ToolOutputItemUnion{
+				{Kind: spec.ContentItemKindText, TextItem: &spec.ContentItemText{Text: text}},
+			},
+		},
+	}
+}
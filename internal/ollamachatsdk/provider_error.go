@@ -0,0 +1,26 @@
+package ollamachatsdk
+
+import (
+	"errors"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ollamaProviderError maps an Ollama API / stream error into a spec.ProviderError.
+// Ollama errors carry only a message, so errors delivered inside the NDJSON stream
+// are classified from their text.
+func ollamaProviderError(provider spec.ProviderName, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var details sdkutil.ProviderErrorDetails
+	var apiErr *ollamaAPIError
+	if errors.As(err, &apiErr) {
+		details.HTTPStatus = apiErr.StatusCode
+		details.Header = apiErr.Header
+	}
+
+	return sdkutil.NewProviderError(provider, err, details)
+}
@@ -0,0 +1,158 @@
+package ollamachatsdk
+
+import (
+	"strings"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ollamaStreamEvents maps /api/chat stream lines to normalized stream events
+// and accumulates them into the full response.
+//
+// Lines carry partial thinking and content, and complete tool calls. Output
+// items are derived: a run of thinking or content is one item, numbered in
+// stream order, and every tool call is an item of its own.
+type ollamaStreamEvents struct {
+	emitter           *sdkutil.StreamEmitter
+	toolChoiceNameMap map[string]spec.ToolChoice
+
+	full            ollamaChatResponse
+	thinking        strings.Builder
+	content         strings.Builder
+	nextOutputIndex int
+	openKind        spec.OutputKind
+	openIndex       int
+}
+
+func newOllamaStreamEvents(
+	emitter *sdkutil.StreamEmitter,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) *ollamaStreamEvents {
+	return &ollamaStreamEvents{
+		emitter:           emitter,
+		toolChoiceNameMap: toolChoiceNameMap,
+	}
+}
+
+func (s *ollamaStreamEvents) handle(chunk *ollamaChatResponse) error {
+	ensureOllamaToolCallIDs(chunk.Message.ToolCalls)
+	s.accumulate(chunk)
+
+	if t := chunk.Message.Thinking; t != "" {
+		if err := s.open(spec.OutputKindReasoningMessage); err != nil {
+			return err
+		}
+		if err := s.emitter.WriteThinking(t); err != nil {
+			return err
+		}
+	}
+	if t := chunk.Message.Content; t != "" {
+		if err := s.open(spec.OutputKindOutputMessage); err != nil {
+			return err
+		}
+		if err := s.emitter.WriteText(t); err != nil {
+			return err
+		}
+	}
+	for _, tc := range chunk.Message.ToolCalls {
+		if err := s.handleToolCall(tc); err != nil {
+			return err
+		}
+	}
+
+	if chunk.Done {
+		return s.closeAll()
+	}
+	return nil
+}
+
+// handleToolCall emits a complete tool call as one item.
+func (s *ollamaStreamEvents) handleToolCall(tc ollamaToolCall) error {
+	if err := s.closeAll(); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(tc.Function.Name)
+	toolType := spec.ToolTypeFunction
+	kind := spec.OutputKindFunctionToolCall
+	if choice, ok := s.toolChoiceNameMap[name]; ok && choice.Type == spec.ToolTypeCustom {
+		toolType = spec.ToolTypeCustom
+		kind = spec.OutputKindCustomToolCall
+	}
+
+	idx := s.nextOutputIndex
+	s.nextOutputIndex++
+	chunk := spec.StreamToolCallChunk{
+		OutputIndex: idx,
+		Type:        toolType,
+		CallID:      tc.ID,
+		Name:        name,
+	}
+	args := ollamaToolCallArguments(tc)
+
+	if err := s.emitter.OutputItemStart(idx, kind, tc.ID); err != nil {
+		return err
+	}
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallStart, chunk); err != nil {
+		return err
+	}
+	delta := chunk
+	delta.ArgumentsDelta = args
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, delta); err != nil {
+		return err
+	}
+	end := chunk
+	end.Arguments = args
+	if err := s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, end); err != nil {
+		return err
+	}
+	return s.emitter.OutputItemStop(idx, kind, tc.ID)
+}
+
+// open starts an item of kind unless one is already open, ending any other.
+func (s *ollamaStreamEvents) open(kind spec.OutputKind) error {
+	if s.openKind == kind {
+		return nil
+	}
+	if err := s.closeAll(); err != nil {
+		return err
+	}
+	s.openKind = kind
+	s.openIndex = s.nextOutputIndex
+	s.nextOutputIndex++
+	return s.emitter.OutputItemStart(s.openIndex, kind, "")
+}
+
+// closeAll ends the open item, if any. It is idempotent.
+func (s *ollamaStreamEvents) closeAll() error {
+	if s.openKind == "" {
+		return nil
+	}
+	kind := s.openKind
+	s.openKind = ""
+	return s.emitter.OutputItemStop(s.openIndex, kind, "")
+}
+
+func (s *ollamaStreamEvents) accumulate(chunk *ollamaChatResponse) {
+	s.thinking.WriteString(chunk.Message.Thinking)
+	s.content.WriteString(chunk.Message.Content)
+	toolCalls := append(s.full.Message.ToolCalls, chunk.Message.ToolCalls...)
+
+	if chunk.Done || s.full.Model == "" {
+		msg := s.full.Message
+		s.full = *chunk
+		s.full.Message = msg
+		if s.full.Message.Role == "" {
+			s.full.Message.Role = chunk.Message.Role
+		}
+	}
+	s.full.Message.Thinking = s.thinking.String()
+	s.full.Message.Content = s.content.String()
+	s.full.Message.ToolCalls = toolCalls
+}
+
+// response returns the response accumulated so far.
+func (s *ollamaStreamEvents) response() *ollamaChatResponse {
+	out := s.full
+	return &out
+}
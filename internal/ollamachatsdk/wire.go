@@ -0,0 +1,181 @@
+package ollamachatsdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ollamaChatRequest is the body of POST /api/chat.
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Format   map[string]any  `json:"format,omitempty"`
+	Options  *ollamaOptions  `json:"options,omitempty"`
+	Stream   bool            `json:"stream"`
+	// Think is a bool, or a level string for models that accept one.
+	Think any `json:"think,omitempty"`
+}
+
+type ollamaOptions struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	TopK             *int     `json:"top_k,omitempty"`
+	Seed             *int64   `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	NumPredict       int      `json:"num_predict,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	ID       string             `json:"id,omitempty"`
+	Function ollamaToolFunction `json:"function"`
+}
+
+type ollamaToolFunction struct {
+	Index     int             `json:"index,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type ollamaTool struct {
+	Type     string        `json:"type"`
+	Function ollamaToolDef `json:"function"`
+}
+
+type ollamaToolDef struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+// ollamaChatResponse is a non-streaming response or one NDJSON stream line.
+// Counts and durations are only set on the final line.
+type ollamaChatResponse struct {
+	Model              string        `json:"model"`
+	CreatedAt          string        `json:"created_at"`
+	Message            ollamaMessage `json:"message"`
+	Done               bool          `json:"done"`
+	DoneReason         string        `json:"done_reason,omitempty"`
+	TotalDuration      int64         `json:"total_duration,omitempty"`
+	LoadDuration       int64         `json:"load_duration,omitempty"`
+	PromptEvalCount    int64         `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64         `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int64         `json:"eval_count,omitempty"`
+	EvalDuration       int64         `json:"eval_duration,omitempty"`
+	Error              string        `json:"error,omitempty"`
+}
+
+// ollamaAPIError is a non-2xx response of the Ollama API.
+type ollamaAPIError struct {
+	StatusCode int
+	Message    string
+	Header     http.Header
+}
+
+func (e *ollamaAPIError) Error() string {
+	return fmt.Sprintf("ollama: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// ollamaStreamError is an error line inside an NDJSON stream.
+type ollamaStreamError struct {
+	Message string
+}
+
+func (e *ollamaStreamError) Error() string {
+	return "ollama stream error: " + e.Message
+}
+
+// ollamaClient sends chat requests to one Ollama endpoint.
+type ollamaClient struct {
+	httpClient *http.Client
+	url        string
+	header     http.Header
+}
+
+// post sends body and returns the response once its status is known. Non-2xx
+// responses are consumed and returned as *ollamaAPIError.
+func (c *ollamaClient) post(ctx context.Context, httpClient *http.Client, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = c.header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	if httpClient == nil {
+		httpClient = c.httpClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	apiErr := &ollamaAPIError{StatusCode: resp.StatusCode, Header: resp.Header}
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+		apiErr.Message = payload.Error
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return nil, apiErr
+}
+
+// ndjsonReader decodes the lines of an NDJSON stream.
+type ndjsonReader struct {
+	r *bufio.Reader
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next line, skipping blank ones, or io.EOF at the end of
+// the stream. An error line is returned as *ollamaStreamError.
+func (n *ndjsonReader) next() (*ollamaChatResponse, error) {
+	for {
+		line, err := n.r.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var chunk ollamaChatResponse
+		if jsonErr := json.Unmarshal(line, &chunk); jsonErr != nil {
+			return nil, fmt.Errorf("ollama stream: invalid line: %w", jsonErr)
+		}
+		if chunk.Error != "" {
+			return nil, &ollamaStreamError{Message: chunk.Error}
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return &chunk, nil
+	}
+}
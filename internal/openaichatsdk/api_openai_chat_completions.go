@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -15,10 +17,15 @@ import (
 	openaiSharedConstant "github.com/openai/openai-go/v3/shared/constant"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/openaisdkutil"
 	"github.com/flexigpt/inference-go/internal/sdkutil"
 	"github.com/flexigpt/inference-go/spec"
 )
 
+// openAIChatOwnedKeys are the request keys built from typed fields, which
+// additional parameters cannot replace.
+var openAIChatOwnedKeys = []string{"model", "messages", "tools", "stream"}
+
 type openAIChatNamedToolParam struct {
 	Name  string
 	Param openai.ChatCompletionToolUnionParam
@@ -28,6 +35,7 @@ type openAIChatNamedToolParam struct {
 type OpenAIChatCompletionsAPI struct {
 	ProviderParam *spec.ProviderParam
 	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
 	client        *openai.Client
 	mu            sync.RWMutex
 }
@@ -35,6 +43,7 @@ type OpenAIChatCompletionsAPI struct {
 func NewOpenAIChatCompletionsAPI(
 	pi spec.ProviderParam,
 	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
 ) (*OpenAIChatCompletionsAPI, error) {
 	if pi.Name == "" {
 		return nil, errors.New("openai chat completions api LLM: invalid args")
@@ -42,6 +51,7 @@ func NewOpenAIChatCompletionsAPI(
 	return &OpenAIChatCompletionsAPI{
 		ProviderParam: &pi,
 		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
 	}, nil
 }
 
@@ -53,7 +63,7 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 		return errors.New("openai chat completion api LLM: no ProviderParam found")
 	}
 	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
-		logutil.Debug(
+		api.logger.Debug(
 			string(
 				api.ProviderParam.Name,
 			) + ": No API key given. Not initializing OpenAIChatCompletionsAPI LLM object",
@@ -63,11 +73,37 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 	}
 
 	pi := *api.ProviderParam // snapshot under lock
-	opts := []option.RequestOption{
+	var httpClient *http.Client
+	if api.debugger != nil {
+		httpClient = api.debugger.HTTPClient(nil)
+	}
+	opts, providerURL := openAIChatClientOptions(pi, httpClient)
+
+	c := openai.NewClient(opts...)
+	api.client = &c
+	api.logger.Info(
+		"openai chat completions api LLM provider initialized",
+		"name",
+		string(pi.Name),
+		"URL",
+		providerURL,
+	)
+	return nil
+}
+
+// openAIChatClientOptions returns the options of a client for pi and the URL it calls. httpClient may be nil.
+func openAIChatClientOptions(
+	pi spec.ProviderParam,
+	httpClient *http.Client,
+) (opts []option.RequestOption, providerURL string) {
+	opts = []option.RequestOption{
 		option.WithAPIKey(pi.APIKey),
+		// Retries belong to the provider set's RetryPolicy; SDK retries would multiply its attempts and hide them
+		// from the debugger.
+		option.WithMaxRetries(0),
 	}
 
-	providerURL := spec.DefaultOpenAIOrigin
+	providerURL = spec.DefaultOpenAIOrigin
 	if pi.Origin != "" {
 		baseURL := strings.TrimSuffix(pi.Origin, "/")
 
@@ -96,22 +132,10 @@ func (api *OpenAIChatCompletionsAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
-		}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
-
-	c := openai.NewClient(opts...)
-	api.client = &c
-	logutil.Info(
-		"openai chat completions api LLM provider initialized",
-		"name",
-		string(pi.Name),
-		"URL",
-		providerURL,
-	)
-	return nil
+	return opts, providerURL
 }
 
 func (api *OpenAIChatCompletionsAPI) DeInitLLM(ctx context.Context) error {
@@ -122,7 +146,7 @@ func (api *OpenAIChatCompletionsAPI) DeInitLLM(ctx context.Context) error {
 	}
 	api.client = nil
 	api.mu.Unlock()
-	logutil.Info(
+	api.logger.Info(
 		"openai chat completions api LLM: provider de initialized",
 		"name",
 		string(name),
@@ -185,16 +209,174 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 	if client == nil {
 		return nil, errors.New("openai chat completions api LLM: client not initialized")
 	}
+	call, err := buildOpenAIChatCall(ctx, inReq, opts, api.debugger, pi.Name)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *openai.ChatCompletion
+		apiErr         error
+	)
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(
+			ctx,
+			client,
+			pi.Name,
+			req.ModelParam.Name,
+			call.params,
+			opts,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			call.params,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	}
+
+	if apiErr != nil {
+		apiErr = openaisdkutil.NewProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CompileRequest builds the Chat Completions API request for a completion and returns it without sending it. The
+// request goes through a client built like the one InitLLM builds, including the debugger's HTTP client; without an
+// API key it carries sdkutil.CompileAPIKey.
+func (api *OpenAIChatCompletionsAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	hasProvider := api.ProviderParam != nil
+	if hasProvider {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if !hasProvider {
+		return nil, errors.New("openai chat completions api LLM: no ProviderParam found")
+	}
+	if strings.TrimSpace(pi.APIKey) == "" {
+		pi.APIKey = sdkutil.CompileAPIKey
+	}
+	call, err := buildOpenAIChatCall(ctx, inReq, opts, api.debugger, pi.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	clientOpts, _ := openAIChatClientOptions(pi, capture.HTTPClient(api.debugger))
+	client := openai.NewClient(clientOpts...)
+	if call.req.ModelParam.Stream {
+		stream := client.Chat.Completions.NewStreaming(captureCtx, call.params, call.requestOptions()...)
+		err = stream.Err()
+		_ = stream.Close()
+	} else {
+		_, err = client.Chat.Completions.New(captureCtx, call.params, call.requestOptions()...)
+	}
+
+	compiled, err := capture.CompiledRequest(err, pi.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// openAIChatCall is a normalized request together with the Chat Completions
+// API parameters built from it.
+type openAIChatCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	params            openai.ChatCompletionNewParams
+	timeout           time.Duration
+	toolChoiceNameMap map[string]spec.ToolChoice
+	additionalParams  *string
+	warns             []spec.Warning
+}
+
+// requestOptions returns opts plus the middleware that merges the request's
+// additional parameters into the serialized body.
+func (c *openAIChatCall) requestOptions(opts ...option.RequestOption) []option.RequestOption {
+	if mw := sdkutil.AdditionalParametersMiddleware(c.additionalParams, openAIChatOwnedKeys...); mw != nil {
+		opts = append(opts, option.WithMiddleware(mw))
+	}
+	return opts
+}
+
+// buildOpenAIChatCall normalizes a request against the provider capabilities
+// and builds the Chat Completions API parameters for it.
+func buildOpenAIChatCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+	providerName spec.ProviderName,
+) (*openAIChatCall, error) {
 	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
 		return nil, errors.New("openai chat completions api LLM: empty completion data")
 	}
-	req, effectiveCapabilities, warns, err := sdkutil.NormalizeRequestForSDK(
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
 		ctx, inReq, opts, spec.ProviderSDKTypeOpenAIChatCompletions, openaichatsdkCapability,
 	)
 	if err != nil {
 		return nil, err
 	}
-	dialect := resolveOpenAIChatParamDialect(effectiveCapabilities)
+	// input_audio only takes inline data.
+	audioWarns, err := sdkutil.NormalizeInlineAudioInputs(req, opts, spec.ProviderSDKTypeOpenAIChatCompletions)
+	if err != nil {
+		return nil, err
+	}
+	warns = append(warns, audioWarns...)
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
+	dialect := resolveOpenAIChatParamDialect(caps)
 
 	// Build OpenAI chat messages.
 	msgs, err := toOpenAIChatMessages(
@@ -202,7 +384,7 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		req.ModelParam.SystemPrompt,
 		req.Inputs,
 		req.ModelParam.Name,
-		pi.Name,
+		providerName,
 	)
 	if err != nil {
 		return nil, err
@@ -218,6 +400,7 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 	if t := req.ModelParam.Temperature; t != nil {
 		params.Temperature = openai.Float(*t)
 	}
+	applyOpenAIChatSamplingParams(&params, &req.ModelParam)
 	applyOpenAIChatCacheControl(&params, req.ModelParam.CacheControl)
 
 	if rp := req.ModelParam.Reasoning; rp != nil &&
@@ -291,69 +474,33 @@ func (api *OpenAIChatCompletionsAPI) FetchCompletion(
 		}
 	}
 
-	var span spec.CompletionSpan
-	if api.debugger != nil {
-		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
-			Provider: pi.Name,
-			Model:    req.ModelParam.Name,
-			Request:  req,
-			Options:  opts,
-		})
-	}
-
-	var (
-		normalizedResp *spec.FetchCompletionResponse
-		fullRawResp    *openai.ChatCompletion
-		apiErr         error
-	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
-		normalizedResp, fullRawResp, apiErr = api.doStreaming(
-			ctx,
-			client,
-			pi.Name,
-			req.ModelParam.Name,
-			params,
-			opts,
-			timeout,
-			toolChoiceNameMap,
-		)
-	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
-	}
-
-	if normalizedResp != nil && len(warns) > 0 {
-		normalizedResp.Warnings = append(normalizedResp.Warnings, warns...)
-	}
-
-	if span != nil {
-		end := spec.CompletionSpanEnd{
-			ProviderResponse: fullRawResp,
-			Response:         normalizedResp, // may be nil
-			Err:              apiErr,
-		}
-		if normalizedResp != nil {
-			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
-				normalizedResp.DebugDetails = dd
-			}
-		} else {
-			_ = span.End(&end) // ignore return; nothing to attach to
-		}
+	additionalParams := req.ModelParam.AdditionalParametersRawJSON
+	extraWarns, err := sdkutil.AdditionalParametersWarnings(params, additionalParams, openAIChatOwnedKeys...)
+	if err != nil {
+		return nil, err
 	}
 
-	return normalizedResp, apiErr
+	return &openAIChatCall{
+		req:               req,
+		capabilities:      caps,
+		params:            params,
+		timeout:           timeout,
+		toolChoiceNameMap: toolChoiceNameMap,
+		additionalParams:  additionalParams,
+		warns:             append(warns, extraWarns...),
+	}, nil
 }
 
 func (api *OpenAIChatCompletionsAPI) doNonStreaming(
 	ctx context.Context,
 	client *openai.Client,
 	params openai.ChatCompletionNewParams,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *openai.ChatCompletion, error) {
 	resp := &spec.FetchCompletionResponse{}
 
-	oaiResp, err := client.Chat.Completions.New(ctx, params, option.WithRequestTimeout(timeout))
+	oaiResp, err := client.Chat.Completions.New(ctx, params, reqOpts...)
 
 	resp.Usage = usageFromOpenAIChatCompletion(oaiResp)
 	if err != nil {
@@ -362,10 +509,38 @@ func (api *OpenAIChatCompletionsAPI) doNonStreaming(
 	}
 
 	resp.Outputs = outputsFromOpenAIChatCompletion(oaiResp, toolChoiceNameMap)
+	resp.StopReason = stopReasonFromOpenAIChatCompletion(oaiResp)
+	resp.Choices = choicesFromOpenAIChatCompletion(oaiResp, toolChoiceNameMap)
+	setOpenAIChatAudioMIME(resp, params.Audio.Format)
 
 	return resp, oaiResp, nil
 }
 
+// setOpenAIChatAudioMIME labels spoken output with the MIME type of the requested format, which the response does
+// not repeat.
+func setOpenAIChatAudioMIME(resp *spec.FetchCompletionResponse, format openai.ChatCompletionAudioParamFormat) {
+	mime := sdkutil.AudioMIMEFromFormat(string(format))
+	if mime == "" {
+		return
+	}
+	set := func(outs []spec.OutputUnion) {
+		for _, o := range outs {
+			if o.OutputMessage == nil {
+				continue
+			}
+			for _, c := range o.OutputMessage.Contents {
+				if c.AudioItem != nil {
+					c.AudioItem.AudioMIME = mime
+				}
+			}
+		}
+	}
+	set(resp.Outputs)
+	for _, c := range resp.Choices {
+		set(c.Outputs)
+	}
+}
+
 func (api *OpenAIChatCompletionsAPI) doStreaming(
 	ctx context.Context,
 	client *openai.Client,
@@ -373,43 +548,19 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 	modelName spec.ModelName,
 	params openai.ChatCompletionNewParams,
 	opts *spec.FetchCompletionOptions,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *openai.ChatCompletion, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-	// No thinking data available in openai chat completions API, hence no thinking writer.
-	emitText := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:          spec.StreamContentKindText,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	// No thinking data available in openai chat completions API, hence no thinking writer.
-	writeText, flushText := sdkutil.NewBufferedStreamerWithError(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
+	// No thinking data available in openai chat completions API, hence no thinking events.
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newOpenAIChatStreamEvents(emitter, toolChoiceNameMap)
 
-	stream := client.Chat.Completions.NewStreaming(
-		ctx,
-		params,
-		option.WithRequestTimeout(timeout),
-	)
+	stream := client.Chat.Completions.NewStreaming(ctx, params, reqOpts...)
 	defer func() { _ = stream.Close() }()
 
 	acc := openai.ChatCompletionAccumulator{}
+	audio := openAIChatStreamAudio{}
 	streamStartedAt := time.Now()
 	lastEventAt := streamStartedAt
 	eventCount := 0
@@ -429,33 +580,20 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 		}
 
 		acc.AddChunk(chunk)
+		audio.add(chunk)
 
-		// When JustFinished* triggers, the current chunk isn't textual content.
-		if _, ok := acc.JustFinishedContent(); ok {
-			continue
-		}
-
-		if _, ok := acc.JustFinishedRefusal(); ok {
-			continue
-		}
-
-		if _, ok := acc.JustFinishedToolCall(); ok {
-			continue
-		}
-
-		// Best to use chunks after handling JustFinished events.
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			streamWriteErr = writeText(chunk.Choices[0].Delta.Content)
-			if streamWriteErr != nil {
-				break
-			}
+		// Text, tool call and output item events are derived from the raw chunk; the
+		// accumulator's JustFinished* helpers are unreliable with parallel tool calls.
+		streamWriteErr = events.handle(chunk)
+		if streamWriteErr != nil {
+			break
 		}
 	}
 
-	var flushErr error
-	if flushText != nil {
-		flushErr = flushText()
+	if streamWriteErr == nil {
+		streamWriteErr = events.closeAll()
 	}
+	flushErr := emitter.Close()
 
 	iteratorErr := stream.Err()
 	if !sawFinishReason && iteratorErr == nil && streamWriteErr == nil {
@@ -463,10 +601,17 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 			"openai chat completions stream ended before a finish_reason",
 		)
 	}
+	if iteratorErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(
+			usageFromOpenAIChatCompletion(&acc.ChatCompletion),
+			stopReasonFromOpenAIChatCompletion(&acc.ChatCompletion),
+		)
+	}
 
 	streamErr := errors.Join(iteratorErr, streamWriteErr, flushErr)
 	if streamErr != nil {
-		logutil.Error(
+		logutil.ErrorContext(
+			ctx,
 			"openai chat completions stream terminated",
 			"provider", string(providerName),
 			"model", string(modelName),
@@ -485,10 +630,58 @@ func (api *OpenAIChatCompletionsAPI) doStreaming(
 	if streamErr != nil {
 		resp.Error = &spec.Error{Message: streamErr.Error()}
 	}
+	audio.apply(&acc.ChatCompletion)
 	resp.Outputs = outputsFromOpenAIChatCompletion(&acc.ChatCompletion, toolChoiceNameMap)
+	resp.StopReason = stopReasonFromOpenAIChatCompletion(&acc.ChatCompletion)
+	resp.Choices = choicesFromOpenAIChatCompletion(&acc.ChatCompletion, toolChoiceNameMap)
+	setOpenAIChatAudioMIME(resp, params.Audio.Format)
 	return resp, &acc.ChatCompletion, streamErr
 }
 
+// applyOpenAIChatSamplingParams maps the sampling fields left by normalization.
+func applyOpenAIChatSamplingParams(params *openai.ChatCompletionNewParams, mp *spec.ModelParam) {
+	if mp.TopP != nil {
+		params.TopP = openai.Float(*mp.TopP)
+	}
+	if mp.Seed != nil {
+		params.Seed = openai.Int(*mp.Seed)
+	}
+	if mp.PresencePenalty != nil {
+		params.PresencePenalty = openai.Float(*mp.PresencePenalty)
+	}
+	if mp.FrequencyPenalty != nil {
+		params.FrequencyPenalty = openai.Float(*mp.FrequencyPenalty)
+	}
+	if len(mp.LogitBias) > 0 {
+		params.LogitBias = make(map[string]int64, len(mp.LogitBias))
+		for token, bias := range mp.LogitBias {
+			params.LogitBias[token] = int64(bias)
+		}
+	}
+	if mp.Candidates > 1 {
+		params.N = openai.Int(int64(mp.Candidates))
+	}
+	if mp.Logprobs != nil {
+		params.Logprobs = openai.Bool(true)
+		if mp.Logprobs.TopAlternatives > 0 {
+			params.TopLogprobs = openai.Int(int64(mp.Logprobs.TopAlternatives))
+		}
+	}
+}
+
+// tokenLogprobsFromOpenAIChat converts the logprobs of a choice, or of a chunk of one.
+func tokenLogprobsFromOpenAIChat(in []openai.ChatCompletionTokenLogprob) []spec.TokenLogprob {
+	return openaisdkutil.TokenLogprobs(
+		in,
+		func(lp openai.ChatCompletionTokenLogprob) (string, float64, []openai.ChatCompletionTokenLogprobTopLogprob) {
+			return lp.Token, lp.Logprob, lp.TopLogprobs
+		},
+		func(alt openai.ChatCompletionTokenLogprobTopLogprob) (string, float64) {
+			return alt.Token, alt.Logprob
+		},
+	)
+}
+
 func applyOpenAIChatOutputParam(params *openai.ChatCompletionNewParams, op *spec.OutputParam) error {
 	if params == nil || op == nil {
 		return nil
@@ -506,6 +699,22 @@ func applyOpenAIChatOutputParam(params *openai.ChatCompletionNewParams, op *spec
 		}
 	}
 
+	if a := op.Audio; a != nil {
+		voice := strings.TrimSpace(a.Voice)
+		if voice == "" {
+			voice = string(openai.ChatCompletionAudioParamVoiceString2Alloy)
+		}
+		format := strings.ToLower(strings.TrimSpace(a.Format))
+		if format == "" {
+			format = string(openai.ChatCompletionAudioParamFormatWAV)
+		}
+		params.Modalities = []string{"text", "audio"}
+		params.Audio = openai.ChatCompletionAudioParam{
+			Format: openai.ChatCompletionAudioParamFormat(format),
+			Voice:  openai.ChatCompletionAudioParamVoiceUnion{OfString: param.NewOpt(voice)},
+		}
+	}
+
 	if op.Format == nil {
 		return nil
 	}
@@ -711,7 +920,7 @@ func filterChatToolsByResolvedAllowed(
 }
 
 func toOpenAIChatMessages(
-	_ context.Context,
+	ctx context.Context,
 	systemPrompt string,
 	inputs []spec.InputUnion,
 	modelName spec.ModelName,
@@ -736,7 +945,7 @@ func toOpenAIChatMessages(
 			if in.InputMessage == nil || in.InputMessage.Role != spec.RoleUser {
 				continue
 			}
-			parts, err := contentItemsToOpenAIUserMessageParts(in.InputMessage.Contents)
+			parts, err := contentItemsToOpenAIUserMessageParts(ctx, in.InputMessage.Contents)
 			if err != nil {
 				return nil, err
 			}
@@ -750,9 +959,19 @@ func toOpenAIChatMessages(
 				continue
 			}
 			parts := contentItemsToAssistantMessageParts(in.OutputMessage.Contents)
+			audioID := assistantAudioID(in.OutputMessage.Contents)
+			if len(parts) == 0 && audioID == "" {
+				continue
+			}
+			m := &openai.ChatCompletionAssistantMessageParam{}
 			if len(parts) > 0 {
-				out = append(out, openai.AssistantMessage(parts))
+				m.Content.OfArrayOfContentParts = parts
 			}
+			if audioID != "" {
+				// Chat Completions replays earlier spoken output by reference.
+				m.Audio = openai.ChatCompletionAssistantMessageParamAudio{ID: audioID}
+			}
+			out = append(out, openai.ChatCompletionMessageParamUnion{OfAssistant: m})
 
 		case spec.InputKindFunctionToolCall, spec.InputKindCustomToolCall:
 			var call *spec.ToolCall
@@ -791,6 +1010,7 @@ func toOpenAIChatMessages(
 }
 
 func contentItemsToOpenAIUserMessageParts(
+	ctx context.Context,
 	items []spec.InputOutputContentItemUnion,
 ) ([]openai.ChatCompletionContentPartUnionParam, error) {
 	out := make([]openai.ChatCompletionContentPartUnionParam, 0, len(items))
@@ -857,12 +1077,38 @@ func contentItemsToOpenAIUserMessageParts(
 
 			}
 
+		case spec.ContentItemKindAudio:
+			if it.AudioItem == nil {
+				continue
+			}
+			a := it.AudioItem
+
+			// input_audio only takes inline data.
+			if data := strings.TrimSpace(a.AudioData); data != "" {
+				mime := strings.TrimSpace(a.AudioMIME)
+				if mime == "" {
+					mime = spec.DefaultAudioDataMIME
+				}
+				out = append(
+					out,
+					openai.InputAudioContentPart(openai.ChatCompletionContentPartInputAudioInputAudioParam{
+						Data:   data,
+						Format: sdkutil.AudioFormatFromMIME(mime),
+					}),
+				)
+			} else {
+				logutil.DebugContext(ctx, "chat completions: audio input needs inline data", "id", a.ID, "name", a.AudioName)
+			}
+
+		case spec.ContentItemKindVideo:
+			logutil.DebugContext(ctx, "chat completions: video input is not supported")
+
 		case spec.ContentItemKindRefusal:
 			// Refusals are assistant outputs, not user inputs.
 			continue
 
 		default:
-			logutil.Debug("chat completions: unknown content item kind for input message", "kind", it.Kind)
+			logutil.DebugContext(ctx, "chat completions: unknown content item kind for input message", "kind", it.Kind)
 		}
 	}
 
@@ -912,6 +1158,18 @@ func contentItemsToAssistantMessageParts(
 	return parts
 }
 
+// assistantAudioID returns the ID of the first audio item with one.
+func assistantAudioID(items []spec.InputOutputContentItemUnion) string {
+	for _, it := range items {
+		if it.Kind == spec.ContentItemKindAudio && it.AudioItem != nil {
+			if id := strings.TrimSpace(it.AudioItem.ID); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
 func toolCallToOpenAIChatAssistantMessage(
 	call *spec.ToolCall,
 ) *openai.ChatCompletionMessageParamUnion {
@@ -1107,8 +1365,34 @@ func outputsFromOpenAIChatCompletion(
 	if resp == nil || len(resp.Choices) == 0 {
 		return nil
 	}
+	return outputsFromOpenAIChatChoice(resp.ID, resp.Choices[0], toolChoiceNameMap)
+}
 
-	choice := resp.Choices[0]
+// choicesFromOpenAIChatCompletion returns every choice of a completion sampled with n > 1, and nil for a single
+// choice.
+func choicesFromOpenAIChatCompletion(
+	resp *openai.ChatCompletion,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.Choice {
+	if resp == nil || len(resp.Choices) < 2 {
+		return nil
+	}
+	out := make([]spec.Choice, 0, len(resp.Choices))
+	for _, choice := range resp.Choices {
+		out = append(out, spec.Choice{
+			Index:      int(choice.Index),
+			Outputs:    outputsFromOpenAIChatChoice(resp.ID, choice, toolChoiceNameMap),
+			StopReason: stopReasonFromOpenAIChatChoice(choice),
+		})
+	}
+	return out
+}
+
+func outputsFromOpenAIChatChoice(
+	respID string,
+	choice openai.ChatCompletionChoice,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) []spec.OutputUnion {
 	msg := choice.Message
 	status := mapOpenAIChatFinishReasonToStatus(choice.FinishReason)
 
@@ -1121,7 +1405,7 @@ func outputsFromOpenAIChatCompletion(
 		}
 
 		outMsg := spec.InputOutputContent{
-			ID:   resp.ID,
+			ID:   respID,
 			Role: spec.RoleAssistant,
 			// Chat Completions does not expose per-block status; use finish_reason.
 			Status: status,
@@ -1139,14 +1423,19 @@ func outputsFromOpenAIChatCompletion(
 		)
 	} else if txt := strings.TrimSpace(msg.Content); txt != "" {
 		textItem := spec.ContentItemText{
-			Text: txt,
+			Text:     txt,
+			Logprobs: tokenLogprobsFromOpenAIChat(choice.Logprobs.Content),
+		}
+		if textItem.Logprobs != nil {
+			// Logprob tokens concatenate to the raw content; trimming would misalign them with the text.
+			textItem.Text = msg.Content
 		}
 		if len(msg.Annotations) > 0 {
 			textItem.Citations = chatAnnotationsToCitations(msg.Annotations)
 		}
 
 		outMsg := spec.InputOutputContent{
-			ID:   resp.ID,
+			ID:   respID,
 			Role: spec.RoleAssistant,
 			// Chat Completions does not expose per-block status; use finish_reason.
 			Status: status,
@@ -1164,6 +1453,26 @@ func outputsFromOpenAIChatCompletion(
 		)
 	}
 
+	// Spoken output; its transcript stays on the audio item.
+	if msg.Audio.ID != "" || msg.Audio.Data != "" {
+		outs = append(outs, spec.OutputUnion{
+			Kind: spec.OutputKindOutputMessage,
+			OutputMessage: &spec.InputOutputContent{
+				ID:     respID,
+				Role:   spec.RoleAssistant,
+				Status: status,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind: spec.ContentItemKindAudio,
+					AudioItem: &spec.ContentItemAudio{
+						ID:         msg.Audio.ID,
+						AudioData:  msg.Audio.Data,
+						Transcript: msg.Audio.Transcript,
+					},
+				}},
+			},
+		})
+	}
+
 	// Tool calls (function/custom).
 	if len(msg.ToolCalls) > 0 {
 		for _, tc := range msg.ToolCalls {
@@ -1286,6 +1595,39 @@ func mapOpenAIChatFinishReasonToStatus(reason string) spec.Status {
 	}
 }
 
+// stopReasonFromOpenAIChatCompletion normalizes the first choice's finish reason.
+func stopReasonFromOpenAIChatCompletion(resp *openai.ChatCompletion) *spec.StopReason {
+	if resp == nil || len(resp.Choices) == 0 {
+		return nil
+	}
+	return stopReasonFromOpenAIChatChoice(resp.Choices[0])
+}
+
+// stopReasonFromOpenAIChatChoice normalizes a choice's finish reason. A stop sequence hit is reported as "stop" and
+// cannot be told apart from a normal end of turn.
+func stopReasonFromOpenAIChatChoice(choice openai.ChatCompletionChoice) *spec.StopReason {
+	if choice.FinishReason == "" {
+		return nil
+	}
+	out := &spec.StopReason{Raw: choice.FinishReason}
+	switch choice.FinishReason {
+	case "stop":
+		out.Kind = spec.StopReasonEndTurn
+		if strings.TrimSpace(choice.Message.Refusal) != "" {
+			out.Kind = spec.StopReasonRefusal
+		}
+	case "length":
+		out.Kind = spec.StopReasonMaxTokens
+	case "tool_calls", "function_call":
+		out.Kind = spec.StopReasonToolUse
+	case "content_filter":
+		out.Kind = spec.StopReasonContentFilter
+	default:
+		out.Kind = spec.StopReasonOther
+	}
+	return out
+}
+
 func usageFromOpenAIChatCompletion(resp *openai.ChatCompletion) *spec.Usage {
 	uOut := &spec.Usage{}
 	if resp == nil {
@@ -1299,6 +1641,9 @@ func usageFromOpenAIChatCompletion(resp *openai.ChatCompletion) *spec.Usage {
 	uOut.InputTokensUncached = max(u.PromptTokens-u.PromptTokensDetails.CachedTokens, 0)
 	uOut.OutputTokens = u.CompletionTokens
 	uOut.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	if audio := u.PromptTokensDetails.AudioTokens; audio > 0 {
+		uOut.InputTokensByModality = map[spec.Modality]int64{spec.ModalityAudioIn: audio}
+	}
 
 	return uOut
 }
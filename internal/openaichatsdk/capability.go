@@ -3,7 +3,11 @@ package openaichatsdk
 import "github.com/flexigpt/inference-go/spec"
 
 var openaichatsdkCapability = spec.ModelCapabilities{
-	ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityImageIn, spec.ModalityFileIn},
+	ModalitiesIn: []spec.Modality{
+		spec.ModalityTextIn,
+		spec.ModalityImageIn,
+		spec.ModalityFileIn,
+	},
 	ModalitiesOut: []spec.Modality{spec.ModalityTextOut},
 
 	ReasoningCapabilities: &spec.ReasoningCapabilities{
@@ -29,6 +33,16 @@ var openaichatsdkCapability = spec.ModelCapabilities{
 		DisallowedWithReasoning: false,
 		MaxSequences:            4,
 	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:             true,
+		SupportsSeed:             true,
+		SupportsPresencePenalty:  true,
+		SupportsFrequencyPenalty: true,
+		SupportsLogitBias:        true,
+		MaxCandidates:            128,
+		SupportsLogprobs:         true,
+		MaxTopLogprobs:           20,
+	},
 	OutputCapabilities: &spec.OutputCapabilities{
 		SupportedOutputFormats: []spec.OutputFormatKind{spec.OutputFormatKindText, spec.OutputFormatKindJSONSchema},
 		SupportsVerbosity:      true,
@@ -0,0 +1,60 @@
+package openaichatsdk
+
+import (
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStopReasonFromOpenAIChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	completion := func(finish, refusal string) *openai.ChatCompletion {
+		return &openai.ChatCompletion{Choices: []openai.ChatCompletionChoice{{
+			FinishReason: finish,
+			Message:      openai.ChatCompletionMessage{Refusal: refusal},
+		}}}
+	}
+
+	tests := []struct {
+		name string
+		resp *openai.ChatCompletion
+		want *spec.StopReason
+	}{
+		{name: "no choices", resp: &openai.ChatCompletion{}, want: nil},
+		{name: "stop", resp: completion("stop", ""), want: &spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "stop"}},
+		{
+			name: "refusal",
+			resp: completion("stop", "I can't help with that."),
+			want: &spec.StopReason{Kind: spec.StopReasonRefusal, Raw: "stop"},
+		},
+		{
+			name: "length",
+			resp: completion("length", ""),
+			want: &spec.StopReason{Kind: spec.StopReasonMaxTokens, Raw: "length"},
+		},
+		{
+			name: "tool calls",
+			resp: completion("tool_calls", ""),
+			want: &spec.StopReason{Kind: spec.StopReasonToolUse, Raw: "tool_calls"},
+		},
+		{
+			name: "content filter",
+			resp: completion("content_filter", ""),
+			want: &spec.StopReason{Kind: spec.StopReasonContentFilter, Raw: "content_filter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := stopReasonFromOpenAIChatCompletion(tt.resp)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
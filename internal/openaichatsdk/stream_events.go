@@ -0,0 +1,242 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// openAIChatStreamCall tracks one in-flight tool call of a Chat Completions stream.
+type openAIChatStreamCall struct {
+	outputIndex int
+	callID      string
+	name        string
+	toolType    spec.ToolType
+	args        strings.Builder
+}
+
+// openAIChatStreamEvents maps Chat Completions chunks of the first choice to
+// normalized stream events.
+//
+// Chat Completions has no notion of output items, so items are derived: the
+// assistant text is one item and every tool call is another, numbered in
+// stream order. Tool calls are streamed one after another, so a call is
+// considered complete when the next one starts or the choice finishes.
+type openAIChatStreamEvents struct {
+	emitter           *sdkutil.StreamEmitter
+	toolChoiceNameMap map[string]spec.ToolChoice
+
+	nextOutputIndex int
+	textOpen        bool
+	textIndex       int
+	textID          string
+
+	calls     map[int64]*openAIChatStreamCall
+	callOrder []int64
+}
+
+func newOpenAIChatStreamEvents(
+	emitter *sdkutil.StreamEmitter,
+	toolChoiceNameMap map[string]spec.ToolChoice,
+) *openAIChatStreamEvents {
+	return &openAIChatStreamEvents{
+		emitter:           emitter,
+		toolChoiceNameMap: toolChoiceNameMap,
+		calls:             map[int64]*openAIChatStreamCall{},
+	}
+}
+
+func (s *openAIChatStreamEvents) handle(chunk openai.ChatCompletionChunk) error {
+	// With n > 1 the chunks of other choices are interleaved; they are only part of the final response.
+	i := slices.IndexFunc(chunk.Choices, func(c openai.ChatCompletionChunkChoice) bool { return c.Index == 0 })
+	if i < 0 {
+		return nil
+	}
+	choice := chunk.Choices[i]
+
+	if choice.Delta.Content != "" {
+		if !s.textOpen {
+			if err := s.closeCalls(); err != nil {
+				return err
+			}
+			s.textOpen = true
+			s.textIndex = s.nextOutputIndex
+			s.textID = chunk.ID
+			s.nextOutputIndex++
+			if err := s.emitter.OutputItemStart(s.textIndex, spec.OutputKindOutputMessage, s.textID); err != nil {
+				return err
+			}
+		}
+		if err := s.emitter.WriteTextLogprobs(
+			choice.Delta.Content,
+			tokenLogprobsFromOpenAIChat(choice.Logprobs.Content),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, dt := range choice.Delta.ToolCalls {
+		if err := s.handleToolCallDelta(dt); err != nil {
+			return err
+		}
+	}
+
+	if choice.FinishReason != "" {
+		return s.closeAll()
+	}
+	return nil
+}
+
+func (s *openAIChatStreamEvents) handleToolCallDelta(dt openai.ChatCompletionChunkChoiceDeltaToolCall) error {
+	// Some OpenAI compatible servers send -1 for single tool calls.
+	key := max(dt.Index, 0)
+
+	call, ok := s.calls[key]
+	if !ok {
+		if err := s.closeText(); err != nil {
+			return err
+		}
+		if err := s.closeCalls(); err != nil {
+			return err
+		}
+		call = &openAIChatStreamCall{
+			outputIndex: s.nextOutputIndex,
+			callID:      dt.ID,
+			name:        dt.Function.Name,
+			toolType:    spec.ToolTypeFunction,
+		}
+		if tc, ok := s.toolChoiceNameMap[strings.TrimSpace(dt.Function.Name)]; ok && tc.Type == spec.ToolTypeCustom {
+			call.toolType = spec.ToolTypeCustom
+		}
+		s.nextOutputIndex++
+		s.calls[key] = call
+		s.callOrder = append(s.callOrder, key)
+
+		if err := s.emitter.OutputItemStart(call.outputIndex, call.outputKind(), call.callID); err != nil {
+			return err
+		}
+		if err := s.emitter.ToolCall(spec.StreamContentKindToolCallStart, call.chunk()); err != nil {
+			return err
+		}
+	}
+
+	if dt.Function.Arguments == "" {
+		return nil
+	}
+	call.args.WriteString(dt.Function.Arguments)
+	tc := call.chunk()
+	tc.ArgumentsDelta = dt.Function.Arguments
+	return s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, tc)
+}
+
+// closeAll ends every open item. It is idempotent.
+func (s *openAIChatStreamEvents) closeAll() error {
+	if err := s.closeText(); err != nil {
+		return err
+	}
+	return s.closeCalls()
+}
+
+func (s *openAIChatStreamEvents) closeText() error {
+	if !s.textOpen {
+		return nil
+	}
+	s.textOpen = false
+	return s.emitter.OutputItemStop(s.textIndex, spec.OutputKindOutputMessage, s.textID)
+}
+
+func (s *openAIChatStreamEvents) closeCalls() error {
+	order := s.callOrder
+	s.callOrder = nil
+	for _, key := range order {
+		call := s.calls[key]
+		if call == nil {
+			continue
+		}
+		delete(s.calls, key)
+
+		tc := call.chunk()
+		tc.Arguments = call.args.String()
+		if err := s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, tc); err != nil {
+			return err
+		}
+		if err := s.emitter.OutputItemStop(call.outputIndex, call.outputKind(), call.callID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *openAIChatStreamCall) outputKind() spec.OutputKind {
+	if c.toolType == spec.ToolTypeCustom {
+		return spec.OutputKindCustomToolCall
+	}
+	return spec.OutputKindFunctionToolCall
+}
+
+func (c *openAIChatStreamCall) chunk() spec.StreamToolCallChunk {
+	return spec.StreamToolCallChunk{
+		OutputIndex: c.outputIndex,
+		Type:        c.toolType,
+		CallID:      c.callID,
+		Name:        c.name,
+	}
+}
+
+// openAIChatStreamAudio accumulates the spoken output of streamed choices by choice index. The SDK's chunk delta and
+// accumulator do not model audio, so the deltas are read from the raw chunk.
+type openAIChatStreamAudio map[int64]*openAIChatStreamAudioChoice
+
+type openAIChatStreamAudioChoice struct {
+	id         string
+	data       strings.Builder
+	transcript strings.Builder
+}
+
+func (a openAIChatStreamAudio) add(chunk openai.ChatCompletionChunk) {
+	for _, choice := range chunk.Choices {
+		// Unknown fields are never reported as valid; check the raw value instead.
+		f, ok := choice.Delta.JSON.ExtraFields["audio"]
+		if !ok || f.Raw() == "" || f.Raw() == "null" {
+			continue
+		}
+		var delta struct {
+			ID         string `json:"id"`
+			Data       string `json:"data"`
+			Transcript string `json:"transcript"`
+		}
+		if err := json.Unmarshal([]byte(f.Raw()), &delta); err != nil {
+			continue
+		}
+		acc := a[choice.Index]
+		if acc == nil {
+			acc = &openAIChatStreamAudioChoice{}
+			a[choice.Index] = acc
+		}
+		if delta.ID != "" {
+			acc.id = delta.ID
+		}
+		acc.data.WriteString(delta.Data)
+		acc.transcript.WriteString(delta.Transcript)
+	}
+}
+
+// apply sets the accumulated audio on the matching choices of the accumulated completion.
+func (a openAIChatStreamAudio) apply(completion *openai.ChatCompletion) {
+	for i := range completion.Choices {
+		acc := a[completion.Choices[i].Index]
+		if acc == nil {
+			continue
+		}
+		completion.Choices[i].Message.Audio = openai.ChatCompletionAudio{
+			ID:         acc.id,
+			Data:       acc.data.String(),
+			Transcript: acc.transcript.String(),
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package openaichatsdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOpenAIChatStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	rawChunks := []string{
+		`{"id":"c","choices":[{"index":0,"delta":{"role":"assistant","content":"Let me check."}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{"tool_calls":[` +
+			`{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{"tool_calls":[` +
+			`{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+
+	var events []spec.StreamEvent
+	emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+		StreamHandler: func(ev spec.StreamEvent) error {
+			events = append(events, ev)
+			return nil
+		},
+		StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+	})
+	s := newOpenAIChatStreamEvents(emitter, nil)
+
+	for i, raw := range rawChunks {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		if err := s.handle(chunk); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	got := make([]spec.StreamContentKind, 0, len(events))
+	for _, ev := range events {
+		got = append(got, ev.Kind)
+	}
+	want := []spec.StreamContentKind{
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindText,
+		spec.StreamContentKindOutputItemStop,
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindToolCallStart,
+		spec.StreamContentKindToolCallDelta,
+		spec.StreamContentKindToolCallDelta,
+		spec.StreamContentKindToolCallEnd,
+		spec.StreamContentKindOutputItemStop,
+		spec.StreamContentKindOutputItemStart,
+		spec.StreamContentKindToolCallStart,
+		spec.StreamContentKindToolCallDelta,
+		spec.StreamContentKindToolCallEnd,
+		spec.StreamContentKindOutputItemStop,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("kinds:\n got %v\nwant %v", got, want)
+	}
+
+	end := events[7].ToolCall
+	if end.CallID != "call_1" || end.Name != "get_weather" || end.Arguments != `{"city":"Paris"}` ||
+		end.OutputIndex != 1 {
+		t.Fatalf("first tool call end: got %+v", end)
+	}
+	if second := events[10].ToolCall; second.CallID != "call_2" || second.OutputIndex != 2 {
+		t.Fatalf("second tool call start: got %+v", second)
+	}
+}
+
+func TestOpenAIChatStreamEventsMultipleChoices(t *testing.T) {
+	t.Parallel()
+
+	rawChunks := []string{
+		`{"id":"c","choices":[{"index":0,"delta":{"role":"assistant","content":"Red"}}]}`,
+		`{"id":"c","choices":[{"index":1,"delta":{"role":"assistant","content":"Blue"}}]}`,
+		`{"id":"c","choices":[{"index":1,"delta":{},"finish_reason":"length"}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+
+	var texts []string
+	emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+		StreamHandler: func(ev spec.StreamEvent) error {
+			if ev.Kind == spec.StreamContentKindText {
+				texts = append(texts, ev.Text.Text)
+			}
+			return nil
+		},
+		StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+	})
+	s := newOpenAIChatStreamEvents(emitter, nil)
+	acc := openai.ChatCompletionAccumulator{}
+
+	for i, raw := range rawChunks {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		acc.AddChunk(chunk)
+		if err := s.handle(chunk); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+	if err := emitter.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !slices.Equal(texts, []string{"Red"}) {
+		t.Fatalf("streamed texts = %v, want only the first choice", texts)
+	}
+
+	choices := choicesFromOpenAIChatCompletion(&acc.ChatCompletion, nil)
+	if len(choices) != 2 {
+		t.Fatalf("choices = %+v, want 2", choices)
+	}
+	for i, want := range []struct {
+		text string
+		stop spec.StopReasonKind
+	}{{"Red", spec.StopReasonEndTurn}, {"Blue", spec.StopReasonMaxTokens}} {
+		c := choices[i]
+		if c.Index != i || c.Outputs[0].OutputMessage.Contents[0].TextItem.Text != want.text ||
+			c.StopReason.Kind != want.stop {
+			t.Errorf("choice %d = %+v, want %q stopped by %s", i, c, want.text, want.stop)
+		}
+	}
+	if got := choicesFromOpenAIChatCompletion(&openai.ChatCompletion{
+		Choices: []openai.ChatCompletionChoice{{FinishReason: "stop"}},
+	}, nil); got != nil {
+		t.Errorf("single choice: got %+v, want nil", got)
+	}
+}
+
+func TestOpenAIChatLogprobs(t *testing.T) {
+	t.Parallel()
+
+	logprobs := `"logprobs":{"content":[{"token":"Yes","bytes":[89,101,115],"logprob":-0.01,` +
+		`"top_logprobs":[{"token":"Yes","bytes":[89,101,115],"logprob":-0.01},{"token":"No","bytes":[78,111],"logprob":-4.6}]}]}`
+	want := []spec.TokenLogprob{{
+		Token:   "Yes",
+		Logprob: -0.01,
+		TopAlternatives: []spec.TokenAlternative{
+			{Token: "Yes", Logprob: -0.01},
+			{Token: "No", Logprob: -4.6},
+		},
+	}}
+
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal([]byte(`{"id":"c","choices":[{"index":0,"finish_reason":"stop",`+
+		`"message":{"role":"assistant","content":"Yes"},`+logprobs+`}]}`), &completion); err != nil {
+		t.Fatal(err)
+	}
+	outs := outputsFromOpenAIChatCompletion(&completion, nil)
+	if len(outs) != 1 || !reflect.DeepEqual(outs[0].OutputMessage.Contents[0].TextItem.Logprobs, want) {
+		t.Fatalf("outputs = %+v, want text with logprobs", outs)
+	}
+
+	// Text carrying logprobs is kept untrimmed so the tokens still line up with it.
+	if err := json.Unmarshal([]byte(`{"id":"c","choices":[{"index":0,"finish_reason":"stop",`+
+		`"message":{"role":"assistant","content":" Yes\n"},`+logprobs+`}]}`), &completion); err != nil {
+		t.Fatal(err)
+	}
+	outs = outputsFromOpenAIChatCompletion(&completion, nil)
+	if got := outs[0].OutputMessage.Contents[0].TextItem.Text; got != " Yes\n" {
+		t.Fatalf("text = %q, want untrimmed content", got)
+	}
+
+	var events []spec.StreamEvent
+	emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+		StreamHandler: func(ev spec.StreamEvent) error {
+			events = append(events, ev)
+			return nil
+		},
+		StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+	})
+	var chunk openai.ChatCompletionChunk
+	if err := json.Unmarshal([]byte(`{"id":"c","choices":[{"index":0,"delta":{"content":"Yes"},`+
+		logprobs+`}]}`), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if err := newOpenAIChatStreamEvents(emitter, nil).handle(chunk); err != nil {
+		t.Fatal(err)
+	}
+	if err := emitter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(events, func(ev spec.StreamEvent) bool { return ev.Kind == spec.StreamContentKindText })
+	if i < 0 || !reflect.DeepEqual(events[i].Text.Logprobs, want) {
+		t.Fatalf("events = %+v, want a text event with logprobs", events)
+	}
+}
+
+func TestOpenAIChatAudioOutput(t *testing.T) {
+	t.Parallel()
+
+	want := &spec.ContentItemAudio{ID: "audio_1", AudioMIME: "audio/mpeg", AudioData: "AAEC", Transcript: "Hello there"}
+
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal([]byte(`{"id":"c","choices":[{"index":0,"finish_reason":"stop","message":`+
+		`{"role":"assistant","content":null,"audio":{"id":"audio_1","data":"AAEC","expires_at":1,`+
+		`"transcript":"Hello there"}}}]}`), &completion); err != nil {
+		t.Fatal(err)
+	}
+	resp := &spec.FetchCompletionResponse{Outputs: outputsFromOpenAIChatCompletion(&completion, nil)}
+	setOpenAIChatAudioMIME(resp, openai.ChatCompletionAudioParamFormatMP3)
+	if len(resp.Outputs) != 1 || !reflect.DeepEqual(resp.Outputs[0].OutputMessage.Contents[0].AudioItem, want) {
+		t.Fatalf("outputs = %+v, want %+v", resp.Outputs, want)
+	}
+
+	// Streamed audio is accumulated from the raw deltas.
+	acc := openai.ChatCompletionAccumulator{}
+	audio := openAIChatStreamAudio{}
+	for _, raw := range []string{
+		`{"id":"c","choices":[{"index":0,"delta":{"role":"assistant","audio":{"id":"audio_1","transcript":"Hello"}}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{"audio":{"data":"AAEC","transcript":" there"}}}]}`,
+		`{"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	} {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			t.Fatal(err)
+		}
+		acc.AddChunk(chunk)
+		audio.add(chunk)
+	}
+	audio.apply(&acc.ChatCompletion)
+	resp = &spec.FetchCompletionResponse{Outputs: outputsFromOpenAIChatCompletion(&acc.ChatCompletion, nil)}
+	setOpenAIChatAudioMIME(resp, openai.ChatCompletionAudioParamFormatMP3)
+	if len(resp.Outputs) != 1 || !reflect.DeepEqual(resp.Outputs[0].OutputMessage.Contents[0].AudioItem, want) {
+		t.Fatalf("streamed outputs = %+v, want %+v", resp.Outputs, want)
+	}
+
+	// Replaying the turn references the audio by ID.
+	msgs, err := toOpenAIChatMessages(t.Context(), "", []spec.InputUnion{{
+		Kind:          spec.InputKindOutputMessage,
+		OutputMessage: resp.Outputs[0].OutputMessage,
+	}}, "m", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0].OfAssistant == nil || msgs[0].OfAssistant.Audio.ID != "audio_1" {
+		t.Fatalf("messages = %+v, want an assistant message referencing audio_1", msgs)
+	}
+	body, err := json.Marshal(msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"audio":{"id":"audio_1"},"role":"assistant"}` {
+		t.Errorf("replayed message = %s", body)
+	}
+}
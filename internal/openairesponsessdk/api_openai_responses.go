@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -16,14 +18,20 @@ import (
 	openaiSharedConstant "github.com/openai/openai-go/v3/shared/constant"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/openaisdkutil"
 	"github.com/flexigpt/inference-go/internal/sdkutil"
 	"github.com/flexigpt/inference-go/spec"
 )
 
+// openAIResponsesOwnedKeys are the request keys built from typed fields, which
+// additional parameters cannot replace.
+var openAIResponsesOwnedKeys = []string{"model", "input", "tools", "stream"}
+
 // OpenAIResponsesAPI struct that implements the CompletionProvider interface.
 type OpenAIResponsesAPI struct {
 	ProviderParam *spec.ProviderParam
 	debugger      spec.CompletionDebugger
+	logger        *slog.Logger
 	client        *openai.Client
 	mu            sync.RWMutex
 }
@@ -31,6 +39,7 @@ type OpenAIResponsesAPI struct {
 func NewOpenAIResponsesAPI(
 	pi spec.ProviderParam,
 	debugger spec.CompletionDebugger,
+	logger *slog.Logger,
 ) (*OpenAIResponsesAPI, error) {
 	if pi.Name == "" {
 		return nil, errors.New("openai responses api LLM: invalid args")
@@ -38,6 +47,7 @@ func NewOpenAIResponsesAPI(
 	return &OpenAIResponsesAPI{
 		ProviderParam: &pi,
 		debugger:      debugger,
+		logger:        logutil.OrDiscard(logger),
 	}, nil
 }
 
@@ -49,7 +59,7 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 		return errors.New("openai responses api LLM: no ProviderParam found")
 	}
 	if strings.TrimSpace(api.ProviderParam.APIKey) == "" {
-		logutil.Debug(
+		api.logger.Debug(
 			string(
 				api.ProviderParam.Name,
 			) + ": No API key given. Not initializing OpenAIResponsesAPI LLM object",
@@ -59,12 +69,37 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 	}
 
 	pi := *api.ProviderParam // snapshot under lock
+	var httpClient *http.Client
+	if api.debugger != nil {
+		httpClient = api.debugger.HTTPClient(nil)
+	}
+	opts, providerURL := openAIResponsesClientOptions(pi, httpClient)
 
-	opts := []option.RequestOption{
+	c := openai.NewClient(opts...)
+	api.client = &c
+	api.logger.Info(
+		"openai responses api LLM provider initialized",
+		"name",
+		string(pi.Name),
+		"URL",
+		providerURL,
+	)
+	return nil
+}
+
+// openAIResponsesClientOptions returns the options of a client for pi and the URL it calls. httpClient may be nil.
+func openAIResponsesClientOptions(
+	pi spec.ProviderParam,
+	httpClient *http.Client,
+) (opts []option.RequestOption, providerURL string) {
+	opts = []option.RequestOption{
 		option.WithAPIKey(pi.APIKey),
+		// Retries belong to the provider set's RetryPolicy; SDK retries would multiply its attempts and hide them
+		// from the debugger.
+		option.WithMaxRetries(0),
 	}
 
-	providerURL := spec.DefaultOpenAIOrigin
+	providerURL = spec.DefaultOpenAIOrigin
 	if pi.Origin != "" {
 		baseURL := strings.TrimSuffix(pi.Origin, "/")
 
@@ -91,22 +126,10 @@ func (api *OpenAIResponsesAPI) InitLLM(ctx context.Context) error {
 		)
 	}
 
-	if api.debugger != nil {
-		if httpClient := api.debugger.HTTPClient(nil); httpClient != nil {
-			opts = append(opts, option.WithHTTPClient(httpClient))
-		}
+	if httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(httpClient))
 	}
-
-	c := openai.NewClient(opts...)
-	api.client = &c
-	logutil.Info(
-		"openai responses api LLM provider initialized",
-		"name",
-		string(pi.Name),
-		"URL",
-		providerURL,
-	)
-	return nil
+	return opts, providerURL
 }
 
 func (api *OpenAIResponsesAPI) DeInitLLM(ctx context.Context) error {
@@ -117,7 +140,7 @@ func (api *OpenAIResponsesAPI) DeInitLLM(ctx context.Context) error {
 	}
 	api.client = nil
 	api.mu.Unlock()
-	logutil.Info(
+	api.logger.Info(
 		"openai responses api LLM: provider de initialized",
 		"name",
 		string(name),
@@ -179,17 +202,226 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	if client == nil {
 		return nil, errors.New("openai responses api LLM: client not initialized")
 	}
+	call, err := buildOpenAIResponsesCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+	req := call.req
+
+	var span spec.CompletionSpan
+	if api.debugger != nil {
+		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
+			Provider: pi.Name,
+			Model:    req.ModelParam.Name,
+			Request:  req,
+			Options:  opts,
+			Attempt:  spec.CompletionAttemptFromContext(ctx),
+		})
+		opts = sdkutil.ObserveStreamEvents(opts, span)
+	}
+
+	var (
+		normalizedResp *spec.FetchCompletionResponse
+		fullRawResp    *responses.Response
+		apiErr         error
+	)
+	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
+	if useStream {
+		normalizedResp, fullRawResp, apiErr = api.doStreaming(
+			ctx,
+			client,
+			pi.Name,
+			req.ModelParam.Name,
+			call.params,
+			opts,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	} else {
+		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(
+			ctx,
+			client,
+			call.params,
+			call.requestOptions(option.WithRequestTimeout(call.timeout)),
+			call.toolChoiceNameMap,
+		)
+	}
+
+	if apiErr != nil {
+		apiErr = openaisdkutil.NewProviderError(pi.Name, apiErr)
+		sdkutil.SetResponseErrorKind(normalizedResp, apiErr)
+	}
+
+	if normalizedResp != nil && len(call.warns) > 0 {
+		normalizedResp.Warnings = append(normalizedResp.Warnings, call.warns...)
+	}
+
+	if span != nil {
+		end := spec.CompletionSpanEnd{
+			ProviderResponse: fullRawResp,
+			Response:         normalizedResp, // may be nil
+			Err:              apiErr,
+		}
+		if normalizedResp != nil {
+			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
+				normalizedResp.DebugDetails = dd
+			}
+		} else {
+			_ = span.End(&end) // ignore return; nothing to attach to
+		}
+	}
+
+	return normalizedResp, apiErr
+}
+
+// CountTokens counts the input tokens of a request with the Responses input token count endpoint.
+func (api *OpenAIResponsesAPI) CountTokens(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.TokenCount, error) {
+	api.mu.RLock()
+	client := api.client
+	var pi spec.ProviderParam
+	if api.ProviderParam != nil {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if client == nil {
+		return nil, errors.New("openai responses api LLM: client not initialized")
+	}
+	call, err := buildOpenAIResponsesCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	p := call.params
+	params := responses.InputTokenCountParams{
+		Model:             param.NewOpt(p.Model),
+		Instructions:      p.Instructions,
+		ParallelToolCalls: p.ParallelToolCalls,
+		Input:             responses.InputTokenCountParamsInputUnion{OfResponseInputItemArray: p.Input.OfInputItemList},
+		Text: responses.InputTokenCountParamsText{
+			Verbosity: string(p.Text.Verbosity),
+			Format:    p.Text.Format,
+		},
+		ToolChoice: responses.InputTokenCountParamsToolChoiceUnion{
+			OfToolChoiceMode: p.ToolChoice.OfToolChoiceMode,
+			OfAllowedTools:   p.ToolChoice.OfAllowedTools,
+		},
+		Tools:     p.Tools,
+		Reasoning: p.Reasoning,
+	}
+
+	res, err := client.Responses.InputTokens.Count(ctx, params, option.WithRequestTimeout(call.timeout))
+	if err != nil {
+		return nil, openaisdkutil.NewProviderError(pi.Name, err)
+	}
+	return &spec.TokenCount{
+		InputTokens: int(res.InputTokens),
+		Source:      spec.TokenCountSourceProvider,
+		Exact:       true,
+		Warnings:    call.warns,
+	}, nil
+}
+
+// CompileRequest builds the Responses API request for a completion and returns it without sending it. The request
+// goes through a client built like the one InitLLM builds, including the debugger's HTTP client; without an API key
+// it carries sdkutil.CompileAPIKey.
+func (api *OpenAIResponsesAPI) CompileRequest(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.CompiledRequest, error) {
+	api.mu.RLock()
+	var pi spec.ProviderParam
+	hasProvider := api.ProviderParam != nil
+	if hasProvider {
+		pi = *api.ProviderParam
+	}
+	api.mu.RUnlock()
+
+	if !hasProvider {
+		return nil, errors.New("openai responses api LLM: no ProviderParam found")
+	}
+	if strings.TrimSpace(pi.APIKey) == "" {
+		pi.APIKey = sdkutil.CompileAPIKey
+	}
+	call, err := buildOpenAIResponsesCall(ctx, inReq, opts, api.debugger)
+	if err != nil {
+		return nil, err
+	}
+
+	captureCtx, capture := sdkutil.NewRequestCapture(ctx)
+	clientOpts, _ := openAIResponsesClientOptions(pi, capture.HTTPClient(api.debugger))
+	client := openai.NewClient(clientOpts...)
+	if call.req.ModelParam.Stream {
+		stream := client.Responses.NewStreaming(captureCtx, call.params, call.requestOptions()...)
+		err = stream.Err()
+		_ = stream.Close()
+	} else {
+		_, err = client.Responses.New(captureCtx, call.params, call.requestOptions()...)
+	}
+
+	compiled, err := capture.CompiledRequest(err, pi.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Warnings = call.warns
+	compiled.EffectiveCapabilities = call.capabilities
+	return compiled, nil
+}
+
+// openAIResponsesCall is a normalized request together with the Responses API
+// parameters built from it.
+type openAIResponsesCall struct {
+	req               *spec.FetchCompletionRequest
+	capabilities      *spec.ModelCapabilities
+	params            responses.ResponseNewParams
+	timeout           time.Duration
+	toolChoiceNameMap map[string]spec.ToolChoice
+	additionalParams  *string
+	warns             []spec.Warning
+}
+
+// requestOptions returns opts plus the middleware that merges the request's
+// additional parameters into the serialized body.
+func (c *openAIResponsesCall) requestOptions(opts ...option.RequestOption) []option.RequestOption {
+	if mw := sdkutil.AdditionalParametersMiddleware(c.additionalParams, openAIResponsesOwnedKeys...); mw != nil {
+		opts = append(opts, option.WithMiddleware(mw))
+	}
+	return opts
+}
+
+// buildOpenAIResponsesCall normalizes a request against the provider
+// capabilities and builds the Responses API parameters for it.
+func buildOpenAIResponsesCall(
+	ctx context.Context,
+	inReq *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	debugger spec.CompletionDebugger,
+) (*openAIResponsesCall, error) {
 	if inReq == nil || len(inReq.Inputs) == 0 || inReq.ModelParam.Name == "" {
 		return nil, errors.New("openai responses api LLM: invalid data")
 	}
-	req, _, warns, err := sdkutil.NormalizeRequestForSDK(
+	req, caps, warns, err := sdkutil.NormalizeRequestForSDK(
 		ctx, inReq, opts, spec.ProviderSDKTypeOpenAIResponses, openairesponsessdkCapability,
 	)
 	if err != nil {
 		return nil, err
 	}
+	// input_audio only takes inline data.
+	audioWarns, err := sdkutil.NormalizeInlineAudioInputs(req, opts, spec.ProviderSDKTypeOpenAIResponses)
+	if err != nil {
+		return nil, err
+	}
+	warns = append(warns, audioWarns...)
+	if err := sdkutil.InterceptRequest(ctx, debugger, req, opts); err != nil {
+		return nil, err
+	}
 
-	sanitizedInputs := sanitizeReasoningInputs(req.Inputs)
+	sanitizedInputs := sanitizeReasoningInputs(ctx, req.Inputs)
 
 	// Build OpenAI Responses input messages.
 	inputItems, err := toOpenAIResponsesInput(
@@ -219,6 +451,15 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 	if req.ModelParam.Temperature != nil {
 		params.Temperature = openai.Float(*req.ModelParam.Temperature)
 	}
+	if req.ModelParam.TopP != nil {
+		params.TopP = openai.Float(*req.ModelParam.TopP)
+	}
+	if lp := req.ModelParam.Logprobs; lp != nil {
+		params.Include = append(params.Include, responses.ResponseIncludableMessageOutputTextLogprobs)
+		if lp.TopAlternatives > 0 {
+			params.TopLogprobs = openai.Int(int64(lp.TopAlternatives))
+		}
+	}
 	applyOpenAIResponsesCacheControl(&params, req.ModelParam.CacheControl)
 	if rp := req.ModelParam.Reasoning; rp != nil &&
 		rp.Type == spec.ReasoningTypeSingleWithLevels {
@@ -282,69 +523,33 @@ func (api *OpenAIResponsesAPI) FetchCompletion(
 		}
 	}
 
-	var span spec.CompletionSpan
-	if api.debugger != nil {
-		ctx, span = api.debugger.StartSpan(ctx, &spec.CompletionSpanStart{
-			Provider: pi.Name,
-			Model:    req.ModelParam.Name,
-			Request:  req,
-			Options:  opts,
-		})
-	}
-
-	var (
-		normalizedResp *spec.FetchCompletionResponse
-		fullRawResp    *responses.Response
-		apiErr         error
-	)
-	useStream := req.ModelParam.Stream && opts != nil && opts.StreamHandler != nil
-	if useStream {
-		normalizedResp, fullRawResp, apiErr = api.doStreaming(
-			ctx,
-			client,
-			pi.Name,
-			req.ModelParam.Name,
-			params,
-			opts,
-			timeout,
-			toolChoiceNameMap,
-		)
-	} else {
-		normalizedResp, fullRawResp, apiErr = api.doNonStreaming(ctx, client, params, timeout, toolChoiceNameMap)
-	}
-
-	if normalizedResp != nil && len(warns) > 0 {
-		normalizedResp.Warnings = append(normalizedResp.Warnings, warns...)
-	}
-
-	if span != nil {
-		end := spec.CompletionSpanEnd{
-			ProviderResponse: fullRawResp,
-			Response:         normalizedResp, // may be nil
-			Err:              apiErr,
-		}
-		if normalizedResp != nil {
-			if dd := span.End(&end); dd != nil && normalizedResp.DebugDetails == nil {
-				normalizedResp.DebugDetails = dd
-			}
-		} else {
-			_ = span.End(&end) // ignore return; nothing to attach to
-		}
+	additionalParams := req.ModelParam.AdditionalParametersRawJSON
+	extraWarns, err := sdkutil.AdditionalParametersWarnings(params, additionalParams, openAIResponsesOwnedKeys...)
+	if err != nil {
+		return nil, err
 	}
 
-	return normalizedResp, apiErr
+	return &openAIResponsesCall{
+		req:               req,
+		capabilities:      caps,
+		params:            params,
+		timeout:           timeout,
+		toolChoiceNameMap: toolChoiceNameMap,
+		additionalParams:  additionalParams,
+		warns:             append(warns, extraWarns...),
+	}, nil
 }
 
 func (api *OpenAIResponsesAPI) doNonStreaming(
 	ctx context.Context,
 	client *openai.Client,
 	params responses.ResponseNewParams,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *responses.Response, error) {
 	resp := &spec.FetchCompletionResponse{}
 
-	oaiResp, err := client.Responses.New(ctx, params, option.WithRequestTimeout(timeout))
+	oaiResp, err := client.Responses.New(ctx, params, reqOpts...)
 	resp.Usage = usageFromOpenAIResponse(oaiResp)
 
 	if err != nil {
@@ -354,6 +559,7 @@ func (api *OpenAIResponsesAPI) doNonStreaming(
 	}
 
 	resp.Outputs = outputsFromOpenAIResponse(oaiResp, toolChoiceNameMap)
+	resp.StopReason = stopReasonFromOpenAIResponse(oaiResp)
 	return resp, oaiResp, nil
 }
 
@@ -364,62 +570,16 @@ func (api *OpenAIResponsesAPI) doStreaming(
 	modelName spec.ModelName,
 	params responses.ResponseNewParams,
 	opts *spec.FetchCompletionOptions,
-	timeout time.Duration,
+	reqOpts []option.RequestOption,
 	toolChoiceNameMap map[string]spec.ToolChoice,
 ) (*spec.FetchCompletionResponse, *responses.Response, error) {
 	resp := &spec.FetchCompletionResponse{}
-	streamCfg := sdkutil.ResolveStreamConfig(opts)
-
-	emitText := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:          spec.StreamContentKindText,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Text: &spec.StreamTextChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	emitThinking := func(chunk string) error {
-		if chunk == "" {
-			return nil
-		}
-		event := spec.StreamEvent{
-			Kind:          spec.StreamContentKindThinking,
-			Provider:      providerName,
-			Model:         modelName,
-			CompletionKey: opts.CompletionKey,
-			Thinking: &spec.StreamThinkingChunk{
-				Text: chunk,
-			},
-		}
-		return sdkutil.SafeCallStreamHandler(opts.StreamHandler, event)
-	}
-
-	writeTextData, flushTextData := sdkutil.NewBufferedStreamerWithError(
-		emitText,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
-	writeThinkingData, flushThinkingData := sdkutil.NewBufferedStreamerWithError(
-		emitThinking,
-		streamCfg.FlushInterval,
-		streamCfg.FlushChunkSize,
-	)
+	emitter := sdkutil.NewStreamEmitter(ctx, providerName, modelName, opts)
+	events := newOpenAIResponsesStreamEvents(emitter)
 
 	var oaiResp responses.Response
 
-	stream := client.Responses.NewStreaming(
-		ctx,
-		params,
-		option.WithRequestTimeout(timeout),
-	)
+	stream := client.Responses.NewStreaming(ctx, params, reqOpts...)
 	defer func() { _ = stream.Close() }()
 
 	streamStartedAt := time.Now()
@@ -435,7 +595,11 @@ func (api *OpenAIResponsesAPI) doStreaming(
 
 		// Incremental assistant text.
 		if chunk.Type == "response.output_text.delta" {
-			streamWriteErr = writeTextData(chunk.Delta)
+			var logprobs []spec.TokenLogprob
+			if chunk.JSON.Logprobs.Valid() {
+				logprobs = tokenLogprobsFromResponsesDelta(chunk.AsResponseOutputTextDelta().Logprobs)
+			}
+			streamWriteErr = emitter.WriteTextLogprobs(chunk.Delta, logprobs)
 			if streamWriteErr != nil {
 				break
 			}
@@ -443,7 +607,7 @@ func (api *OpenAIResponsesAPI) doStreaming(
 
 		// Incremental reasoning text.
 		if chunk.Type == "response.reasoning_summary_text.delta" {
-			streamWriteErr = writeThinkingData(chunk.Delta)
+			streamWriteErr = emitter.WriteThinking(chunk.Delta)
 			if streamWriteErr != nil {
 				break
 			}
@@ -451,12 +615,18 @@ func (api *OpenAIResponsesAPI) doStreaming(
 
 		// Incremental reasoning text.
 		if chunk.Type == "response.reasoning_text.delta" {
-			streamWriteErr = writeThinkingData(chunk.Delta)
+			streamWriteErr = emitter.WriteThinking(chunk.Delta)
 			if streamWriteErr != nil {
 				break
 			}
 		}
 
+		// Output item boundaries, tool calls, web search progress and citations.
+		streamWriteErr = events.handle(chunk)
+		if streamWriteErr != nil {
+			break
+		}
+
 		if chunk.Type == "response.completed" {
 			oaiResp = chunk.Response
 			sawTerminal = true
@@ -483,13 +653,7 @@ func (api *OpenAIResponsesAPI) doStreaming(
 			break
 		}
 	}
-	var flushErr error
-	if flushTextData != nil {
-		flushErr = errors.Join(flushErr, flushTextData())
-	}
-	if flushThinkingData != nil {
-		flushErr = errors.Join(flushErr, flushThinkingData())
-	}
+	flushErr := emitter.Close()
 
 	iteratorErr := stream.Err()
 	if !sawTerminal && iteratorErr == nil && streamWriteErr == nil {
@@ -497,10 +661,14 @@ func (api *OpenAIResponsesAPI) doStreaming(
 			"openai responses stream ended before a terminal response event",
 		)
 	}
+	if iteratorErr == nil && streamWriteErr == nil && flushErr == nil {
+		streamWriteErr = emitter.Completed(usageFromOpenAIResponse(&oaiResp), stopReasonFromOpenAIResponse(&oaiResp))
+	}
 
 	streamErr := errors.Join(iteratorErr, streamWriteErr, flushErr)
 	if streamErr != nil {
-		logutil.Error(
+		logutil.ErrorContext(
+			ctx,
 			"openai responses stream terminated",
 			"provider", string(providerName),
 			"model", string(modelName),
@@ -523,6 +691,7 @@ func (api *OpenAIResponsesAPI) doStreaming(
 	if len(oaiResp.Output) > 0 {
 		resp.Outputs = outputsFromOpenAIResponse(&oaiResp, toolChoiceNameMap)
 	}
+	resp.StopReason = stopReasonFromOpenAIResponse(&oaiResp)
 
 	return resp, &oaiResp, streamErr
 }
@@ -686,7 +855,7 @@ func openAIResponsesAllowedToolEntry(t sdkutil.ResolvedAllowedTool) (map[string]
 }
 
 func toOpenAIResponsesInput(
-	_ context.Context,
+	ctx context.Context,
 	inputs []spec.InputUnion,
 ) (responses.ResponseInputParam, error) {
 	var out responses.ResponseInputParam
@@ -704,7 +873,7 @@ func toOpenAIResponsesInput(
 				// Other roles are not valid for input message type.
 				continue
 			}
-			items, err := contentItemsToOpenAIInputContent(in.InputMessage.Contents)
+			items, err := contentItemsToOpenAIInputContent(ctx, in.InputMessage.Contents)
 			if err != nil {
 				return nil, err
 			}
@@ -726,7 +895,7 @@ func toOpenAIResponsesInput(
 				// Both are assistant generated.
 				continue
 			}
-			items, err := contentItemsToOpenAIOutputContent(in.OutputMessage.Contents)
+			items, err := contentItemsToOpenAIOutputContent(ctx, in.OutputMessage.Contents)
 			if err != nil {
 				return nil, err
 			}
@@ -784,7 +953,7 @@ func toOpenAIResponsesInput(
 				output = in.CustomToolOutput
 			}
 
-			if tc := toolOutputToOpenAIResponses(output); tc != nil {
+			if tc := toolOutputToOpenAIResponses(ctx, output); tc != nil {
 				out = append(out, *tc)
 			}
 
@@ -798,6 +967,7 @@ func toOpenAIResponsesInput(
 
 // contentItemsToOpenAI converts spec.Content items to OpenAI input message parts.
 func contentItemsToOpenAIInputContent(
+	ctx context.Context,
 	items []spec.InputOutputContentItemUnion,
 ) ([]responses.ResponseInputContentUnionParam, error) {
 	out := make([]responses.ResponseInputContentUnionParam, 0, len(items))
@@ -853,7 +1023,7 @@ func contentItemsToOpenAIInputContent(
 					OfInputImage: &oaiImg,
 				})
 			} else {
-				logutil.Debug("no data or url present for image", "id", img.ID, "name", img.ImageName)
+				logutil.DebugContext(ctx, "no data or url present for image", "id", img.ID, "name", img.ImageName)
 			}
 
 		case spec.ContentItemKindFile:
@@ -886,13 +1056,41 @@ func contentItemsToOpenAIInputContent(
 					OfInputFile: &fileParam,
 				})
 			} else {
-				logutil.Debug("no data or url present for file", "id", f.ID, "name", f.FileName)
+				logutil.DebugContext(ctx, "no data or url present for file", "id", f.ID, "name", f.FileName)
 			}
+		case spec.ContentItemKindAudio:
+			if it.AudioItem == nil {
+				continue
+			}
+			a := it.AudioItem
+
+			// input_audio only takes inline data.
+			if data := strings.TrimSpace(a.AudioData); data != "" {
+				mime := strings.TrimSpace(a.AudioMIME)
+				if mime == "" {
+					mime = spec.DefaultAudioDataMIME
+				}
+				// The SDK's input content union has no audio variant yet; send the part as an override.
+				out = append(out, param.Override[responses.ResponseInputContentUnionParam](
+					responses.ResponseInputAudioParam{
+						InputAudio: responses.ResponseInputAudioInputAudioParam{
+							Data:   data,
+							Format: sdkutil.AudioFormatFromMIME(mime),
+						},
+					},
+				))
+			} else {
+				logutil.DebugContext(ctx, "no data present for audio", "id", a.ID, "name", a.AudioName)
+			}
+
+		case spec.ContentItemKindVideo:
+			logutil.DebugContext(ctx, "video input is not supported")
+
 		case spec.ContentItemKindRefusal:
 			// Refusal should not be present in InputMessage.
 			continue
 		default:
-			logutil.Debug("unknown content for input messages", "kind", it.Kind)
+			logutil.DebugContext(ctx, "unknown content for input messages", "kind", it.Kind)
 		}
 	}
 	return out, nil
@@ -900,6 +1098,7 @@ func contentItemsToOpenAIInputContent(
 
 // contentItemsToOpenAI converts spec.Content items to OpenAI output message parts.
 func contentItemsToOpenAIOutputContent(
+	ctx context.Context,
 	items []spec.InputOutputContentItemUnion,
 ) ([]responses.ResponseOutputMessageContentUnionParam, error) {
 	out := make([]responses.ResponseOutputMessageContentUnionParam, 0, len(items))
@@ -931,7 +1130,7 @@ func contentItemsToOpenAIOutputContent(
 		case spec.ContentItemKindImage, spec.ContentItemKindFile:
 			// Image and PDF should not be present in OutputMessage.
 		default:
-			logutil.Debug("unknown content for output messages", "kind", it.Kind)
+			logutil.DebugContext(ctx, "unknown content for output messages", "kind", it.Kind)
 		}
 	}
 	return out, nil
@@ -1091,6 +1290,7 @@ func webSearchToolCallToOpenAIResponses(
 }
 
 func toolOutputToOpenAIResponses(
+	ctx context.Context,
 	toolOutput *spec.ToolOutput,
 ) *responses.ResponseInputItemUnionParam {
 	if toolOutput == nil || strings.TrimSpace(toolOutput.CallID) == "" {
@@ -1110,7 +1310,7 @@ func toolOutputToOpenAIResponses(
 			}
 		}
 
-		items, err := contentItemsToOpenAIFunctionCallOutputContent(toolOutput.Contents)
+		items, err := contentItemsToOpenAIFunctionCallOutputContent(ctx, toolOutput.Contents)
 		if err != nil {
 			return nil
 		}
@@ -1139,7 +1339,7 @@ func toolOutputToOpenAIResponses(
 			}
 		}
 
-		fcItems, err := contentItemsToOpenAIFunctionCallOutputContent(toolOutput.Contents)
+		fcItems, err := contentItemsToOpenAIFunctionCallOutputContent(ctx, toolOutput.Contents)
 		if err != nil {
 			return nil
 		}
@@ -1210,6 +1410,7 @@ func toolOutputAsSingleString(toolOutput *spec.ToolOutput) (string, bool) {
 
 // contentItemsToOpenAI converts spec.Content items to OpenAI input message parts.
 func contentItemsToOpenAIFunctionCallOutputContent(
+	ctx context.Context,
 	items []spec.ToolOutputItemUnion,
 ) ([]responses.ResponseFunctionCallOutputItemUnionParam, error) {
 	out := make([]responses.ResponseFunctionCallOutputItemUnionParam, 0, len(items))
@@ -1269,7 +1470,7 @@ func contentItemsToOpenAIFunctionCallOutputContent(
 					OfInputImage: &oaiImg,
 				})
 			} else {
-				logutil.Debug("no data or url present for image", "id", img.ID, "name", img.ImageName)
+				logutil.DebugContext(ctx, "no data or url present for image", "id", img.ID, "name", img.ImageName)
 			}
 
 		case spec.ContentItemKindFile:
@@ -1302,13 +1503,13 @@ func contentItemsToOpenAIFunctionCallOutputContent(
 					OfInputFile: &fileParam,
 				})
 			} else {
-				logutil.Debug("no data or url present for file", "id", f.ID, "name", f.FileName)
+				logutil.DebugContext(ctx, "no data or url present for file", "id", f.ID, "name", f.FileName)
 			}
 		case spec.ContentItemKindRefusal:
 			// Refusal should not be present in call output.
 			continue
 		default:
-			logutil.Debug("unknown content for input messages", "kind", it.Kind)
+			logutil.DebugContext(ctx, "unknown content for input messages", "kind", it.Kind)
 		}
 	}
 	return out, nil
@@ -1422,6 +1623,7 @@ func outputsFromOpenAIResponse(
 					textItem := spec.ContentItemText{
 						Text:      c.Text,
 						Citations: responsesAnnotationsToCitations(c.Annotations),
+						Logprobs:  tokenLogprobsFromResponses(c.Logprobs),
 					}
 					outMsg.Contents = append(
 						outMsg.Contents,
@@ -1651,6 +1853,52 @@ func responsesAnnotationsToCitations(
 	return out
 }
 
+// stopReasonFromOpenAIResponse derives a stop reason from the response status, its incomplete details and its
+// output items, as the Responses API has no stop reason field. Raw is the incomplete reason when there is one, else
+// the status.
+func stopReasonFromOpenAIResponse(resp *responses.Response) *spec.StopReason {
+	if resp == nil {
+		return nil
+	}
+	out := &spec.StopReason{Raw: string(resp.Status)}
+	switch resp.Status {
+	case responses.ResponseStatusCompleted:
+		out.Kind = spec.StopReasonEndTurn
+		for _, item := range resp.Output {
+			switch item.Type {
+			case string(openaiSharedConstant.FunctionCall("").Default()),
+				string(openaiSharedConstant.CustomToolCall("").Default()):
+				out.Kind = spec.StopReasonToolUse
+			case string(openaiSharedConstant.Message("").Default()):
+				for _, c := range item.AsMessage().Content {
+					if strings.TrimSpace(c.Refusal) != "" && out.Kind == spec.StopReasonEndTurn {
+						out.Kind = spec.StopReasonRefusal
+					}
+				}
+			default:
+			}
+		}
+	case responses.ResponseStatusIncomplete:
+		out.Kind = spec.StopReasonOther
+		if reason := resp.IncompleteDetails.Reason; reason != "" {
+			out.Raw = reason
+			switch reason {
+			case "max_output_tokens":
+				out.Kind = spec.StopReasonMaxTokens
+			case "content_filter":
+				out.Kind = spec.StopReasonContentFilter
+			default:
+			}
+		}
+	case responses.ResponseStatusFailed, responses.ResponseStatusCancelled:
+		out.Kind = spec.StopReasonOther
+	default:
+		// Not finished.
+		return nil
+	}
+	return out
+}
+
 // usageFromOpenAIResponse normalizes OpenAI Responses API usage into spec.Usage.
 func usageFromOpenAIResponse(resp *responses.Response) *spec.Usage {
 	uOut := &spec.Usage{}
@@ -1666,6 +1914,13 @@ func usageFromOpenAIResponse(resp *responses.Response) *spec.Usage {
 	uOut.OutputTokens = u.OutputTokens
 	uOut.ReasoningTokens = u.OutputTokensDetails.ReasoningTokens
 
+	// Usage has no server tool counts; every web search call output item is billed as one call.
+	for _, item := range resp.Output {
+		if item.Type == string(openaiSharedConstant.WebSearchCall("").Default()) {
+			uOut.WebSearchRequests++
+		}
+	}
+
 	return uOut
 }
 
@@ -1686,3 +1941,28 @@ func fromOpenAIStatus(status string) spec.Status {
 		return spec.Status(status)
 	}
 }
+
+func tokenLogprobsFromResponses(in []responses.ResponseOutputTextLogprob) []spec.TokenLogprob {
+	return openaisdkutil.TokenLogprobs(
+		in,
+		func(lp responses.ResponseOutputTextLogprob) (string, float64, []responses.ResponseOutputTextLogprobTopLogprob) {
+			return lp.Token, lp.Logprob, lp.TopLogprobs
+		},
+		func(alt responses.ResponseOutputTextLogprobTopLogprob) (string, float64) {
+			return alt.Token, alt.Logprob
+		},
+	)
+}
+
+// tokenLogprobsFromResponsesDelta converts the logprobs of a response.output_text.delta event.
+func tokenLogprobsFromResponsesDelta(in []responses.ResponseTextDeltaEventLogprob) []spec.TokenLogprob {
+	return openaisdkutil.TokenLogprobs(
+		in,
+		func(lp responses.ResponseTextDeltaEventLogprob) (string, float64, []responses.ResponseTextDeltaEventLogprobTopLogprob) {
+			return lp.Token, lp.Logprob, lp.TopLogprobs
+		},
+		func(alt responses.ResponseTextDeltaEventLogprobTopLogprob) (string, float64) {
+			return alt.Token, alt.Logprob
+		},
+	)
+}
@@ -3,7 +3,11 @@ package openairesponsessdk
 import "github.com/flexigpt/inference-go/spec"
 
 var openairesponsessdkCapability = spec.ModelCapabilities{
-	ModalitiesIn:  []spec.Modality{spec.ModalityTextIn, spec.ModalityImageIn, spec.ModalityFileIn},
+	ModalitiesIn: []spec.Modality{
+		spec.ModalityTextIn,
+		spec.ModalityImageIn,
+		spec.ModalityFileIn,
+	},
 	ModalitiesOut: []spec.Modality{spec.ModalityTextOut},
 
 	ReasoningCapabilities: &spec.ReasoningCapabilities{
@@ -29,6 +33,11 @@ var openairesponsessdkCapability = spec.ModelCapabilities{
 		DisallowedWithReasoning: false,
 		MaxSequences:            0,
 	},
+	SamplingCapabilities: &spec.SamplingCapabilities{
+		SupportsTopP:     true,
+		SupportsLogprobs: true,
+		MaxTopLogprobs:   20,
+	},
 	OutputCapabilities: &spec.OutputCapabilities{
 		SupportedOutputFormats: []spec.OutputFormatKind{spec.OutputFormatKindText, spec.OutputFormatKindJSONSchema},
 		SupportsVerbosity:      true,
@@ -0,0 +1,61 @@
+package openairesponsessdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOpenAIResponsesLogprobs(t *testing.T) {
+	t.Parallel()
+
+	want := []spec.TokenLogprob{{
+		Token:   "Yes",
+		Logprob: -0.01,
+		TopAlternatives: []spec.TokenAlternative{
+			{Token: "Yes", Logprob: -0.01},
+			{Token: "No", Logprob: -4.6},
+		},
+	}}
+
+	var resp responses.Response
+	if err := json.Unmarshal([]byte(`{"status": "completed", "output": [
+		{"type": "message", "id": "m1", "role": "assistant", "status": "completed", "content": [
+			{"type": "output_text", "text": "Yes", "annotations": [], "logprobs": [
+				{"token": "Yes", "bytes": [89, 101, 115], "logprob": -0.01, "top_logprobs": [
+					{"token": "Yes", "bytes": [89, 101, 115], "logprob": -0.01},
+					{"token": "No", "bytes": [78, 111], "logprob": -4.6}
+				]}
+			]}
+		]}
+	]}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	outs := outputsFromOpenAIResponse(&resp, nil)
+	if len(outs) != 1 || outs[0].OutputMessage == nil {
+		t.Fatalf("outputs = %+v", outs)
+	}
+	if got := outs[0].OutputMessage.Contents[0].TextItem.Logprobs; !reflect.DeepEqual(got, want) {
+		t.Errorf("output logprobs = %+v, want %+v", got, want)
+	}
+
+	var delta responses.ResponseStreamEventUnion
+	if err := json.Unmarshal([]byte(`{"type": "response.output_text.delta", "item_id": "m1", "output_index": 0,
+		"content_index": 0, "delta": "Yes", "sequence_number": 3, "logprobs": [
+			{"token": "Yes", "logprob": -0.01, "top_logprobs": [
+				{"token": "Yes", "logprob": -0.01}, {"token": "No", "logprob": -4.6}
+			]}
+		]}`), &delta); err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenLogprobsFromResponsesDelta(delta.AsResponseOutputTextDelta().Logprobs); !reflect.DeepEqual(
+		got,
+		want,
+	) {
+		t.Errorf("delta logprobs = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package openairesponsessdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestStopReasonFromOpenAIResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  string
+		want *spec.StopReason
+	}{
+		{name: "in progress", raw: `{"status": "in_progress"}`, want: nil},
+		{
+			name: "completed",
+			raw: `{"status": "completed", "output": [
+				{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "hi"}]}
+			]}`,
+			want: &spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "completed"},
+		},
+		{
+			name: "function call",
+			raw: `{"status": "completed", "output": [
+				{"type": "function_call", "call_id": "c1", "name": "f", "arguments": "{}"}
+			]}`,
+			want: &spec.StopReason{Kind: spec.StopReasonToolUse, Raw: "completed"},
+		},
+		{
+			name: "refusal",
+			raw: `{"status": "completed", "output": [
+				{"type": "message", "role": "assistant", "content": [{"type": "refusal", "refusal": "no"}]}
+			]}`,
+			want: &spec.StopReason{Kind: spec.StopReasonRefusal, Raw: "completed"},
+		},
+		{
+			name: "max output tokens",
+			raw:  `{"status": "incomplete", "incomplete_details": {"reason": "max_output_tokens"}}`,
+			want: &spec.StopReason{Kind: spec.StopReasonMaxTokens, Raw: "max_output_tokens"},
+		},
+		{
+			name: "content filter",
+			raw:  `{"status": "incomplete", "incomplete_details": {"reason": "content_filter"}}`,
+			want: &spec.StopReason{Kind: spec.StopReasonContentFilter, Raw: "content_filter"},
+		},
+		{
+			name: "failed",
+			raw:  `{"status": "failed"}`,
+			want: &spec.StopReason{Kind: spec.StopReasonOther, Raw: "failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var resp responses.Response
+			if err := json.Unmarshal([]byte(tt.raw), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			got := stopReasonFromOpenAIResponse(&resp)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
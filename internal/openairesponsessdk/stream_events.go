@@ -0,0 +1,177 @@
+package openairesponsessdk
+
+import (
+	"github.com/openai/openai-go/v3/responses"
+	openaiSharedConstant "github.com/openai/openai-go/v3/shared/constant"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// openAIResponsesStreamItem tracks one in-flight output item of a Responses stream.
+type openAIResponsesStreamItem struct {
+	kind     spec.OutputKind
+	id       string
+	callID   string
+	name     string
+	toolType spec.ToolType
+}
+
+// openAIResponsesStreamEvents maps Responses stream events other than text and
+// reasoning deltas to normalized stream events. Output indexes are the
+// provider's output_index.
+type openAIResponsesStreamEvents struct {
+	emitter *sdkutil.StreamEmitter
+	items   map[int64]*openAIResponsesStreamItem
+}
+
+func newOpenAIResponsesStreamEvents(emitter *sdkutil.StreamEmitter) *openAIResponsesStreamEvents {
+	return &openAIResponsesStreamEvents{
+		emitter: emitter,
+		items:   map[int64]*openAIResponsesStreamItem{},
+	}
+}
+
+func (s *openAIResponsesStreamEvents) handle(chunk responses.ResponseStreamEventUnion) error {
+	idx := int(chunk.OutputIndex)
+
+	switch chunk.Type {
+	case "response.output_item.added":
+		item := openAIResponsesStreamItemFromOutput(chunk.Item)
+		if item == nil {
+			return nil
+		}
+		s.items[chunk.OutputIndex] = item
+		if err := s.emitter.OutputItemStart(idx, item.kind, item.id); err != nil {
+			return err
+		}
+		if item.toolType == spec.ToolTypeFunction || item.toolType == spec.ToolTypeCustom {
+			return s.emitter.ToolCall(spec.StreamContentKindToolCallStart, s.toolCallChunk(idx, item))
+		}
+
+	case "response.function_call_arguments.delta", "response.custom_tool_call_input.delta":
+		item := s.items[chunk.OutputIndex]
+		if item == nil || chunk.Delta == "" {
+			return nil
+		}
+		tc := s.toolCallChunk(idx, item)
+		tc.ArgumentsDelta = chunk.Delta
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallDelta, tc)
+
+	case "response.web_search_call.in_progress", "response.web_search_call.searching":
+		status := spec.StatusInProgress
+		if chunk.Type == "response.web_search_call.searching" {
+			status = spec.StatusSearching
+		}
+		return s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      chunk.ItemID,
+			Status:      status,
+		})
+
+	case "response.output_text.annotation.added":
+		a := chunk.Annotation
+		if a.Type != string(openaiSharedConstant.URLCitation("").Default()) {
+			// Only URL citations are currently supported.
+			return nil
+		}
+		return s.emitter.Citation(idx, spec.Citation{
+			Kind: spec.CitationKindURL,
+			URLCitation: &spec.URLCitation{
+				URL:        a.URL,
+				Title:      a.Title,
+				StartIndex: a.StartIndex,
+				EndIndex:   a.EndIndex,
+			},
+		})
+
+	case "response.output_item.done":
+		item := s.items[chunk.OutputIndex]
+		if item == nil {
+			return nil
+		}
+		delete(s.items, chunk.OutputIndex)
+		if err := s.handleItemDone(idx, item, chunk.Item); err != nil {
+			return err
+		}
+		return s.emitter.OutputItemStop(idx, item.kind, item.id)
+
+	default:
+		// Text, reasoning and lifecycle events are handled by the caller.
+	}
+	return nil
+}
+
+func (s *openAIResponsesStreamEvents) handleItemDone(
+	idx int,
+	item *openAIResponsesStreamItem,
+	done responses.ResponseOutputItemUnion,
+) error {
+	switch item.kind {
+	case spec.OutputKindFunctionToolCall:
+		tc := s.toolCallChunk(idx, item)
+		tc.Arguments = done.AsFunctionCall().Arguments
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, tc)
+
+	case spec.OutputKindCustomToolCall:
+		tc := s.toolCallChunk(idx, item)
+		tc.Arguments = done.AsCustomToolCall().Input
+		return s.emitter.ToolCall(spec.StreamContentKindToolCallEnd, tc)
+
+	case spec.OutputKindWebSearchToolCall:
+		ws := done.AsWebSearchCall()
+		status := spec.StatusCompleted
+		if ws.Status == "failed" {
+			status = spec.StatusFailed
+		}
+		return s.emitter.WebSearchCall(spec.StreamWebSearchCallChunk{
+			OutputIndex: idx,
+			CallID:      item.id,
+			Status:      status,
+			Query:       ws.Action.Query,
+		})
+
+	default:
+		return nil
+	}
+}
+
+func (s *openAIResponsesStreamEvents) toolCallChunk(
+	idx int,
+	item *openAIResponsesStreamItem,
+) spec.StreamToolCallChunk {
+	return spec.StreamToolCallChunk{
+		OutputIndex: idx,
+		Type:        item.toolType,
+		CallID:      item.callID,
+		Name:        item.name,
+	}
+}
+
+func openAIResponsesStreamItemFromOutput(item responses.ResponseOutputItemUnion) *openAIResponsesStreamItem {
+	out := &openAIResponsesStreamItem{id: item.ID}
+	switch item.Type {
+	case string(openaiSharedConstant.Message("").Default()):
+		out.kind = spec.OutputKindOutputMessage
+	case string(openaiSharedConstant.Reasoning("").Default()):
+		out.kind = spec.OutputKindReasoningMessage
+	case string(openaiSharedConstant.FunctionCall("").Default()):
+		out.kind = spec.OutputKindFunctionToolCall
+		out.toolType = spec.ToolTypeFunction
+		out.callID = item.CallID
+		out.name = item.Name
+	case string(openaiSharedConstant.CustomToolCall("").Default()):
+		out.kind = spec.OutputKindCustomToolCall
+		out.toolType = spec.ToolTypeCustom
+		out.callID = item.CallID
+		out.name = item.Name
+	case string(openaiSharedConstant.WebSearchCall("").Default()):
+		out.kind = spec.OutputKindWebSearchToolCall
+		out.toolType = spec.ToolTypeWebSearch
+		out.callID = item.ID
+	default:
+		// Output item types we do not normalize.
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+package openairesponsessdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/openai/openai-go/v3/responses"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestOpenAIResponsesStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		rawEvents []string
+		want      []string
+		verify    func(t *testing.T, events []spec.StreamEvent)
+	}{
+		{
+			name: "web search text and function call",
+			rawEvents: []string{
+				`{"type":"response.output_item.added","output_index":0,"item":` +
+					`{"type":"web_search_call","id":"ws_1","status":"in_progress"}}`,
+				`{"type":"response.web_search_call.in_progress","output_index":0,"item_id":"ws_1"}`,
+				`{"type":"response.web_search_call.searching","output_index":0,"item_id":"ws_1"}`,
+				`{"type":"response.output_item.done","output_index":0,"item":{"type":"web_search_call","id":"ws_1",` +
+					`"status":"completed","action":{"type":"search","query":"go"}}}`,
+				`{"type":"response.output_item.added","output_index":1,"item":` +
+					`{"type":"message","id":"msg_1","role":"assistant","status":"in_progress","content":[]}}`,
+				`{"type":"response.output_text.delta","output_index":1,"item_id":"msg_1","delta":"Go is great."}`,
+				`{"type":"response.output_text.annotation.added","output_index":1,"item_id":"msg_1","annotation":` +
+					`{"type":"url_citation","url":"https://go.dev","title":"Go","start_index":0,"end_index":12}}`,
+				`{"type":"response.output_item.done","output_index":1,"item":` +
+					`{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[]}}`,
+				`{"type":"response.output_item.added","output_index":2,"item":{"type":"function_call","id":"fc_1",` +
+					`"call_id":"call_1","name":"get_weather","arguments":""}}`,
+				`{"type":"response.function_call_arguments.delta","output_index":2,"item_id":"fc_1",` +
+					`"delta":"{\"city\":"}`,
+				`{"type":"response.function_call_arguments.delta","output_index":2,"item_id":"fc_1",` +
+					`"delta":"\"Paris\"}"}`,
+				`{"type":"response.output_item.done","output_index":2,"item":{"type":"function_call","id":"fc_1",` +
+					`"call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}`,
+			},
+			want: []string{
+				"outputItemStart@0",
+				"webSearchCall@0",
+				"webSearchCall@0",
+				"webSearchCall@0",
+				"outputItemStop@0",
+				"outputItemStart@1",
+				"text",
+				"citation@1",
+				"outputItemStop@1",
+				"outputItemStart@2",
+				"toolCallStart@2",
+				"toolCallDelta@2",
+				"toolCallDelta@2",
+				"toolCallEnd@2",
+				"outputItemStop@2",
+			},
+			verify: func(t *testing.T, events []spec.StreamEvent) {
+				t.Helper()
+				for i, want := range []spec.Status{spec.StatusInProgress, spec.StatusSearching, spec.StatusCompleted} {
+					if ws := events[1+i].WebSearchCall; ws.Status != want || ws.CallID != "ws_1" {
+						t.Errorf("web search event %d: got %+v, want status %s", i, ws, want)
+					}
+				}
+				if ws := events[3].WebSearchCall; ws.Query != "go" {
+					t.Errorf("web search done: got %+v", ws)
+				}
+				if item := events[5].OutputItem; item.Kind != spec.OutputKindOutputMessage || item.ID != "msg_1" {
+					t.Errorf("message item start: got %+v", item)
+				}
+				if c := events[7].Citation.Citation.URLCitation; c == nil || c.URL != "https://go.dev" ||
+					c.EndIndex != 12 {
+					t.Errorf("citation: got %+v", events[7].Citation)
+				}
+				if end := events[13].ToolCall; end.Arguments != `{"city":"Paris"}` || end.CallID != "call_1" ||
+					end.Name != "get_weather" || end.Type != spec.ToolTypeFunction {
+					t.Errorf("function call end: got %+v", end)
+				}
+			},
+		},
+		{
+			name: "reasoning and custom tool call",
+			rawEvents: []string{
+				`{"type":"response.output_item.added","output_index":0,"item":` +
+					`{"type":"reasoning","id":"rs_1","summary":[]}}`,
+				`{"type":"response.output_item.done","output_index":0,"item":` +
+					`{"type":"reasoning","id":"rs_1","summary":[]}}`,
+				`{"type":"response.output_item.added","output_index":1,"item":{"type":"custom_tool_call","id":"ct_1",` +
+					`"call_id":"call_2","name":"run_sql","input":""}}`,
+				`{"type":"response.custom_tool_call_input.delta","output_index":1,"item_id":"ct_1","delta":"SELECT 1"}`,
+				`{"type":"response.custom_tool_call_input.delta","output_index":1,"item_id":"ct_1","delta":""}`,
+				`{"type":"response.output_item.done","output_index":1,"item":{"type":"custom_tool_call","id":"ct_1",` +
+					`"call_id":"call_2","name":"run_sql","input":"SELECT 1"}}`,
+			},
+			want: []string{
+				"outputItemStart@0",
+				"outputItemStop@0",
+				"outputItemStart@1",
+				"toolCallStart@1",
+				"toolCallDelta@1",
+				"toolCallEnd@1",
+				"outputItemStop@1",
+			},
+			verify: func(t *testing.T, events []spec.StreamEvent) {
+				t.Helper()
+				if item := events[0].OutputItem; item.Kind != spec.OutputKindReasoningMessage || item.ID != "rs_1" {
+					t.Errorf("reasoning item start: got %+v", item)
+				}
+				if end := events[5].ToolCall; end.Arguments != "SELECT 1" || end.Type != spec.ToolTypeCustom {
+					t.Errorf("custom call end: got %+v", end)
+				}
+			},
+		},
+		{
+			name: "unknown items and annotations",
+			rawEvents: []string{
+				`{"type":"response.output_item.added","output_index":0,"item":` +
+					`{"type":"image_generation_call","id":"ig_1","status":"in_progress"}}`,
+				`{"type":"response.function_call_arguments.delta","output_index":0,"item_id":"ig_1","delta":"x"}`,
+				`{"type":"response.output_item.done","output_index":0,"item":` +
+					`{"type":"image_generation_call","id":"ig_1","status":"completed"}}`,
+				`{"type":"response.output_text.annotation.added","output_index":1,"item_id":"msg_1","annotation":` +
+					`{"type":"file_citation","file_id":"f_1","filename":"a.txt","index":0}}`,
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var events []spec.StreamEvent
+			emitter := sdkutil.NewStreamEmitter(t.Context(), "p", "m", &spec.FetchCompletionOptions{
+				StreamHandler: func(ev spec.StreamEvent) error {
+					events = append(events, ev)
+					return nil
+				},
+				StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000},
+			})
+			s := newOpenAIResponsesStreamEvents(emitter)
+
+			for i, raw := range tt.rawEvents {
+				var chunk responses.ResponseStreamEventUnion
+				if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+					t.Fatalf("event %d: %v", i, err)
+				}
+				// Text deltas are written by the caller, as in the streaming loop.
+				if chunk.Type == "response.output_text.delta" {
+					if err := emitter.WriteText(chunk.Delta); err != nil {
+						t.Fatalf("event %d: %v", i, err)
+					}
+				}
+				if err := s.handle(chunk); err != nil {
+					t.Fatalf("event %d: %v", i, err)
+				}
+			}
+			if err := emitter.Close(); err != nil {
+				t.Fatalf("close: %v", err)
+			}
+
+			got := make([]string, 0, len(events))
+			for _, ev := range events {
+				got = append(got, openAIResponsesStreamEventLabel(ev))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("events:\n got %v\nwant %v", got, tt.want)
+			}
+			if tt.verify != nil {
+				tt.verify(t, events)
+			}
+		})
+	}
+}
+
+// openAIResponsesStreamEventLabel renders an event as its kind and, where it has one, its output index.
+func openAIResponsesStreamEventLabel(ev spec.StreamEvent) string {
+	switch {
+	case ev.OutputItem != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.OutputItem.OutputIndex)
+	case ev.ToolCall != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.ToolCall.OutputIndex)
+	case ev.WebSearchCall != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.WebSearchCall.OutputIndex)
+	case ev.Citation != nil:
+		return fmt.Sprintf("%s@%d", ev.Kind, ev.Citation.OutputIndex)
+	default:
+		return string(ev.Kind)
+	}
+}
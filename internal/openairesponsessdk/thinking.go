@@ -1,6 +1,7 @@
 package openairesponsessdk
 
 import (
+	"context"
 	"strings"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
@@ -83,7 +84,7 @@ func reasoningContentToOpenAIItem(
 //
 // This prevents leaking or incorrectly forwarding signature-based / plaintext reasoning content
 // (e.g. from other providers) into the OpenAI Responses API.
-func sanitizeReasoningInputs(inputs []spec.InputUnion) []spec.InputUnion {
+func sanitizeReasoningInputs(ctx context.Context, inputs []spec.InputUnion) []spec.InputUnion {
 	if len(inputs) == 0 {
 		return nil
 	}
@@ -141,7 +142,8 @@ func sanitizeReasoningInputs(inputs []spec.InputUnion) []spec.InputUnion {
 	}
 
 	if droppedReasoning > 0 {
-		logutil.Debug(
+		logutil.DebugContext(
+			ctx,
 			"openai responses: sanitized reasoning messages",
 			"hasEncrypted", hasEncrypted,
 			"kept", keptReasoning,
@@ -0,0 +1,27 @@
+package openaisdkutil
+
+import "github.com/flexigpt/inference-go/spec"
+
+// TokenLogprobs converts the per token logprobs of an OpenAI SDK response into spec.TokenLogprob values. The SDK
+// declares one struct per endpoint and event for the same token / logprob / top_logprobs shape, so token extracts
+// the chosen token of an entry together with its alternatives and alt extracts each alternative.
+func TokenLogprobs[L, A any](
+	in []L,
+	token func(L) (string, float64, []A),
+	alt func(A) (string, float64),
+) []spec.TokenLogprob {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]spec.TokenLogprob, 0, len(in))
+	for _, lp := range in {
+		tok, logprob, top := token(lp)
+		tl := spec.TokenLogprob{Token: tok, Logprob: logprob}
+		for _, a := range top {
+			altTok, altLogprob := alt(a)
+			tl.TopAlternatives = append(tl.TopAlternatives, spec.TokenAlternative{Token: altTok, Logprob: altLogprob})
+		}
+		out = append(out, tl)
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+// Package openaisdkutil holds helpers shared by the adapters built on the OpenAI Go SDK.
+package openaisdkutil
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/ssestream"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// NewProviderError maps an OpenAI SDK / stream error into a spec.ProviderError.
+func NewProviderError(provider spec.ProviderName, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var details sdkutil.ProviderErrorDetails
+	var apiErr *openai.Error
+	var streamErr *ssestream.StreamError
+	switch {
+	case errors.As(err, &apiErr):
+		details.HTTPStatus = apiErr.StatusCode
+		details.ProviderCode = apiErr.Code
+		if details.ProviderCode == "" {
+			details.ProviderCode = apiErr.Type
+		}
+		if apiErr.Response != nil {
+			details.Header = apiErr.Response.Header
+			details.RequestID = apiErr.Response.Header.Get("x-request-id")
+		}
+	case errors.As(err, &streamErr):
+		details.ProviderCode = openAIStreamErrorCode(streamErr.Event.Data)
+	default:
+		// Transport, context or adapter generated error.
+	}
+
+	return sdkutil.NewProviderError(provider, err, details)
+}
+
+// openAIStreamErrorCode extracts error.code (or error.type) from an SSE error payload.
+func openAIStreamErrorCode(data []byte) string {
+	var payload struct {
+		Error struct {
+			Code string `json:"code"`
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return ""
+	}
+	if payload.Error.Code != "" {
+		return payload.Error.Code
+	}
+	return payload.Error.Type
+}
@@ -0,0 +1,68 @@
+package openaisdkutil
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/ssestream"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestNewProviderError(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("x-request-id", "req_1")
+	header.Set("Retry-After", "2")
+
+	cases := []struct {
+		name        string
+		err         error
+		wantKind    spec.ProviderErrorKind
+		wantCode    string
+		wantRequest string
+	}{
+		{
+			name: "api error",
+			err: &openai.Error{
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "rate_limit_error",
+				Request:    httptest.NewRequest(http.MethodPost, "/v1/responses", http.NoBody),
+				Response:   &http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+			},
+			wantKind:    spec.ProviderErrorKindRateLimited,
+			wantCode:    "rate_limit_error",
+			wantRequest: "req_1",
+		},
+		{
+			name: "stream error",
+			err: &ssestream.StreamError{Event: ssestream.Event{
+				Data: []byte(`{"error":{"code":"context_length_exceeded"}}`),
+			}},
+			wantKind: spec.ProviderErrorKindContextLengthExceeded,
+			wantCode: "context_length_exceeded",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var pe *spec.ProviderError
+			if !errors.As(NewProviderError("openai", tc.err), &pe) {
+				t.Fatal("expected a spec.ProviderError")
+			}
+			if pe.Kind != tc.wantKind || pe.ProviderCode != tc.wantCode || pe.RequestID != tc.wantRequest {
+				t.Fatalf("got kind=%q code=%q request=%q", pe.Kind, pe.ProviderCode, pe.RequestID)
+			}
+		})
+	}
+
+	if NewProviderError("openai", nil) != nil {
+		t.Fatal("expected nil for a nil error")
+	}
+}
@@ -0,0 +1,182 @@
+package sdkutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	warnAdditionalParamOwned     = "additional_parameter_owned_key_dropped"
+	warnAdditionalParamOverrides = "additional_parameter_overrides_value"
+)
+
+// MergeAdditionalParameters deep-merges ModelParam.AdditionalParametersRawJSON
+// into a JSON request body.
+//
+// Objects are merged key by key; any other value replaces the one in body and
+// is reported with a warning. Top-level keys listed in owned are built by the
+// adapter from typed fields and are dropped with a warning. body is returned
+// unchanged when raw is nil or blank.
+func MergeAdditionalParameters(body []byte, raw *string, owned ...string) ([]byte, []spec.Warning, error) {
+	extra, err := parseAdditionalParameters(raw)
+	if err != nil || len(extra) == 0 {
+		return body, nil, err
+	}
+
+	var dst map[string]any
+	if err := decodeJSONObject(body, &dst); err != nil {
+		return nil, nil, fmt.Errorf("request body is not a JSON object: %w", err)
+	}
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	warns := mergeAdditionalParameters(dst, extra, owned)
+
+	out, err := json.Marshal(dst)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, warns, nil
+}
+
+// MergeAdditionalParametersMap is MergeAdditionalParameters for a request body
+// that is still a map, as SDKs that build the body as a map hand it out. dst
+// is modified in place.
+func MergeAdditionalParametersMap(dst map[string]any, raw *string, owned ...string) ([]spec.Warning, error) {
+	extra, err := parseAdditionalParameters(raw)
+	if err != nil || len(extra) == 0 {
+		return nil, err
+	}
+	return mergeAdditionalParameters(dst, extra, owned), nil
+}
+
+// AdditionalParametersWarnings validates raw against the JSON encoding of the
+// SDK params a request is sent with and returns the warnings merging it
+// produces. Adapters that merge through AdditionalParametersMiddleware call it
+// once when they build the request.
+func AdditionalParametersWarnings(params any, raw *string, owned ...string) ([]spec.Warning, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	_, warns, err := MergeAdditionalParameters(body, raw, owned...)
+	return warns, err
+}
+
+// AdditionalParametersMiddleware returns SDK middleware that merges raw into
+// the body the SDK serialized, using MergeAdditionalParameters. It runs on
+// every attempt, so retries send the merged body too. It returns nil when raw
+// is nil or blank.
+func AdditionalParametersMiddleware(
+	raw *string,
+	owned ...string,
+) func(*http.Request, func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil
+	}
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		if err := mergeAdditionalParametersIntoRequest(req, raw, owned); err != nil {
+			return nil, err
+		}
+		return next(req)
+	}
+}
+
+func mergeAdditionalParametersIntoRequest(req *http.Request, raw *string, owned []string) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return err
+	}
+	merged, _, err := MergeAdditionalParameters(body, raw, owned...)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(merged))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(merged)), nil }
+	req.ContentLength = int64(len(merged))
+	return nil
+}
+
+func parseAdditionalParameters(raw *string) (map[string]any, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	var extra map[string]any
+	if err := decodeJSONObject([]byte(*raw), &extra); err != nil {
+		return nil, fmt.Errorf("additionalParametersRawJSON must be a JSON object: %w", err)
+	}
+	return extra, nil
+}
+
+func mergeAdditionalParameters(dst, extra map[string]any, owned []string) []spec.Warning {
+	var warns []spec.Warning
+	for _, key := range sortedKeys(extra) {
+		if slices.Contains(owned, key) {
+			warns = append(warns, spec.Warning{
+				Code:    warnAdditionalParamOwned,
+				Message: fmt.Sprintf("additional parameter %q is set by the adapter and was dropped", key),
+			})
+			continue
+		}
+		warns = mergeJSONValue(dst, key, extra[key], key, warns)
+	}
+	return warns
+}
+
+func mergeJSONValue(dst map[string]any, key string, val any, path string, warns []spec.Warning) []spec.Warning {
+	cur, exists := dst[key]
+	curObj, curIsObj := cur.(map[string]any)
+	valObj, valIsObj := val.(map[string]any)
+	if curIsObj && valIsObj {
+		for _, k := range sortedKeys(valObj) {
+			warns = mergeJSONValue(curObj, k, valObj[k], path+"."+k, warns)
+		}
+		return warns
+	}
+	if exists {
+		warns = append(warns, spec.Warning{
+			Code:    warnAdditionalParamOverrides,
+			Message: fmt.Sprintf("additional parameter %q overrides the value built from the request", path),
+		})
+	}
+	dst[key] = val
+	return warns
+}
+
+// decodeJSONObject decodes a JSON object keeping numbers as json.Number, so
+// large integers survive the round trip.
+func decodeJSONObject(data []byte, v *map[string]any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
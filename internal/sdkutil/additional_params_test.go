@@ -0,0 +1,129 @@
+package sdkutil
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMergeAdditionalParameters(t *testing.T) {
+	body := []byte(`{"model":"m","options":{"temperature":0.2,"stop":["x"]},"stream":true}`)
+
+	tests := []struct {
+		name      string
+		raw       string
+		owned     []string
+		want      string
+		wantCodes []string
+		wantErr   bool
+	}{
+		{
+			name: "blank raw keeps body",
+			raw:  "  ",
+			want: string(body),
+		},
+		{
+			name: "nested objects merge",
+			raw:  `{"keep_alive":"10m","options":{"num_ctx":8192,"seed":42}}`,
+			want: `{"keep_alive":"10m","model":"m","options":{"num_ctx":8192,"seed":42,"stop":["x"],` +
+				`"temperature":0.2},"stream":true}`,
+		},
+		{
+			name:      "scalar collision overrides with warning",
+			raw:       `{"options":{"temperature":0.9}}`,
+			want:      `{"model":"m","options":{"stop":["x"],"temperature":0.9},"stream":true}`,
+			wantCodes: []string{warnAdditionalParamOverrides},
+		},
+		{
+			name:      "owned keys are dropped",
+			raw:       `{"model":"other","stream":false,"seed":1}`,
+			owned:     []string{"model", "stream"},
+			want:      `{"model":"m","options":{"stop":["x"],"temperature":0.2},"seed":1,"stream":true}`,
+			wantCodes: []string{warnAdditionalParamOwned, warnAdditionalParamOwned},
+		},
+		{
+			name:    "non object raw",
+			raw:     `[1,2]`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing data",
+			raw:     `{"a":1} {"b":2}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := tt.raw
+			got, warns, err := MergeAdditionalParameters(body, &raw, tt.owned...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeAdditionalParameters: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("body = %s, want %s", got, tt.want)
+			}
+			if len(warns) != len(tt.wantCodes) {
+				t.Fatalf("warnings = %+v, want codes %v", warns, tt.wantCodes)
+			}
+			for i, w := range warns {
+				if w.Code != tt.wantCodes[i] {
+					t.Fatalf("warning %d = %q, want %q", i, w.Code, tt.wantCodes[i])
+				}
+			}
+		})
+	}
+
+	if got, warns, err := MergeAdditionalParameters(body, nil); err != nil || string(got) != string(body) ||
+		warns != nil {
+		t.Fatalf("nil raw = %s, %v, %v", got, warns, err)
+	}
+}
+
+func TestAdditionalParametersMiddleware(t *testing.T) {
+	if mw := AdditionalParametersMiddleware(nil, "model"); mw != nil {
+		t.Fatal("middleware for a nil raw is not nil")
+	}
+
+	raw := `{"model":"other","seed":7,"metadata":{"team":"a"}}`
+	mw := AdditionalParametersMiddleware(&raw, "model")
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString(`{"model":"m"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"metadata":{"team":"a"},"model":"m","seed":7}`
+	_, err = mw(req, func(r *http.Request) (*http.Response, error) {
+		got, _ := io.ReadAll(r.Body)
+		if string(got) != want || r.ContentLength != int64(len(want)) {
+			t.Fatalf("body = %s (length %d), want %s", got, r.ContentLength, want)
+		}
+		again, _ := r.GetBody()
+		if got, _ := io.ReadAll(again); string(got) != want {
+			t.Fatalf("GetBody = %s, want %s", got, want)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+
+	warns, err := AdditionalParametersWarnings(map[string]any{"model": "m"}, &raw, "model")
+	if err != nil || len(warns) != 1 || warns[0].Code != warnAdditionalParamOwned {
+		t.Fatalf("warnings = %+v, %v", warns, err)
+	}
+
+	bad := `{"seed":`
+	if _, err := AdditionalParametersWarnings(map[string]any{}, &bad); err == nil ||
+		!strings.Contains(err.Error(), "additionalParametersRawJSON") {
+		t.Fatalf("err = %v, want an invalid JSON error", err)
+	}
+}
@@ -0,0 +1,63 @@
+package sdkutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// NormalizeInlineAudioInputs drops audio input items that carry no inline AudioData, for SDKs whose API only
+// accepts inline audio. It runs on a request already returned by NormalizeRequestForSDK and honors the same
+// normalization mode: strict mode returns a *spec.CapabilityValidationError, the other modes drop each item with a
+// warning.
+func NormalizeInlineAudioInputs(
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	sdkType spec.ProviderSDKType,
+) ([]spec.Warning, error) {
+	if req == nil {
+		return nil, nil
+	}
+	n := &normalizer{mode: spec.NormalizationModeBestEffort}
+	if opts != nil && opts.NormalizationMode != "" {
+		n.mode = opts.NormalizationMode
+	}
+
+	for i := range req.Inputs {
+		msg := req.Inputs[i].InputMessage
+		if req.Inputs[i].Kind != spec.InputKindInputMessage || msg == nil {
+			continue
+		}
+		kept := make([]spec.InputOutputContentItemUnion, 0, len(msg.Contents))
+		for j, c := range msg.Contents {
+			if c.Kind != spec.ContentItemKindAudio || c.AudioItem == nil ||
+				strings.TrimSpace(c.AudioItem.AudioData) != "" {
+				kept = append(kept, c)
+				continue
+			}
+			n.reject(
+				fmt.Sprintf("inputs[%d].inputMessage.contents[%d].audioItem", i, j),
+				"modalitiesIn",
+				"audio_input_dropped_not_inline",
+				fmt.Sprintf(
+					"audio input at inputs[%d] was dropped because sdkType=%s only accepts inline audioData.",
+					i,
+					sdkType,
+				),
+			)
+		}
+		if len(kept) != len(msg.Contents) {
+			msg.Contents = kept
+		}
+	}
+
+	if len(n.violations) > 0 {
+		return n.warnings, &spec.CapabilityValidationError{
+			ProviderSDKType: sdkType,
+			ModelName:       req.ModelParam.Name,
+			Violations:      n.violations,
+		}
+	}
+	return n.warnings, nil
+}
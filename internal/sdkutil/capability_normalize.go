@@ -15,6 +15,32 @@ var EmptyJSONArgs = map[string]any{
 	"additionalProperties": false,
 }
 
+// ResolveModelCapabilities returns the capabilities of model: those from opts.CapabilityResolver when set, else
+// providerCapabilities.
+func ResolveModelCapabilities(
+	ctx context.Context,
+	opts *spec.FetchCompletionOptions,
+	sdkType spec.ProviderSDKType,
+	model spec.ModelName,
+	providerCapabilities spec.ModelCapabilities,
+) (*spec.ModelCapabilities, error) {
+	if opts == nil || opts.CapabilityResolver == nil {
+		return &providerCapabilities, nil
+	}
+	caps, err := opts.CapabilityResolver.ResolveModelCapabilities(ctx, spec.ResolveModelCapabilitiesRequest{
+		ProviderSDKType: sdkType,
+		ModelName:       model,
+		CompletionKey:   opts.CompletionKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if caps == nil {
+		return nil, errors.New("capability resolver returned nil ModelCapabilities")
+	}
+	return caps, nil
+}
+
 func NormalizeRequestForSDK(
 	ctx context.Context,
 	req *spec.FetchCompletionRequest,
@@ -26,19 +52,19 @@ func NormalizeRequestForSDK(
 		return nil, nil, nil, errors.New("nil request")
 	}
 
-	caps := &providerCapabilities
-	if opts != nil && opts.CapabilityResolver != nil {
-		caps, err = opts.CapabilityResolver.ResolveModelCapabilities(ctx, spec.ResolveModelCapabilitiesRequest{
-			ProviderSDKType: sdkType,
-			ModelName:       req.ModelParam.Name,
-			CompletionKey:   opts.CompletionKey,
-		})
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		if caps == nil {
-			return nil, nil, nil, errors.New("capability resolver returned nil ModelCapabilities")
-		}
+	caps, err := ResolveModelCapabilities(ctx, opts, sdkType, req.ModelParam.Name, providerCapabilities)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	n := &normalizer{mode: spec.NormalizationModeBestEffort}
+	if opts != nil && opts.NormalizationMode != "" {
+		n.mode = opts.NormalizationMode
+	}
+	switch n.mode {
+	case spec.NormalizationModeBestEffort, spec.NormalizationModeStrict, spec.NormalizationModeCoerce:
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown normalization mode %q", n.mode)
 	}
 
 	nreq, err := CloneFetchCompletionRequest(req)
@@ -48,89 +74,75 @@ func NormalizeRequestForSDK(
 
 	// Modalities validation (inferred from inputs).
 	used := getInputModalitiesForValidation(nreq.Inputs)
-	if err := requireModalities(used, caps.ModalitiesIn); err != nil {
-		return nil, nil, nil, err
+	for _, m := range used {
+		if containsModality(caps.ModalitiesIn, m) {
+			continue
+		}
+		if err := n.refuse(
+			"inputs",
+			"modalitiesIn",
+			"input_modality_unsupported",
+			fmt.Sprintf("input modality %q unsupported", m),
+		); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	// Reasoning validation / safe-dropping.
-	if nreq.ModelParam.Reasoning != nil {
-		if caps.ReasoningCapabilities == nil || !caps.ReasoningCapabilities.SupportsReasoningConfig ||
-			!supportsReasoningType(*nreq.ModelParam.Reasoning, caps.ReasoningCapabilities) {
-			warnings = append(warnings, spec.Warning{
-				Code:    "reasoning_dropped_unsupported",
-				Message: "Reasoning was dropped because it is not supported by the selected SDK/model.",
-			})
-			nreq.ModelParam.Reasoning = nil
-		} else {
-			// SummaryStyle: safe to drop if unsupported.
-			if nreq.ModelParam.Reasoning.SummaryStyle != nil && !caps.ReasoningCapabilities.SupportsSummaryStyle {
-				warnings = append(warnings, spec.Warning{
-					Code:    "reasoning_summaryStyle_dropped",
-					Message: "reasoning.summaryStyle is not supported and was dropped.",
-				})
-				rp := *nreq.ModelParam.Reasoning
-				rp.SummaryStyle = nil
-				nreq.ModelParam.Reasoning = &rp
-			}
-
-			// Level validation (do not “nearest-map”; drop reasoning in bestEffort).
-			if nreq.ModelParam.Reasoning.Type == spec.ReasoningTypeSingleWithLevels {
-				if !supportsReasoningLevel(
-					nreq.ModelParam.Reasoning.Level,
-					caps.ReasoningCapabilities.SupportedReasoningLevels,
-				) {
-					warnings = append(warnings, spec.Warning{
-						Code: "reasoning_dropped_invalid_level",
-						Message: fmt.Sprintf(
-							"Reasoning was dropped because level %q is unsupported.",
-							nreq.ModelParam.Reasoning.Level,
-						),
-					})
-					nreq.ModelParam.Reasoning = nil
-				}
-			}
-		}
-	}
+	normalizeReasoning(nreq, caps.ReasoningCapabilities, n)
 
 	// Enforce SDK constraints: e.g. Anthropic temperature disallowed when reasoning enabled.
 	if nreq.ModelParam.Reasoning != nil &&
 		caps.ReasoningCapabilities != nil &&
 		caps.ReasoningCapabilities.TemperatureDisallowedWhenEnabled {
 		if nreq.ModelParam.Temperature != nil {
-			warnings = append(warnings, spec.Warning{
-				Code:    "temperature_dropped_reasoning_enabled",
-				Message: "temperature was dropped because reasoning/thinking is enabled for this SDK/model.",
-			})
+			n.reject(
+				"modelParam.temperature",
+				"reasoningCapabilities.temperatureDisallowedWhenEnabled",
+				"temperature_dropped_reasoning_enabled",
+				"temperature was dropped because reasoning/thinking is enabled for this SDK/model.",
+			)
 			nreq.ModelParam.Temperature = nil
 		}
 	}
 
+	// Sampling parameters besides temperature.
+	if err := normalizeSampling(nreq, caps.SamplingCapabilities, n); err != nil {
+		return nil, caps, n.warnings, err
+	}
+
 	// Stop sequences.
 	if len(nreq.ModelParam.StopSequences) > 0 {
 		if caps.StopSequenceCapabilities == nil || !caps.StopSequenceCapabilities.IsSupported {
-			warnings = append(warnings, spec.Warning{
-				Code:    "stopSequences_dropped_unsupported",
-				Message: "stopSequences was dropped because it is not supported by this SDK/model.",
-			})
+			n.reject(
+				"modelParam.stopSequences",
+				"stopSequenceCapabilities.isSupported",
+				"stopSequences_dropped_unsupported",
+				"stopSequences was dropped because it is not supported by this SDK/model.",
+			)
 			nreq.ModelParam.StopSequences = nil
 		} else {
 			if caps.StopSequenceCapabilities.MaxSequences > 0 &&
 				len(nreq.ModelParam.StopSequences) > caps.StopSequenceCapabilities.MaxSequences {
-				warnings = append(warnings, spec.Warning{
-					Code: "stopSequences_truncated",
-					Message: fmt.Sprintf(
+				n.reject(
+					"modelParam.stopSequences",
+					"stopSequenceCapabilities.maxSequences",
+					"stopSequences_truncated",
+					fmt.Sprintf(
 						"stopSequences was truncated to max=%d.",
 						caps.StopSequenceCapabilities.MaxSequences,
 					),
-				})
+				)
 				nreq.ModelParam.StopSequences = nreq.ModelParam.StopSequences[:caps.StopSequenceCapabilities.MaxSequences]
 			}
 
 			if caps.StopSequenceCapabilities.DisallowedWithReasoning && nreq.ModelParam.Reasoning != nil {
-				warnings = append(warnings, spec.Warning{
-					Code:    "stopSequences_dropped_reasoning",
-					Message: "stopSequences was dropped because it is incompatible with reasoning for this SDK/model.",
-				})
+				n.reject(
+					"modelParam.stopSequences",
+					"stopSequenceCapabilities.disallowedWithReasoning",
+					"stopSequences_dropped_reasoning",
+					"stopSequences was dropped because it is incompatible with reasoning for this SDK/model.",
+				)
 				nreq.ModelParam.StopSequences = nil
 			}
 		}
@@ -142,37 +154,63 @@ func NormalizeRequestForSDK(
 
 		if op.Format != nil {
 			if !supportsOutputFormat(op.Format.Kind, caps.OutputCapabilities.SupportedOutputFormats) {
-				return nil, caps, warnings, fmt.Errorf(
-					"output format %q unsupported for sdkType=%s",
-					op.Format.Kind,
-					sdkType,
-				)
+				if err := n.refuse(
+					"modelParam.outputParam.format",
+					"outputCapabilities.supportedOutputFormats",
+					"output_format_unsupported",
+					fmt.Sprintf("output format %q unsupported for sdkType=%s", op.Format.Kind, sdkType),
+				); err != nil {
+					return nil, caps, n.warnings, err
+				}
 			}
 		}
 
 		if op.Verbosity != nil && !caps.OutputCapabilities.SupportsVerbosity {
-			warnings = append(warnings, spec.Warning{
-				Code:    "verbosity_dropped_unsupported",
-				Message: "outputParam.verbosity was dropped because it is not supported by this SDK/model.",
-			})
+			n.reject(
+				"modelParam.outputParam.verbosity",
+				"outputCapabilities.supportsVerbosity",
+				"verbosity_dropped_unsupported",
+				"outputParam.verbosity was dropped because it is not supported by this SDK/model.",
+			)
 			cop := *op
 			cop.Verbosity = nil
 			nreq.ModelParam.OutputParam = &cop
 		}
 	}
 
+	// OutputParam: spoken output needs the audioOut modality.
+	if op := nreq.ModelParam.OutputParam; op != nil && op.Audio != nil &&
+		!containsModality(caps.ModalitiesOut, spec.ModalityAudioOut) {
+		n.reject(
+			"modelParam.outputParam.audio",
+			"modalitiesOut",
+			"audio_output_dropped_unsupported",
+			"outputParam.audio was dropped because audio output is not supported by this SDK/model.",
+		)
+		cop := *op
+		cop.Audio = nil
+		nreq.ModelParam.OutputParam = &cop
+	}
+
 	// OutputParam: if caps.OutputCapabilities is nil, treat format as unsupported and verbosity as droppable.
 	if nreq.ModelParam.OutputParam != nil && caps.OutputCapabilities == nil {
 		if nreq.ModelParam.OutputParam.Format != nil {
-			return nil, caps, warnings, errors.New(
+			if err := n.refuse(
+				"modelParam.outputParam.format",
+				"outputCapabilities.supportedOutputFormats",
+				"output_format_unsupported",
 				"outputParam.format requested but output capabilities are unavailable/unsupported for this SDK/model",
-			)
+			); err != nil {
+				return nil, caps, n.warnings, err
+			}
 		}
 		if nreq.ModelParam.OutputParam.Verbosity != nil {
-			warnings = append(warnings, spec.Warning{
-				Code:    "verbosity_dropped_unsupported",
-				Message: "outputParam.verbosity was dropped because it is not supported by this SDK/model.",
-			})
+			n.reject(
+				"modelParam.outputParam.verbosity",
+				"outputCapabilities",
+				"verbosity_dropped_unsupported",
+				"outputParam.verbosity was dropped because it is not supported by this SDK/model.",
+			)
 			cop := *nreq.ModelParam.OutputParam
 			cop.Verbosity = nil
 			nreq.ModelParam.OutputParam = &cop
@@ -181,25 +219,36 @@ func NormalizeRequestForSDK(
 
 	// Tools: validate ToolChoices / ToolPolicy against capabilities.
 	if (len(nreq.ToolChoices) > 0 || nreq.ToolPolicy != nil) && caps.ToolCapabilities == nil {
-		return nil, caps, warnings, errors.New(
+		field := "toolChoices"
+		if len(nreq.ToolChoices) == 0 {
+			field = "toolPolicy"
+		}
+		if err := n.refuse(
+			field,
+			"toolCapabilities.supportedToolTypes",
+			"tools_unsupported",
 			"tools/toolPolicy provided but tools are not supported by selected SDK/model",
-		)
+		); err != nil {
+			return nil, caps, n.warnings, err
+		}
 	}
 
 	if len(nreq.ToolChoices) > 0 && caps.ToolCapabilities != nil {
 		filtered := make([]spec.ToolChoice, 0, len(nreq.ToolChoices))
-		for _, tc := range nreq.ToolChoices {
+		for i, tc := range nreq.ToolChoices {
 			if supportsToolType(tc.Type, caps.ToolCapabilities.SupportedToolTypes) {
 				filtered = append(filtered, tc)
 				continue
 			}
-			warnings = append(warnings, spec.Warning{
-				Code: "toolChoice_dropped_unsupported",
-				Message: fmt.Sprintf(
+			n.reject(
+				fmt.Sprintf("toolChoices[%d].type", i),
+				"toolCapabilities.supportedToolTypes",
+				"toolChoice_dropped_unsupported",
+				fmt.Sprintf(
 					"toolChoice type %q was dropped because it is unsupported by this SDK/model.",
 					tc.Type,
 				),
-			})
+			)
 		}
 		nreq.ToolChoices = filtered
 	}
@@ -208,33 +257,38 @@ func NormalizeRequestForSDK(
 	if nreq.ToolPolicy != nil {
 		if caps.ToolCapabilities != nil &&
 			!supportsToolPolicyMode(nreq.ToolPolicy.Mode, caps.ToolCapabilities.SupportedToolPolicyModes) {
-			return nil, caps, warnings, fmt.Errorf(
-				"toolPolicy.mode %q unsupported for sdkType=%s",
-				nreq.ToolPolicy.Mode,
-				sdkType,
-			)
+			if err := n.refuse(
+				"toolPolicy.mode",
+				"toolCapabilities.supportedToolPolicyModes",
+				"toolPolicy_mode_unsupported",
+				fmt.Sprintf("toolPolicy.mode %q unsupported for sdkType=%s", nreq.ToolPolicy.Mode, sdkType),
+			); err != nil {
+				return nil, caps, n.warnings, err
+			}
 		}
 		if (nreq.ToolPolicy.Mode == spec.ToolPolicyModeAny ||
 			nreq.ToolPolicy.Mode == spec.ToolPolicyModeTool) &&
 			len(nreq.ToolChoices) == 0 {
-			return nil, caps, warnings, fmt.Errorf("toolPolicy.mode=%s requires toolChoices", nreq.ToolPolicy.Mode)
+			return nil, caps, n.warnings, fmt.Errorf("toolPolicy.mode=%s requires toolChoices", nreq.ToolPolicy.Mode)
 		}
 
 		if nreq.ToolPolicy.Mode == spec.ToolPolicyModeTool && len(nreq.ToolPolicy.AllowedTools) == 0 {
-			return nil, caps, warnings, errors.New("toolPolicy.mode=tool requires allowedTools")
+			return nil, caps, n.warnings, errors.New("toolPolicy.mode=tool requires allowedTools")
 		}
 
 		// Forced tool count constraint (bestEffort: keep first N).
 		if caps.ToolCapabilities != nil && caps.ToolCapabilities.MaxForcedTools > 0 &&
 			nreq.ToolPolicy.Mode == spec.ToolPolicyModeTool {
 			if len(nreq.ToolPolicy.AllowedTools) > caps.ToolCapabilities.MaxForcedTools {
-				warnings = append(warnings, spec.Warning{
-					Code: "allowedTools_truncated",
-					Message: fmt.Sprintf(
+				n.reject(
+					"toolPolicy.allowedTools",
+					"toolCapabilities.maxForcedTools",
+					"allowedTools_truncated",
+					fmt.Sprintf(
 						"allowedTools truncated to %d due to SDK/model limitation.",
 						caps.ToolCapabilities.MaxForcedTools,
 					),
-				})
+				)
 				cp := *nreq.ToolPolicy
 				cp.AllowedTools = cp.AllowedTools[:caps.ToolCapabilities.MaxForcedTools]
 				nreq.ToolPolicy = &cp
@@ -243,113 +297,150 @@ func NormalizeRequestForSDK(
 	}
 
 	// Client tool outputs: normalize according to SDK/model transport capability.
+	// Collapsing keeps the content, so it is reported as a warning in every mode.
 	toolWarnings, err := normalizeClientToolOutputsForSDK(nreq, caps.ToolCapabilities)
 	if err != nil {
-		return nil, caps, warnings, err
+		return nil, caps, n.warnings, err
 	}
-	warnings = append(warnings, toolWarnings...)
-	warnings = append(warnings, normalizeRequestCacheControls(nreq, caps.CacheCapabilities)...)
+	n.warnings = append(n.warnings, toolWarnings...)
+	normalizeRequestCacheControls(nreq, caps.CacheCapabilities, n)
 
-	return nreq, caps, warnings, nil
+	if len(n.violations) > 0 {
+		return nil, caps, n.warnings, &spec.CapabilityValidationError{
+			ProviderSDKType: sdkType,
+			ModelName:       req.ModelParam.Name,
+			Violations:      n.violations,
+		}
+	}
+	return nreq, caps, n.warnings, nil
+}
+
+// normalizer collects the changes NormalizeRequestForSDK makes to a request. In strict mode rejected fields are
+// recorded as violations instead of warnings.
+type normalizer struct {
+	mode       spec.NormalizationMode
+	warnings   []spec.Warning
+	violations []spec.CapabilityViolation
+}
+
+// reject reports that field was dropped or truncated because of capabilityPath. The caller still applies the
+// change; in strict mode the changed request is discarded.
+func (n *normalizer) reject(field, capabilityPath, code, message string) {
+	if n.mode == spec.NormalizationModeStrict {
+		n.violate(field, capabilityPath, code, message)
+		return
+	}
+	n.warn(code, message)
+}
+
+// refuse reports a requested feature that cannot be dropped without changing the meaning of the request. In strict
+// mode it is recorded as a violation like any other; in the other modes the returned error fails the call.
+func (n *normalizer) refuse(field, capabilityPath, code, message string) error {
+	if n.mode == spec.NormalizationModeStrict {
+		n.violate(field, capabilityPath, code, message)
+		return nil
+	}
+	return errors.New(message)
+}
+
+func (n *normalizer) violate(field, capabilityPath, code, message string) {
+	n.violations = append(n.violations, spec.CapabilityViolation{
+		Field:          field,
+		CapabilityPath: capabilityPath,
+		Code:           code,
+		Message:        message,
+	})
+}
+
+func (n *normalizer) warn(code, message string) {
+	n.warnings = append(n.warnings, spec.Warning{Code: code, Message: message})
 }
 
 func getInputModalitiesForValidation(inputs []spec.InputUnion) []spec.Modality {
 	// Treat text as always required.
-	needText := true
-	needImage := false
-	needFile := false
-	needAudio := false
-	needVideo := false
+	need := map[spec.Modality]bool{spec.ModalityTextIn: true}
 
 	for _, in := range inputs {
 		if IsInputUnionEmpty(in) {
 			continue
 		}
 
-		var msg *spec.InputOutputContent
+		var kinds []spec.ContentItemKind
 		switch in.Kind {
 		case spec.InputKindInputMessage:
-			msg = in.InputMessage
+			kinds = messageContentKinds(in.InputMessage)
 		case spec.InputKindOutputMessage:
-			msg = in.OutputMessage
+			kinds = messageContentKinds(in.OutputMessage)
 		case spec.InputKindFunctionToolOutput:
-			if in.FunctionToolOutput != nil {
-				for _, c := range in.FunctionToolOutput.Contents {
-					switch c.Kind {
-					case spec.ContentItemKindText:
-						needText = true
-					case spec.ContentItemKindImage:
-						needImage = true
-					case spec.ContentItemKindFile:
-						needFile = true
-					default:
-						// Add new content types as needed.
-					}
-				}
-			}
+			kinds = toolOutputContentKinds(in.FunctionToolOutput)
 		case spec.InputKindCustomToolOutput:
-			if in.CustomToolOutput != nil {
-				for _, c := range in.CustomToolOutput.Contents {
-					switch c.Kind {
-					case spec.ContentItemKindText:
-						needText = true
-					case spec.ContentItemKindImage:
-						needImage = true
-					case spec.ContentItemKindFile:
-						needFile = true
-					default:
-						// Add new content types as needed.
-					}
-				}
-			}
+			kinds = toolOutputContentKinds(in.CustomToolOutput)
 		default:
-			// Modality checks via only input and output message.
+			// Modality checks via only messages and tool outputs.
 		}
 
-		if msg == nil {
-			continue
-		}
-		for _, c := range msg.Contents {
-			switch c.Kind {
-			case spec.ContentItemKindText:
-				needText = true
-			case spec.ContentItemKindImage:
-				needImage = true
-			case spec.ContentItemKindFile:
-				needFile = true
-			default:
-				// Add new content types as needed.
-				// Refusal doesn't constitute a modality requirement as of now.
+		for _, k := range kinds {
+			if m, ok := contentItemInputModality(k); ok {
+				need[m] = true
 			}
 		}
 	}
 
 	var out []spec.Modality
-	if needText {
-		out = append(out, spec.ModalityTextIn)
+	for _, m := range []spec.Modality{
+		spec.ModalityTextIn,
+		spec.ModalityImageIn,
+		spec.ModalityFileIn,
+		spec.ModalityAudioIn,
+		spec.ModalityVideoIn,
+	} {
+		if need[m] {
+			out = append(out, m)
+		}
 	}
-	if needImage {
-		out = append(out, spec.ModalityImageIn)
+	return out
+}
+
+func messageContentKinds(msg *spec.InputOutputContent) []spec.ContentItemKind {
+	if msg == nil {
+		return nil
 	}
-	if needFile {
-		out = append(out, spec.ModalityFileIn)
+	kinds := make([]spec.ContentItemKind, 0, len(msg.Contents))
+	for _, c := range msg.Contents {
+		kinds = append(kinds, c.Kind)
 	}
-	if needAudio {
-		out = append(out, spec.ModalityAudioIn)
+	return kinds
+}
+
+func toolOutputContentKinds(out *spec.ToolOutput) []spec.ContentItemKind {
+	if out == nil {
+		return nil
 	}
-	if needVideo {
-		out = append(out, spec.ModalityVideoIn)
+	kinds := make([]spec.ContentItemKind, 0, len(out.Contents))
+	for _, c := range out.Contents {
+		kinds = append(kinds, c.Kind)
 	}
-	return out
+	return kinds
 }
 
-func requireModalities(used, supported []spec.Modality) error {
-	for _, m := range used {
-		if !containsModality(supported, m) {
-			return fmt.Errorf("input modality %q unsupported", m)
-		}
+// contentItemInputModality returns the input modality a content item of kind needs.
+func contentItemInputModality(kind spec.ContentItemKind) (spec.Modality, bool) {
+	switch kind {
+	case spec.ContentItemKindText:
+		return spec.ModalityTextIn, true
+	case spec.ContentItemKindImage:
+		return spec.ModalityImageIn, true
+	case spec.ContentItemKindFile:
+		return spec.ModalityFileIn, true
+	case spec.ContentItemKindAudio:
+		return spec.ModalityAudioIn, true
+	case spec.ContentItemKindVideo:
+		return spec.ModalityVideoIn, true
+	default:
+		// Add new content types as needed.
+		// Refusal doesn't constitute a modality requirement as of now.
+		return "", false
 	}
-	return nil
 }
 
 func containsModality(list []spec.Modality, v spec.Modality) bool {
@@ -0,0 +1,459 @@
+package sdkutil
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func normalizeModeTestCaps() spec.ModelCapabilities {
+	return spec.ModelCapabilities{
+		ModalitiesIn: []spec.Modality{spec.ModalityTextIn},
+		ReasoningCapabilities: &spec.ReasoningCapabilities{
+			SupportsReasoningConfig: true,
+			SupportedReasoningTypes: []spec.ReasoningType{
+				spec.ReasoningTypeSingleWithLevels,
+				spec.ReasoningTypeHybridWithTokens,
+			},
+			SupportedReasoningLevels: []spec.ReasoningLevel{
+				spec.ReasoningLevelLow,
+				spec.ReasoningLevelHigh,
+			},
+			HybridTokenBudgetCapabilities: &spec.ReasoningTokenBudgetCapabilities{
+				MinAllowed: 1024,
+				MaxAllowed: 8192,
+			},
+			TemperatureDisallowedWhenEnabled: true,
+		},
+		StopSequenceCapabilities: &spec.StopSequenceCapabilities{IsSupported: true, MaxSequences: 1},
+		CacheCapabilities: &spec.CacheCapabilities{
+			InputOutputContent: &spec.CacheControlCapabilities{
+				SupportedKinds: []spec.CacheControlKind{spec.CacheControlKindEphemeral},
+			},
+		},
+	}
+}
+
+func normalizeModeTestRequest(reasoning *spec.ReasoningParam) *spec.FetchCompletionRequest {
+	return &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{
+			Name:          "test-model",
+			Reasoning:     reasoning,
+			Temperature:   new(0.5),
+			StopSequences: []string{"a", "b"},
+		},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "hi"},
+				}},
+				CacheControl: &spec.CacheControl{Kind: spec.CacheControlKindEphemeral, TTL: spec.CacheControlTTL1h},
+			},
+		}},
+	}
+}
+
+func TestNormalizeRequestForSDKStrict(t *testing.T) {
+	req := normalizeModeTestRequest(&spec.ReasoningParam{
+		Type:  spec.ReasoningTypeSingleWithLevels,
+		Level: spec.ReasoningLevelMedium,
+	})
+
+	got, _, _, err := NormalizeRequestForSDK(
+		t.Context(),
+		req,
+		&spec.FetchCompletionOptions{NormalizationMode: spec.NormalizationModeStrict},
+		spec.ProviderSDKTypeAnthropic,
+		normalizeModeTestCaps(),
+	)
+	if got != nil {
+		t.Fatalf("request = %+v, want nil", got)
+	}
+	var cve *spec.CapabilityValidationError
+	if !errors.As(err, &cve) {
+		t.Fatalf("err = %v, want *spec.CapabilityValidationError", err)
+	}
+	if cve.ProviderSDKType != spec.ProviderSDKTypeAnthropic || cve.ModelName != "test-model" {
+		t.Fatalf("sdkType/model = %q/%q", cve.ProviderSDKType, cve.ModelName)
+	}
+
+	want := []spec.CapabilityViolation{
+		{
+			Field:          "modelParam.reasoning.level",
+			CapabilityPath: "reasoningCapabilities.supportedReasoningLevels",
+			Code:           "reasoning_dropped_invalid_level",
+		},
+		{
+			Field:          "modelParam.stopSequences",
+			CapabilityPath: "stopSequenceCapabilities.maxSequences",
+			Code:           "stopSequences_truncated",
+		},
+		{
+			Field:          "inputs[0].inputMessage.cacheControl.ttl",
+			CapabilityPath: "cacheCapabilities.inputOutputContent.supportsTTL",
+			Code:           "cacheControl_ttl_dropped_unsupported",
+		},
+	}
+	gotViolations := make([]spec.CapabilityViolation, 0, len(cve.Violations))
+	for _, v := range cve.Violations {
+		if v.Message == "" {
+			t.Errorf("violation %q has no message", v.Field)
+		}
+		v.Message = ""
+		gotViolations = append(gotViolations, v)
+	}
+	if !reflect.DeepEqual(gotViolations, want) {
+		t.Fatalf("violations = %+v, want %+v", gotViolations, want)
+	}
+
+	if req.ModelParam.Reasoning == nil || len(req.ModelParam.StopSequences) != 2 {
+		t.Fatalf("input request was modified: %+v", req.ModelParam)
+	}
+}
+
+func TestNormalizeRequestForSDKStrictContractViolations(t *testing.T) {
+	req := normalizeModeTestRequest(nil)
+	req.ModelParam.StopSequences = nil
+	req.Inputs[0].InputMessage.CacheControl = nil
+	req.Inputs[0].InputMessage.Contents = append(req.Inputs[0].InputMessage.Contents, spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindImage,
+		ImageItem: &spec.ContentItemImage{ImageURL: "https://example.com/cat.png"},
+	})
+	req.ModelParam.OutputParam = &spec.OutputParam{Format: &spec.OutputFormat{Kind: spec.OutputFormatKindJSONSchema}}
+	req.ToolPolicy = &spec.ToolPolicy{Mode: spec.ToolPolicyModeAuto}
+
+	for _, mode := range []spec.NormalizationMode{spec.NormalizationModeStrict, spec.NormalizationModeBestEffort} {
+		_, _, _, err := NormalizeRequestForSDK(
+			t.Context(),
+			req,
+			&spec.FetchCompletionOptions{NormalizationMode: mode},
+			spec.ProviderSDKTypeAnthropic,
+			normalizeModeTestCaps(),
+		)
+		var cve *spec.CapabilityValidationError
+		if mode != spec.NormalizationModeStrict {
+			if err == nil || errors.As(err, &cve) {
+				t.Fatalf("%s: err = %v, want a plain error", mode, err)
+			}
+			continue
+		}
+		if !errors.As(err, &cve) {
+			t.Fatalf("err = %v, want *spec.CapabilityValidationError", err)
+		}
+		got := make([]string, 0, len(cve.Violations))
+		for _, v := range cve.Violations {
+			got = append(got, v.Field+" "+v.CapabilityPath)
+		}
+		want := []string{
+			"inputs modalitiesIn",
+			"modelParam.outputParam.format outputCapabilities.supportedOutputFormats",
+			"toolPolicy toolCapabilities.supportedToolTypes",
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("violations = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNormalizeRequestForSDKStrictSupportedRequest(t *testing.T) {
+	req := normalizeModeTestRequest(&spec.ReasoningParam{
+		Type:  spec.ReasoningTypeSingleWithLevels,
+		Level: spec.ReasoningLevelHigh,
+	})
+	req.ModelParam.Temperature = nil
+	req.ModelParam.StopSequences = []string{"a"}
+	req.Inputs[0].InputMessage.CacheControl.TTL = ""
+
+	got, _, warns, err := NormalizeRequestForSDK(
+		t.Context(),
+		req,
+		&spec.FetchCompletionOptions{NormalizationMode: spec.NormalizationModeStrict},
+		spec.ProviderSDKTypeAnthropic,
+		normalizeModeTestCaps(),
+	)
+	if err != nil {
+		t.Fatalf("NormalizeRequestForSDK error: %v", err)
+	}
+	if len(warns) != 0 {
+		t.Fatalf("warnings = %+v, want none", warns)
+	}
+	if got.ModelParam.Reasoning == nil || got.ModelParam.Reasoning.Level != spec.ReasoningLevelHigh {
+		t.Fatalf("reasoning = %+v", got.ModelParam.Reasoning)
+	}
+}
+
+func TestNormalizeRequestForSDKModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          spec.NormalizationMode
+		reasoning     *spec.ReasoningParam
+		wantReasoning *spec.ReasoningParam
+		wantCodes     []string
+	}{
+		{
+			name: "best effort drops unsupported level",
+			reasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelMedium,
+			},
+			wantReasoning: nil,
+			wantCodes: []string{
+				"reasoning_dropped_invalid_level",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+		{
+			name:          "best effort keeps out of range budget",
+			mode:          spec.NormalizationModeBestEffort,
+			reasoning:     &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 100},
+			wantReasoning: &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 100},
+			wantCodes: []string{
+				"temperature_dropped_reasoning_enabled",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+		{
+			name: "coerce maps level to nearest, higher on tie",
+			mode: spec.NormalizationModeCoerce,
+			reasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelMedium,
+			},
+			wantReasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelHigh,
+			},
+			wantCodes: []string{
+				"reasoning_level_coerced",
+				"temperature_dropped_reasoning_enabled",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+		{
+			name: "coerce maps level below the supported range",
+			mode: spec.NormalizationModeCoerce,
+			reasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelNone,
+			},
+			wantReasoning: &spec.ReasoningParam{
+				Type:  spec.ReasoningTypeSingleWithLevels,
+				Level: spec.ReasoningLevelLow,
+			},
+			wantCodes: []string{
+				"reasoning_level_coerced",
+				"temperature_dropped_reasoning_enabled",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+		{
+			name:          "coerce clamps budget to max",
+			mode:          spec.NormalizationModeCoerce,
+			reasoning:     &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 100000},
+			wantReasoning: &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 8192},
+			wantCodes: []string{
+				"reasoning_tokens_clamped",
+				"temperature_dropped_reasoning_enabled",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+		{
+			name:          "coerce raises disallowed zero budget to min",
+			mode:          spec.NormalizationModeCoerce,
+			reasoning:     &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 0},
+			wantReasoning: &spec.ReasoningParam{Type: spec.ReasoningTypeHybridWithTokens, Tokens: 1024},
+			wantCodes: []string{
+				"reasoning_tokens_clamped",
+				"temperature_dropped_reasoning_enabled",
+				"stopSequences_truncated",
+				"cacheControl_ttl_dropped_unsupported",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, warns, err := NormalizeRequestForSDK(
+				t.Context(),
+				normalizeModeTestRequest(tt.reasoning),
+				&spec.FetchCompletionOptions{NormalizationMode: tt.mode},
+				spec.ProviderSDKTypeAnthropic,
+				normalizeModeTestCaps(),
+			)
+			if err != nil {
+				t.Fatalf("NormalizeRequestForSDK error: %v", err)
+			}
+			if !reflect.DeepEqual(got.ModelParam.Reasoning, tt.wantReasoning) {
+				t.Fatalf("reasoning = %+v, want %+v", got.ModelParam.Reasoning, tt.wantReasoning)
+			}
+			codes := make([]string, 0, len(warns))
+			for _, w := range warns {
+				codes = append(codes, w.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Fatalf("warning codes = %v, want %v", codes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestNormalizeRequestForSDKUnknownMode(t *testing.T) {
+	_, _, _, err := NormalizeRequestForSDK(
+		t.Context(),
+		normalizeModeTestRequest(nil),
+		&spec.FetchCompletionOptions{NormalizationMode: "lenient"},
+		spec.ProviderSDKTypeAnthropic,
+		normalizeModeTestCaps(),
+	)
+	if err == nil {
+		t.Fatal("expected error for unknown normalization mode")
+	}
+}
+
+func TestNormalizeRequestForSDKMediaModalities(t *testing.T) {
+	audio := spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindAudio,
+		AudioItem: &spec.ContentItemAudio{AudioMIME: "audio/wav", AudioData: "UklGRg=="},
+	}
+	video := spec.InputOutputContentItemUnion{
+		Kind:      spec.ContentItemKindVideo,
+		VideoItem: &spec.ContentItemVideo{VideoURL: "gs://bucket/clip.mp4"},
+	}
+
+	tests := []struct {
+		name     string
+		item     spec.InputOutputContentItemUnion
+		supports []spec.Modality
+		wantErr  bool
+	}{
+		{name: "audio unsupported", item: audio, wantErr: true},
+		{name: "video unsupported", item: video, wantErr: true},
+		{name: "audio supported", item: audio, supports: []spec.Modality{spec.ModalityAudioIn}},
+		{name: "video supported", item: video, supports: []spec.Modality{spec.ModalityVideoIn}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := normalizeModeTestRequest(nil)
+			req.Inputs[0].InputMessage.Contents = append(req.Inputs[0].InputMessage.Contents, tt.item)
+			caps := normalizeModeTestCaps()
+			caps.ModalitiesIn = append(caps.ModalitiesIn, tt.supports...)
+
+			_, _, _, err := NormalizeRequestForSDK(
+				t.Context(),
+				req,
+				nil,
+				spec.ProviderSDKTypeGoogleGenerateContent,
+				caps,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeRequestForSDKToolOutputModalities(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     spec.InputKind
+		item     spec.ContentItemKind
+		supports []spec.Modality
+		wantErr  bool
+	}{
+		{
+			name:    "function audio unsupported",
+			kind:    spec.InputKindFunctionToolOutput,
+			item:    spec.ContentItemKindAudio,
+			wantErr: true,
+		},
+		{
+			name:    "custom video unsupported",
+			kind:    spec.InputKindCustomToolOutput,
+			item:    spec.ContentItemKindVideo,
+			wantErr: true,
+		},
+		{
+			name:     "function audio supported",
+			kind:     spec.InputKindFunctionToolOutput,
+			item:     spec.ContentItemKindAudio,
+			supports: []spec.Modality{spec.ModalityAudioIn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &spec.ToolOutput{
+				Type:     spec.ToolTypeFunction,
+				CallID:   "call_1",
+				Name:     "record",
+				Contents: []spec.ToolOutputItemUnion{{Kind: tt.item}},
+			}
+			in := spec.InputUnion{Kind: tt.kind}
+			if tt.kind == spec.InputKindFunctionToolOutput {
+				in.FunctionToolOutput = out
+			} else {
+				in.CustomToolOutput = out
+			}
+			req := normalizeModeTestRequest(nil)
+			req.Inputs = append(req.Inputs, in)
+			caps := normalizeModeTestCaps()
+			caps.ModalitiesIn = append(caps.ModalitiesIn, tt.supports...)
+
+			_, _, _, err := NormalizeRequestForSDK(
+				t.Context(),
+				req,
+				nil,
+				spec.ProviderSDKTypeGoogleGenerateContent,
+				caps,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "input modality") {
+				t.Fatalf("err = %v, want an unsupported input modality error", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeRequestForSDKAudioOutput(t *testing.T) {
+	for _, supported := range []bool{false, true} {
+		req := normalizeModeTestRequest(nil)
+		req.ModelParam.OutputParam = &spec.OutputParam{Audio: &spec.AudioOutputParam{Voice: "alloy"}}
+		caps := normalizeModeTestCaps()
+		if supported {
+			caps.ModalitiesOut = []spec.Modality{spec.ModalityTextOut, spec.ModalityAudioOut}
+		}
+
+		got, _, warns, err := NormalizeRequestForSDK(
+			t.Context(),
+			req,
+			nil,
+			spec.ProviderSDKTypeOpenAIChatCompletions,
+			caps,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kept := got.ModelParam.OutputParam.Audio != nil; kept != supported {
+			t.Errorf("supported=%v: audio kept = %v", supported, kept)
+		}
+		dropped := slices.ContainsFunc(warns, func(w spec.Warning) bool {
+			return w.Code == "audio_output_dropped_unsupported"
+		})
+		if dropped == supported {
+			t.Errorf("supported=%v: warnings = %+v", supported, warns)
+		}
+	}
+}
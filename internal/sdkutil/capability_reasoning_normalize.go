@@ -0,0 +1,171 @@
+package sdkutil
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// reasoningLevelOrder ranks reasoning levels for nearest-mapping in coerce mode.
+var reasoningLevelOrder = []spec.ReasoningLevel{
+	spec.ReasoningLevelNone,
+	spec.ReasoningLevelMinimal,
+	spec.ReasoningLevelLow,
+	spec.ReasoningLevelMedium,
+	spec.ReasoningLevelHigh,
+	spec.ReasoningLevelXHigh,
+	spec.ReasoningLevelMax,
+}
+
+func normalizeReasoning(req *spec.FetchCompletionRequest, caps *spec.ReasoningCapabilities, n *normalizer) {
+	if req.ModelParam.Reasoning == nil {
+		return
+	}
+
+	if caps == nil || !caps.SupportsReasoningConfig || !supportsReasoningType(*req.ModelParam.Reasoning, caps) {
+		capPath := "reasoningCapabilities.supportedReasoningTypes"
+		switch {
+		case caps == nil:
+			capPath = "reasoningCapabilities"
+		case !caps.SupportsReasoningConfig:
+			capPath = "reasoningCapabilities.supportsReasoningConfig"
+		}
+		n.reject(
+			"modelParam.reasoning",
+			capPath,
+			"reasoning_dropped_unsupported",
+			"Reasoning was dropped because it is not supported by the selected SDK/model.",
+		)
+		req.ModelParam.Reasoning = nil
+		return
+	}
+
+	rp := *req.ModelParam.Reasoning
+
+	// SummaryStyle: safe to drop if unsupported.
+	if rp.SummaryStyle != nil && !caps.SupportsSummaryStyle {
+		n.reject(
+			"modelParam.reasoning.summaryStyle",
+			"reasoningCapabilities.supportsSummaryStyle",
+			"reasoning_summaryStyle_dropped",
+			"reasoning.summaryStyle is not supported and was dropped.",
+		)
+		rp.SummaryStyle = nil
+	}
+
+	switch rp.Type {
+	case spec.ReasoningTypeSingleWithLevels:
+		if supportsReasoningLevel(rp.Level, caps.SupportedReasoningLevels) {
+			break
+		}
+		if n.mode == spec.NormalizationModeCoerce {
+			if level, ok := nearestReasoningLevel(rp.Level, caps.SupportedReasoningLevels); ok {
+				n.warn(
+					"reasoning_level_coerced",
+					fmt.Sprintf("Reasoning level %q is unsupported and was mapped to %q.", rp.Level, level),
+				)
+				rp.Level = level
+				break
+			}
+		}
+		// Outside coerce mode, do not "nearest-map"; drop reasoning.
+		n.reject(
+			"modelParam.reasoning.level",
+			"reasoningCapabilities.supportedReasoningLevels",
+			"reasoning_dropped_invalid_level",
+			fmt.Sprintf("Reasoning was dropped because level %q is unsupported.", rp.Level),
+		)
+		req.ModelParam.Reasoning = nil
+		return
+
+	case spec.ReasoningTypeHybridWithTokens:
+		budget := caps.HybridTokenBudgetCapabilities
+		if budget == nil || reasoningTokensAllowed(rp.Tokens, budget) {
+			break
+		}
+		switch n.mode {
+		case spec.NormalizationModeCoerce:
+			tokens := clampReasoningTokens(rp.Tokens, budget)
+			n.warn(
+				"reasoning_tokens_clamped",
+				fmt.Sprintf(
+					"reasoning.tokens=%d is out of the allowed range and was clamped to %d.",
+					rp.Tokens,
+					tokens,
+				),
+			)
+			rp.Tokens = tokens
+		case spec.NormalizationModeStrict:
+			n.reject(
+				"modelParam.reasoning.tokens",
+				"reasoningCapabilities.hybridTokenBudgetCapabilities",
+				"reasoning_tokens_out_of_range",
+				fmt.Sprintf("reasoning.tokens=%d is out of the allowed range.", rp.Tokens),
+			)
+		default:
+			// Best effort leaves the budget to the adapter/provider, as before.
+		}
+
+	default:
+	}
+
+	req.ModelParam.Reasoning = &rp
+}
+
+// nearestReasoningLevel returns the supported level closest to level. Ties go to the higher level.
+func nearestReasoningLevel(
+	level spec.ReasoningLevel,
+	supported []spec.ReasoningLevel,
+) (spec.ReasoningLevel, bool) {
+	want := slices.Index(reasoningLevelOrder, level)
+	if want < 0 {
+		return "", false
+	}
+
+	best, bestDist := spec.ReasoningLevel(""), -1
+	for _, l := range supported {
+		idx := slices.Index(reasoningLevelOrder, l)
+		if idx < 0 {
+			continue
+		}
+		dist := idx - want
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist ||
+			(dist == bestDist && idx > slices.Index(reasoningLevelOrder, best)) {
+			best, bestDist = l, dist
+		}
+	}
+	return best, bestDist >= 0
+}
+
+func reasoningTokensAllowed(tokens int, caps *spec.ReasoningTokenBudgetCapabilities) bool {
+	switch {
+	case tokens == -1:
+		return caps.MinusOneAllowed
+	case tokens == 0:
+		return caps.ZeroAllowed
+	case tokens < 0:
+		return false
+	case caps.MinAllowed > 0 && tokens < caps.MinAllowed:
+		return false
+	case caps.MaxAllowed > 0 && tokens > caps.MaxAllowed:
+		return false
+	default:
+		return true
+	}
+}
+
+// clampReasoningTokens returns the allowed budget nearest to tokens. Disallowed zero and -1 budgets are raised to
+// the minimum.
+func clampReasoningTokens(tokens int, caps *spec.ReasoningTokenBudgetCapabilities) int {
+	if caps.MinAllowed > 0 && tokens < caps.MinAllowed {
+		tokens = caps.MinAllowed
+	}
+	if caps.MaxAllowed > 0 && tokens > caps.MaxAllowed {
+		tokens = caps.MaxAllowed
+	}
+	return max(tokens, 1)
+}
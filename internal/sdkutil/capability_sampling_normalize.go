@@ -0,0 +1,161 @@
+package sdkutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// samplingParam is one optional sampling field of ModelParam.
+type samplingParam struct {
+	name      string
+	capField  string
+	set       bool
+	supported bool
+	// reasoningBound params are dropped when reasoning is enabled and the
+	// capabilities disallow sampling with reasoning.
+	reasoningBound bool
+	clear          func()
+}
+
+// normalizeSampling validates the sampling fields of req and drops the ones
+// caps does not support, the same way temperature is handled.
+func normalizeSampling(req *spec.FetchCompletionRequest, caps *spec.SamplingCapabilities, n *normalizer) error {
+	mp := &req.ModelParam
+	if err := validateSamplingParams(mp); err != nil {
+		return err
+	}
+
+	var c spec.SamplingCapabilities
+	if caps != nil {
+		c = *caps
+	}
+	capPath := func(field string) string {
+		if caps == nil {
+			return "samplingCapabilities"
+		}
+		return "samplingCapabilities." + field
+	}
+
+	params := []samplingParam{
+		{
+			name: "topP", capField: "supportsTopP", set: mp.TopP != nil, supported: c.SupportsTopP,
+			reasoningBound: true, clear: func() { mp.TopP = nil },
+		},
+		{
+			name: "topK", capField: "supportsTopK", set: mp.TopK != nil, supported: c.SupportsTopK,
+			reasoningBound: true, clear: func() { mp.TopK = nil },
+		},
+		{
+			name: "seed", capField: "supportsSeed", set: mp.Seed != nil, supported: c.SupportsSeed,
+			clear: func() { mp.Seed = nil },
+		},
+		{
+			name: "presencePenalty", capField: "supportsPresencePenalty", set: mp.PresencePenalty != nil,
+			supported: c.SupportsPresencePenalty, reasoningBound: true, clear: func() { mp.PresencePenalty = nil },
+		},
+		{
+			name: "frequencyPenalty", capField: "supportsFrequencyPenalty", set: mp.FrequencyPenalty != nil,
+			supported: c.SupportsFrequencyPenalty, reasoningBound: true, clear: func() { mp.FrequencyPenalty = nil },
+		},
+		{
+			name: "logitBias", capField: "supportsLogitBias", set: len(mp.LogitBias) > 0,
+			supported: c.SupportsLogitBias, reasoningBound: true, clear: func() { mp.LogitBias = nil },
+		},
+	}
+
+	for _, p := range params {
+		if !p.set {
+			continue
+		}
+		switch {
+		case !p.supported:
+			n.reject(
+				"modelParam."+p.name,
+				capPath(p.capField),
+				p.name+"_dropped_unsupported",
+				p.name+" was dropped because it is not supported by this SDK/model.",
+			)
+			p.clear()
+		case p.reasoningBound && c.DisallowedWithReasoning && mp.Reasoning != nil:
+			n.reject(
+				"modelParam."+p.name,
+				capPath("disallowedWithReasoning"),
+				p.name+"_dropped_reasoning_enabled",
+				p.name+" was dropped because reasoning/thinking is enabled for this SDK/model.",
+			)
+			p.clear()
+		default:
+		}
+	}
+
+	if mp.Candidates > 1 {
+		switch {
+		case c.MaxCandidates <= 1:
+			n.reject(
+				"modelParam.candidates",
+				capPath("maxCandidates"),
+				"candidates_dropped_unsupported",
+				"candidates was dropped because this SDK/model returns a single candidate.",
+			)
+			mp.Candidates = 0
+		case mp.Candidates > c.MaxCandidates:
+			n.reject(
+				"modelParam.candidates",
+				capPath("maxCandidates"),
+				"candidates_truncated",
+				fmt.Sprintf("candidates was truncated to max=%d.", c.MaxCandidates),
+			)
+			mp.Candidates = c.MaxCandidates
+		default:
+		}
+	}
+
+	if lp := mp.Logprobs; lp != nil {
+		switch {
+		case !c.SupportsLogprobs:
+			n.reject(
+				"modelParam.logprobs",
+				capPath("supportsLogprobs"),
+				"logprobs_dropped_unsupported",
+				"logprobs was dropped because it is not supported by this SDK/model.",
+			)
+			mp.Logprobs = nil
+		case lp.TopAlternatives > c.MaxTopLogprobs:
+			n.reject(
+				"modelParam.logprobs.topAlternatives",
+				capPath("maxTopLogprobs"),
+				"logprobs_top_alternatives_truncated",
+				fmt.Sprintf("logprobs.topAlternatives was truncated to max=%d.", c.MaxTopLogprobs),
+			)
+			mp.Logprobs = &spec.LogprobsParam{TopAlternatives: c.MaxTopLogprobs}
+		default:
+		}
+	}
+	return nil
+}
+
+// validateSamplingParams rejects sampling values that no provider accepts.
+// Provider-specific ranges, such as for penalties, are left to the provider.
+func validateSamplingParams(mp *spec.ModelParam) error {
+	if mp.TopP != nil && (*mp.TopP < 0 || *mp.TopP > 1) {
+		return fmt.Errorf("modelParam.topP must be in [0, 1], got %v", *mp.TopP)
+	}
+	if mp.TopK != nil && *mp.TopK < 1 {
+		return fmt.Errorf("modelParam.topK must be positive, got %d", *mp.TopK)
+	}
+	if mp.Candidates < 0 {
+		return errors.New("modelParam.candidates must not be negative")
+	}
+	if mp.Logprobs != nil && mp.Logprobs.TopAlternatives < 0 {
+		return errors.New("modelParam.logprobs.topAlternatives must not be negative")
+	}
+	for token := range mp.LogitBias {
+		if _, err := strconv.ParseInt(token, 10, 64); err != nil {
+			return fmt.Errorf("modelParam.logitBias: token %q is not a token ID", token)
+		}
+	}
+	return nil
+}
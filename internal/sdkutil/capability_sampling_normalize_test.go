@@ -0,0 +1,229 @@
+package sdkutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func samplingTestRequest(reasoning *spec.ReasoningParam) *spec.FetchCompletionRequest {
+	req := normalizeModeTestRequest(reasoning)
+	req.ModelParam.Temperature = nil
+	req.ModelParam.StopSequences = nil
+	req.Inputs[0].InputMessage.CacheControl = nil
+	req.ModelParam.TopP = new(0.9)
+	req.ModelParam.TopK = new(40)
+	req.ModelParam.Seed = new(int64(7))
+	req.ModelParam.PresencePenalty = new(0.5)
+	req.ModelParam.FrequencyPenalty = new(0.25)
+	req.ModelParam.LogitBias = map[string]int{"50256": -100}
+	req.ModelParam.Candidates = 4
+	return req
+}
+
+func TestNormalizeSampling(t *testing.T) {
+	low := &spec.ReasoningParam{Type: spec.ReasoningTypeSingleWithLevels, Level: spec.ReasoningLevelLow}
+
+	tests := []struct {
+		name      string
+		sampling  *spec.SamplingCapabilities
+		reasoning *spec.ReasoningParam
+		want      spec.ModelParam
+		wantCodes []string
+	}{
+		{
+			name: "all supported",
+			sampling: &spec.SamplingCapabilities{
+				SupportsTopP: true, SupportsTopK: true, SupportsSeed: true, SupportsPresencePenalty: true,
+				SupportsFrequencyPenalty: true, SupportsLogitBias: true, MaxCandidates: 8,
+			},
+			want: samplingTestRequest(nil).ModelParam,
+		},
+		{
+			name: "nil capabilities drop everything",
+			want: spec.ModelParam{Name: "test-model"},
+			wantCodes: []string{
+				"topP_dropped_unsupported",
+				"topK_dropped_unsupported",
+				"seed_dropped_unsupported",
+				"presencePenalty_dropped_unsupported",
+				"frequencyPenalty_dropped_unsupported",
+				"logitBias_dropped_unsupported",
+				"candidates_dropped_unsupported",
+			},
+		},
+		{
+			name:     "candidates truncated and unsupported fields dropped",
+			sampling: &spec.SamplingCapabilities{SupportsTopP: true, SupportsSeed: true, MaxCandidates: 2},
+			want: spec.ModelParam{
+				Name:       "test-model",
+				TopP:       new(0.9),
+				Seed:       new(int64(7)),
+				Candidates: 2,
+			},
+			wantCodes: []string{
+				"topK_dropped_unsupported",
+				"presencePenalty_dropped_unsupported",
+				"frequencyPenalty_dropped_unsupported",
+				"logitBias_dropped_unsupported",
+				"candidates_truncated",
+			},
+		},
+		{
+			name: "reasoning drops bound params but keeps seed",
+			sampling: &spec.SamplingCapabilities{
+				SupportsTopP: true, SupportsTopK: true, SupportsSeed: true, SupportsPresencePenalty: true,
+				SupportsFrequencyPenalty: true, SupportsLogitBias: true, MaxCandidates: 8,
+				DisallowedWithReasoning: true,
+			},
+			reasoning: low,
+			want: spec.ModelParam{
+				Name:       "test-model",
+				Reasoning:  low,
+				Seed:       new(int64(7)),
+				Candidates: 4,
+			},
+			wantCodes: []string{
+				"topP_dropped_reasoning_enabled",
+				"topK_dropped_reasoning_enabled",
+				"presencePenalty_dropped_reasoning_enabled",
+				"frequencyPenalty_dropped_reasoning_enabled",
+				"logitBias_dropped_reasoning_enabled",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := normalizeModeTestCaps()
+			caps.SamplingCapabilities = tt.sampling
+
+			got, _, warns, err := NormalizeRequestForSDK(
+				t.Context(), samplingTestRequest(tt.reasoning), nil, spec.ProviderSDKTypeOpenAIChatCompletions, caps,
+			)
+			if err != nil {
+				t.Fatalf("NormalizeRequestForSDK error: %v", err)
+			}
+			if !reflect.DeepEqual(got.ModelParam, tt.want) {
+				t.Fatalf("modelParam = %+v, want %+v", got.ModelParam, tt.want)
+			}
+			var codes []string
+			for _, w := range warns {
+				codes = append(codes, w.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantCodes) {
+				t.Fatalf("warning codes = %v, want %v", codes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestNormalizeSamplingStrict(t *testing.T) {
+	caps := normalizeModeTestCaps()
+	caps.SamplingCapabilities = &spec.SamplingCapabilities{SupportsTopP: true}
+	req := samplingTestRequest(nil)
+	req.ModelParam.TopK, req.ModelParam.Seed, req.ModelParam.PresencePenalty = nil, nil, nil
+	req.ModelParam.FrequencyPenalty, req.ModelParam.LogitBias = nil, nil
+
+	_, _, _, err := NormalizeRequestForSDK(
+		t.Context(), req, &spec.FetchCompletionOptions{NormalizationMode: spec.NormalizationModeStrict},
+		spec.ProviderSDKTypeOpenAIResponses, caps,
+	)
+	var cve *spec.CapabilityValidationError
+	if !errors.As(err, &cve) || len(cve.Violations) != 1 ||
+		cve.Violations[0].CapabilityPath != "samplingCapabilities.maxCandidates" {
+		t.Fatalf("err = %v, want a single maxCandidates violation", err)
+	}
+}
+
+func TestNormalizeSamplingInvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(mp *spec.ModelParam)
+	}{
+		{name: "topP above one", mutate: func(mp *spec.ModelParam) { mp.TopP = new(1.5) }},
+		{name: "zero topK", mutate: func(mp *spec.ModelParam) { mp.TopK = new(0) }},
+		{name: "negative candidates", mutate: func(mp *spec.ModelParam) { mp.Candidates = -1 }},
+		{name: "non numeric logit bias token", mutate: func(mp *spec.ModelParam) {
+			mp.LogitBias = map[string]int{"hello": 5}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := samplingTestRequest(nil)
+			tt.mutate(&req.ModelParam)
+			_, _, _, err := NormalizeRequestForSDK(
+				t.Context(), req, nil, spec.ProviderSDKTypeOpenAIChatCompletions, normalizeModeTestCaps(),
+			)
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestNormalizeLogprobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling *spec.SamplingCapabilities
+		in       *spec.LogprobsParam
+		want     *spec.LogprobsParam
+		wantCode string
+	}{
+		{
+			name:     "supported",
+			sampling: &spec.SamplingCapabilities{SupportsLogprobs: true, MaxTopLogprobs: 20},
+			in:       &spec.LogprobsParam{TopAlternatives: 5},
+			want:     &spec.LogprobsParam{TopAlternatives: 5},
+		},
+		{
+			name:     "unsupported",
+			sampling: &spec.SamplingCapabilities{SupportsTopP: true},
+			in:       &spec.LogprobsParam{},
+			wantCode: "logprobs_dropped_unsupported",
+		},
+		{
+			name:     "top alternatives truncated",
+			sampling: &spec.SamplingCapabilities{SupportsLogprobs: true, MaxTopLogprobs: 5},
+			in:       &spec.LogprobsParam{TopAlternatives: 10},
+			want:     &spec.LogprobsParam{TopAlternatives: 5},
+			wantCode: "logprobs_top_alternatives_truncated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caps := normalizeModeTestCaps()
+			caps.SamplingCapabilities = tt.sampling
+			req := samplingTestRequest(nil)
+			req.ModelParam = spec.ModelParam{Name: req.ModelParam.Name, Logprobs: tt.in}
+
+			got, _, warns, err := NormalizeRequestForSDK(
+				t.Context(), req, nil, spec.ProviderSDKTypeOpenAIChatCompletions, caps,
+			)
+			if err != nil {
+				t.Fatalf("NormalizeRequestForSDK error: %v", err)
+			}
+			if !reflect.DeepEqual(got.ModelParam.Logprobs, tt.want) {
+				t.Fatalf("logprobs = %+v, want %+v", got.ModelParam.Logprobs, tt.want)
+			}
+			var code string
+			if len(warns) > 0 {
+				code = warns[0].Code
+			}
+			if len(warns) > 1 || code != tt.wantCode {
+				t.Fatalf("warnings = %+v, want %q", warns, tt.wantCode)
+			}
+		})
+	}
+
+	req := samplingTestRequest(nil)
+	req.ModelParam.Logprobs = &spec.LogprobsParam{TopAlternatives: -1}
+	if _, _, _, err := NormalizeRequestForSDK(
+		t.Context(), req, nil, spec.ProviderSDKTypeOpenAIChatCompletions, normalizeModeTestCaps(),
+	); err == nil {
+		t.Fatal("negative topAlternatives: expected a validation error")
+	}
+}
@@ -42,3 +42,11 @@ func CloneIntPtr(p *int) *int {
 	v := *p
 	return &v
 }
+
+func CloneInt64Ptr(p *int64) *int64 {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -20,3 +20,22 @@ type CompletionProvider interface {
 		opts *spec.FetchCompletionOptions,
 	) (*spec.FetchCompletionResponse, error)
 }
+
+// TokenCounter is implemented by providers that can count the input tokens of a request server-side.
+type TokenCounter interface {
+	CountTokens(
+		ctx context.Context,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.TokenCount, error)
+}
+
+// RequestCompiler is implemented by providers that can build the provider HTTP request for a completion without
+// sending it.
+type RequestCompiler interface {
+	CompileRequest(
+		ctx context.Context,
+		fetchCompletionRequest *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.CompiledRequest, error)
+}
@@ -0,0 +1,53 @@
+package sdkutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ObserveStreamEvents returns opts with its StreamHandler wrapped so that span sees every event first, if span
+// implements spec.CompletionStreamObserver. Otherwise opts is returned unchanged. opts itself is never modified.
+func ObserveStreamEvents(opts *spec.FetchCompletionOptions, span spec.CompletionSpan) *spec.FetchCompletionOptions {
+	if opts == nil || opts.StreamHandler == nil {
+		return opts
+	}
+	observer, ok := span.(spec.CompletionStreamObserver)
+	if !ok {
+		return opts
+	}
+
+	handler := opts.StreamHandler
+	out := *opts
+	out.StreamHandler = func(event spec.StreamEvent) error {
+		observer.ObserveStreamEvent(event)
+		return handler(event)
+	}
+	return &out
+}
+
+// InterceptRequest passes the normalized request to debugger, if it implements
+// spec.CompletionRequestInterceptor. req is the call's own copy; the
+// interceptor gets a shallow copy of opts, so the caller's options, later
+// retry attempts and emulated candidates never see its changes to them.
+func InterceptRequest(
+	ctx context.Context,
+	debugger spec.CompletionDebugger,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) error {
+	i, ok := debugger.(spec.CompletionRequestInterceptor)
+	if !ok {
+		return nil
+	}
+	var optsCopy *spec.FetchCompletionOptions
+	if opts != nil {
+		o := *opts
+		optsCopy = &o
+	}
+	if err := i.InterceptRequest(ctx, req, optsCopy); err != nil {
+		return fmt.Errorf("request interceptor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package sdkutil
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type observingSpan struct{ log *[]string }
+
+func (s observingSpan) End(*spec.CompletionSpanEnd) any { return nil }
+
+func (s observingSpan) ObserveStreamEvent(ev spec.StreamEvent) {
+	*s.log = append(*s.log, "observe:"+ev.Text.Text)
+}
+
+type plainSpan struct{}
+
+func (plainSpan) End(*spec.CompletionSpanEnd) any { return nil }
+
+func TestObserveStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	var log []string
+	handler := func(ev spec.StreamEvent) error {
+		log = append(log, "handle:"+ev.Text.Text)
+		return nil
+	}
+	opts := &spec.FetchCompletionOptions{CompletionKey: "k", StreamHandler: handler}
+
+	if got := ObserveStreamEvents(opts, plainSpan{}); got != opts {
+		t.Error("opts replaced for a span that does not observe events")
+	}
+	if got := ObserveStreamEvents(opts, nil); got != opts {
+		t.Error("opts replaced for a nil span")
+	}
+
+	got := ObserveStreamEvents(opts, observingSpan{log: &log})
+	if got == opts || got.CompletionKey != "k" {
+		t.Fatalf("ObserveStreamEvents() = %+v, want a copy of opts", got)
+	}
+	if err := got.StreamHandler(spec.StreamEvent{Text: &spec.StreamTextChunk{Text: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"observe:a", "handle:a"}; !slices.Equal(log, want) {
+		t.Errorf("calls = %v, want %v", log, want)
+	}
+
+	// The caller's options keep the original handler.
+	log = nil
+	_ = opts.StreamHandler(spec.StreamEvent{Text: &spec.StreamTextChunk{Text: "b"}})
+	if want := []string{"handle:b"}; !slices.Equal(log, want) {
+		t.Errorf("calls = %v, want %v", log, want)
+	}
+}
+
+// mutatingInterceptor changes both the request and the options it is given.
+type mutatingInterceptor struct{}
+
+func (mutatingInterceptor) HTTPClient(*http.Client) *http.Client { return nil }
+
+func (mutatingInterceptor) StartSpan(
+	ctx context.Context,
+	_ *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	return ctx, nil
+}
+
+func (mutatingInterceptor) InterceptRequest(
+	_ context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) error {
+	req.ModelParam.Name = "intercepted"
+	opts.CompletionKey = "intercepted"
+	opts.StreamHandler = nil
+	return nil
+}
+
+func TestInterceptRequestCopiesOptions(t *testing.T) {
+	t.Parallel()
+
+	req := &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{Name: "m"}}
+	opts := &spec.FetchCompletionOptions{
+		CompletionKey: "k",
+		StreamHandler: func(spec.StreamEvent) error { return nil },
+	}
+	if err := InterceptRequest(t.Context(), mutatingInterceptor{}, req, opts); err != nil {
+		t.Fatal(err)
+	}
+	if req.ModelParam.Name != "intercepted" {
+		t.Errorf("model = %q, want the interceptor's change to the request", req.ModelParam.Name)
+	}
+	if opts.CompletionKey != "k" || opts.StreamHandler == nil {
+		t.Errorf("opts = %+v, want the caller's options unchanged", opts)
+	}
+}
@@ -107,6 +107,29 @@ func isContentItemEmpty(it spec.InputOutputContentItemUnion) bool {
 			f.AdditionalContext == "" &&
 			f.CitationConfig == nil
 
+	case spec.ContentItemKindAudio:
+		if it.AudioItem == nil {
+			return true
+		}
+		a := it.AudioItem
+		return a.ID == "" &&
+			a.AudioName == "" &&
+			a.AudioURL == "" &&
+			a.AudioData == "" &&
+			a.FileID == "" &&
+			a.Transcript == ""
+
+	case spec.ContentItemKindVideo:
+		if it.VideoItem == nil {
+			return true
+		}
+		v := it.VideoItem
+		return v.ID == "" &&
+			v.VideoName == "" &&
+			v.VideoURL == "" &&
+			v.VideoData == "" &&
+			v.FileID == ""
+
 	default:
 		// Unknown or zero-value kind.
 		return true
@@ -0,0 +1,55 @@
+package sdkutil
+
+import (
+	"mime"
+	"strings"
+)
+
+// AudioFormatFromMIME returns the short audio format name, e.g. "wav" or "mp3", that OpenAI style APIs expect for
+// an audio MIME type. Unknown types yield their subtype.
+func AudioFormatFromMIME(mimeType string) string {
+	mt, _, err := mime.ParseMediaType(strings.TrimSpace(mimeType))
+	if err != nil {
+		mt = strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	switch mt {
+	case "audio/wav", "audio/wave", "audio/x-wav", "audio/vnd.wave":
+		return "wav"
+	case "audio/mpeg", "audio/mp3", "audio/mpeg3", "audio/x-mpeg-3":
+		return "mp3"
+	case "audio/flac", "audio/x-flac":
+		return "flac"
+	case "audio/aac", "audio/x-aac":
+		return "aac"
+	case "audio/opus", "audio/ogg":
+		return "opus"
+	case "audio/pcm", "audio/l16":
+		return "pcm16"
+	default:
+		_, sub, ok := strings.Cut(mt, "/")
+		if !ok {
+			return mt
+		}
+		return sub
+	}
+}
+
+// AudioMIMEFromFormat is the inverse of AudioFormatFromMIME for the formats OpenAI style APIs return.
+func AudioMIMEFromFormat(format string) string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "wav":
+		return "audio/wav"
+	case "mp3":
+		return "audio/mpeg"
+	case "flac":
+		return "audio/flac"
+	case "aac":
+		return "audio/aac"
+	case "opus":
+		return "audio/ogg"
+	case "pcm16":
+		return "audio/pcm"
+	default:
+		return ""
+	}
+}
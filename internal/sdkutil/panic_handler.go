@@ -1,6 +1,7 @@
 package sdkutil
 
 import (
+	"context"
 	"runtime/debug"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
@@ -8,9 +9,9 @@ import (
 
 // Recover logs a panic (if any) at error level and prevents it from bringing
 // down the goroutine's caller. It does not modify any returned error.
-func Recover(msg string, fields ...any) {
+func Recover(ctx context.Context, msg string, fields ...any) {
 	if r := recover(); r != nil {
 		fields := append(fields, "panic", r, "stack", string(debug.Stack()))
-		logutil.Error(msg, fields...)
+		logutil.ErrorContext(ctx, msg, fields...)
 	}
 }
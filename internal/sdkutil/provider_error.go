@@ -0,0 +1,202 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// ProviderErrorDetails carries whatever an adapter could extract from its SDK
+// error before classification. All fields are optional.
+type ProviderErrorDetails struct {
+	HTTPStatus   int
+	ProviderCode string
+	RequestID    string
+	Header       http.Header
+}
+
+var (
+	contentFilteredMarkers = []string{
+		"content_filter",
+		"content_policy",
+		"content policy",
+		"prohibited_content",
+		"responsible ai policy",
+	}
+	contextLengthMarkers = []string{
+		"context_length_exceeded",
+		"maximum context length",
+		"context window",
+		"prompt is too long",
+		"input token count",
+		"exceeds the maximum number of tokens",
+		"too many tokens",
+	}
+	quotaExceededMarkers = []string{
+		"insufficient_quota",
+		"billing_error",
+		"exceeded your current quota",
+	}
+)
+
+// NewProviderError classifies err and wraps it in a *spec.ProviderError.
+//
+// It returns nil for a nil err, and err unchanged if it already wraps a
+// ProviderError or originates from the caller's StreamHandler.
+func NewProviderError(provider spec.ProviderName, err error, details ProviderErrorDetails) error {
+	if err == nil {
+		return nil
+	}
+	var existing *spec.ProviderError
+	if errors.As(err, &existing) {
+		return err
+	}
+	var handlerErr *streamHandlerError
+	if errors.As(err, &handlerErr) {
+		return err
+	}
+
+	pe := &spec.ProviderError{
+		Kind:         classifyProviderError(err, details),
+		Provider:     provider,
+		HTTPStatus:   details.HTTPStatus,
+		ProviderCode: details.ProviderCode,
+		RequestID:    details.RequestID,
+		Err:          err,
+	}
+	if d, ok := RetryAfterFromHeader(details.Header, time.Now()); ok {
+		pe.RetryAfter = d
+	}
+	return pe
+}
+
+// SetResponseErrorKind copies the ProviderError kind wrapped by err (if any)
+// into resp.Error.Code.
+func SetResponseErrorKind(resp *spec.FetchCompletionResponse, err error) {
+	if resp == nil || err == nil {
+		return
+	}
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		return
+	}
+	if resp.Error == nil {
+		resp.Error = &spec.Error{Message: err.Error()}
+	}
+	resp.Error.Code = string(pe.Kind)
+}
+
+// RetryAfterFromHeader parses "retry-after-ms" and "Retry-After" (seconds or
+// HTTP date) response headers. The millisecond variant takes precedence.
+func RetryAfterFromHeader(h http.Header, now time.Time) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After-Ms")); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func classifyProviderError(err error, details ProviderErrorDetails) spec.ProviderErrorKind {
+	if errors.Is(err, context.Canceled) {
+		return spec.ProviderErrorKindCancelled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return spec.ProviderErrorKindTimeout
+	}
+
+	text := strings.ToLower(details.ProviderCode + " " + err.Error())
+	switch {
+	case containsAny(text, contentFilteredMarkers):
+		return spec.ProviderErrorKindContentFiltered
+	case containsAny(text, contextLengthMarkers):
+		return spec.ProviderErrorKindContextLengthExceeded
+	case containsAny(text, quotaExceededMarkers):
+		return spec.ProviderErrorKindQuotaExceeded
+	default:
+		// Fall.
+	}
+
+	if details.HTTPStatus != 0 {
+		return classifyHTTPStatus(details.HTTPStatus)
+	}
+
+	// No HTTP status: errors reported inside a stream or transport failures.
+	switch {
+	case strings.Contains(text, "rate_limit"), strings.Contains(text, "resource_exhausted"):
+		return spec.ProviderErrorKindRateLimited
+	case strings.Contains(text, "overloaded"), strings.Contains(text, "unavailable"):
+		return spec.ProviderErrorKindOverloaded
+	case strings.Contains(text, "authentication_error"),
+		strings.Contains(text, "permission_error"),
+		strings.Contains(text, "invalid_api_key"),
+		strings.Contains(text, "unauthenticated"),
+		strings.Contains(text, "permission_denied"):
+		return spec.ProviderErrorKindAuth
+	case strings.Contains(text, "invalid_request"), strings.Contains(text, "invalid_argument"):
+		return spec.ProviderErrorKindInvalidRequest
+	case strings.Contains(text, "timeout_error"), strings.Contains(text, "deadline_exceeded"):
+		return spec.ProviderErrorKindTimeout
+	default:
+		// Fall.
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return spec.ProviderErrorKindTimeout
+	}
+	return spec.ProviderErrorKindUnknown
+}
+
+func classifyHTTPStatus(status int) spec.ProviderErrorKind {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return spec.ProviderErrorKindAuth
+	case http.StatusTooManyRequests:
+		return spec.ProviderErrorKindRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return spec.ProviderErrorKindTimeout
+	case http.StatusServiceUnavailable, 529:
+		return spec.ProviderErrorKindOverloaded
+	case 499:
+		return spec.ProviderErrorKindCancelled
+	case http.StatusBadRequest,
+		http.StatusNotFound,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnprocessableEntity:
+		return spec.ProviderErrorKindInvalidRequest
+	default:
+		return spec.ProviderErrorKindUnknown
+	}
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestNewProviderError_Classification(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		err     error
+		details ProviderErrorDetails
+		want    spec.ProviderErrorKind
+	}{
+		{
+			name:    "rate limited status",
+			err:     errors.New("too many requests"),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusTooManyRequests},
+			want:    spec.ProviderErrorKindRateLimited,
+		},
+		{
+			name:    "quota exceeded beats rate limit status",
+			err:     errors.New(`{"code":"insufficient_quota"}`),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusTooManyRequests},
+			want:    spec.ProviderErrorKindQuotaExceeded,
+		},
+		{
+			name:    "anthropic overloaded status",
+			err:     errors.New("overloaded"),
+			details: ProviderErrorDetails{HTTPStatus: 529},
+			want:    spec.ProviderErrorKindOverloaded,
+		},
+		{
+			name:    "auth status",
+			err:     errors.New("bad key"),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusUnauthorized},
+			want:    spec.ProviderErrorKindAuth,
+		},
+		{
+			name:    "context length from openai code",
+			err:     errors.New("bad request"),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusBadRequest, ProviderCode: "context_length_exceeded"},
+			want:    spec.ProviderErrorKindContextLengthExceeded,
+		},
+		{
+			name: "context length from anthropic message",
+			err: errors.New(
+				`{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}`,
+			),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusBadRequest},
+			want:    spec.ProviderErrorKindContextLengthExceeded,
+		},
+		{
+			name:    "content filtered",
+			err:     errors.New("API finished as incomplete, content_filter"),
+			details: ProviderErrorDetails{},
+			want:    spec.ProviderErrorKindContentFiltered,
+		},
+		{
+			name:    "plain bad request",
+			err:     errors.New("bad request"),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusBadRequest},
+			want:    spec.ProviderErrorKindInvalidRequest,
+		},
+		{
+			name: "overloaded inside stream",
+			err: errors.New(
+				`received error while streaming: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			),
+			want: spec.ProviderErrorKindOverloaded,
+		},
+		{
+			name: "gemini resource exhausted without status",
+			err:  errors.New("quota"),
+			details: ProviderErrorDetails{
+				ProviderCode: "RESOURCE_EXHAUSTED",
+			},
+			want: spec.ProviderErrorKindRateLimited,
+		},
+		{
+			name: "cancelled",
+			err:  fmt.Errorf("request: %w", context.Canceled),
+			want: spec.ProviderErrorKindCancelled,
+		},
+		{
+			name: "deadline",
+			err:  fmt.Errorf("request: %w", context.DeadlineExceeded),
+			want: spec.ProviderErrorKindTimeout,
+		},
+		{
+			name:    "server error",
+			err:     errors.New("internal"),
+			details: ProviderErrorDetails{HTTPStatus: http.StatusInternalServerError},
+			want:    spec.ProviderErrorKindUnknown,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewProviderError("p", tc.err, tc.details)
+			var pe *spec.ProviderError
+			if !errors.As(err, &pe) {
+				t.Fatalf("expected *spec.ProviderError, got %T", err)
+			}
+			if pe.Kind != tc.want {
+				t.Fatalf("kind: got %q want %q", pe.Kind, tc.want)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatal("expected provider error to unwrap to the original error")
+			}
+		})
+	}
+}
+
+func TestNewProviderError_PassThrough(t *testing.T) {
+	t.Parallel()
+
+	if NewProviderError("p", nil, ProviderErrorDetails{}) != nil {
+		t.Fatal("expected nil for nil error")
+	}
+
+	handlerErr := SafeCallStreamHandler(t.Context(),
+		func(spec.StreamEvent) error { return errors.New("caller stop") },
+		spec.StreamEvent{},
+	)
+	got := NewProviderError("p", errors.Join(handlerErr, context.Canceled), ProviderErrorDetails{})
+	var pe *spec.ProviderError
+	if errors.As(got, &pe) {
+		t.Fatal("stream handler errors must not be reported as provider errors")
+	}
+
+	first := NewProviderError("p", errors.New("x"), ProviderErrorDetails{HTTPStatus: http.StatusBadRequest})
+	if again := NewProviderError("q", first, ProviderErrorDetails{}); again != first {
+		t.Fatal("expected an existing provider error to be returned unchanged")
+	}
+}
+
+func TestNewProviderError_RetryAfterAndResponseCode(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	h.Set("retry-after-ms", "250")
+	err := NewProviderError("p", errors.New("slow down"), ProviderErrorDetails{
+		HTTPStatus: http.StatusTooManyRequests,
+		RequestID:  "req_1",
+		Header:     h,
+	})
+
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *spec.ProviderError, got %T", err)
+	}
+	if pe.RetryAfter != 250*time.Millisecond || pe.RequestID != "req_1" || !pe.IsTransient() {
+		t.Fatalf("unexpected provider error: %+v", pe)
+	}
+
+	resp := &spec.FetchCompletionResponse{Error: &spec.Error{Message: "slow down"}}
+	SetResponseErrorKind(resp, err)
+	if resp.Error.Code != string(spec.ProviderErrorKindRateLimited) {
+		t.Fatalf("response error code: got %q", resp.Error.Code)
+	}
+}
+
+func TestRetryAfterFromHeader(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"absent", nil, 0, false},
+		{"milliseconds take precedence", map[string]string{
+			"retry-after-ms": "1500",
+			"Retry-After":    "10",
+		}, 1500 * time.Millisecond, true},
+		{"seconds", map[string]string{"Retry-After": "2"}, 2 * time.Second, true},
+		{"http date", map[string]string{
+			"Retry-After": now.Add(5 * time.Second).Format(http.TimeFormat),
+		}, 5 * time.Second, true},
+		{"garbage", map[string]string{"Retry-After": "soon"}, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := http.Header{}
+			for k, v := range tc.header {
+				h.Set(k, v)
+			}
+			got, ok := RetryAfterFromHeader(h, now)
+			if ok != tc.wantOK || got != tc.want {
+				t.Fatalf("got (%v, %v) want (%v, %v)", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
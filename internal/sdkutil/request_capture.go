@@ -0,0 +1,157 @@
+package sdkutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const redactedValue = "***"
+
+// CompileAPIKey is the credential adapters compile a request with when the provider has no API key set. Compiled
+// requests mask it like a real key.
+const CompileAPIKey = "compile-request-placeholder-key"
+
+var errRequestCaptured = errors.New("request captured, not sent")
+
+// Header names whose values are always masked in a compiled request.
+var credentialHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"api-key",
+	"x-api-key",
+	"x-goog-api-key",
+	"x-amz-security-token",
+}
+
+// RequestCapture is an http.RoundTripper that records the first request an SDK client sends through it instead of
+// sending it, then cancels the context returned by NewRequestCapture so the SDK gives up without retrying.
+type RequestCapture struct {
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	req     *http.Request
+	body    []byte
+	bodyErr error
+}
+
+// NewRequestCapture returns a context to make the SDK call with and a capture for its HTTP client.
+func NewRequestCapture(ctx context.Context) (context.Context, *RequestCapture) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return ctx, &RequestCapture{cancel: cancel}
+}
+
+// HTTPClient returns a client that sends every request to the capture. With a debugger, the capture replaces the
+// network below the debugger's HTTP client, so headers and body changes made by its transports are captured as
+// FetchCompletion would send them.
+func (c *RequestCapture) HTTPClient(debugger spec.CompletionDebugger) *http.Client {
+	client := &http.Client{Transport: c}
+	if debugger != nil {
+		if wrapped := debugger.HTTPClient(client); wrapped != nil {
+			return wrapped
+		}
+	}
+	return client
+}
+
+func (c *RequestCapture) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.req == nil {
+		c.req = r
+		if r.Body != nil {
+			c.body, c.bodyErr = io.ReadAll(r.Body)
+		}
+	}
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+	c.cancel(errRequestCaptured)
+	return nil, errRequestCaptured
+}
+
+// CompiledRequest returns the recorded request with credentials masked. Header values and URL query values
+// containing one of secrets are masked too. callErr is the error of the SDK call; it is returned when the SDK failed
+// before sending anything.
+func (c *RequestCapture) CompiledRequest(callErr error, secrets ...string) (*spec.CompiledRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancel(nil)
+
+	if c.req == nil {
+		if callErr == nil {
+			callErr = errors.New("no request was made")
+		}
+		return nil, fmt.Errorf("compile request: %w", callErr)
+	}
+	if c.bodyErr != nil {
+		return nil, fmt.Errorf("compile request: read body: %w", c.bodyErr)
+	}
+
+	return &spec.CompiledRequest{
+		Method:  c.req.Method,
+		URL:     redactURL(c.req.URL, secrets),
+		Headers: redactHeaders(c.req.Header, secrets),
+		Body:    bytes.Clone(c.body),
+	}, nil
+}
+
+func redactHeaders(h http.Header, secrets []string) http.Header {
+	out := make(http.Header, len(h))
+	for k, vals := range h {
+		masked := make([]string, len(vals))
+		for i, v := range vals {
+			if isCredentialHeader(k) || containsSecret(v, secrets) {
+				masked[i] = redactedValue
+			} else {
+				masked[i] = v
+			}
+		}
+		out[k] = masked
+	}
+	return out
+}
+
+func redactURL(u *url.URL, secrets []string) string {
+	if u == nil {
+		return ""
+	}
+	q := u.Query()
+	changed := false
+	for k, vals := range q {
+		for i, v := range vals {
+			if strings.EqualFold(k, "key") || containsSecret(v, secrets) {
+				vals[i] = redactedValue
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	cp := *u
+	cp.RawQuery = q.Encode()
+	return cp.String()
+}
+
+func isCredentialHeader(name string) bool {
+	return slices.Contains(credentialHeaders, strings.ToLower(name))
+}
+
+func containsSecret(v string, secrets []string) bool {
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); s != "" && strings.Contains(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package sdkutil
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestCapture(t *testing.T) {
+	ctx, capture := NewRequestCapture(t.Context())
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		"https://example.com/v1/x?key=abc&alt=sse&token=sk-secret",
+		strings.NewReader(`{"a":1}`),
+	)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer other")
+	req.Header.Set("X-Custom-Auth", "sk-secret")
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+
+	_, callErr := capture.HTTPClient(nil).Do(req)
+	if callErr == nil {
+		t.Fatal("expected the captured call to fail")
+	}
+	if ctx.Err() == nil {
+		t.Fatal("expected the capture context to be canceled")
+	}
+
+	got, err := capture.CompiledRequest(callErr, "sk-secret")
+	if err != nil {
+		t.Fatalf("CompiledRequest: %v", err)
+	}
+	if got.Method != http.MethodPost || string(got.Body) != `{"a":1}` {
+		t.Fatalf("method/body = %q/%s", got.Method, got.Body)
+	}
+	if got.URL != "https://example.com/v1/x?alt=sse&key=%2A%2A%2A&token=%2A%2A%2A" {
+		t.Fatalf("url = %q", got.URL)
+	}
+	for name, want := range map[string]string{
+		"Authorization":     redactedValue,
+		"X-Custom-Auth":     redactedValue,
+		"Anthropic-Version": "2023-06-01",
+	} {
+		if v := got.Headers.Get(name); v != want {
+			t.Errorf("header %s = %q, want %q", name, v, want)
+		}
+	}
+}
+
+func TestRequestCaptureNoRequest(t *testing.T) {
+	_, capture := NewRequestCapture(t.Context())
+	sdkErr := errors.New("invalid params")
+	if _, err := capture.CompiledRequest(sdkErr); !errors.Is(err, sdkErr) {
+		t.Fatalf("err = %v, want %v", err, sdkErr)
+	}
+}
@@ -0,0 +1,237 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// StreamEmitter delivers the stream events of a single completion to the
+// caller's StreamHandler.
+//
+// Text and thinking are buffered and flushed by size, by timer, whenever the
+// buffered kind changes, and before any other event is emitted. The handler is
+// therefore never called concurrently and observes events in provider order.
+type StreamEmitter struct {
+	// logCtx carries the call's logger; it is only used for logging.
+	logCtx        context.Context
+	handler       spec.StreamHandler
+	provider      spec.ProviderName
+	model         spec.ModelName
+	completionKey string
+	maxSize       int
+
+	mu      sync.Mutex
+	buf     strings.Builder
+	bufKind spec.StreamContentKind
+	// bufLogprobs are those of the buffered text, delivered with it.
+	bufLogprobs []spec.TokenLogprob
+	firstErr    error
+	closed      bool
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewStreamEmitter starts an emitter for one streaming call. Close must be
+// called once the provider stream is done.
+func NewStreamEmitter(
+	ctx context.Context,
+	provider spec.ProviderName,
+	model spec.ModelName,
+	opts *spec.FetchCompletionOptions,
+) *StreamEmitter {
+	cfg := ResolveStreamConfig(opts)
+	e := &StreamEmitter{
+		logCtx:   context.WithoutCancel(ctx),
+		provider: provider,
+		model:    model,
+		maxSize:  cfg.FlushChunkSize,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if opts != nil {
+		e.handler = opts.StreamHandler
+		e.completionKey = opts.CompletionKey
+	}
+
+	ticker := time.NewTicker(cfg.FlushInterval)
+	go func() {
+		defer Recover(e.logCtx, "stream emitter background flush panic")
+		defer close(e.stopped)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				e.mu.Lock()
+				_ = e.flushLocked()
+				e.mu.Unlock()
+			case <-e.done:
+				return
+			}
+		}
+	}()
+	return e
+}
+
+// WriteText buffers an assistant text fragment.
+func (e *StreamEmitter) WriteText(chunk string) error {
+	return e.write(spec.StreamContentKindText, chunk, nil)
+}
+
+// WriteTextLogprobs buffers an assistant text fragment together with the
+// log probabilities of its tokens.
+func (e *StreamEmitter) WriteTextLogprobs(chunk string, logprobs []spec.TokenLogprob) error {
+	return e.write(spec.StreamContentKindText, chunk, logprobs)
+}
+
+// WriteThinking buffers a reasoning / thinking fragment.
+func (e *StreamEmitter) WriteThinking(chunk string) error {
+	return e.write(spec.StreamContentKindThinking, chunk, nil)
+}
+
+// Emit flushes any buffered text or thinking and then delivers event. Provider,
+// Model and CompletionKey are filled in by the emitter. Emit may be used after
+// Close.
+func (e *StreamEmitter) Emit(event spec.StreamEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.flushLocked(); err != nil {
+		return err
+	}
+	return e.deliverLocked(event)
+}
+
+// OutputItemStart emits an outputItemStart event.
+func (e *StreamEmitter) OutputItemStart(index int, kind spec.OutputKind, id string) error {
+	return e.Emit(spec.StreamEvent{
+		Kind:       spec.StreamContentKindOutputItemStart,
+		OutputItem: &spec.StreamOutputItemChunk{OutputIndex: index, Kind: kind, ID: id},
+	})
+}
+
+// OutputItemStop emits an outputItemStop event.
+func (e *StreamEmitter) OutputItemStop(index int, kind spec.OutputKind, id string) error {
+	return e.Emit(spec.StreamEvent{
+		Kind:       spec.StreamContentKindOutputItemStop,
+		OutputItem: &spec.StreamOutputItemChunk{OutputIndex: index, Kind: kind, ID: id},
+	})
+}
+
+// ToolCall emits one of the toolCallStart, toolCallDelta or toolCallEnd events.
+func (e *StreamEmitter) ToolCall(kind spec.StreamContentKind, chunk spec.StreamToolCallChunk) error {
+	return e.Emit(spec.StreamEvent{Kind: kind, ToolCall: &chunk})
+}
+
+// WebSearchCall emits a webSearchCall status event.
+func (e *StreamEmitter) WebSearchCall(chunk spec.StreamWebSearchCallChunk) error {
+	return e.Emit(spec.StreamEvent{Kind: spec.StreamContentKindWebSearchCall, WebSearchCall: &chunk})
+}
+
+// Citation emits a citation event for the output message at index.
+func (e *StreamEmitter) Citation(index int, citation spec.Citation) error {
+	return e.Emit(spec.StreamEvent{
+		Kind:     spec.StreamContentKindCitation,
+		Citation: &spec.StreamCitationChunk{OutputIndex: index, Citation: citation},
+	})
+}
+
+// Completed emits the final completed event.
+func (e *StreamEmitter) Completed(usage *spec.Usage, stopReason *spec.StopReason) error {
+	return e.Emit(spec.StreamEvent{
+		Kind:      spec.StreamContentKindCompleted,
+		Completed: &spec.StreamCompletedChunk{Usage: usage, StopReason: stopReason},
+	})
+}
+
+// Close stops the flush timer and delivers any buffered data. It is safe to
+// call more than once and returns the first handler error, if any.
+func (e *StreamEmitter) Close() error {
+	e.closeOnce.Do(func() {
+		e.mu.Lock()
+		e.closed = true
+		e.mu.Unlock()
+
+		close(e.done)
+		<-e.stopped
+
+		e.mu.Lock()
+		e.closeErr = e.flushLocked()
+		e.mu.Unlock()
+	})
+	return e.closeErr
+}
+
+func (e *StreamEmitter) write(kind spec.StreamContentKind, chunk string, logprobs []spec.TokenLogprob) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.firstErr != nil {
+		return e.firstErr
+	}
+	if e.closed {
+		return errors.New("stream emitter is closed")
+	}
+	if chunk == "" && len(logprobs) == 0 {
+		return nil
+	}
+
+	if e.bufKind != kind {
+		if err := e.flushLocked(); err != nil {
+			return err
+		}
+		e.bufKind = kind
+	}
+	e.buf.WriteString(chunk)
+	e.bufLogprobs = append(e.bufLogprobs, logprobs...)
+	if e.buf.Len() >= e.maxSize {
+		return e.flushLocked()
+	}
+	return nil
+}
+
+// flushLocked delivers the buffered text or thinking. The caller must hold mu;
+// keeping it locked while invoking the handler serializes all deliveries.
+func (e *StreamEmitter) flushLocked() error {
+	if e.firstErr != nil {
+		return e.firstErr
+	}
+	if e.buf.Len() == 0 && len(e.bufLogprobs) == 0 {
+		return nil
+	}
+
+	data := e.buf.String()
+	logprobs := e.bufLogprobs
+	e.buf.Reset()
+	e.bufLogprobs = nil
+
+	event := spec.StreamEvent{Kind: e.bufKind}
+	if e.bufKind == spec.StreamContentKindThinking {
+		event.Thinking = &spec.StreamThinkingChunk{Text: data}
+	} else {
+		event.Text = &spec.StreamTextChunk{Text: data, Logprobs: logprobs}
+	}
+	return e.deliverLocked(event)
+}
+
+func (e *StreamEmitter) deliverLocked(event spec.StreamEvent) error {
+	if e.firstErr != nil {
+		return e.firstErr
+	}
+	event.Provider = e.provider
+	event.Model = e.model
+	event.CompletionKey = e.completionKey
+	if err := SafeCallStreamHandler(e.logCtx, e.handler, event); err != nil {
+		e.firstErr = err
+		return err
+	}
+	return nil
+}
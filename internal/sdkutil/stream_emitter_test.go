@@ -0,0 +1,165 @@
+package sdkutil
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type recordedEvents struct {
+	events []spec.StreamEvent
+	failAt int
+}
+
+func (r *recordedEvents) handler(ev spec.StreamEvent) error {
+	r.events = append(r.events, ev)
+	if r.failAt > 0 && len(r.events) >= r.failAt {
+		return errors.New("handler failed")
+	}
+	return nil
+}
+
+func (r *recordedEvents) kinds() []spec.StreamContentKind {
+	out := make([]spec.StreamContentKind, 0, len(r.events))
+	for _, ev := range r.events {
+		out = append(out, ev.Kind)
+	}
+	return out
+}
+
+func newTestStreamEmitter(r *recordedEvents) *StreamEmitter {
+	return NewStreamEmitter(context.Background(), "p", "m", &spec.FetchCompletionOptions{
+		CompletionKey: "k",
+		StreamHandler: r.handler,
+		// Keep the timer out of the way so that flushes are deterministic.
+		StreamConfig: &spec.StreamConfig{FlushIntervalMillis: 60_000, FlushChunkSize: 1 << 20},
+	})
+}
+
+func TestStreamEmitterOrdering(t *testing.T) {
+	t.Parallel()
+
+	r := &recordedEvents{}
+	e := newTestStreamEmitter(r)
+
+	steps := []func() error{
+		func() error { return e.WriteThinking("think ") },
+		func() error { return e.WriteThinking("more") },
+		func() error { return e.WriteText("hello ") },
+		func() error { return e.WriteText("world") },
+		func() error {
+			return e.ToolCall(spec.StreamContentKindToolCallStart, spec.StreamToolCallChunk{CallID: "c1", Name: "fn"})
+		},
+		func() error { return e.WriteText("after") },
+		e.Close,
+		func() error {
+			return e.Completed(
+				&spec.Usage{OutputTokens: 3},
+				&spec.StopReason{Kind: spec.StopReasonEndTurn, Raw: "end_turn"},
+			)
+		},
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	want := []spec.StreamContentKind{
+		spec.StreamContentKindThinking,
+		spec.StreamContentKindText,
+		spec.StreamContentKindToolCallStart,
+		spec.StreamContentKindText,
+		spec.StreamContentKindCompleted,
+	}
+	if got := r.kinds(); !slices.Equal(got, want) {
+		t.Fatalf("kinds: got %v want %v", got, want)
+	}
+	if got := r.events[0].Thinking.Text; got != "think more" {
+		t.Fatalf("thinking: got %q", got)
+	}
+	if got := r.events[1].Text.Text; got != "hello world" {
+		t.Fatalf("text: got %q", got)
+	}
+	for i, ev := range r.events {
+		if ev.Provider != "p" || ev.Model != "m" || ev.CompletionKey != "k" {
+			t.Fatalf("event %d: missing metadata: %+v", i, ev)
+		}
+	}
+	if c := r.events[4].Completed; c == nil || c.StopReason.Raw != "end_turn" || c.Usage.OutputTokens != 3 {
+		t.Fatalf("completed: got %+v", c)
+	}
+}
+
+func TestStreamEmitterHandlerErrorIsSticky(t *testing.T) {
+	t.Parallel()
+
+	r := &recordedEvents{failAt: 1}
+	e := newTestStreamEmitter(r)
+
+	if err := e.OutputItemStart(0, spec.OutputKindOutputMessage, "id"); err == nil {
+		t.Fatal("expected handler error")
+	}
+	if err := e.WriteText("ignored"); err == nil {
+		t.Fatal("expected sticky error from write")
+	}
+	if err := e.Close(); err == nil {
+		t.Fatal("expected sticky error from close")
+	}
+	if len(r.events) != 1 {
+		t.Fatalf("expected a single delivered event, got %d", len(r.events))
+	}
+}
+
+func TestStreamEmitterWriteAfterClose(t *testing.T) {
+	t.Parallel()
+
+	r := &recordedEvents{}
+	e := newTestStreamEmitter(r)
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := e.WriteText("late"); err == nil {
+		t.Fatal("expected error writing after close")
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestStreamEmitterTextLogprobs(t *testing.T) {
+	t.Parallel()
+
+	r := &recordedEvents{}
+	e := newTestStreamEmitter(r)
+
+	hi := spec.TokenLogprob{Token: "hi", Logprob: -0.1}
+	there := spec.TokenLogprob{Token: " there", Logprob: -0.5}
+	for _, step := range []func() error{
+		func() error { return e.WriteTextLogprobs("hi", []spec.TokenLogprob{hi}) },
+		func() error { return e.WriteTextLogprobs(" there", []spec.TokenLogprob{there}) },
+		func() error { return e.WriteThinking("hmm") },
+		func() error { return e.WriteText("!") },
+		e.Close,
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(r.events) != 3 {
+		t.Fatalf("events = %+v, want 3", r.events)
+	}
+	if got := r.events[0].Text; got.Text != "hi there" ||
+		!slices.EqualFunc(got.Logprobs, []spec.TokenLogprob{hi, there}, func(a, b spec.TokenLogprob) bool {
+			return a.Token == b.Token && a.Logprob == b.Logprob
+		}) {
+		t.Errorf("first text = %+v, want both fragments with their logprobs", got)
+	}
+	if got := r.events[2].Text; got.Text != "!" || got.Logprobs != nil {
+		t.Errorf("last text = %+v, want no logprobs", got)
+	}
+}
@@ -1,11 +1,9 @@
 package sdkutil
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"runtime/debug"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
@@ -17,115 +15,10 @@ const (
 	FlushChunkSize = 1024
 )
 
-// NewBufferedStreamerWithError buffers stream data while preserving callback
-// errors from size-based, timer-based, and final flushes.
-func NewBufferedStreamerWithError(
-	onDataFlush func(string) error,
-	flushInterval time.Duration,
-	maxSize int,
-) (write func(string) error, flush func() error) {
-	if flushInterval <= 0 {
-		flushInterval = FlushInterval
-	}
-	if maxSize <= 0 {
-		maxSize = FlushChunkSize
-	}
-
-	var mu sync.Mutex
-	var buf strings.Builder
-	var firstErr error
-	var closed bool
-
-	ticker := time.NewTicker(flushInterval)
-	done := make(chan struct{})
-	stopped := make(chan struct{})
-
-	// The caller must hold mu. Keeping mu locked while invoking the callback
-	// prevents this streamer's timer and size-based flushes from racing.
-	flushBufferLocked := func() error {
-		if firstErr != nil {
-			return firstErr
-		}
-		if buf.Len() == 0 {
-			return nil
-		}
-
-		data := buf.String()
-		buf.Reset()
-		if err := onDataFlush(data); err != nil {
-			firstErr = err
-			return err
-		}
-		return nil
-	}
-
-	// Background goroutine time-based flush.
-	go func() {
-		defer Recover("buffered streamer background flush panic")
-		defer close(stopped)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				mu.Lock()
-				_ = flushBufferLocked()
-				mu.Unlock()
-			case <-done:
-				return
-			}
-		}
-	}()
-
-	// Returns the wrapped write.
-	write = func(chunk string) error {
-		mu.Lock()
-		defer mu.Unlock()
-
-		if firstErr != nil {
-			return firstErr
-		}
-		if closed {
-			return errors.New("buffered streamer is closed")
-		}
-		if chunk == "" {
-			return nil
-		}
-
-		buf.WriteString(chunk)
-		if buf.Len() >= maxSize {
-			return flushBufferLocked()
-		}
-		return nil
-	}
-
-	var once sync.Once
-	var flushErr error
-
-	// Flush everything, stop the ticker, and report callback errors.
-	flush = func() error {
-		once.Do(func() {
-			mu.Lock()
-			closed = true
-			mu.Unlock()
-
-			close(done)
-			<-stopped
-
-			mu.Lock()
-			flushErr = flushBufferLocked()
-			mu.Unlock()
-		})
-		return flushErr
-	}
-
-	return write, flush
-}
-
 // SafeCallStreamHandler invokes the provided StreamHandler and converts any
 // panic into an error while logging the panic details. This prevents user
 // callbacks from crashing the streaming loop.
-func SafeCallStreamHandler(handler spec.StreamHandler, event spec.StreamEvent) (err error) {
+func SafeCallStreamHandler(ctx context.Context, handler spec.StreamHandler, event spec.StreamEvent) (err error) {
 	if handler == nil {
 		return nil
 	}
@@ -133,20 +26,34 @@ func SafeCallStreamHandler(handler spec.StreamHandler, event spec.StreamEvent) (
 	// We use an inline recover here so we can both log and surface an error.
 	defer func() {
 		if r := recover(); r != nil {
-			logutil.Error("stream handler panic",
+			logutil.ErrorContext(ctx, "stream handler panic",
 				"panic", r,
 				"kind", event.Kind,
 				"provider", event.Provider,
 				"model", event.Model,
 				"stack", string(debug.Stack()),
 			)
-			err = fmt.Errorf("stream handler panic: %v", r)
+			err = &streamHandlerError{err: fmt.Errorf("stream handler panic: %v", r)}
 		}
 	}()
 
-	return handler(event)
+	if herr := handler(event); herr != nil {
+		return &streamHandlerError{err: herr}
+	}
+	return nil
 }
 
+// streamHandlerError marks errors returned by the caller's StreamHandler so
+// that they are not mistaken for provider failures. It is transparent to
+// errors.Is/As and keeps the original message.
+type streamHandlerError struct {
+	err error
+}
+
+func (e *streamHandlerError) Error() string { return e.err.Error() }
+
+func (e *streamHandlerError) Unwrap() error { return e.err }
+
 // ResolvedStreamConfig is the fully-specified streaming configuration used by
 // providers after applying sensible defaults.
 type ResolvedStreamConfig struct {
@@ -1,63 +1,50 @@
 package sdkutil
 
 import (
+	"encoding/json"
 	"regexp"
-	"slices"
 	"strings"
 
-	"github.com/flexigpt/inference-go/internal/logutil"
 	"github.com/flexigpt/inference-go/spec"
 )
 
-func FilterMessagesByTokenCount(
-	messages []spec.InputUnion,
-	maxTokenCount int,
-) []spec.InputUnion {
-	if len(messages) == 0 {
-		return nil
+// CountRequestTokens estimates the input tokens of a request with tokenizer: the system prompt, all inputs and the
+// tool definitions. A nil tokenizer uses a regex based heuristic.
+func CountRequestTokens(req *spec.FetchCompletionRequest, tokenizer spec.Tokenizer) int {
+	if req == nil {
+		return 0
 	}
+	tok := ResolveTokenizer(tokenizer)
 
-	totalTokens := 0
-	var filtered []spec.InputUnion
-
-	// 1) Basic token-based filtering, newest-first.
-	for _, v := range slices.Backward(messages) {
-		msg := v
-		tokensInMsg := countHeuristicTokensInInputUnion(msg)
-
-		if totalTokens+tokensInMsg <= maxTokenCount || len(filtered) == 0 {
-			filtered = append(filtered, msg)
-			totalTokens += tokensInMsg
-
-			if totalTokens > maxTokenCount {
-				break
-			}
-		} else {
-			break
-		}
+	total := tok.CountTokens(req.ModelParam.SystemPrompt)
+	for _, in := range req.Inputs {
+		total += countTokensInInputUnion(tok, in)
 	}
-
-	// 2) Reverse back to chronological order.
-	for i, j := 0, len(filtered)-1; i < j; i, j = i+1, j-1 {
-		filtered[i], filtered[j] = filtered[j], filtered[i]
+	for _, tc := range req.ToolChoices {
+		total += countTokensInToolChoice(tok, tc)
 	}
+	return total
+}
 
-	// 3) Prune orphan tool outputs (those whose CallID has no matching ToolCall).
-	filtered = pruneOrphanToolOutputs(filtered)
-
-	if len(filtered) < len(messages) {
-		logutil.Debug(
-			"filtered messages are less than input",
-			"originalCount", len(messages),
-			"filteredCount", len(filtered),
-			"approxTokens", totalTokens,
-		)
+func countTokensInToolChoice(tok spec.Tokenizer, tc spec.ToolChoice) int {
+	total := tok.CountTokens(tc.Name) + tok.CountTokens(tc.Description)
+	if len(tc.Arguments) > 0 {
+		// The JSON schema is sent as is, so count its serialized form.
+		if b, err := json.Marshal(tc.Arguments); err == nil {
+			total += tok.CountTokens(string(b))
+		}
 	}
+	return total
+}
 
-	return filtered
+// CountInputTokens estimates the tokens of a single input with tokenizer. A nil tokenizer uses a regex based
+// heuristic.
+func CountInputTokens(in spec.InputUnion, tokenizer spec.Tokenizer) int {
+	return countTokensInInputUnion(ResolveTokenizer(tokenizer), in)
 }
 
-func pruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
+// PruneOrphanToolOutputs drops tool outputs whose CallID has no matching tool call in msgs.
+func PruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
 	if len(msgs) == 0 {
 		return msgs
 	}
@@ -122,41 +109,41 @@ func pruneOrphanToolOutputs(msgs []spec.InputUnion) []spec.InputUnion {
 	return out
 }
 
-func countHeuristicTokensInInputUnion(in spec.InputUnion) int {
+func countTokensInInputUnion(tok spec.Tokenizer, in spec.InputUnion) int {
 	switch in.Kind {
 	case spec.InputKindInputMessage:
-		return countTokensInInputOutputContent(in.InputMessage)
+		return countTokensInInputOutputContent(tok, in.InputMessage)
 
 	case spec.InputKindOutputMessage:
-		return countTokensInInputOutputContent(in.OutputMessage)
+		return countTokensInInputOutputContent(tok, in.OutputMessage)
 
 	case spec.InputKindReasoningMessage:
-		return countTokensInReasoningContent(in.ReasoningMessage)
+		return countTokensInReasoningContent(tok, in.ReasoningMessage)
 
 	case spec.InputKindFunctionToolCall:
-		return countTokensInToolCall(in.FunctionToolCall)
+		return countTokensInToolCall(tok, in.FunctionToolCall)
 
 	case spec.InputKindCustomToolCall:
-		return countTokensInToolCall(in.CustomToolCall)
+		return countTokensInToolCall(tok, in.CustomToolCall)
 
 	case spec.InputKindWebSearchToolCall:
-		return countTokensInToolCall(in.WebSearchToolCall)
+		return countTokensInToolCall(tok, in.WebSearchToolCall)
 
 	case spec.InputKindFunctionToolOutput:
-		return countTokensInToolOutput(in.FunctionToolOutput)
+		return countTokensInToolOutput(tok, in.FunctionToolOutput)
 
 	case spec.InputKindCustomToolOutput:
-		return countTokensInToolOutput(in.CustomToolOutput)
+		return countTokensInToolOutput(tok, in.CustomToolOutput)
 
 	case spec.InputKindWebSearchToolOutput:
-		return countTokensInToolOutput(in.WebSearchToolOutput)
+		return countTokensInToolOutput(tok, in.WebSearchToolOutput)
 
 	default:
 		return 0
 	}
 }
 
-func countTokensInInputOutputContent(c *spec.InputOutputContent) int {
+func countTokensInInputOutputContent(tok spec.Tokenizer, c *spec.InputOutputContent) int {
 	if c == nil {
 		return 0
 	}
@@ -165,62 +152,62 @@ func countTokensInInputOutputContent(c *spec.InputOutputContent) int {
 		switch it.Kind {
 		case spec.ContentItemKindText:
 			if it.TextItem != nil {
-				total += countHeuristicTokensInString(it.TextItem.Text)
+				total += tok.CountTokens(it.TextItem.Text)
 			}
 		case spec.ContentItemKindRefusal:
 			if it.RefusalItem != nil {
-				total += countHeuristicTokensInString(it.RefusalItem.Refusal)
+				total += tok.CountTokens(it.RefusalItem.Refusal)
 			}
-		case spec.ContentItemKindImage:
+		case spec.ContentItemKindImage, spec.ContentItemKindAudio, spec.ContentItemKindVideo:
 			// Ignore.
 		case spec.ContentItemKindFile:
 			if it.FileItem != nil {
 				// AdditionalContext is the main textual part.
-				total += countHeuristicTokensInString(it.FileItem.AdditionalContext)
+				total += tok.CountTokens(it.FileItem.AdditionalContext)
 			}
 		}
 	}
 	return total
 }
 
-func countTokensInReasoningContent(r *spec.ReasoningContent) int {
+func countTokensInReasoningContent(tok spec.Tokenizer, r *spec.ReasoningContent) int {
 	if r == nil {
 		return 0
 	}
 	total := 0
 	for _, s := range r.Summary {
-		total += countHeuristicTokensInString(s)
+		total += tok.CountTokens(s)
 	}
 	for _, t := range r.Thinking {
-		total += countHeuristicTokensInString(t)
+		total += tok.CountTokens(t)
 	}
 	for _, t := range r.RedactedThinking {
-		total += countHeuristicTokensInString(t)
+		total += tok.CountTokens(t)
 	}
-	// EncryptedContent is opaque; ignore for heuristic token counting.
+	// EncryptedContent is opaque; ignore for local token counting.
 	return total
 }
 
-func countTokensInToolCall(call *spec.ToolCall) int {
+func countTokensInToolCall(tok spec.Tokenizer, call *spec.ToolCall) int {
 	if call == nil {
 		return 0
 	}
 	total := 0
 
 	// Tool name + raw arguments text.
-	total += countHeuristicTokensInString(call.Name)
-	total += countHeuristicTokensInString(call.Arguments)
+	total += tok.CountTokens(call.Name)
+	total += tok.CountTokens(call.Arguments)
 
 	// For web search calls, queries and patterns matter most.
 	for _, item := range call.WebSearchToolCallItems {
 		switch item.Kind {
 		case spec.WebSearchToolCallKindSearch:
 			if item.SearchItem != nil {
-				total += countHeuristicTokensInString(item.SearchItem.Query)
+				total += tok.CountTokens(item.SearchItem.Query)
 			}
 		case spec.WebSearchToolCallKindFind:
 			if item.FindItem != nil {
-				total += countHeuristicTokensInString(item.FindItem.Pattern)
+				total += tok.CountTokens(item.FindItem.Pattern)
 			}
 		case spec.WebSearchToolCallKindOpenPage:
 			// URL only; typically short. Ignored for simplicity.
@@ -230,7 +217,7 @@ func countTokensInToolCall(call *spec.ToolCall) int {
 	return total
 }
 
-func countTokensInToolOutput(out *spec.ToolOutput) int {
+func countTokensInToolOutput(tok spec.Tokenizer, out *spec.ToolOutput) int {
 	if out == nil {
 		return 0
 	}
@@ -239,15 +226,15 @@ func countTokensInToolOutput(out *spec.ToolOutput) int {
 	// Function/custom outputs: text content items.
 	for _, it := range out.Contents {
 		if it.Kind == spec.ContentItemKindText && it.TextItem != nil {
-			total += countHeuristicTokensInString(it.TextItem.Text)
+			total += tok.CountTokens(it.TextItem.Text)
 		}
 	}
 
 	// Web search outputs: titles + rendered content carry most of the text.
 	for _, it := range out.WebSearchToolOutputItems {
 		if it.Kind == spec.WebSearchToolOutputKindSearch && it.SearchItem != nil {
-			total += countHeuristicTokensInString(it.SearchItem.Title)
-			total += countHeuristicTokensInString(it.SearchItem.RenderedContent)
+			total += tok.CountTokens(it.SearchItem.Title)
+			total += tok.CountTokens(it.SearchItem.RenderedContent)
 		}
 		// Error items are usually tiny; we ignore them.
 	}
@@ -257,6 +244,21 @@ func countTokensInToolOutput(out *spec.ToolOutput) int {
 
 var tokenRegex = regexp.MustCompile(`\w+|[^\s\w]`)
 
+// heuristicTokenizer is the Tokenizer used when none is configured.
+type heuristicTokenizer struct{}
+
+func (heuristicTokenizer) CountTokens(text string) int {
+	return countHeuristicTokensInString(text)
+}
+
+// ResolveTokenizer returns tokenizer, or the regex based heuristic if it is nil.
+func ResolveTokenizer(tokenizer spec.Tokenizer) spec.Tokenizer {
+	if tokenizer == nil {
+		return heuristicTokenizer{}
+	}
+	return tokenizer
+}
+
 // countHeuristicTokensInString approximates token count by splitting into
 // word-like chunks and single punctuation/symbol characters. This tends
 // to be closer to modern OpenAI BPE tokenization than splitting only on
@@ -0,0 +1,70 @@
+package sdkutil
+
+import (
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// byteTokenizer counts every byte as a token.
+type byteTokenizer struct{}
+
+func (byteTokenizer) CountTokens(text string) int { return len(text) }
+
+func textInput(text string) spec.InputUnion {
+	return spec.InputUnion{
+		Kind: spec.InputKindInputMessage,
+		InputMessage: &spec.InputOutputContent{
+			Role: spec.RoleUser,
+			Contents: []spec.InputOutputContentItemUnion{{
+				Kind:     spec.ContentItemKindText,
+				TextItem: &spec.ContentItemText{Text: text},
+			}},
+		},
+	}
+}
+
+func TestCountRequestTokens(t *testing.T) {
+	t.Parallel()
+
+	req := &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{SystemPrompt: "sys"},
+		Inputs:     []spec.InputUnion{textInput("hello")},
+		ToolChoices: []spec.ToolChoice{{
+			Type:        spec.ToolTypeFunction,
+			Name:        "fn",
+			Description: "desc",
+			Arguments:   map[string]any{"type": "object"},
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		tokenizer spec.Tokenizer
+		want      int
+	}{
+		{name: "custom tokenizer", tokenizer: byteTokenizer{}, want: 3 + 5 + 2 + 4 + len(`{"type":"object"}`)},
+		// Heuristic: sys, hello, fn, desc, and { " type " : " object " } in the schema.
+		{name: "heuristic by default", want: 1 + 1 + 1 + 1 + 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := CountRequestTokens(req, tt.tokenizer); got != tt.want {
+				t.Fatalf("got %d want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountInputTokens(t *testing.T) {
+	t.Parallel()
+
+	in := textInput("one two three")
+	if got := CountInputTokens(in, nil); got != 3 {
+		t.Fatalf("heuristic: got %d want 3", got)
+	}
+	if got := CountInputTokens(in, byteTokenizer{}); got != 13 {
+		t.Fatalf("byte tokenizer: got %d want 13", got)
+	}
+}
@@ -0,0 +1,151 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// anthropicSignature is the signature of every thinking block. The mock does
+// not check signatures sent back in later turns.
+const anthropicSignature = "mock-signature"
+
+func anthropicMessage(c call) map[string]any {
+	content := make([]map[string]any, 0, len(c.turn.Blocks))
+	for _, b := range c.turn.Blocks {
+		content = append(content, anthropicBlock(b, true))
+	}
+	msg := anthropicMessageStart(c)
+	msg["content"] = content
+	msg["stop_reason"] = anthropicStopReason(c.turn.Stop)
+	msg["usage"] = anthropicUsage(c.turn.Usage, true)
+	return msg
+}
+
+func streamAnthropic(sse *sseWriter, c call) {
+	start := anthropicMessageStart(c)
+	start["content"] = []any{}
+	start["usage"] = anthropicUsage(Usage{InputTokens: c.turn.Usage.InputTokens}, true)
+	sse.event("message_start", map[string]any{"type": "message_start", "message": start})
+
+	for idx, b := range c.turn.Blocks {
+		sse.event("content_block_start", map[string]any{
+			"type":          "content_block_start",
+			"index":         idx,
+			"content_block": anthropicBlock(b, false),
+		})
+		delta := func(d map[string]any) {
+			sse.delta("content_block_delta", map[string]any{"type": "content_block_delta", "index": idx, "delta": d})
+		}
+		switch b.Kind {
+		case BlockKindText:
+			for _, part := range sse.chunks(b.Text) {
+				delta(map[string]any{"type": "text_delta", "text": part})
+			}
+			for _, cit := range b.Citations {
+				sse.event("content_block_delta", map[string]any{
+					"type":  "content_block_delta",
+					"index": idx,
+					"delta": map[string]any{"type": "citations_delta", "citation": anthropicCitation(cit)},
+				})
+			}
+		case BlockKindReasoning:
+			for _, part := range sse.chunks(b.Text) {
+				delta(map[string]any{"type": "thinking_delta", "thinking": part})
+			}
+			sse.event("content_block_delta", map[string]any{
+				"type":  "content_block_delta",
+				"index": idx,
+				"delta": map[string]any{"type": "signature_delta", "signature": anthropicSignature},
+			})
+		case BlockKindToolCall:
+			for _, part := range sse.chunks(b.Arguments) {
+				delta(map[string]any{"type": "input_json_delta", "partial_json": part})
+			}
+		default:
+		}
+		sse.event("content_block_stop", map[string]any{"type": "content_block_stop", "index": idx})
+	}
+
+	sse.event("message_delta", map[string]any{
+		"type":  "message_delta",
+		"delta": map[string]any{"stop_reason": anthropicStopReason(c.turn.Stop), "stop_sequence": nil},
+		"usage": anthropicUsage(c.turn.Usage, false),
+	})
+	sse.event("message_stop", map[string]any{"type": "message_stop"})
+}
+
+func anthropicMessageStart(c call) map[string]any {
+	return map[string]any{
+		"id":            fmt.Sprintf("msg_mock_%d", c.n),
+		"type":          "message",
+		"role":          "assistant",
+		"model":         c.model,
+		"stop_reason":   nil,
+		"stop_sequence": nil,
+	}
+}
+
+// anthropicBlock returns the content block of b, or the empty block opening
+// its stream when full is false.
+func anthropicBlock(b Block, full bool) map[string]any {
+	switch b.Kind {
+	case BlockKindReasoning:
+		if !full {
+			return map[string]any{"type": "thinking", "thinking": "", "signature": ""}
+		}
+		return map[string]any{"type": "thinking", "thinking": b.Text, "signature": anthropicSignature}
+	case BlockKindToolCall:
+		input := json.RawMessage("{}")
+		if full && b.Arguments != "" {
+			input = json.RawMessage(b.Arguments)
+		}
+		return map[string]any{"type": "tool_use", "id": b.CallID, "name": b.Name, "input": input}
+	default:
+		if !full {
+			return map[string]any{"type": "text", "text": ""}
+		}
+		block := map[string]any{"type": "text", "text": b.Text}
+		if len(b.Citations) > 0 {
+			cits := make([]map[string]any, 0, len(b.Citations))
+			for _, cit := range b.Citations {
+				cits = append(cits, anthropicCitation(cit))
+			}
+			block["citations"] = cits
+		}
+		return block
+	}
+}
+
+func anthropicCitation(c Citation) map[string]any {
+	return map[string]any{
+		"type":            "web_search_result_location",
+		"url":             c.URL,
+		"title":           c.Title,
+		"cited_text":      c.CitedText,
+		"encrypted_index": "mock-index",
+	}
+}
+
+func anthropicStopReason(s StopReason) string {
+	switch s {
+	case StopToolUse:
+		return "tool_use"
+	case StopMaxTokens:
+		return "max_tokens"
+	default:
+		return "end_turn"
+	}
+}
+
+// anthropicUsage returns the usage of a message, or the cumulative usage of
+// its message_delta event when withInput is false.
+func anthropicUsage(u Usage, withInput bool) map[string]any {
+	out := map[string]any{
+		"output_tokens":         u.OutputTokens,
+		"output_tokens_details": map[string]any{"thinking_tokens": u.ReasoningTokens},
+	}
+	if withInput {
+		out["input_tokens"] = u.InputTokens
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	bedrockConverseOperation       = "/converse"
+	bedrockConverseStreamOperation = "/converse-stream"
+)
+
+// bedrockModel returns the model and streaming mode of a Converse path,
+// .../model/{modelId}/converse or .../converse-stream.
+func bedrockModel(escapedPath string) (string, bool) {
+	p, stream := strings.CutSuffix(escapedPath, bedrockConverseStreamOperation)
+	if !stream {
+		p = strings.TrimSuffix(p, bedrockConverseOperation)
+	}
+	model := p[strings.LastIndex(p, "/")+1:]
+	if m, err := url.PathUnescape(model); err == nil {
+		model = m
+	}
+	return model, stream
+}
+
+func bedrockResponse(c call) map[string]any {
+	content := make([]map[string]any, 0, len(c.turn.Blocks))
+	for _, b := range c.turn.Blocks {
+		content = append(content, bedrockBlock(b))
+	}
+	return map[string]any{
+		"output": map[string]any{
+			"message": map[string]any{"role": "assistant", "content": content},
+		},
+		"stopReason": bedrockStopReason(c.turn.Stop),
+		"usage":      bedrockUsage(c.turn.Usage),
+		"metrics":    map[string]any{"latencyMs": 1},
+	}
+}
+
+// streamBedrock sends ConverseStream events. Only tool use blocks get a
+// contentBlockStart event; reasoning blocks end with their signature.
+func streamBedrock(sse *sseWriter, c call) {
+	sse.event("messageStart", map[string]any{"role": "assistant"})
+
+	for idx, b := range c.turn.Blocks {
+		delta := func(d map[string]any) {
+			sse.delta("contentBlockDelta", map[string]any{"contentBlockIndex": idx, "delta": d})
+		}
+		switch b.Kind {
+		case BlockKindText:
+			for _, part := range sse.chunks(b.Text) {
+				delta(map[string]any{"text": part})
+			}
+			for _, cit := range b.Citations {
+				sse.event("contentBlockDelta", map[string]any{
+					"contentBlockIndex": idx,
+					"delta":             map[string]any{"citation": bedrockCitation(cit)},
+				})
+			}
+		case BlockKindReasoning:
+			for _, part := range sse.chunks(b.Text) {
+				delta(map[string]any{"reasoningContent": map[string]any{"text": part}})
+			}
+			sse.event("contentBlockDelta", map[string]any{
+				"contentBlockIndex": idx,
+				"delta": map[string]any{
+					"reasoningContent": map[string]any{"signature": anthropicSignature},
+				},
+			})
+		case BlockKindToolCall:
+			sse.event("contentBlockStart", map[string]any{
+				"contentBlockIndex": idx,
+				"start":             map[string]any{"toolUse": map[string]any{"toolUseId": b.CallID, "name": b.Name}},
+			})
+			for _, part := range sse.chunks(b.Arguments) {
+				delta(map[string]any{"toolUse": map[string]any{"input": part}})
+			}
+		default:
+		}
+		sse.event("contentBlockStop", map[string]any{"contentBlockIndex": idx})
+	}
+
+	sse.event("messageStop", map[string]any{"stopReason": bedrockStopReason(c.turn.Stop)})
+	sse.event("metadata", map[string]any{
+		"usage":   bedrockUsage(c.turn.Usage),
+		"metrics": map[string]any{"latencyMs": 1},
+	})
+}
+
+func bedrockBlock(b Block) map[string]any {
+	switch b.Kind {
+	case BlockKindReasoning:
+		return map[string]any{"reasoningContent": map[string]any{
+			"reasoningText": map[string]any{"text": b.Text, "signature": anthropicSignature},
+		}}
+	case BlockKindToolCall:
+		input := json.RawMessage("{}")
+		if b.Arguments != "" {
+			input = json.RawMessage(b.Arguments)
+		}
+		return map[string]any{"toolUse": map[string]any{"toolUseId": b.CallID, "name": b.Name, "input": input}}
+	default:
+		if len(b.Citations) == 0 {
+			return map[string]any{"text": b.Text}
+		}
+		citations := make([]map[string]any, 0, len(b.Citations))
+		for _, cit := range b.Citations {
+			citations = append(citations, bedrockCitation(cit))
+		}
+		return map[string]any{"citationsContent": map[string]any{
+			"content":   []map[string]any{{"text": b.Text}},
+			"citations": citations,
+		}}
+	}
+}
+
+func bedrockCitation(c Citation) map[string]any {
+	out := map[string]any{
+		"title":    c.Title,
+		"location": map[string]any{"web": map[string]any{"url": c.URL}},
+	}
+	if c.CitedText != "" {
+		out["sourceContent"] = []map[string]any{{"text": c.CitedText}}
+	}
+	return out
+}
+
+func bedrockUsage(u Usage) map[string]any {
+	return map[string]any{
+		"inputTokens":  u.InputTokens,
+		"outputTokens": u.OutputTokens,
+		"totalTokens":  u.InputTokens + u.OutputTokens,
+	}
+}
+
+func bedrockStopReason(s StopReason) string {
+	switch s {
+	case StopToolUse:
+		return "tool_use"
+	case StopMaxTokens:
+		return "max_tokens"
+	default:
+		return "end_turn"
+	}
+}
+
+func bedrockErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "ValidationException"
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "AccessDeniedException"
+	case http.StatusNotFound:
+		return "ResourceNotFoundException"
+	case http.StatusTooManyRequests:
+		return "ThrottlingException"
+	case http.StatusServiceUnavailable:
+		return "ServiceUnavailableException"
+	default:
+		return "InternalServerException"
+	}
+}
+
+// eventStreamFrame encodes one application/vnd.amazon.eventstream message
+// with string headers:
+//
+//	total length | headers length | prelude CRC | headers | payload | message CRC
+func eventStreamFrame(eventType string, payload []byte) []byte {
+	const stringHeader = 7
+	var headers bytes.Buffer
+	for _, h := range [][2]string{
+		{":event-type", eventType},
+		{":content-type", "application/json"},
+		{":message-type", "event"},
+	} {
+		headers.WriteByte(byte(len(h[0])))
+		headers.WriteString(h[0])
+		headers.WriteByte(stringHeader)
+		_ = binary.Write(&headers, binary.BigEndian, uint16(len(h[1])))
+		headers.WriteString(h[1])
+	}
+
+	total := 12 + headers.Len() + len(payload) + 4
+	out := make([]byte, 8, total)
+	binary.BigEndian.PutUint32(out[0:4], uint32(total))
+	binary.BigEndian.PutUint32(out[4:8], uint32(headers.Len()))
+	out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+	out = append(out, headers.Bytes()...)
+	out = append(out, payload...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+}
@@ -0,0 +1,76 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// googleResponse returns a response holding blocks, with the finish reason
+// and usage of the turn when final is set.
+func googleResponse(c call, blocks []Block, final bool) map[string]any {
+	parts := make([]map[string]any, 0, len(blocks))
+	for _, b := range blocks {
+		parts = append(parts, googlePart(b))
+	}
+	cand := map[string]any{
+		"index":   0,
+		"content": map[string]any{"role": "model", "parts": parts},
+	}
+	out := map[string]any{
+		"responseId":   fmt.Sprintf("mock-%d", c.n),
+		"modelVersion": c.model,
+		"candidates":   []map[string]any{cand},
+	}
+	if final {
+		cand["finishReason"] = googleFinishReason(c.turn.Stop)
+		out["usageMetadata"] = map[string]any{
+			"promptTokenCount":     c.turn.Usage.InputTokens,
+			"candidatesTokenCount": c.turn.Usage.OutputTokens - c.turn.Usage.ReasoningTokens,
+			"thoughtsTokenCount":   c.turn.Usage.ReasoningTokens,
+			"totalTokenCount":      c.turn.Usage.InputTokens + c.turn.Usage.OutputTokens,
+		}
+	}
+	return out
+}
+
+// streamGoogle sends one chunk per text or reasoning delta and per tool call,
+// which Gemini does not split, and a final chunk with the finish reason.
+func streamGoogle(sse *sseWriter, c call) {
+	for _, b := range c.turn.Blocks {
+		switch b.Kind {
+		case BlockKindText, BlockKindReasoning:
+			for _, part := range sse.chunks(b.Text) {
+				delta := b
+				delta.Text = part
+				sse.delta("", googleResponse(c, []Block{delta}, false))
+			}
+		case BlockKindToolCall:
+			sse.delta("", googleResponse(c, []Block{b}, false))
+		default:
+		}
+	}
+	sse.event("", googleResponse(c, nil, true))
+}
+
+// googlePart returns the part of b. Gemini text parts carry no citations.
+func googlePart(b Block) map[string]any {
+	switch b.Kind {
+	case BlockKindReasoning:
+		return map[string]any{"text": b.Text, "thought": true}
+	case BlockKindToolCall:
+		args := json.RawMessage("{}")
+		if b.Arguments != "" {
+			args = json.RawMessage(b.Arguments)
+		}
+		return map[string]any{"functionCall": map[string]any{"id": b.CallID, "name": b.Name, "args": args}}
+	default:
+		return map[string]any{"text": b.Text}
+	}
+}
+
+func googleFinishReason(s StopReason) string {
+	if s == StopMaxTokens {
+		return "MAX_TOKENS"
+	}
+	return "STOP"
+}
@@ -0,0 +1,80 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ollamaResponse returns a chat response holding blocks, with the done reason
+// and counts of the turn when final is set.
+func ollamaResponse(c call, blocks []Block, final bool) map[string]any {
+	var content, thinking strings.Builder
+	var toolCalls []map[string]any
+	for _, b := range blocks {
+		switch b.Kind {
+		case BlockKindReasoning:
+			thinking.WriteString(b.Text)
+		case BlockKindToolCall:
+			args := json.RawMessage("{}")
+			if b.Arguments != "" {
+				args = json.RawMessage(b.Arguments)
+			}
+			toolCalls = append(toolCalls, map[string]any{
+				"id": b.CallID,
+				"function": map[string]any{
+					"index":     len(toolCalls),
+					"name":      b.Name,
+					"arguments": args,
+				},
+			})
+		default:
+			content.WriteString(b.Text)
+		}
+	}
+
+	msg := map[string]any{"role": "assistant", "content": content.String()}
+	if thinking.Len() > 0 {
+		msg["thinking"] = thinking.String()
+	}
+	if len(toolCalls) > 0 {
+		msg["tool_calls"] = toolCalls
+	}
+	out := map[string]any{
+		"model":      c.model,
+		"created_at": "2026-01-01T00:00:00Z",
+		"message":    msg,
+		"done":       final,
+	}
+	if final {
+		out["done_reason"] = ollamaDoneReason(c.turn.Stop)
+		out["prompt_eval_count"] = c.turn.Usage.InputTokens
+		out["eval_count"] = c.turn.Usage.OutputTokens
+	}
+	return out
+}
+
+// streamOllama sends one line per text or reasoning delta and per tool call,
+// which Ollama does not split, and a final done line.
+func streamOllama(sse *sseWriter, c call) {
+	for _, b := range c.turn.Blocks {
+		switch b.Kind {
+		case BlockKindText, BlockKindReasoning:
+			for _, part := range sse.chunks(b.Text) {
+				sse.delta("", ollamaResponse(c, []Block{{Kind: b.Kind, Text: part}}, false))
+			}
+		case BlockKindToolCall:
+			sse.delta("", ollamaResponse(c, []Block{b}, false))
+		default:
+		}
+	}
+	sse.event("", ollamaResponse(c, nil, true))
+}
+
+// ollamaDoneReason returns the done reason of s. Ollama ends tool call turns
+// with "stop" too.
+func ollamaDoneReason(s StopReason) string {
+	if s == StopMaxTokens {
+		return "length"
+	}
+	return "stop"
+}
@@ -0,0 +1,137 @@
+package mockserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+func openAIChatCompletion(c call) map[string]any {
+	text, annotations := openAIChatText(c.turn.Blocks)
+	msg := map[string]any{"role": "assistant", "content": nil, "refusal": nil}
+	if text != "" {
+		msg["content"] = text
+		msg["annotations"] = annotations
+	}
+	var toolCalls []map[string]any
+	for _, b := range c.turn.Blocks {
+		if b.Kind == BlockKindToolCall {
+			toolCalls = append(toolCalls, map[string]any{
+				"id":       b.CallID,
+				"type":     "function",
+				"function": map[string]any{"name": b.Name, "arguments": b.Arguments},
+			})
+		}
+	}
+	if len(toolCalls) > 0 {
+		msg["tool_calls"] = toolCalls
+	}
+
+	out := openAIChatHead(c, "chat.completion")
+	out["choices"] = []map[string]any{{
+		"index":         0,
+		"message":       msg,
+		"finish_reason": openAIChatFinishReason(c.turn.Stop),
+		"logprobs":      nil,
+	}}
+	out["usage"] = openAIChatUsage(c.turn.Usage)
+	return out
+}
+
+// streamOpenAIChat streams the text and tool calls of a turn. Chat completion
+// chunks cannot carry annotations, so citations are not streamed.
+func streamOpenAIChat(sse *sseWriter, c call) {
+	chunk := func(delta map[string]any, finish any) map[string]any {
+		out := openAIChatHead(c, "chat.completion.chunk")
+		out["choices"] = []map[string]any{{"index": 0, "delta": delta, "finish_reason": finish, "logprobs": nil}}
+		return out
+	}
+
+	sse.event("", chunk(map[string]any{"role": "assistant", "content": ""}, nil))
+	toolIdx := 0
+	for _, b := range c.turn.Blocks {
+		switch b.Kind {
+		case BlockKindText:
+			for _, part := range sse.chunks(b.Text) {
+				sse.delta("", chunk(map[string]any{"content": part}, nil))
+			}
+		case BlockKindToolCall:
+			sse.event("", chunk(map[string]any{"tool_calls": []map[string]any{{
+				"index":    toolIdx,
+				"id":       b.CallID,
+				"type":     "function",
+				"function": map[string]any{"name": b.Name, "arguments": ""},
+			}}}, nil))
+			for _, part := range sse.chunks(b.Arguments) {
+				sse.delta("", chunk(map[string]any{"tool_calls": []map[string]any{{
+					"index":    toolIdx,
+					"function": map[string]any{"arguments": part},
+				}}}, nil))
+			}
+			toolIdx++
+		default:
+		}
+	}
+	sse.event("", chunk(map[string]any{}, openAIChatFinishReason(c.turn.Stop)))
+
+	usage := openAIChatHead(c, "chat.completion.chunk")
+	usage["choices"] = []any{}
+	usage["usage"] = openAIChatUsage(c.turn.Usage)
+	sse.event("", usage)
+	sse.raw("", "[DONE]")
+}
+
+func openAIChatHead(c call, object string) map[string]any {
+	return map[string]any{
+		"id":      fmt.Sprintf("chatcmpl-mock-%d", c.n),
+		"object":  object,
+		"created": 0,
+		"model":   c.model,
+	}
+}
+
+// openAIChatText joins the text blocks of a turn into one message, with the
+// citations as URL annotations over the joined text.
+func openAIChatText(blocks []Block) (string, []map[string]any) {
+	var sb strings.Builder
+	annotations := []map[string]any{}
+	for _, b := range blocks {
+		if b.Kind != BlockKindText {
+			continue
+		}
+		offset := len([]rune(sb.String()))
+		sb.WriteString(b.Text)
+		for _, cit := range b.Citations {
+			start, end := citationSpan(b.Text, cit)
+			annotations = append(annotations, map[string]any{
+				"type": "url_citation",
+				"url_citation": map[string]any{
+					"url":         cit.URL,
+					"title":       cit.Title,
+					"start_index": offset + start,
+					"end_index":   offset + end,
+				},
+			})
+		}
+	}
+	return sb.String(), annotations
+}
+
+func openAIChatFinishReason(s StopReason) string {
+	switch s {
+	case StopToolUse:
+		return "tool_calls"
+	case StopMaxTokens:
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+func openAIChatUsage(u Usage) map[string]any {
+	return map[string]any{
+		"prompt_tokens":             u.InputTokens,
+		"completion_tokens":         u.OutputTokens,
+		"total_tokens":              u.InputTokens + u.OutputTokens,
+		"completion_tokens_details": map[string]any{"reasoning_tokens": u.ReasoningTokens},
+	}
+}
@@ -0,0 +1,170 @@
+package mockserver
+
+import "fmt"
+
+// openAIResponse returns the response object of a turn with the given status.
+func openAIResponse(c call, status string) map[string]any {
+	output := make([]map[string]any, 0, len(c.turn.Blocks))
+	if status != "in_progress" {
+		for idx, b := range c.turn.Blocks {
+			output = append(output, openAIResponsesItem(c, idx, b, true))
+		}
+		if c.turn.Stop == StopMaxTokens {
+			status = "incomplete"
+		}
+	}
+	out := map[string]any{
+		"id":                  fmt.Sprintf("resp_mock_%d", c.n),
+		"object":              "response",
+		"created_at":          0,
+		"status":              status,
+		"model":               c.model,
+		"output":              output,
+		"error":               nil,
+		"incomplete_details":  nil,
+		"parallel_tool_calls": true,
+		"tool_choice":         "auto",
+		"tools":               []any{},
+	}
+	if status == "incomplete" {
+		out["incomplete_details"] = map[string]any{"reason": "max_output_tokens"}
+	}
+	if status != "in_progress" {
+		out["usage"] = map[string]any{
+			"input_tokens":          c.turn.Usage.InputTokens,
+			"input_tokens_details":  map[string]any{"cached_tokens": 0},
+			"output_tokens":         c.turn.Usage.OutputTokens,
+			"output_tokens_details": map[string]any{"reasoning_tokens": c.turn.Usage.ReasoningTokens},
+			"total_tokens":          c.turn.Usage.InputTokens + c.turn.Usage.OutputTokens,
+		}
+	}
+	return out
+}
+
+func streamOpenAIResponses(sse *sseWriter, c call) {
+	sse.event("response.created", map[string]any{
+		"type":     "response.created",
+		"response": openAIResponse(c, "in_progress"),
+	})
+
+	for idx, b := range c.turn.Blocks {
+		itemID := openAIResponsesItemID(c, idx, b)
+		sse.event("response.output_item.added", map[string]any{
+			"type":         "response.output_item.added",
+			"output_index": idx,
+			"item":         openAIResponsesItem(c, idx, b, false),
+		})
+		delta := func(typ, part string) {
+			sse.delta(typ, map[string]any{
+				"type":          typ,
+				"item_id":       itemID,
+				"output_index":  idx,
+				"content_index": 0,
+				"delta":         part,
+			})
+		}
+		switch b.Kind {
+		case BlockKindText:
+			for _, part := range sse.chunks(b.Text) {
+				delta("response.output_text.delta", part)
+			}
+			for i, a := range openAIResponsesAnnotations(b) {
+				sse.event("response.output_text.annotation.added", map[string]any{
+					"type":             "response.output_text.annotation.added",
+					"item_id":          itemID,
+					"output_index":     idx,
+					"content_index":    0,
+					"annotation_index": i,
+					"annotation":       a,
+				})
+			}
+		case BlockKindReasoning:
+			for _, part := range sse.chunks(b.Text) {
+				delta("response.reasoning_text.delta", part)
+			}
+		case BlockKindToolCall:
+			for _, part := range sse.chunks(b.Arguments) {
+				delta("response.function_call_arguments.delta", part)
+			}
+		default:
+		}
+		sse.event("response.output_item.done", map[string]any{
+			"type":         "response.output_item.done",
+			"output_index": idx,
+			"item":         openAIResponsesItem(c, idx, b, true),
+		})
+	}
+
+	done := openAIResponse(c, "completed")
+	typ := "response.completed"
+	if done["status"] == "incomplete" {
+		typ = "response.incomplete"
+	}
+	sse.event(typ, map[string]any{"type": typ, "response": done})
+}
+
+// openAIResponsesItem returns the output item of b, or the item announcing it
+// in a stream when full is false.
+func openAIResponsesItem(c call, idx int, b Block, full bool) map[string]any {
+	status := "completed"
+	if !full {
+		status = "in_progress"
+	}
+	item := map[string]any{"id": openAIResponsesItemID(c, idx, b), "status": status}
+	switch b.Kind {
+	case BlockKindReasoning:
+		item["type"] = "reasoning"
+		item["summary"] = []any{}
+		if full {
+			item["content"] = []map[string]any{{"type": "reasoning_text", "text": b.Text}}
+		}
+	case BlockKindToolCall:
+		item["type"] = "function_call"
+		item["call_id"] = b.CallID
+		item["name"] = b.Name
+		item["arguments"] = ""
+		if full {
+			item["arguments"] = b.Arguments
+		}
+	default:
+		item["type"] = "message"
+		item["role"] = "assistant"
+		item["content"] = []any{}
+		if full {
+			item["content"] = []map[string]any{{
+				"type":        "output_text",
+				"text":        b.Text,
+				"annotations": openAIResponsesAnnotations(b),
+				"logprobs":    []any{},
+			}}
+		}
+	}
+	return item
+}
+
+func openAIResponsesItemID(c call, idx int, b Block) string {
+	prefix := "msg"
+	switch b.Kind {
+	case BlockKindReasoning:
+		prefix = "rs"
+	case BlockKindToolCall:
+		prefix = "fc"
+	default:
+	}
+	return fmt.Sprintf("%s_mock_%d_%d", prefix, c.n, idx)
+}
+
+func openAIResponsesAnnotations(b Block) []map[string]any {
+	out := make([]map[string]any, 0, len(b.Citations))
+	for _, cit := range b.Citations {
+		start, end := citationSpan(b.Text, cit)
+		out = append(out, map[string]any{
+			"type":        "url_citation",
+			"url":         cit.URL,
+			"title":       cit.Title,
+			"start_index": start,
+			"end_index":   end,
+		})
+	}
+	return out
+}
@@ -0,0 +1,556 @@
+// Package mockserver is a local HTTP stand-in for the provider APIs, for
+// testing the real adapters end to end without network access.
+//
+// A Server answers each request with the next scripted Turn, encoded in the
+// wire format the request was sent in:
+//
+//   - Anthropic Messages: POST .../messages
+//   - OpenAI Chat Completions: POST .../chat/completions
+//   - OpenAI Responses: POST .../responses
+//   - Google Generate Content: POST .../models/{model}:generateContent or
+//     :streamGenerateContent
+//   - Ollama Chat: POST .../api/chat
+//   - Bedrock Converse: POST .../model/{modelId}/converse or /converse-stream
+//
+// Streaming requests get server-sent events, NDJSON lines for Ollama, or AWS
+// event stream frames for Bedrock. The server does not check credentials or
+// signatures. Point a provider at the server by using Server.URL as its origin:
+//
+//	srv := mockserver.New(mockserver.Config{Turns: []mockserver.Turn{
+//		{Blocks: []mockserver.Block{mockserver.Text("Hello.")}},
+//	}})
+//	defer srv.Close()
+//	ps.AddProvider(ctx, "anthropic", &inference.AddProviderConfig{
+//		SDKType: spec.ProviderSDKTypeAnthropic,
+//		Origin:  srv.URL,
+//	})
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultChunkSize is the number of runes per streamed text, reasoning or tool
+// argument delta when Config.ChunkSize is not set.
+const DefaultChunkSize = 8
+
+// Format is a provider wire format.
+type Format string
+
+const (
+	FormatAnthropicMessages     Format = "anthropicMessages"
+	FormatOpenAIChatCompletions Format = "openAIChatCompletions"
+	FormatOpenAIResponses       Format = "openAIResponses"
+	FormatGoogleGenerateContent Format = "googleGenerateContent"
+	FormatOllamaChat            Format = "ollamaChat"
+	FormatBedrockConverse       Format = "bedrockConverse"
+	formatUnknown               Format = ""
+)
+
+const (
+	googleGenerateContentMethod = ":generateContent"
+	googleStreamGenerateMethod  = ":streamGenerateContent"
+)
+
+// BlockKind is the kind of a scripted output block.
+type BlockKind string
+
+const (
+	BlockKindText      BlockKind = "text"
+	BlockKindReasoning BlockKind = "reasoning"
+	BlockKindToolCall  BlockKind = "toolCall"
+)
+
+// Block is one output block of a turn.
+//
+// OpenAI Chat Completions has no reasoning output, so reasoning blocks are
+// left out of its responses, and its text blocks are joined into one message.
+// Gemini and Ollama text has no citations, so they are left out of their
+// responses. Ollama and Bedrock report no reasoning token count.
+type Block struct {
+	Kind BlockKind
+
+	// Text is the text of text and reasoning blocks.
+	Text      string
+	Citations []Citation
+
+	// CallID, Name and Arguments describe a function tool call. Arguments
+	// must be a JSON object.
+	CallID    string
+	Name      string
+	Arguments string
+}
+
+// Citation is a URL citation of a text block.
+type Citation struct {
+	URL       string
+	Title     string
+	CitedText string
+}
+
+// StopReason is the scripted end of a turn.
+type StopReason string
+
+const (
+	// StopEndTurn is the default, or StopToolUse for turns with a tool call.
+	StopEndTurn   StopReason = "endTurn"
+	StopToolUse   StopReason = "toolUse"
+	StopMaxTokens StopReason = "maxTokens"
+)
+
+// Usage is the scripted token usage of a turn. OutputTokens includes
+// ReasoningTokens.
+type Usage struct {
+	InputTokens     int64
+	OutputTokens    int64
+	ReasoningTokens int64
+}
+
+// Error is a scripted HTTP error response.
+type Error struct {
+	Status  int
+	Message string
+
+	// RetryAfter is sent as "retry-after-ms" when set.
+	RetryAfter time.Duration
+}
+
+// Turn is the scripted answer to one request.
+type Turn struct {
+	Blocks []Block
+	Stop   StopReason
+	Usage  Usage
+
+	// Error answers with an HTTP error instead of Blocks.
+	Error *Error
+
+	// AbortAfter, if positive, drops the connection of a streamed response
+	// after that many text, reasoning and tool argument deltas.
+	AbortAfter int
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Format Format
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+	Stream bool
+}
+
+// Config configures a Server.
+type Config struct {
+	Turns []Turn
+
+	// ChunkSize is the number of runes per streamed delta, DefaultChunkSize
+	// if not positive.
+	ChunkSize int
+}
+
+// Server is a running mock provider server. It is safe for concurrent use.
+type Server struct {
+	// URL is the origin of the server, for AddProviderConfig.Origin.
+	URL string
+
+	srv       *httptest.Server
+	chunkSize int
+
+	mu       sync.Mutex
+	turns    []Turn
+	next     int
+	requests []Request
+}
+
+// New starts a Server answering with cfg.Turns in order. Close must be called
+// once the server is no longer needed.
+func New(cfg Config) *Server {
+	s := &Server{
+		chunkSize: cfg.ChunkSize,
+		turns:     append([]Turn(nil), cfg.Turns...),
+	}
+	if s.chunkSize <= 0 {
+		s.chunkSize = DefaultChunkSize
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddTurns appends turns to the script.
+func (s *Server) AddTurns(turns ...Turn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.turns = append(s.turns, turns...)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Remaining returns the number of scripted turns not used yet.
+func (s *Server) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.turns) - s.next
+}
+
+// Text returns a text block.
+func Text(text string, citations ...Citation) Block {
+	return Block{Kind: BlockKindText, Text: text, Citations: citations}
+}
+
+// Reasoning returns a reasoning block.
+func Reasoning(text string) Block {
+	return Block{Kind: BlockKindReasoning, Text: text}
+}
+
+// ToolCall returns a function tool call block.
+func ToolCall(callID, name, arguments string) Block {
+	return Block{Kind: BlockKindToolCall, CallID: callID, Name: name, Arguments: arguments}
+}
+
+// call is one request being answered.
+type call struct {
+	format Format
+	model  string
+	stream bool
+	n      int
+	turn   Turn
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c := call{format: formatOf(r)}
+	var head struct {
+		Model  string `json:"model"`
+		Stream *bool  `json:"stream"`
+	}
+	_ = json.Unmarshal(body, &head)
+	c.model = head.Model
+	// Ollama streams unless told otherwise.
+	c.stream = c.format == FormatOllamaChat
+	if head.Stream != nil {
+		c.stream = *head.Stream
+	}
+	switch c.format {
+	case FormatGoogleGenerateContent:
+		c.model, c.stream = googleModel(r.URL.Path)
+	case FormatBedrockConverse:
+		c.model, c.stream = bedrockModel(r.URL.EscapedPath())
+	default:
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Format: c.format,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+		Stream: c.stream,
+	})
+	c.n = len(s.requests)
+	ok := s.next < len(s.turns)
+	if ok {
+		c.turn = s.turns[s.next]
+		s.next++
+	}
+	s.mu.Unlock()
+
+	switch {
+	case c.format == formatUnknown:
+		http.Error(w, fmt.Sprintf("mockserver: unknown endpoint %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+	case !ok:
+		writeError(w, c.format, &Error{
+			Status:  http.StatusInternalServerError,
+			Message: fmt.Sprintf("mockserver: no scripted turn left for request %d", c.n),
+		})
+	case c.turn.Error != nil:
+		writeError(w, c.format, c.turn.Error)
+	default:
+		s.writeTurn(w, c)
+	}
+}
+
+func (s *Server) writeTurn(w http.ResponseWriter, c call) {
+	if c.turn.Stop == "" {
+		c.turn.Stop = StopEndTurn
+		for _, b := range c.turn.Blocks {
+			if b.Kind == BlockKindToolCall {
+				c.turn.Stop = StopToolUse
+			}
+		}
+	}
+	if c.model == "" {
+		c.model = "mock-model"
+	}
+
+	if !c.stream {
+		var v any
+		switch c.format {
+		case FormatAnthropicMessages:
+			v = anthropicMessage(c)
+		case FormatOpenAIChatCompletions:
+			v = openAIChatCompletion(c)
+		case FormatOpenAIResponses:
+			v = openAIResponse(c, "completed")
+		case FormatGoogleGenerateContent:
+			v = googleResponse(c, c.turn.Blocks, true)
+		case FormatOllamaChat:
+			v = ollamaResponse(c, c.turn.Blocks, true)
+		case FormatBedrockConverse:
+			v = bedrockResponse(c)
+		default:
+		}
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+
+	sse := &sseWriter{w: w, abortAfter: c.turn.AbortAfter, chunkSize: s.chunkSize}
+	switch c.format {
+	case FormatOllamaChat:
+		sse.ndjson = true
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	case FormatBedrockConverse:
+		sse.eventStream = true
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+	default:
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.WriteHeader(http.StatusOK)
+	switch c.format {
+	case FormatAnthropicMessages:
+		streamAnthropic(sse, c)
+	case FormatOpenAIChatCompletions:
+		streamOpenAIChat(sse, c)
+	case FormatOpenAIResponses:
+		streamOpenAIResponses(sse, c)
+	case FormatGoogleGenerateContent:
+		streamGoogle(sse, c)
+	case FormatOllamaChat:
+		streamOllama(sse, c)
+	case FormatBedrockConverse:
+		streamBedrock(sse, c)
+	default:
+	}
+}
+
+func formatOf(r *http.Request) Format {
+	if r.Method != http.MethodPost {
+		return formatUnknown
+	}
+	p := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case strings.HasSuffix(p, "/api/chat"):
+		return FormatOllamaChat
+	case strings.HasSuffix(p, bedrockConverseOperation), strings.HasSuffix(p, bedrockConverseStreamOperation):
+		return FormatBedrockConverse
+	case strings.HasSuffix(p, "/messages"):
+		return FormatAnthropicMessages
+	case strings.HasSuffix(p, "/chat/completions"):
+		return FormatOpenAIChatCompletions
+	case strings.HasSuffix(p, "/responses"):
+		return FormatOpenAIResponses
+	case strings.HasSuffix(p, googleGenerateContentMethod), strings.HasSuffix(p, googleStreamGenerateMethod):
+		return FormatGoogleGenerateContent
+	default:
+		return formatUnknown
+	}
+}
+
+// googleModel returns the model and streaming mode of a Generate Content path.
+func googleModel(path string) (string, bool) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	if m, ok := strings.CutSuffix(name, googleStreamGenerateMethod); ok {
+		return m, true
+	}
+	return strings.TrimSuffix(name, googleGenerateContentMethod), false
+}
+
+func writeError(w http.ResponseWriter, format Format, e *Error) {
+	if e.RetryAfter > 0 {
+		w.Header().Set("retry-after-ms", strconv.FormatInt(e.RetryAfter.Milliseconds(), 10))
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+
+	var v any
+	switch format {
+	case FormatAnthropicMessages:
+		v = map[string]any{
+			"type":  "error",
+			"error": map[string]any{"type": anthropicErrorType(e.Status), "message": msg},
+		}
+	case FormatOpenAIChatCompletions, FormatOpenAIResponses:
+		v = map[string]any{"error": map[string]any{
+			"message": msg,
+			"type":    openAIErrorType(e.Status),
+			"code":    openAIErrorType(e.Status),
+			"param":   nil,
+		}}
+	case FormatGoogleGenerateContent:
+		v = map[string]any{"error": map[string]any{
+			"code":    e.Status,
+			"message": msg,
+			"status":  googleErrorStatus(e.Status),
+		}}
+	case FormatOllamaChat:
+		v = map[string]any{"error": msg}
+	case FormatBedrockConverse:
+		w.Header().Set("x-amzn-ErrorType", bedrockErrorType(e.Status))
+		v = map[string]any{"message": msg}
+	default:
+		v = map[string]any{"error": msg}
+	}
+	writeJSON(w, e.Status, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// sseWriter writes server-sent events, bare JSON lines when ndjson is set or
+// event stream frames when eventStream is set, and drops the connection once
+// abortAfter deltas were written.
+type sseWriter struct {
+	w           http.ResponseWriter
+	ndjson      bool
+	eventStream bool
+	abortAfter  int
+	chunkSize   int
+	deltas      int
+}
+
+// event writes one event; name is omitted when empty.
+func (s *sseWriter) event(name string, v any) {
+	data, _ := json.Marshal(v)
+	s.raw(name, string(data))
+}
+
+func (s *sseWriter) raw(name, data string) {
+	switch {
+	case s.ndjson:
+		_, _ = fmt.Fprintf(s.w, "%s\n", data)
+	case s.eventStream:
+		_, _ = s.w.Write(eventStreamFrame(name, []byte(data)))
+	case name != "":
+		_, _ = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data)
+	default:
+		_, _ = fmt.Fprintf(s.w, "data: %s\n\n", data)
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// delta writes an event carrying a text, reasoning or argument delta.
+func (s *sseWriter) delta(name string, v any) {
+	s.event(name, v)
+	s.deltas++
+	if s.abortAfter > 0 && s.deltas >= s.abortAfter {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// chunks splits text into deltas of chunkSize runes.
+func (s *sseWriter) chunks(text string) []string {
+	var out []string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		n := min(s.chunkSize, len(runes))
+		out = append(out, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return out
+}
+
+// citationSpan returns the rune offsets of the cited text in text, or the
+// whole text.
+func citationSpan(text string, c Citation) (start, end int) {
+	if i := strings.Index(text, c.CitedText); c.CitedText != "" && i >= 0 {
+		start = len([]rune(text[:i]))
+		return start, start + len([]rune(c.CitedText))
+	}
+	return 0, len([]rune(text))
+}
+
+func anthropicErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	default:
+		return "api_error"
+	}
+}
+
+func openAIErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "invalid_api_key"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusTooManyRequests:
+		return "rate_limit_exceeded"
+	default:
+		return "server_error"
+	}
+}
+
+func googleErrorStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "INVALID_ARGUMENT"
+	case http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	default:
+		return "INTERNAL"
+	}
+}
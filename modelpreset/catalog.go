@@ -29,6 +29,9 @@ type ModelPreset struct {
 	// CapabilitiesOverride is a runtime capability patch applied over provider/base SDK capabilities.
 	// It is not the derived/effective capability profile.
 	CapabilitiesOverride *capabilityoverride.ModelCapabilitiesOverride `json:"capabilitiesOverride,omitempty"`
+
+	// Pricing holds the list prices of the model, if known. Use DeriveModelPricing to layer caller overrides on it.
+	Pricing *PricingOverride `json:"pricing,omitempty"`
 }
 
 type ProviderPreset struct {
@@ -54,6 +57,7 @@ type Catalog struct {
 
 var catalogProviders = map[spec.ProviderName]ProviderPreset{
 	ProviderAnthropic:       providerAnthropic,
+	ProviderBedrock:         providerBedrock,
 	ProviderLocalAI:         providerLocalAI,
 	ProviderLMStudio:        providerLMStudio,
 	ProviderGoogleGemini:    providerGoogleGemini,
@@ -143,12 +147,22 @@ func CloneModelPreset(in ModelPreset) ModelPreset {
 	out := in
 	out.ModelParam = cloneModelParam(in.ModelParam)
 	out.CapabilitiesOverride = capabilityoverride.CloneModelCapabilitiesOverride(in.CapabilitiesOverride)
+	out.Pricing = ClonePricingOverride(in.Pricing)
 	return out
 }
 
 func cloneModelParam(in spec.ModelParam) spec.ModelParam {
 	out := in
 	out.Temperature = sdkutil.CloneFloat64Ptr(in.Temperature)
+	out.TopP = sdkutil.CloneFloat64Ptr(in.TopP)
+	out.TopK = sdkutil.CloneIntPtr(in.TopK)
+	out.Seed = sdkutil.CloneInt64Ptr(in.Seed)
+	out.PresencePenalty = sdkutil.CloneFloat64Ptr(in.PresencePenalty)
+	out.FrequencyPenalty = sdkutil.CloneFloat64Ptr(in.FrequencyPenalty)
+	out.LogitBias = maps.Clone(in.LogitBias)
+	if in.Logprobs != nil {
+		out.Logprobs = new(*in.Logprobs)
+	}
 	out.Reasoning = cloneReasoningParam(in.Reasoning)
 	out.CacheControl = cloneCacheControl(in.CacheControl)
 	out.OutputParam = cloneOutputParam(in.OutputParam)
@@ -195,6 +209,10 @@ func cloneOutputParam(in *spec.OutputParam) *spec.OutputParam {
 		}
 		out.Format = &f
 	}
+	if in.Audio != nil {
+		a := *in.Audio
+		out.Audio = &a
+	}
 	return &out
 }
 
@@ -0,0 +1,174 @@
+package modelpreset
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+var ErrPricingNotFound = errors.New("model pricing not found")
+
+// PricingOverride is a patch-like version of spec.ModelPricing, with the same semantics as
+// capabilityoverride.ModelCapabilitiesOverride: a nil field is not provided, a non-nil field overrides. Cache write
+// prices are merged by TTL.
+//
+// Prices are in Currency per million tokens, except WebSearchPerCall.
+type PricingOverride struct {
+	Currency *string `json:"currency,omitempty"`
+
+	InputPerMTok       *float64 `json:"inputPerMTok,omitempty"`
+	CachedInputPerMTok *float64 `json:"cachedInputPerMTok,omitempty"`
+
+	CacheWritePerMTok map[spec.CacheControlTTL]float64 `json:"cacheWritePerMTok,omitempty"`
+
+	OutputPerMTok    *float64 `json:"outputPerMTok,omitempty"`
+	ReasoningPerMTok *float64 `json:"reasoningPerMTok,omitempty"`
+
+	WebSearchPerCall *float64 `json:"webSearchPerCall,omitempty"`
+}
+
+// DeriveModelPricing layers overrides in order and returns the effective pricing.
+//
+// Input and output prices are required. Unset prices default to: USD currency, cached input and cache writes at the
+// input price, reasoning at the output price, web search free.
+func DeriveModelPricing(overrides ...*PricingOverride) (*spec.ModelPricing, error) {
+	var merged PricingOverride
+	provided := false
+	for _, ov := range overrides {
+		if ov == nil {
+			continue
+		}
+		if err := ValidatePricingOverride(ov); err != nil {
+			return nil, err
+		}
+		provided = true
+		applyPricingOverride(&merged, ov)
+	}
+	if !provided {
+		return nil, ErrPricingNotFound
+	}
+	if merged.InputPerMTok == nil || merged.OutputPerMTok == nil {
+		return nil, errors.New("model pricing: inputPerMTok and outputPerMTok are required")
+	}
+
+	out := &spec.ModelPricing{
+		Currency:           spec.PricingCurrencyUSD,
+		InputPerMTok:       *merged.InputPerMTok,
+		CachedInputPerMTok: *merged.InputPerMTok,
+		CacheWritePerMTok:  merged.CacheWritePerMTok,
+		OutputPerMTok:      *merged.OutputPerMTok,
+		ReasoningPerMTok:   *merged.OutputPerMTok,
+	}
+	if merged.Currency != nil {
+		out.Currency = *merged.Currency
+	}
+	if merged.CachedInputPerMTok != nil {
+		out.CachedInputPerMTok = *merged.CachedInputPerMTok
+	}
+	if merged.ReasoningPerMTok != nil {
+		out.ReasoningPerMTok = *merged.ReasoningPerMTok
+	}
+	if merged.WebSearchPerCall != nil {
+		out.WebSearchPerCall = *merged.WebSearchPerCall
+	}
+	return out, nil
+}
+
+// PriceUsage prices usage with the preset's pricing, patched by overrides.
+func PriceUsage(mp ModelPreset, usage spec.Usage, overrides ...*PricingOverride) (*spec.Cost, error) {
+	pricing, err := DeriveModelPricing(append([]*PricingOverride{mp.Pricing}, overrides...)...)
+	if err != nil {
+		return nil, fmt.Errorf("model preset %q: %w", mp.ID, err)
+	}
+	cost := pricing.Cost(usage)
+	return &cost, nil
+}
+
+func ValidatePricingOverride(o *PricingOverride) error {
+	if o == nil {
+		return nil
+	}
+	if o.Currency != nil && *o.Currency == "" {
+		return errors.New("currency: must not be empty")
+	}
+
+	prices := []struct {
+		name string
+		v    *float64
+	}{
+		{"inputPerMTok", o.InputPerMTok},
+		{"cachedInputPerMTok", o.CachedInputPerMTok},
+		{"outputPerMTok", o.OutputPerMTok},
+		{"reasoningPerMTok", o.ReasoningPerMTok},
+		{"webSearchPerCall", o.WebSearchPerCall},
+	}
+	for _, p := range prices {
+		if p.v != nil {
+			if err := validatePrice(*p.v); err != nil {
+				return fmt.Errorf("%s: %w", p.name, err)
+			}
+		}
+	}
+	for ttl, v := range o.CacheWritePerMTok {
+		if ttl == "" {
+			return errors.New("cacheWritePerMTok: empty ttl")
+		}
+		if err := validatePrice(v); err != nil {
+			return fmt.Errorf("cacheWritePerMTok[%s]: %w", ttl, err)
+		}
+	}
+	return nil
+}
+
+func ClonePricingOverride(in *PricingOverride) *PricingOverride {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	out.Currency = sdkutil.CloneStringPtr(in.Currency)
+	out.InputPerMTok = sdkutil.CloneFloat64Ptr(in.InputPerMTok)
+	out.CachedInputPerMTok = sdkutil.CloneFloat64Ptr(in.CachedInputPerMTok)
+	out.CacheWritePerMTok = maps.Clone(in.CacheWritePerMTok)
+	out.OutputPerMTok = sdkutil.CloneFloat64Ptr(in.OutputPerMTok)
+	out.ReasoningPerMTok = sdkutil.CloneFloat64Ptr(in.ReasoningPerMTok)
+	out.WebSearchPerCall = sdkutil.CloneFloat64Ptr(in.WebSearchPerCall)
+	return &out
+}
+
+func applyPricingOverride(dst, ov *PricingOverride) {
+	if ov.Currency != nil {
+		dst.Currency = ov.Currency
+	}
+	if ov.InputPerMTok != nil {
+		dst.InputPerMTok = ov.InputPerMTok
+	}
+	if ov.CachedInputPerMTok != nil {
+		dst.CachedInputPerMTok = ov.CachedInputPerMTok
+	}
+	if ov.OutputPerMTok != nil {
+		dst.OutputPerMTok = ov.OutputPerMTok
+	}
+	if ov.ReasoningPerMTok != nil {
+		dst.ReasoningPerMTok = ov.ReasoningPerMTok
+	}
+	if ov.WebSearchPerCall != nil {
+		dst.WebSearchPerCall = ov.WebSearchPerCall
+	}
+	if ov.CacheWritePerMTok != nil {
+		if dst.CacheWritePerMTok == nil {
+			dst.CacheWritePerMTok = make(map[spec.CacheControlTTL]float64, len(ov.CacheWritePerMTok))
+		}
+		maps.Copy(dst.CacheWritePerMTok, ov.CacheWritePerMTok)
+	}
+}
+
+func validatePrice(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		return fmt.Errorf("invalid price %v", v)
+	}
+	return nil
+}
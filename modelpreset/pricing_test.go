@@ -0,0 +1,178 @@
+package modelpreset
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestDeriveModelPricing(t *testing.T) {
+	t.Parallel()
+
+	base := &PricingOverride{
+		InputPerMTok:      new(2.0),
+		OutputPerMTok:     new(8.0),
+		CacheWritePerMTok: map[spec.CacheControlTTL]float64{spec.CacheControlTTL5m: 2.5},
+	}
+
+	tests := []struct {
+		name      string
+		overrides []*PricingOverride
+		want      *spec.ModelPricing
+		wantErr   error
+	}{
+		{name: "none", overrides: []*PricingOverride{nil}, wantErr: ErrPricingNotFound},
+		{
+			name:      "defaults",
+			overrides: []*PricingOverride{base},
+			want: &spec.ModelPricing{
+				Currency:           spec.PricingCurrencyUSD,
+				InputPerMTok:       2,
+				CachedInputPerMTok: 2,
+				CacheWritePerMTok:  map[spec.CacheControlTTL]float64{spec.CacheControlTTL5m: 2.5},
+				OutputPerMTok:      8,
+				ReasoningPerMTok:   8,
+			},
+		},
+		{
+			name: "layered",
+			overrides: []*PricingOverride{base, {
+				Currency:           new("EUR"),
+				CachedInputPerMTok: new(0.2),
+				CacheWritePerMTok:  map[spec.CacheControlTTL]float64{spec.CacheControlTTL1h: 4},
+				OutputPerMTok:      new(6.0),
+				WebSearchPerCall:   new(0.01),
+			}},
+			want: &spec.ModelPricing{
+				Currency:           "EUR",
+				InputPerMTok:       2,
+				CachedInputPerMTok: 0.2,
+				CacheWritePerMTok: map[spec.CacheControlTTL]float64{
+					spec.CacheControlTTL5m: 2.5,
+					spec.CacheControlTTL1h: 4,
+				},
+				OutputPerMTok:    6,
+				ReasoningPerMTok: 6,
+				WebSearchPerCall: 0.01,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := DeriveModelPricing(tt.overrides...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error: got %v want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeriveModelPricing: %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Fatalf("pricing:\n got %s\nwant %s", gotJSON, wantJSON)
+			}
+		})
+	}
+
+	if len(base.CacheWritePerMTok) != 1 {
+		t.Fatal("base override was modified")
+	}
+}
+
+func TestDeriveModelPricingInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"missing output", `{"inputPerMTok": 1}`},
+		{"negative price", `{"inputPerMTok": 1, "outputPerMTok": -1}`},
+		{"empty currency", `{"currency": "", "inputPerMTok": 1, "outputPerMTok": 1}`},
+		{"empty ttl", `{"inputPerMTok": 1, "outputPerMTok": 1, "cacheWritePerMTok": {"": 1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ov PricingOverride
+			if err := json.Unmarshal([]byte(tt.json), &ov); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if _, err := DeriveModelPricing(&ov); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestPriceUsage(t *testing.T) {
+	t.Parallel()
+
+	mp, err := Model(ProviderAnthropic, PresetClaudeSonnet46)
+	if err != nil {
+		t.Fatalf("Model: %v", err)
+	}
+	usage := spec.Usage{InputTokensUncached: 1_000_000, InputTokensCached: 1_000_000, OutputTokens: 100_000}
+
+	cost, err := PriceUsage(mp, usage)
+	if err != nil {
+		t.Fatalf("PriceUsage: %v", err)
+	}
+	if cost.Input != 3 || cost.CachedInput != 0.3 || cost.Output != 1.5 || cost.Total != 4.8 {
+		t.Fatalf("cost: got %+v", cost)
+	}
+
+	var ov PricingOverride
+	if err := json.Unmarshal([]byte(`{"inputPerMTok": 1}`), &ov); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	cost, err = PriceUsage(mp, usage, &ov)
+	if err != nil {
+		t.Fatalf("PriceUsage: %v", err)
+	}
+	if cost.Input != 1 {
+		t.Fatalf("override not applied: %+v", cost)
+	}
+
+	if _, err := PriceUsage(ModelPreset{ID: "custom"}, usage); !errors.Is(err, ErrPricingNotFound) {
+		t.Fatalf("expected ErrPricingNotFound, got %v", err)
+	}
+}
+
+func TestCatalogPricingIsValid(t *testing.T) {
+	t.Parallel()
+
+	priced := map[spec.ProviderName]int{}
+	for name, pp := range DefaultCatalog().Providers {
+		for id, mp := range pp.ModelPresets {
+			if mp.Pricing == nil {
+				continue
+			}
+			if _, err := DeriveModelPricing(mp.Pricing); err != nil {
+				t.Fatalf("%s/%s: %v", name, id, err)
+			}
+			priced[name]++
+		}
+	}
+	for _, name := range []spec.ProviderName{
+		ProviderAnthropic,
+		ProviderBedrock,
+		ProviderGoogleGemini,
+		ProviderOpenAIChat,
+		ProviderOpenAIResponses,
+	} {
+		if priced[name] == 0 {
+			t.Fatalf("expected priced presets for provider %s", name)
+		}
+	}
+}
@@ -24,6 +24,10 @@ const (
 	ModelNameClaudeSonnet45 spec.ModelName = "claude-sonnet-4-5-20250929"
 	ModelNameClaudeSonnet4  spec.ModelName = "claude-sonnet-4-20250514"
 	ModelNameClaudeHaiku45  spec.ModelName = "claude-haiku-4-5-20251001"
+
+	ModelNameClaudeOpus41Bedrock   spec.ModelName = "us.anthropic.claude-opus-4-1-20250805-v1:0"
+	ModelNameClaudeSonnet45Bedrock spec.ModelName = "us.anthropic.claude-sonnet-4-5-20250929-v1:0"
+	ModelNameClaudeHaiku45Bedrock  spec.ModelName = "us.anthropic.claude-haiku-4-5-20251001-v1:0"
 )
 
 const (
@@ -208,6 +212,7 @@ var modelAnthropicOpus46 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(5, 25, 0.5, 6.25, 10),
 }
 
 var modelAnthropicOpus45 = ModelPreset{
@@ -232,6 +237,7 @@ var modelAnthropicOpus45 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(5, 25, 0.5, 6.25, 10),
 }
 
 var modelAnthropicOpus41 = ModelPreset{
@@ -256,6 +262,7 @@ var modelAnthropicOpus41 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(15, 75, 1.5, 18.75, 30),
 }
 
 var modelAnthropicSonnet5 = ModelPreset{
@@ -316,6 +323,7 @@ var modelAnthropicSonnet46 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(3, 15, 0.3, 3.75, 6),
 }
 
 var modelAnthropicSonnet45 = ModelPreset{
@@ -340,6 +348,7 @@ var modelAnthropicSonnet45 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(3, 15, 0.3, 3.75, 6),
 }
 
 var modelAnthropicSonnet4 = ModelPreset{
@@ -357,6 +366,7 @@ var modelAnthropicSonnet4 = ModelPreset{
 		Timeout:         1800,
 		CacheControl:    cacheEphemeral5m(),
 	},
+	Pricing: anthropicPricing(3, 15, 0.3, 3.75, 6),
 }
 
 var modelAnthropicHaiku45 = ModelPreset{
@@ -381,6 +391,7 @@ var modelAnthropicHaiku45 = ModelPreset{
 			},
 		},
 	},
+	Pricing: anthropicPricing(1, 5, 0.1, 1.25, 2),
 }
 
 var providerAnthropic = ProviderPreset{
@@ -468,3 +479,17 @@ var providerAnthropic = ProviderPreset{
 		PresetClaudeHaiku45:  modelAnthropicHaiku45,
 	},
 }
+
+// anthropicPricing returns Anthropic list prices in USD. Web search costs $10 per 1000 searches for all models.
+func anthropicPricing(input, output, cacheRead, cacheWrite5m, cacheWrite1h float64) *PricingOverride {
+	return &PricingOverride{
+		InputPerMTok:       new(input),
+		CachedInputPerMTok: new(cacheRead),
+		CacheWritePerMTok: map[spec.CacheControlTTL]float64{
+			spec.CacheControlTTL5m: cacheWrite5m,
+			spec.CacheControlTTL1h: cacheWrite1h,
+		},
+		OutputPerMTok:    new(output),
+		WebSearchPerCall: new(0.01),
+	}
+}
@@ -0,0 +1,175 @@
+package modelpreset
+
+import (
+	"github.com/flexigpt/inference-go/capabilityoverride"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	ProviderBedrock spec.ProviderName = "bedrock"
+
+	DisplayNameProviderBedrock = "AWS Bedrock"
+)
+
+// Bedrock model IDs are cross-region inference profiles where the model needs
+// one. Cache points have no TTL, so the Claude presets cache without one.
+
+var modelBedrockClaudeOpus41 = ModelPreset{
+	ID:          PresetClaudeOpus41,
+	Name:        ModelNameClaudeOpus41Bedrock,
+	DisplayName: DisplayNameClaudeOpus41,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameClaudeOpus41Bedrock,
+		Stream:          true,
+		MaxPromptLength: 200000,
+		MaxOutputLength: 32000,
+		Temperature:     new(0.1),
+		Reasoning:       reasoningHybrid(1024),
+		SystemPrompt:    "",
+		Timeout:         1800,
+		CacheControl:    &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+	},
+	Pricing: bedrockClaudePricing(15, 75),
+}
+
+var modelBedrockClaudeSonnet45 = ModelPreset{
+	ID:          PresetClaudeSonnet45,
+	Name:        ModelNameClaudeSonnet45Bedrock,
+	DisplayName: DisplayNameClaudeSonnet45,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameClaudeSonnet45Bedrock,
+		Stream:          true,
+		MaxPromptLength: 200000,
+		MaxOutputLength: 64000,
+		Temperature:     new(0.1),
+		Reasoning:       reasoningHybrid(1024),
+		SystemPrompt:    "",
+		Timeout:         1800,
+		CacheControl:    &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+	},
+	Pricing: bedrockClaudePricing(3.3, 16.5),
+}
+
+var modelBedrockClaudeHaiku45 = ModelPreset{
+	ID:          PresetClaudeHaiku45,
+	Name:        ModelNameClaudeHaiku45Bedrock,
+	DisplayName: DisplayNameClaudeHaiku45,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameClaudeHaiku45Bedrock,
+		Stream:          true,
+		MaxPromptLength: 200000,
+		MaxOutputLength: 64000,
+		Temperature:     new(0.1),
+		Reasoning:       reasoningHybrid(1024),
+		SystemPrompt:    "",
+		Timeout:         1800,
+		CacheControl:    &spec.CacheControl{Kind: spec.CacheControlKindEphemeral},
+	},
+	Pricing: bedrockClaudePricing(1.1, 5.5),
+}
+
+var modelBedrockLlama4Maverick = ModelPreset{
+	ID:          PresetLlama4Maverick,
+	Name:        ModelNameLlama4MaverickBedrock,
+	DisplayName: DisplayNameLlama4Maverick,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameLlama4MaverickBedrock,
+		Stream:          true,
+		MaxPromptLength: 1000000,
+		MaxOutputLength: 8192,
+		Temperature:     new(0.1),
+		SystemPrompt:    "",
+		Timeout:         1800,
+	},
+	CapabilitiesOverride: capBedrockNonReasoning(true),
+}
+
+var modelBedrockLlama4Scout = ModelPreset{
+	ID:          PresetLlama4Scout,
+	Name:        ModelNameLlama4ScoutBedrock,
+	DisplayName: DisplayNameLlama4Scout,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameLlama4ScoutBedrock,
+		Stream:          true,
+		MaxPromptLength: 3500000,
+		MaxOutputLength: 8192,
+		Temperature:     new(0.1),
+		SystemPrompt:    "",
+		Timeout:         1800,
+	},
+	CapabilitiesOverride: capBedrockNonReasoning(true),
+}
+
+var modelBedrockMistralLarge3 = ModelPreset{
+	ID:          PresetMistralLarge3,
+	Name:        ModelNameMistralLarge3Bedrock,
+	DisplayName: DisplayNameMistralLarge3,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameMistralLarge3Bedrock,
+		Stream:          true,
+		MaxPromptLength: 256000,
+		MaxOutputLength: 32768,
+		Temperature:     new(0.1),
+		SystemPrompt:    "",
+		Timeout:         1800,
+	},
+	CapabilitiesOverride: capBedrockNonReasoning(false),
+}
+
+// The API key is a Bedrock API key, "ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN]" or
+// spec.BedrockEnvironmentCredentials. The region is taken from the origin.
+var providerBedrock = ProviderPreset{
+	Name:                     ProviderBedrock,
+	DisplayName:              DisplayNameProviderBedrock,
+	SDKType:                  spec.ProviderSDKTypeBedrockConverse,
+	Origin:                   spec.DefaultBedrockRuntimeOrigin,
+	ChatCompletionPathPrefix: spec.DefaultBedrockConversePrefix,
+	APIKeyHeaderKey:          spec.DefaultAuthorizationHeaderKey,
+	CapabilitiesOverride: &capabilityoverride.ModelCapabilitiesOverride{
+		ModalitiesIn: []spec.Modality{
+			spec.ModalityTextIn,
+			spec.ModalityImageIn,
+			spec.ModalityFileIn,
+		},
+		ModalitiesOut: []spec.Modality{
+			spec.ModalityTextOut,
+		},
+	},
+	ModelPresets: map[ModelPresetID]ModelPreset{
+		PresetClaudeOpus41:   modelBedrockClaudeOpus41,
+		PresetClaudeSonnet45: modelBedrockClaudeSonnet45,
+		PresetClaudeHaiku45:  modelBedrockClaudeHaiku45,
+		PresetLlama4Maverick: modelBedrockLlama4Maverick,
+		PresetLlama4Scout:    modelBedrockLlama4Scout,
+		PresetMistralLarge3:  modelBedrockMistralLarge3,
+	},
+}
+
+// capBedrockNonReasoning is the capability patch for models that take no
+// reasoning config through Converse.
+func capBedrockNonReasoning(images bool) *capabilityoverride.ModelCapabilitiesOverride {
+	c := capTextOnly()
+	if images {
+		c = capTextImage()
+	}
+	c.ModalitiesIn = append(c.ModalitiesIn, spec.ModalityFileIn)
+	c.ReasoningCapabilities = &capabilityoverride.ReasoningCapabilitiesOverride{
+		SupportsReasoningConfig: new(false),
+	}
+	return c
+}
+
+// bedrockClaudePricing returns Bedrock on-demand prices in USD for the Claude
+// presets' "us." inference profiles, which for Sonnet 4.5 and later carry a 10%
+// premium over global ones. Cache points are written at 1.25x and read at 0.1x
+// the input price.
+func bedrockClaudePricing(input, output float64) *PricingOverride {
+	return &PricingOverride{
+		InputPerMTok:       new(input),
+		CachedInputPerMTok: new(input * 0.1),
+		CacheWritePerMTok: map[spec.CacheControlTTL]float64{
+			spec.CacheControlTTL5m: input * 1.25,
+		},
+		OutputPerMTok: new(output),
+	}
+}
@@ -213,6 +213,7 @@ var modelGoogleGemini31Pro = ModelPreset{
 			SupportsSummaryStyle: new(true),
 		},
 	},
+	Pricing: geminiPricing(2, 0.2, 12, 0.014),
 }
 
 var modelGoogleGemini31FlashLite = ModelPreset{
@@ -275,6 +276,7 @@ var modelGoogleGemini3Flash = ModelPreset{
 			SupportsSummaryStyle: new(true),
 		},
 	},
+	Pricing: geminiPricing(0.5, 0.05, 3, 0.014),
 }
 
 var modelGoogleGemini25Flash = ModelPreset{
@@ -304,6 +306,7 @@ var modelGoogleGemini25Flash = ModelPreset{
 			},
 		},
 	},
+	Pricing: geminiPricing(0.3, 0.03, 2.5, 0.035),
 }
 
 var modelGoogleGemini25FlashLite = ModelPreset{
@@ -333,6 +336,7 @@ var modelGoogleGemini25FlashLite = ModelPreset{
 			},
 		},
 	},
+	Pricing: geminiPricing(0.1, 0.025, 0.4, 0.035),
 }
 
 var providerGoogleGemini = ProviderPreset{
@@ -348,6 +352,8 @@ var providerGoogleGemini = ProviderPreset{
 			spec.ModalityTextIn,
 			spec.ModalityImageIn,
 			spec.ModalityFileIn,
+			spec.ModalityAudioIn,
+			spec.ModalityVideoIn,
 		},
 		ModalitiesOut: []spec.Modality{
 			spec.ModalityTextOut,
@@ -419,3 +425,15 @@ var providerGoogleGemini = ProviderPreset{
 		PresetGemini25FlashLite: modelGoogleGemini25FlashLite,
 	},
 }
+
+// geminiPricing returns Gemini Developer API paid tier list prices in USD for text, image and video input of prompts
+// up to 200k tokens. Audio input and longer prompts are billed higher. webSearch is the price of one Google Search
+// grounding request beyond the free daily allowance.
+func geminiPricing(input, cachedInput, output, webSearch float64) *PricingOverride {
+	return &PricingOverride{
+		InputPerMTok:       new(input),
+		CachedInputPerMTok: new(cachedInput),
+		OutputPerMTok:      new(output),
+		WebSearchPerCall:   new(webSearch),
+	}
+}
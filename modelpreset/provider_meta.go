@@ -6,6 +6,9 @@ const (
 	ModelNameLlama4BehemothLocal spec.ModelName = "llama4-behemoth"
 	ModelNameLlama4MaverickLocal spec.ModelName = "llama4-maverick"
 	ModelNameLlama4ScoutLocal    spec.ModelName = "llama4-scout"
+
+	ModelNameLlama4MaverickBedrock spec.ModelName = "us.meta.llama4-maverick-17b-instruct-v1:0"
+	ModelNameLlama4ScoutBedrock    spec.ModelName = "us.meta.llama4-scout-17b-instruct-v1:0"
 )
 
 const (
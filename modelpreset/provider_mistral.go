@@ -25,6 +25,8 @@ const (
 	ModelNameDevstral224BLocal spec.ModelName = "devstral-small-2-24b"
 
 	ModelNameMinistral314BOllama spec.ModelName = "ministral-3:14b"
+
+	ModelNameMistralLarge3Bedrock spec.ModelName = "mistral.mistral-large-3-675b-instruct"
 )
 
 const (
@@ -25,7 +25,7 @@ var modelOllamaGemma426B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaGemma4E4B = ModelPreset{
@@ -42,7 +42,7 @@ var modelOllamaGemma4E4B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaGPTOSS20B = ModelPreset{
@@ -59,7 +59,7 @@ var modelOllamaGPTOSS20B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(false), false, true),
+	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(false), false, false),
 }
 
 var modelOllamaQwen3635B = ModelPreset{
@@ -76,7 +76,7 @@ var modelOllamaQwen3635B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaQwen3627B = ModelPreset{
@@ -93,7 +93,7 @@ var modelOllamaQwen3627B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaDeepSeekR18B = ModelPreset{
@@ -110,7 +110,7 @@ var modelOllamaDeepSeekR18B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaQwen3VL30B = ModelPreset{
@@ -127,7 +127,7 @@ var modelOllamaQwen3VL30B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaMinistral314B = ModelPreset{
@@ -144,7 +144,7 @@ var modelOllamaMinistral314B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, true),
+	CapabilitiesOverride: capTextImageReasoning(reasoningLevels(true), false, false),
 }
 
 var modelOllamaQwen3Coder30B = ModelPreset{
@@ -177,20 +177,19 @@ var modelOllamaPhi4Reasoning14B = ModelPreset{
 		SystemPrompt:    "",
 		Timeout:         1800,
 	},
-	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(false), false, true),
+	CapabilitiesOverride: capTextOnlyReasoning(reasoningLevels(false), false, false),
 }
 
 var providerOllama = ProviderPreset{
 	Name:                     ProviderOllama,
 	DisplayName:              DisplayNameProviderOllama,
-	SDKType:                  spec.ProviderSDKTypeAnthropic,
-	Origin:                   "http://127.0.0.1:11434",
-	ChatCompletionPathPrefix: spec.DefaultAnthropicChatCompletionPrefix,
-	APIKeyHeaderKey:          spec.DefaultAnthropicAuthorizationHeaderKey,
+	SDKType:                  spec.ProviderSDKTypeOllamaChat,
+	Origin:                   spec.DefaultOllamaOrigin,
+	ChatCompletionPathPrefix: spec.DefaultOllamaChatPrefix,
+	APIKeyHeaderKey:          spec.DefaultAuthorizationHeaderKey,
 	DefaultHeaders: map[string]string{
 		spec.DefaultContentTypeHeaderKey: spec.DefaultContentTypeHeader,
 		"accept":                         spec.DefaultContentTypeHeader,
-		"anthropic-version":              "2023-06-01",
 	},
 	CapabilitiesOverride: &capabilityoverride.ModelCapabilitiesOverride{
 		ModalitiesIn: []spec.Modality{
@@ -210,7 +209,7 @@ var providerOllama = ProviderPreset{
 				spec.OutputFormatKindText,
 				spec.OutputFormatKindJSONSchema,
 			},
-			SupportsVerbosity: new(true),
+			SupportsVerbosity: new(false),
 		},
 		ToolCapabilities: &capabilityoverride.ToolCapabilitiesOverride{
 			SupportedToolTypes: []spec.ToolType{
@@ -223,7 +222,7 @@ var providerOllama = ProviderPreset{
 			SupportsParallelToolCalls: new(false),
 			MaxForcedTools:            new(0),
 			SupportedClientToolOutputFormats: []spec.ToolOutputFormatKind{
-				spec.ToolOutputFormatKindContentItemList,
+				spec.ToolOutputFormatKindString,
 			},
 		},
 	},
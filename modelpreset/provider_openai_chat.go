@@ -10,6 +10,10 @@ const (
 	ProviderOpenAIChat spec.ProviderName = "openai"
 
 	DisplayNameProviderOpenAIChat = "OpenAI Chat Completions API"
+
+	ModelNameGPTAudio   spec.ModelName = "gpt-audio"
+	DisplayNameGPTAudio                = "GPT Audio"
+	PresetGPTAudio      ModelPresetID  = "gptAudio"
 )
 
 var openAIChatNoReasoningOverride = &capabilityoverride.ModelCapabilitiesOverride{
@@ -33,6 +37,7 @@ var modelOpenAIChatGPT41 = ModelPreset{
 		Timeout:         1800,
 	},
 	CapabilitiesOverride: openAIChatNoReasoningOverride,
+	Pricing:              openAIPricing(2, 0.5, 8, 0),
 }
 
 var modelOpenAIChatGPT41Mini = ModelPreset{
@@ -49,6 +54,7 @@ var modelOpenAIChatGPT41Mini = ModelPreset{
 		Timeout:         1800,
 	},
 	CapabilitiesOverride: openAIChatNoReasoningOverride,
+	Pricing:              openAIPricing(0.4, 0.1, 1.6, 0),
 }
 
 var modelOpenAIChatGPT4o = ModelPreset{
@@ -65,6 +71,7 @@ var modelOpenAIChatGPT4o = ModelPreset{
 		Timeout:         1800,
 	},
 	CapabilitiesOverride: openAIChatNoReasoningOverride,
+	Pricing:              openAIPricing(2.5, 1.25, 10, 0),
 }
 
 var modelOpenAIChatGPT4oMini = ModelPreset{
@@ -81,6 +88,35 @@ var modelOpenAIChatGPT4oMini = ModelPreset{
 		Timeout:         1800,
 	},
 	CapabilitiesOverride: openAIChatNoReasoningOverride,
+	Pricing:              openAIPricing(0.15, 0.075, 0.6, 0),
+}
+
+// modelOpenAIChatGPTAudio is the only chat preset that declares audio; the other OpenAI models reject input_audio
+// and audio output.
+var modelOpenAIChatGPTAudio = ModelPreset{
+	ID:          PresetGPTAudio,
+	Name:        ModelNameGPTAudio,
+	DisplayName: DisplayNameGPTAudio,
+	ModelParam: spec.ModelParam{
+		Name:            ModelNameGPTAudio,
+		Stream:          true,
+		MaxPromptLength: 128000,
+		MaxOutputLength: 16384,
+		Temperature:     new(0.1),
+		SystemPrompt:    "",
+		Timeout:         1800,
+	},
+	CapabilitiesOverride: &capabilityoverride.ModelCapabilitiesOverride{
+		ModalitiesIn: []spec.Modality{
+			spec.ModalityTextIn,
+			spec.ModalityAudioIn,
+		},
+		ModalitiesOut: []spec.Modality{
+			spec.ModalityTextOut,
+			spec.ModalityAudioOut,
+		},
+		ReasoningCapabilities: openAIChatNoReasoningOverride.ReasoningCapabilities,
+	},
 }
 
 var providerOpenAIChat = ProviderPreset{
@@ -153,5 +189,6 @@ var providerOpenAIChat = ProviderPreset{
 		PresetGPT41Mini: modelOpenAIChatGPT41Mini,
 		PresetGPT4o:     modelOpenAIChatGPT4o,
 		PresetGPT4oMini: modelOpenAIChatGPT4oMini,
+		PresetGPTAudio:  modelOpenAIChatGPTAudio,
 	},
 }
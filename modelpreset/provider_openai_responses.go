@@ -282,6 +282,7 @@ var modelOpenAIResponsesGPT53Codex = ModelPreset{
 		spec.ReasoningLevelHigh,
 		spec.ReasoningLevelXHigh,
 	}),
+	Pricing: openAIPricing(1.75, 0.175, 14, 0.01),
 }
 
 var modelOpenAIResponsesGPT52 = ModelPreset{
@@ -305,6 +306,7 @@ var modelOpenAIResponsesGPT52 = ModelPreset{
 		spec.ReasoningLevelHigh,
 		spec.ReasoningLevelXHigh,
 	}),
+	Pricing: openAIPricing(1.75, 0.175, 14, 0.01),
 }
 
 var modelOpenAIResponsesGPT52Codex = ModelPreset{
@@ -327,6 +329,7 @@ var modelOpenAIResponsesGPT52Codex = ModelPreset{
 		spec.ReasoningLevelHigh,
 		spec.ReasoningLevelXHigh,
 	}),
+	Pricing: openAIPricing(1.75, 0.175, 14, 0.01),
 }
 
 var modelOpenAIResponsesGPT51 = ModelPreset{
@@ -349,6 +352,7 @@ var modelOpenAIResponsesGPT51 = ModelPreset{
 		spec.ReasoningLevelMedium,
 		spec.ReasoningLevelHigh,
 	}),
+	Pricing: openAIPricing(1.25, 0.125, 10, 0.01),
 }
 
 var modelOpenAIResponsesGPT51Codex = ModelPreset{
@@ -370,6 +374,7 @@ var modelOpenAIResponsesGPT51Codex = ModelPreset{
 		spec.ReasoningLevelMedium,
 		spec.ReasoningLevelHigh,
 	}),
+	Pricing: openAIPricing(1.25, 0.125, 10, 0.01),
 }
 
 var modelOpenAIResponsesGPT51CodexMax = ModelPreset{
@@ -391,6 +396,7 @@ var modelOpenAIResponsesGPT51CodexMax = ModelPreset{
 		spec.ReasoningLevelMedium,
 		spec.ReasoningLevelHigh,
 	}),
+	Pricing: openAIPricing(1.25, 0.125, 10, 0.01),
 }
 
 var modelOpenAIResponsesGPT5Mini = ModelPreset{
@@ -413,6 +419,7 @@ var modelOpenAIResponsesGPT5Mini = ModelPreset{
 		spec.ReasoningLevelMedium,
 		spec.ReasoningLevelHigh,
 	}),
+	Pricing: openAIPricing(0.25, 0.025, 2, 0.01),
 }
 
 func openAIResponsesReasoningOverride(levels []spec.ReasoningLevel) *capabilityoverride.ModelCapabilitiesOverride {
@@ -425,6 +432,9 @@ func openAIResponsesReasoningOverride(levels []spec.ReasoningLevel) *capabilityo
 			SupportedReasoningLevels: levels,
 			SupportsSummaryStyle:     new(true),
 		},
+		SamplingCapabilities: &capabilityoverride.SamplingCapabilitiesOverride{
+			DisallowedWithReasoning: new(true),
+		},
 	}
 }
 
@@ -511,3 +521,14 @@ var providerOpenAIResponses = ProviderPreset{
 		PresetGPT5Mini:      modelOpenAIResponsesGPT5Mini,
 	},
 }
+
+// openAIPricing returns OpenAI standard tier list prices in USD. Prompt caching is automatic and cache writes are not
+// billed. webSearch is the price of one web search tool call; zero where the API offers no web search tool.
+func openAIPricing(input, cachedInput, output, webSearch float64) *PricingOverride {
+	return &PricingOverride{
+		InputPerMTok:       new(input),
+		CachedInputPerMTok: new(cachedInput),
+		OutputPerMTok:      new(output),
+		WebSearchPerCall:   new(webSearch),
+	}
+}
@@ -0,0 +1,338 @@
+// Package oteldebug provides a spec.CompletionDebugger that traces completions
+// and records metrics with OpenTelemetry, following the GenAI semantic
+// conventions.
+//
+// Each provider call gets a client span named "chat {model}" carrying the
+// provider, request parameters, token usage, finish reason and, for streaming
+// calls, the time to the first chunk. Tool calls of the response are added as
+// span events. Message content is never recorded.
+//
+// Combine it with debugclient.HTTPCompletionDebugger through
+// debugclient.Chain to get both.
+package oteldebug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/semconv/v1.41.0/genaiconv"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/flexigpt/inference-go/modelpreset"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const instrumentationName = "github.com/flexigpt/inference-go/oteldebug"
+
+// Config selects the OpenTelemetry providers used by the debugger.
+//
+// Nil providers fall back to the global ones from otel.GetTracerProvider and
+// otel.GetMeterProvider.
+type Config struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// Bucket boundaries advised by the GenAI semantic conventions.
+var (
+	durationBuckets = []float64{
+		0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92,
+	}
+	tokenBuckets = []float64{
+		1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864,
+	}
+)
+
+// providerNames maps the provider names of modelpreset to the well-known
+// gen_ai.provider.name values. Other providers are reported by their name.
+var providerNames = map[spec.ProviderName]genaiconv.ProviderNameAttr{
+	modelpreset.ProviderAnthropic:       genaiconv.ProviderNameAnthropic,
+	modelpreset.ProviderOpenAIChat:      genaiconv.ProviderNameOpenAI,
+	modelpreset.ProviderOpenAIResponses: genaiconv.ProviderNameOpenAI,
+	modelpreset.ProviderGoogleGemini:    genaiconv.ProviderNameGCPGemini,
+	modelpreset.ProviderMistral:         genaiconv.ProviderNameMistralAI,
+	modelpreset.ProviderXAI:             genaiconv.ProviderNameXAI,
+}
+
+// CompletionDebugger implements spec.CompletionDebugger with OpenTelemetry
+// spans and metrics. It returns no debug details and leaves the HTTP client
+// untouched.
+type CompletionDebugger struct {
+	tracer trace.Tracer
+
+	duration     genaiconv.ClientOperationDuration
+	tokenUsage   genaiconv.ClientTokenUsage
+	firstChunk   genaiconv.ClientOperationTimeToFirstChunk
+	timePerChunk genaiconv.ClientOperationTimePerOutputChunk
+}
+
+// NewCompletionDebugger creates the debugger and its metric instruments.
+//
+// Config may be nil; in that case the global providers are used.
+func NewCompletionDebugger(config *Config) (*CompletionDebugger, error) {
+	var c Config
+	if config != nil {
+		c = *config
+	}
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := c.MeterProvider.Meter(instrumentationName)
+	d := &CompletionDebugger{tracer: c.TracerProvider.Tracer(instrumentationName)}
+
+	var errs [4]error
+	d.duration, errs[0] = genaiconv.NewClientOperationDuration(
+		meter,
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	d.tokenUsage, errs[1] = genaiconv.NewClientTokenUsage(
+		meter,
+		metric.WithExplicitBucketBoundaries(tokenBuckets...),
+	)
+	d.firstChunk, errs[2] = genaiconv.NewClientOperationTimeToFirstChunk(
+		meter,
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	d.timePerChunk, errs[3] = genaiconv.NewClientOperationTimePerOutputChunk(
+		meter,
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err := errors.Join(errs[:]...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// HTTPClient implements spec.CompletionDebugger.HTTPClient. It returns nil, so
+// the provider's default client is used.
+func (d *CompletionDebugger) HTTPClient(*http.Client) *http.Client {
+	return nil
+}
+
+// StartSpan implements spec.CompletionDebugger.StartSpan.
+func (d *CompletionDebugger) StartSpan(
+	ctx context.Context,
+	info *spec.CompletionSpanStart,
+) (context.Context, spec.CompletionSpan) {
+	if info == nil {
+		info = &spec.CompletionSpanStart{}
+	}
+	s := &otelSpan{
+		d:        d,
+		start:    time.Now(),
+		provider: providerName(info.Provider),
+		model:    string(info.Model),
+	}
+	if info.Request != nil {
+		s.stream = info.Request.ModelParam.Stream && info.Options != nil && info.Options.StreamHandler != nil
+	}
+
+	ctx, s.span = d.tracer.Start(
+		ctx,
+		string(genaiconv.OperationNameChat)+" "+s.model,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(s.start),
+		trace.WithAttributes(requestAttributes(s, info)...),
+	)
+	s.ctx = ctx
+	return ctx, s
+}
+
+type otelSpan struct {
+	d    *CompletionDebugger
+	span trace.Span
+	ctx  context.Context
+
+	start    time.Time
+	provider genaiconv.ProviderNameAttr
+	model    string
+	stream   bool
+
+	mu         sync.Mutex
+	chunks     int
+	firstChunk time.Time
+	lastChunk  time.Time
+}
+
+// ObserveStreamEvent implements spec.CompletionStreamObserver.
+func (s *otelSpan) ObserveStreamEvent(spec.StreamEvent) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chunks == 0 {
+		s.firstChunk = now
+	}
+	s.lastChunk = now
+	s.chunks++
+}
+
+// End implements spec.CompletionSpan.End.
+func (s *otelSpan) End(end *spec.CompletionSpanEnd) any {
+	if end == nil {
+		end = &spec.CompletionSpanEnd{}
+	}
+	now := time.Now()
+	metricAttrs := []attribute.KeyValue{semconv.GenAIRequestModel(s.model)}
+
+	s.mu.Lock()
+	chunks, firstChunk, lastChunk := s.chunks, s.firstChunk, s.lastChunk
+	s.mu.Unlock()
+	if chunks > 0 {
+		ttfc := firstChunk.Sub(s.start).Seconds()
+		s.span.SetAttributes(semconv.GenAIResponseTimeToFirstChunk(ttfc))
+		s.d.firstChunk.Record(s.ctx, ttfc, genaiconv.OperationNameChat, s.provider, metricAttrs...)
+		if chunks > 1 {
+			perChunk := lastChunk.Sub(firstChunk).Seconds() / float64(chunks-1)
+			s.d.timePerChunk.Record(s.ctx, perChunk, genaiconv.OperationNameChat, s.provider, metricAttrs...)
+		}
+	}
+
+	if resp := end.Response; resp != nil {
+		s.span.SetAttributes(responseAttributes(resp)...)
+		addToolCallEvents(s.span, resp.Outputs)
+		if u := resp.Usage; u != nil {
+			s.d.tokenUsage.Record(
+				s.ctx, u.InputTokensTotal, genaiconv.OperationNameChat, s.provider, genaiconv.TokenTypeInput,
+				metricAttrs...,
+			)
+			s.d.tokenUsage.Record(
+				s.ctx, u.OutputTokens, genaiconv.OperationNameChat, s.provider, genaiconv.TokenTypeOutput,
+				metricAttrs...,
+			)
+		}
+	}
+
+	if end.Err != nil {
+		errType := errorType(end.Err)
+		s.span.SetAttributes(semconv.ErrorTypeKey.String(errType))
+		s.span.RecordError(end.Err)
+		s.span.SetStatus(codes.Error, end.Err.Error())
+		metricAttrs = append(metricAttrs, semconv.ErrorTypeKey.String(errType))
+	}
+	s.d.duration.Record(s.ctx, now.Sub(s.start).Seconds(), genaiconv.OperationNameChat, s.provider, metricAttrs...)
+
+	s.span.End(trace.WithTimestamp(now))
+	return nil
+}
+
+func providerName(p spec.ProviderName) genaiconv.ProviderNameAttr {
+	if name, ok := providerNames[p]; ok {
+		return name
+	}
+	return genaiconv.ProviderNameAttr(p)
+}
+
+func requestAttributes(s *otelSpan, info *spec.CompletionSpanStart) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.GenAIOperationNameChat,
+		semconv.GenAIProviderNameKey.String(string(s.provider)),
+		semconv.GenAIRequestModel(s.model),
+		semconv.GenAIRequestStream(s.stream),
+	}
+	if info.Request == nil {
+		return attrs
+	}
+	mp := info.Request.ModelParam
+	if mp.MaxOutputLength > 0 {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(mp.MaxOutputLength))
+	}
+	if mp.Temperature != nil {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(*mp.Temperature))
+	}
+	if mp.TopP != nil {
+		attrs = append(attrs, semconv.GenAIRequestTopP(*mp.TopP))
+	}
+	if mp.TopK != nil {
+		attrs = append(attrs, semconv.GenAIRequestTopK(float64(*mp.TopK)))
+	}
+	if mp.Seed != nil {
+		attrs = append(attrs, semconv.GenAIRequestSeed(int(*mp.Seed)))
+	}
+	if mp.PresencePenalty != nil {
+		attrs = append(attrs, semconv.GenAIRequestPresencePenalty(*mp.PresencePenalty))
+	}
+	if mp.FrequencyPenalty != nil {
+		attrs = append(attrs, semconv.GenAIRequestFrequencyPenalty(*mp.FrequencyPenalty))
+	}
+	if mp.Candidates > 1 {
+		attrs = append(attrs, semconv.GenAIRequestChoiceCount(mp.Candidates))
+	}
+	if len(mp.StopSequences) > 0 {
+		attrs = append(attrs, semconv.GenAIRequestStopSequences(mp.StopSequences...))
+	}
+	return attrs
+}
+
+func responseAttributes(resp *spec.FetchCompletionResponse) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if sr := resp.StopReason; sr != nil {
+		reason := sr.Raw
+		if reason == "" {
+			reason = string(sr.Kind)
+		}
+		attrs = append(attrs, semconv.GenAIResponseFinishReasons(reason))
+	}
+	if u := resp.Usage; u != nil {
+		attrs = append(attrs,
+			semconv.GenAIUsageInputTokensKey.Int64(u.InputTokensTotal),
+			semconv.GenAIUsageOutputTokensKey.Int64(u.OutputTokens),
+		)
+		if u.InputTokensCached > 0 {
+			attrs = append(attrs, semconv.GenAIUsageCacheReadInputTokensKey.Int64(u.InputTokensCached))
+		}
+		if u.InputTokensCacheWrite > 0 {
+			attrs = append(attrs, semconv.GenAIUsageCacheCreationInputTokensKey.Int64(u.InputTokensCacheWrite))
+		}
+		if u.ReasoningTokens > 0 {
+			attrs = append(attrs, semconv.GenAIUsageReasoningOutputTokensKey.Int64(u.ReasoningTokens))
+		}
+	}
+	return attrs
+}
+
+// addToolCallEvents adds a "gen_ai.tool.call" event per tool call output. Call
+// arguments are content and are left out.
+func addToolCallEvents(span trace.Span, outputs []spec.OutputUnion) {
+	for _, o := range outputs {
+		var call *spec.ToolCall
+		switch o.Kind {
+		case spec.OutputKindFunctionToolCall:
+			call = o.FunctionToolCall
+		case spec.OutputKindCustomToolCall:
+			call = o.CustomToolCall
+		case spec.OutputKindWebSearchToolCall:
+			call = o.WebSearchToolCall
+		default:
+		}
+		if call == nil {
+			continue
+		}
+		span.AddEvent("gen_ai.tool.call", trace.WithAttributes(
+			semconv.GenAIToolName(call.Name),
+			semconv.GenAIToolCallID(call.CallID),
+			semconv.GenAIToolTypeKey.String(string(call.Type)),
+		))
+	}
+}
+
+// errorType returns the error.type of err: the ProviderError kind when there
+// is one, "_OTHER" otherwise.
+func errorType(err error) string {
+	var pe *spec.ProviderError
+	if errors.As(err, &pe) && pe.Kind != "" {
+		return string(pe.Kind)
+	}
+	return string(genaiconv.ErrorTypeOther)
+}
@@ -0,0 +1,211 @@
+package oteldebug
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type testTelemetry struct {
+	spans   *tracetest.InMemoryExporter
+	metrics *sdkmetric.ManualReader
+	dbg     *CompletionDebugger
+}
+
+func newTestTelemetry(t *testing.T) *testTelemetry {
+	t.Helper()
+
+	spans := tracetest.NewInMemoryExporter()
+	metrics := sdkmetric.NewManualReader()
+	dbg, err := NewCompletionDebugger(&Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(metrics)),
+	})
+	if err != nil {
+		t.Fatalf("NewCompletionDebugger: %v", err)
+	}
+	return &testTelemetry{spans: spans, metrics: metrics, dbg: dbg}
+}
+
+// histogramCounts returns the number of recorded values per metric name and
+// gen_ai.token.type, e.g. "gen_ai.client.token.usage/input".
+func (tt *testTelemetry) histogramCounts(t *testing.T) map[string]uint64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := tt.metrics.Collect(t.Context(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	out := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					out[m.Name] += dp.Count
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					tokenType, _ := dp.Attributes.Value("gen_ai.token.type")
+					out[m.Name+"/"+tokenType.AsString()] += dp.Count
+				}
+			default:
+			}
+		}
+	}
+	return out
+}
+
+func spanAttrs(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	out := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+func TestCompletionDebuggerStreamingSpan(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTelemetry(t)
+	temperature := 0.2
+	_, span := tt.dbg.StartSpan(t.Context(), &spec.CompletionSpanStart{
+		Provider: "anthropic",
+		Model:    "claude-x",
+		Request: &spec.FetchCompletionRequest{ModelParam: spec.ModelParam{
+			Name:            "claude-x",
+			Stream:          true,
+			MaxOutputLength: 512,
+			Temperature:     &temperature,
+		}},
+		Options: &spec.FetchCompletionOptions{StreamHandler: func(spec.StreamEvent) error { return nil }},
+	})
+	observer := span.(spec.CompletionStreamObserver)
+	observer.ObserveStreamEvent(spec.StreamEvent{Kind: spec.StreamContentKindText})
+	observer.ObserveStreamEvent(spec.StreamEvent{Kind: spec.StreamContentKindText})
+
+	if got := span.End(&spec.CompletionSpanEnd{Response: &spec.FetchCompletionResponse{
+		StopReason: &spec.StopReason{Kind: spec.StopReasonToolUse, Raw: "tool_use"},
+		Usage: &spec.Usage{
+			InputTokensTotal:  100,
+			InputTokensCached: 60,
+			OutputTokens:      20,
+			ReasoningTokens:   5,
+		},
+		Outputs: []spec.OutputUnion{{
+			Kind: spec.OutputKindFunctionToolCall,
+			FunctionToolCall: &spec.ToolCall{
+				Type:      spec.ToolTypeFunction,
+				CallID:    "call-1",
+				Name:      "lookup",
+				Arguments: `{"secret":"x"}`,
+			},
+		}},
+	}}); got != nil {
+		t.Errorf("End() = %v, want nil debug details", got)
+	}
+
+	stubs := tt.spans.GetSpans()
+	if len(stubs) != 1 {
+		t.Fatalf("got %d spans, want 1", len(stubs))
+	}
+	s := stubs[0]
+	if s.Name != "chat claude-x" {
+		t.Errorf("span name = %q, want %q", s.Name, "chat claude-x")
+	}
+	attrs := spanAttrs(s)
+	wantStrings := map[attribute.Key]string{
+		"gen_ai.operation.name": "chat",
+		"gen_ai.provider.name":  "anthropic",
+		"gen_ai.request.model":  "claude-x",
+	}
+	for k, want := range wantStrings {
+		if got := attrs[k].AsString(); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	wantInts := map[attribute.Key]int64{
+		"gen_ai.request.max_tokens":            512,
+		"gen_ai.usage.input_tokens":            100,
+		"gen_ai.usage.output_tokens":           20,
+		"gen_ai.usage.cache_read.input_tokens": 60,
+		"gen_ai.usage.reasoning.output_tokens": 5,
+	}
+	for k, want := range wantInts {
+		if got := attrs[k].AsInt64(); got != want {
+			t.Errorf("%s = %d, want %d", k, got, want)
+		}
+	}
+	if _, ok := attrs["gen_ai.usage.cache_creation.input_tokens"]; ok {
+		t.Error("gen_ai.usage.cache_creation.input_tokens set without cache writes")
+	}
+	if !attrs["gen_ai.request.stream"].AsBool() {
+		t.Error("gen_ai.request.stream = false, want true")
+	}
+	if got := attrs["gen_ai.response.finish_reasons"].AsStringSlice(); len(got) != 1 || got[0] != "tool_use" {
+		t.Errorf("gen_ai.response.finish_reasons = %v, want [tool_use]", got)
+	}
+	if _, ok := attrs["gen_ai.response.time_to_first_chunk"]; !ok {
+		t.Error("gen_ai.response.time_to_first_chunk not set")
+	}
+	if len(s.Events) != 1 || s.Events[0].Name != "gen_ai.tool.call" {
+		t.Fatalf("events = %v, want one gen_ai.tool.call", s.Events)
+	}
+	for _, kv := range s.Events[0].Attributes {
+		if kv.Key == "gen_ai.tool.call.arguments" {
+			t.Error("tool call arguments must not be recorded")
+		}
+	}
+
+	counts := tt.histogramCounts(t)
+	want := map[string]uint64{
+		"gen_ai.client.operation.duration":              1,
+		"gen_ai.client.operation.time_to_first_chunk":   1,
+		"gen_ai.client.operation.time_per_output_chunk": 1,
+		"gen_ai.client.token.usage/input":               1,
+		"gen_ai.client.token.usage/output":              1,
+	}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%s recorded %d times, want %d (all: %v)", name, counts[name], n, counts)
+		}
+	}
+}
+
+func TestCompletionDebuggerErrorSpan(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTelemetry(t)
+	_, span := tt.dbg.StartSpan(t.Context(), &spec.CompletionSpanStart{Provider: "openrouter", Model: "m"})
+	span.End(&spec.CompletionSpanEnd{
+		Err: &spec.ProviderError{Kind: spec.ProviderErrorKindRateLimited, Err: errors.New("slow down")},
+	})
+
+	s := tt.spans.GetSpans()[0]
+	if s.Status.Code != codes.Error {
+		t.Errorf("status = %v, want Error", s.Status.Code)
+	}
+	attrs := spanAttrs(s)
+	if got := attrs["gen_ai.provider.name"].AsString(); got != "openrouter" {
+		t.Errorf("gen_ai.provider.name = %q, want openrouter", got)
+	}
+	if got := attrs["error.type"].AsString(); got != string(spec.ProviderErrorKindRateLimited) {
+		t.Errorf("error.type = %q, want %q", got, spec.ProviderErrorKindRateLimited)
+	}
+	if _, ok := attrs["gen_ai.response.time_to_first_chunk"]; ok {
+		t.Error("gen_ai.response.time_to_first_chunk set for a non-streaming call")
+	}
+
+	counts := tt.histogramCounts(t)
+	if counts["gen_ai.client.operation.duration"] != 1 || counts["gen_ai.client.operation.time_to_first_chunk"] != 0 {
+		t.Errorf("histograms = %v, want only one duration", counts)
+	}
+}
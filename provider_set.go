@@ -20,11 +20,14 @@ import (
 	"sync"
 
 	"github.com/flexigpt/inference-go/capabilityoverride"
+	"github.com/flexigpt/inference-go/contextstrategy"
 	"github.com/flexigpt/inference-go/internal/anthropicsdk"
+	"github.com/flexigpt/inference-go/internal/bedrockconversesdk"
 	"github.com/flexigpt/inference-go/internal/googlegeneratecontentsdk"
 	"github.com/flexigpt/inference-go/modelpreset"
 
 	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/ollamachatsdk"
 	"github.com/flexigpt/inference-go/internal/openaichatsdk"
 	"github.com/flexigpt/inference-go/internal/openairesponsessdk"
 	"github.com/flexigpt/inference-go/internal/sdkutil"
@@ -35,17 +38,34 @@ import (
 // nil builder or a nil returned debugger disable debugging for that provider.
 type DebugClientBuilder func(p spec.ProviderParam) spec.CompletionDebugger
 
+// CompletionProvider is the interface implemented by every provider adapter. Implement it to register a custom
+// provider with AddCompletionProvider.
+type CompletionProvider = sdkutil.CompletionProvider
+
+// CompletionProviderBuilder builds a provider registered with AddCompletionProvider from its ProviderParam, the
+// debugger the DebugClientBuilder returned for it (or nil), and the ProviderSet's logger.
+type CompletionProviderBuilder func(
+	p spec.ProviderParam,
+	dbg spec.CompletionDebugger,
+	logger *slog.Logger,
+) (CompletionProvider, error)
+
 type ProviderSetAPI struct {
 	mu sync.RWMutex
 
 	providers          map[spec.ProviderName]sdkutil.CompletionProvider
 	logger             *slog.Logger
 	debugClientBuilder DebugClientBuilder
+	retryPolicy        *spec.RetryPolicy
+	tokenizer          spec.Tokenizer
 }
 
 // ProviderSetOption configures optional behavior for ProviderSetAPI.
 type ProviderSetOption func(*ProviderSetAPI)
 
+// WithLogger configures the logger of the ProviderSet and of the providers
+// added to it. Records of a call carry the provider, model and, when set, the
+// completion key and request ID of its FetchCompletionOptions.
 func WithLogger(logger *slog.Logger) ProviderSetOption {
 	return func(ps *ProviderSetAPI) {
 		ps.logger = logger
@@ -61,9 +81,32 @@ func WithDebugClientBuilder(builder DebugClientBuilder) ProviderSetOption {
 	}
 }
 
-// NewProviderSetAPI creates a new ProviderSet and installs the process-wide
-// logger used by this SDK. The logger is chosen via WithLoggerBuilder; if no
-// builder is provided or it returns nil, a no-op logger is used.
+// WithRetryPolicy configures the default retry policy applied to every
+// FetchCompletion call. FetchCompletionOptions.RetryPolicy, when set, takes
+// precedence. A nil policy (the default) disables retries.
+func WithRetryPolicy(policy *spec.RetryPolicy) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		if policy == nil {
+			ps.retryPolicy = nil
+			return
+		}
+		p := *policy
+		ps.retryPolicy = &p
+	}
+}
+
+// WithTokenizer configures the default Tokenizer used to trim inputs to
+// ModelParam.MaxPromptLength and by CountTokens when a provider cannot count
+// tokens itself. FetchCompletionOptions.Tokenizer, when set, takes precedence.
+// A nil tokenizer (the default) uses a regex based heuristic.
+func WithTokenizer(tokenizer spec.Tokenizer) ProviderSetOption {
+	return func(ps *ProviderSetAPI) {
+		ps.tokenizer = tokenizer
+	}
+}
+
+// NewProviderSetAPI creates a new ProviderSet. Its logger is set with
+// WithLogger and is scoped to this instance; without one, nothing is logged.
 func NewProviderSetAPI(
 	opts ...ProviderSetOption,
 ) (*ProviderSetAPI, error) {
@@ -77,11 +120,7 @@ func NewProviderSetAPI(
 		}
 	}
 
-	if ps.logger != nil {
-		logutil.SetDefault(ps.logger)
-	} else {
-		logutil.SetDefault(nil)
-	}
+	ps.logger = logutil.OrDiscard(ps.logger)
 
 	return ps, nil
 }
@@ -139,21 +178,11 @@ func (ps *ProviderSetAPI) AddProvider(
 	if config == nil || provider == "" || config.Origin == "" {
 		return spec.ProviderParam{}, errors.New("invalid params")
 	}
-
-	ps.mu.Lock()
-	defer ps.mu.Unlock()
-
-	_, exists := ps.providers[provider]
-	if exists {
-		return spec.ProviderParam{}, errors.New(
-			"invalid provider: cannot add a provider with same name as an existing provider, delete first",
-		)
-	}
 	if ok := isProviderSDKTypeSupported(config.SDKType); !ok {
 		return spec.ProviderParam{}, errors.New("unsupported provider api type")
 	}
 
-	providerInfo := spec.ProviderParam{
+	return ps.addProvider(ctx, spec.ProviderParam{
 		Name:                     provider,
 		SDKType:                  config.SDKType,
 		APIKey:                   "",
@@ -161,6 +190,39 @@ func (ps *ProviderSetAPI) AddProvider(
 		ChatCompletionPathPrefix: config.ChatCompletionPathPrefix,
 		APIKeyHeaderKey:          config.APIKeyHeaderKey,
 		DefaultHeaders:           sdkutil.CloneStringMap(config.DefaultHeaders),
+	}, getProviderAPI)
+}
+
+// AddCompletionProvider registers a provider built by build instead of one of the inbuilt adapters, e.g. a
+// fakeprovider.Provider in tests. sdkType is reported in the provider's ProviderParam and passed to capability
+// resolvers. Retries, debuggers and the other ProviderSet features apply as they do for inbuilt providers.
+func (ps *ProviderSetAPI) AddCompletionProvider(
+	ctx context.Context,
+	provider spec.ProviderName,
+	sdkType spec.ProviderSDKType,
+	build CompletionProviderBuilder,
+) (spec.ProviderParam, error) {
+	if provider == "" || build == nil {
+		return spec.ProviderParam{}, errors.New("invalid params")
+	}
+	return ps.addProvider(ctx, spec.ProviderParam{Name: provider, SDKType: sdkType}, build)
+}
+
+func (ps *ProviderSetAPI) addProvider(
+	ctx context.Context,
+	providerInfo spec.ProviderParam,
+	build CompletionProviderBuilder,
+) (spec.ProviderParam, error) {
+	provider := providerInfo.Name
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	_, exists := ps.providers[provider]
+	if exists {
+		return spec.ProviderParam{}, errors.New(
+			"invalid provider: cannot add a provider with same name as an existing provider, delete first",
+		)
 	}
 
 	var dbg spec.CompletionDebugger
@@ -168,15 +230,22 @@ func (ps *ProviderSetAPI) AddProvider(
 		dbg = ps.debugClientBuilder(providerInfo)
 	}
 
-	cp, err := getProviderAPI(providerInfo, dbg)
+	cp, err := build(providerInfo, dbg, ps.logger)
 	if err != nil {
 		return spec.ProviderParam{}, err
 	}
+	if cp == nil {
+		return spec.ProviderParam{}, errors.New("invalid provider: builder returned nil")
+	}
+	info := cp.GetProviderInfo(ctx)
+	if info == nil {
+		return spec.ProviderParam{}, fmt.Errorf("invalid provider: provider %s returned no ProviderParam", provider)
+	}
 	ps.providers[provider] = cp
 
-	logutil.Info("add provider", "name", provider)
+	ps.logger.Info("add provider", "name", provider)
 
-	return *cp.GetProviderInfo(ctx), nil
+	return *info, nil
 }
 
 func (ps *ProviderSetAPI) DeleteProvider(
@@ -197,7 +266,7 @@ func (ps *ProviderSetAPI) DeleteProvider(
 
 	// Best-effort cleanup outside the lock.
 	_ = p.DeInitLLM(ctx)
-	logutil.Info("deleteProvider", "name", provider)
+	ps.logger.Info("deleteProvider", "name", provider)
 
 	return nil
 }
@@ -250,6 +319,15 @@ func (ps *ProviderSetAPI) GetProviderCapability(
 }
 
 // FetchCompletion processes a completion request for a given provider.
+//
+// When ModelParam.Candidates asks for more than one candidate and the model
+// cannot return several per response, the candidates are sampled with
+// concurrent requests, of which only the first streams.
+//
+// If a retry policy is configured (via WithRetryPolicy or
+// opts.RetryPolicy), transient provider failures are retried with backoff
+// until the policy's attempt or time budget is exhausted. Streaming calls are
+// never retried once an event has been delivered to the StreamHandler.
 func (ps *ProviderSetAPI) FetchCompletion(
 	ctx context.Context,
 	provider spec.ProviderName,
@@ -268,53 +346,152 @@ func (ps *ProviderSetAPI) FetchCompletion(
 	if !exists {
 		return nil, errors.New("invalid provider")
 	}
+	ctx = logutil.NewContext(ctx, ps.requestLogger(provider, fetchCompletionRequest.ModelParam.Name, opts))
 
-	reqCopy := *fetchCompletionRequest
+	reqCopy, contextWarns, err := ps.fitRequestInputs(ctx, fetchCompletionRequest, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	// If a max prompt length (in tokens) is configured, apply heuristic filtering.
-	if reqCopy.ModelParam.MaxPromptLength > 0 {
-		reqCopy.Inputs = sdkutil.FilterMessagesByTokenCount(
-			fetchCompletionRequest.Inputs,
-			reqCopy.ModelParam.MaxPromptLength,
-		)
+	retryPolicy := ps.retryPolicy
+	if opts != nil && opts.RetryPolicy != nil {
+		retryPolicy = opts.RetryPolicy
 	}
 
-	resp, err := p.FetchCompletion(
-		ctx,
-		&reqCopy,
-		opts,
+	fetch := func(
+		ctx context.Context,
+		req *spec.FetchCompletionRequest,
+		opts *spec.FetchCompletionOptions,
+	) (*spec.FetchCompletionResponse, int, error) {
+		return fetchCompletionWithRetry(ctx, p, provider, req, opts, retryPolicy)
+	}
+	emulated, err := emulatedCandidateCount(ctx, p, reqCopy, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetch completion failed for provider %s: %w", provider, err)
+	}
+	var (
+		resp     *spec.FetchCompletionResponse
+		attempts int
 	)
+	if emulated > 0 {
+		resp, attempts, err = fetchEmulatedCandidates(ctx, emulated, reqCopy, opts, fetch)
+	} else {
+		resp, attempts, err = fetch(ctx, reqCopy, opts)
+	}
+	if resp != nil && len(contextWarns) > 0 {
+		resp.Warnings = append(resp.Warnings, contextWarns...)
+	}
+	if resp != nil && resp.Usage != nil && opts != nil && opts.Pricing != nil {
+		cost := opts.Pricing.Cost(*resp.Usage)
+		resp.Cost = &cost
+	}
 	if err != nil {
 		// Return any partial response we got alongside a contextual error.
+		if attempts > 1 {
+			return resp, fmt.Errorf(
+				"fetch completion failed for provider %s after %d attempts: %w",
+				provider,
+				attempts,
+				err,
+			)
+		}
 		return resp, fmt.Errorf("fetch completion failed for provider %s: %w", provider, err)
 	}
 
 	return resp, nil
 }
 
+// requestLogger returns the logger for one call, carrying its provider, model,
+// completion key and request ID. Empty values are omitted.
+func (ps *ProviderSetAPI) requestLogger(
+	provider spec.ProviderName,
+	model spec.ModelName,
+	opts *spec.FetchCompletionOptions,
+) *slog.Logger {
+	var attrs []any
+	if provider != "" {
+		attrs = append(attrs, "provider", string(provider))
+	}
+	if model != "" {
+		attrs = append(attrs, "model", string(model))
+	}
+	if opts != nil && opts.CompletionKey != "" {
+		attrs = append(attrs, "completionKey", opts.CompletionKey)
+	}
+	if opts != nil && opts.RequestID != "" {
+		attrs = append(attrs, "requestID", opts.RequestID)
+	}
+	if len(attrs) == 0 {
+		return ps.logger
+	}
+	return ps.logger.With(attrs...)
+}
+
+// fitRequestInputs returns a shallow copy of the request whose inputs fit into ModelParam.MaxPromptLength tokens,
+// together with the warnings of the context strategy.
+func (ps *ProviderSetAPI) fitRequestInputs(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionRequest, []spec.Warning, error) {
+	reqCopy := *req
+	if reqCopy.ModelParam.MaxPromptLength <= 0 {
+		return &reqCopy, nil, nil
+	}
+
+	strategy := contextstrategy.NewestFirst()
+	if opts != nil && opts.ContextStrategy != nil {
+		strategy = opts.ContextStrategy
+	}
+	inputs, warns, err := strategy.FitInputs(ctx, req.Inputs, spec.ContextBudget{
+		MaxTokens: reqCopy.ModelParam.MaxPromptLength,
+		Tokenizer: sdkutil.ResolveTokenizer(ps.resolveTokenizer(opts)),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("fit inputs into max prompt length: %w", err)
+	}
+	if len(inputs) == 0 {
+		return nil, nil, errors.New("no inputs left after fitting into max prompt length")
+	}
+	reqCopy.Inputs = inputs
+	return &reqCopy, warns, nil
+}
+
 func isProviderSDKTypeSupported(t spec.ProviderSDKType) bool {
 	if t == spec.ProviderSDKTypeAnthropic ||
 		t == spec.ProviderSDKTypeOpenAIChatCompletions ||
 		t == spec.ProviderSDKTypeOpenAIResponses ||
-		t == spec.ProviderSDKTypeGoogleGenerateContent {
+		t == spec.ProviderSDKTypeGoogleGenerateContent ||
+		t == spec.ProviderSDKTypeOllamaChat ||
+		t == spec.ProviderSDKTypeBedrockConverse {
 		return true
 	}
 	return false
 }
 
-func getProviderAPI(p spec.ProviderParam, dbg spec.CompletionDebugger) (sdkutil.CompletionProvider, error) {
+func getProviderAPI(
+	p spec.ProviderParam,
+	dbg spec.CompletionDebugger,
+	logger *slog.Logger,
+) (CompletionProvider, error) {
 	switch p.SDKType {
 	case spec.ProviderSDKTypeAnthropic:
-		return anthropicsdk.NewAnthropicMessagesAPI(p, dbg)
+		return anthropicsdk.NewAnthropicMessagesAPI(p, dbg, logger)
 
 	case spec.ProviderSDKTypeOpenAIChatCompletions:
-		return openaichatsdk.NewOpenAIChatCompletionsAPI(p, dbg)
+		return openaichatsdk.NewOpenAIChatCompletionsAPI(p, dbg, logger)
 
 	case spec.ProviderSDKTypeOpenAIResponses:
-		return openairesponsessdk.NewOpenAIResponsesAPI(p, dbg)
+		return openairesponsessdk.NewOpenAIResponsesAPI(p, dbg, logger)
 
 	case spec.ProviderSDKTypeGoogleGenerateContent:
-		return googlegeneratecontentsdk.NewGoogleGenerateContentAPI(p, dbg)
+		return googlegeneratecontentsdk.NewGoogleGenerateContentAPI(p, dbg, logger)
+
+	case spec.ProviderSDKTypeOllamaChat:
+		return ollamachatsdk.NewOllamaChatAPI(p, dbg, logger)
+
+	case spec.ProviderSDKTypeBedrockConverse:
+		return bedrockconversesdk.NewBedrockConverseAPI(p, dbg, logger)
 	}
 
 	return nil, errors.New("invalid provider api type")
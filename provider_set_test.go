@@ -0,0 +1,200 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/fakeprovider"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+func TestFetchCompletionCost(t *testing.T) {
+	t.Parallel()
+
+	usage := &spec.Usage{
+		InputTokensTotal:    3_000_000,
+		InputTokensCached:   2_000_000,
+		InputTokensUncached: 1_000_000,
+		OutputTokens:        2_000_000,
+		ReasoningTokens:     1_000_000,
+	}
+	pricing := &spec.ModelPricing{
+		Currency:           spec.PricingCurrencyUSD,
+		InputPerMTok:       3,
+		CachedInputPerMTok: 0.5,
+		OutputPerMTok:      10,
+		ReasoningPerMTok:   20,
+	}
+
+	tests := []struct {
+		name  string
+		usage *spec.Usage
+		opts  *spec.FetchCompletionOptions
+		want  *spec.Cost
+	}{
+		{name: "no pricing", usage: usage, opts: nil, want: nil},
+		{
+			name:  "priced",
+			usage: usage,
+			opts:  &spec.FetchCompletionOptions{Pricing: pricing},
+			want: &spec.Cost{
+				Currency:    spec.PricingCurrencyUSD,
+				Input:       3,
+				CachedInput: 1,
+				Output:      10,
+				Reasoning:   20,
+				Total:       34,
+			},
+		},
+		{
+			name: "cache writes and web search",
+			usage: &spec.Usage{
+				InputTokensTotal:           4_000_000,
+				InputTokensUncached:        4_000_000,
+				InputTokensCacheWrite:      3_000_000,
+				InputTokensCacheWriteByTTL: map[spec.CacheControlTTL]int64{spec.CacheControlTTL1h: 1_000_000},
+				WebSearchRequests:          2,
+			},
+			opts: &spec.FetchCompletionOptions{Pricing: &spec.ModelPricing{
+				InputPerMTok: 3,
+				CacheWritePerMTok: map[spec.CacheControlTTL]float64{
+					spec.CacheControlTTL5m: 4,
+					spec.CacheControlTTL1h: 6,
+				},
+				WebSearchPerCall: 0.5,
+			}},
+			// 1M plain input, 1M 1h writes and 2M writes without a TTL, priced as 5m writes.
+			want: &spec.Cost{Input: 3, CacheWrite: 14, WebSearch: 1, Total: 18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ps := newScriptedProviderSet(t, &scriptedProvider{usage: tt.usage})
+			resp, err := ps.FetchCompletion(t.Context(), "scripted", retryTestRequest(), tt.opts)
+			if err != nil {
+				t.Fatalf("FetchCompletion: %v", err)
+			}
+			if (resp.Cost == nil) != (tt.want == nil) || (tt.want != nil && *resp.Cost != *tt.want) {
+				t.Fatalf("cost: got %+v want %+v", resp.Cost, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderSetLoggerIsPerInstance(t *testing.T) {
+	t.Parallel()
+
+	newSet := func(buf *bytes.Buffer) *ProviderSetAPI {
+		return newScriptedProviderSet(
+			t,
+			&scriptedProvider{errs: []error{providerStatusError(http.StatusServiceUnavailable, nil), nil}},
+			WithLogger(slog.New(slog.NewJSONHandler(buf, nil))),
+			WithRetryPolicy(fastRetryPolicy(2)),
+		)
+	}
+	var bufA, bufB bytes.Buffer
+	psA, psB := newSet(&bufA), newSet(&bufB)
+
+	for _, c := range []struct {
+		ps  *ProviderSetAPI
+		key string
+	}{{psA, "key-a"}, {psB, "key-b"}} {
+		opts := &spec.FetchCompletionOptions{CompletionKey: c.key, RequestID: c.key + "-req"}
+		if _, err := c.ps.FetchCompletion(t.Context(), "scripted", retryTestRequest(), opts); err != nil {
+			t.Fatalf("FetchCompletion(%s): %v", c.key, err)
+		}
+	}
+
+	for _, c := range []struct {
+		buf *bytes.Buffer
+		key string
+	}{{&bufA, "key-a"}, {&bufB, "key-b"}} {
+		var rec map[string]any
+		if err := json.Unmarshal(c.buf.Bytes(), &rec); err != nil {
+			t.Fatalf("want exactly one JSON record for %s, got %q: %v", c.key, c.buf.String(), err)
+		}
+		want := map[string]any{
+			"level":         "WARN",
+			"provider":      "scripted",
+			"model":         "m",
+			"completionKey": c.key,
+			"requestID":     c.key + "-req",
+		}
+		for k, v := range want {
+			if rec[k] != v {
+				t.Errorf("%s: record[%q] = %v, want %v", c.key, k, rec[k], v)
+			}
+		}
+	}
+}
+
+func TestAddCompletionProvider(t *testing.T) {
+	t.Parallel()
+
+	ps, err := NewProviderSetAPI(WithRetryPolicy(fastRetryPolicy(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ps.AddProvider(t.Context(), "fake", &AddProviderConfig{SDKType: spec.ProviderSDKTypeFake}); err == nil {
+		t.Error("AddProvider() with the fake SDK type: error = nil")
+	}
+	if _, err := ps.AddCompletionProvider(t.Context(), "fake", spec.ProviderSDKTypeFake, nil); err == nil {
+		t.Error("AddCompletionProvider() without a builder: error = nil")
+	}
+
+	fake := fakeprovider.New(fakeprovider.Config{Turns: []fakeprovider.Turn{
+		{Err: fakeprovider.RateLimitError(time.Millisecond)},
+		{Outputs: []spec.OutputUnion{fakeprovider.Text("hello")}},
+	}})
+	info, err := ps.AddCompletionProvider(t.Context(), "fake", spec.ProviderSDKTypeFake, fake.Adapter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "fake" || info.SDKType != spec.ProviderSDKTypeFake {
+		t.Errorf("provider info = %+v", info)
+	}
+
+	resp, err := ps.FetchCompletion(t.Context(), "fake", retryTestRequest(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Outputs) != 1 || resp.Outputs[0].OutputMessage.Contents[0].TextItem.Text != "hello" {
+		t.Errorf("outputs = %+v, want the scripted text", resp.Outputs)
+	}
+	if got := len(fake.Requests()); got != 2 {
+		t.Errorf("fake received %d requests, want the rate limited attempt and its retry", got)
+	}
+}
+
+// noInfoProvider is a custom provider that reports no ProviderParam.
+type noInfoProvider struct{ scriptedProvider }
+
+func (p *noInfoProvider) GetProviderInfo(context.Context) *spec.ProviderParam { return nil }
+
+func TestAddCompletionProviderWithoutProviderInfo(t *testing.T) {
+	t.Parallel()
+
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := func(spec.ProviderParam, spec.CompletionDebugger, *slog.Logger) (CompletionProvider, error) {
+		return &noInfoProvider{}, nil
+	}
+	if _, err := ps.AddCompletionProvider(t.Context(), "custom", "custom", build); err == nil ||
+		!strings.Contains(err.Error(), "returned no ProviderParam") {
+		t.Fatalf("AddCompletionProvider() error = %v, want a missing ProviderParam error", err)
+	}
+	if _, err := ps.FetchCompletion(t.Context(), "custom", retryTestRequest(), nil); err == nil {
+		t.Error("FetchCompletion() on the rejected provider: error = nil")
+	}
+}
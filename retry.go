@@ -0,0 +1,216 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+const (
+	defaultRetryMaxAttempts       = 3
+	defaultRetryInitialBackoff    = 500 * time.Millisecond
+	defaultRetryMaxBackoff        = 30 * time.Second
+	defaultRetryBackoffMultiplier = 2.0
+	retryJitterFraction           = 0.2
+)
+
+// resolvedRetryPolicy is a RetryPolicy with defaults applied and durations resolved.
+type resolvedRetryPolicy struct {
+	maxAttempts       int
+	initialBackoff    time.Duration
+	maxBackoff        time.Duration
+	multiplier        float64
+	jitter            bool
+	maxElapsed        time.Duration
+	honourRetryAfter  bool
+	shouldRetryCustom func(err error) bool
+}
+
+func resolveRetryPolicy(p *spec.RetryPolicy) resolvedRetryPolicy {
+	r := resolvedRetryPolicy{
+		maxAttempts:      defaultRetryMaxAttempts,
+		initialBackoff:   defaultRetryInitialBackoff,
+		maxBackoff:       defaultRetryMaxBackoff,
+		multiplier:       defaultRetryBackoffMultiplier,
+		jitter:           true,
+		honourRetryAfter: true,
+	}
+	if p == nil {
+		return r
+	}
+	if p.MaxAttempts > 0 {
+		r.maxAttempts = p.MaxAttempts
+	}
+	if p.InitialBackoffMillis > 0 {
+		r.initialBackoff = time.Duration(p.InitialBackoffMillis) * time.Millisecond
+	}
+	if p.MaxBackoffMillis > 0 {
+		r.maxBackoff = time.Duration(p.MaxBackoffMillis) * time.Millisecond
+	}
+	if r.maxBackoff < r.initialBackoff {
+		r.maxBackoff = r.initialBackoff
+	}
+	if p.BackoffMultiplier >= 1 {
+		r.multiplier = p.BackoffMultiplier
+	}
+	if p.MaxElapsedMillis > 0 {
+		r.maxElapsed = time.Duration(p.MaxElapsedMillis) * time.Millisecond
+	}
+	r.jitter = !p.DisableJitter
+	r.honourRetryAfter = !p.DisableRetryAfter
+	r.shouldRetryCustom = p.ShouldRetry
+	return r
+}
+
+// backoff returns the wait before the given retry (1 for the first retry).
+func (r resolvedRetryPolicy) backoff(retry int) time.Duration {
+	d := float64(r.initialBackoff) * math.Pow(r.multiplier, float64(retry-1))
+	if r.jitter {
+		d *= 1 - retryJitterFraction + 2*retryJitterFraction*rand.Float64() //nolint:gosec // Jitter only.
+	}
+	if d > float64(r.maxBackoff) {
+		d = float64(r.maxBackoff)
+	}
+	return time.Duration(d)
+}
+
+func (r resolvedRetryPolicy) shouldRetry(err error) bool {
+	if r.shouldRetryCustom != nil {
+		return r.shouldRetryCustom(err)
+	}
+	return isRetryableCompletionError(err)
+}
+
+// fetchCompletionWithRetry invokes the provider until it succeeds, a non retryable error is returned, the attempt or
+// time budget is exhausted, or a stream event has been delivered to the caller.
+func fetchCompletionWithRetry(
+	ctx context.Context,
+	p sdkutil.CompletionProvider,
+	provider spec.ProviderName,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	policy *spec.RetryPolicy,
+) (resp *spec.FetchCompletionResponse, attempts int, err error) {
+	rp := resolveRetryPolicy(policy)
+	if policy == nil || rp.maxAttempts <= 1 {
+		resp, err = p.FetchCompletion(ctx, req, opts)
+		return resp, 1, err
+	}
+
+	// Track stream delivery so that a partially streamed response is never retried.
+	var streamed atomic.Bool
+	attemptOpts := opts
+	if opts != nil && opts.StreamHandler != nil {
+		o := *opts
+		handler := opts.StreamHandler
+		o.StreamHandler = func(event spec.StreamEvent) error {
+			streamed.Store(true)
+			return handler(event)
+		}
+		attemptOpts = &o
+	}
+
+	startedAt := time.Now()
+	var prevErrs []string
+	for attempt := 1; ; attempt++ {
+		attemptCtx := spec.WithCompletionAttempt(ctx, spec.CompletionAttempt{
+			Attempt:        attempt,
+			MaxAttempts:    rp.maxAttempts,
+			PreviousErrors: prevErrs,
+		})
+		resp, err = p.FetchCompletion(attemptCtx, req, attemptOpts)
+		if err == nil {
+			if attempt > 1 && resp != nil {
+				resp.Warnings = append(resp.Warnings, spec.Warning{
+					Code:    "completion_retried",
+					Message: fmt.Sprintf("completion succeeded after %d attempts", attempt),
+				})
+			}
+			return resp, attempt, nil
+		}
+
+		if attempt >= rp.maxAttempts || streamed.Load() || ctx.Err() != nil || !rp.shouldRetry(err) {
+			return resp, attempt, err
+		}
+
+		wait := rp.backoff(attempt)
+		if rp.honourRetryAfter {
+			// Retrying before the server's hint has passed would only be refused again, so a hint longer than the
+			// policy's backoff cap ends the retries and the caller gets the error with its RetryAfter.
+			if ra, ok := retryAfterFromError(err); ok {
+				if ra > rp.maxBackoff {
+					return resp, attempt, err
+				}
+				wait = ra
+			}
+		}
+		if rp.maxElapsed > 0 && time.Since(startedAt)+wait > rp.maxElapsed {
+			return resp, attempt, err
+		}
+
+		logutil.WarnContext(
+			ctx,
+			"fetch completion attempt failed, retrying",
+			"attempt", attempt,
+			"maxAttempts", rp.maxAttempts,
+			"wait", wait,
+			"error", err,
+		)
+		prevErrs = append(prevErrs, err.Error())
+
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			return resp, attempt, errors.Join(err, sleepErr)
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// isRetryableCompletionError reports whether err looks like a transient provider or network failure.
+func isRetryableCompletionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pe *spec.ProviderError
+	if errors.As(err, &pe) && pe.IsTransient() {
+		return true
+	}
+
+	// Connection level failures (resets, refused dials) carry no HTTP status.
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryAfterFromError returns the server provided retry delay carried by err, if any.
+func retryAfterFromError(err error) (time.Duration, bool) {
+	var pe *spec.ProviderError
+	if errors.As(err, &pe) && pe.RetryAfter > 0 {
+		return pe.RetryAfter, true
+	}
+	return 0, false
+}
@@ -0,0 +1,385 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+type scriptedProvider struct {
+	errs     []error
+	stream   []bool
+	calls    int
+	attempts []*spec.CompletionAttempt
+	models   []spec.ModelName
+	params   []spec.ModelParam
+	inputs   [][]spec.InputUnion
+	usage    *spec.Usage
+}
+
+func (p *scriptedProvider) InitLLM(context.Context) error   { return nil }
+func (p *scriptedProvider) DeInitLLM(context.Context) error { return nil }
+func (p *scriptedProvider) GetProviderInfo(context.Context) *spec.ProviderParam {
+	return &spec.ProviderParam{Name: "scripted"}
+}
+func (p *scriptedProvider) IsConfigured(context.Context) bool               { return true }
+func (p *scriptedProvider) SetProviderAPIKey(context.Context, string) error { return nil }
+func (p *scriptedProvider) GetProviderCapability(context.Context) (spec.ModelCapabilities, error) {
+	return spec.ModelCapabilities{
+		ModalitiesIn:     []spec.Modality{spec.ModalityTextIn},
+		ToolCapabilities: &spec.ToolCapabilities{SupportedToolTypes: []spec.ToolType{spec.ToolTypeFunction}},
+	}, nil
+}
+
+func (p *scriptedProvider) FetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	i := p.calls
+	p.calls++
+	p.attempts = append(p.attempts, spec.CompletionAttemptFromContext(ctx))
+	p.models = append(p.models, req.ModelParam.Name)
+	p.params = append(p.params, req.ModelParam)
+	p.inputs = append(p.inputs, req.Inputs)
+
+	if i < len(p.stream) && p.stream[i] && opts != nil && opts.StreamHandler != nil {
+		_ = opts.StreamHandler(spec.StreamEvent{
+			Kind: spec.StreamContentKindText,
+			Text: &spec.StreamTextChunk{Text: "partial"},
+		})
+	}
+	if i < len(p.errs) && p.errs[i] != nil {
+		return &spec.FetchCompletionResponse{Error: &spec.Error{Message: p.errs[i].Error()}}, p.errs[i]
+	}
+	return &spec.FetchCompletionResponse{Usage: p.usage}, nil
+}
+
+func newScriptedProviderSet(t *testing.T, p *scriptedProvider, opts ...ProviderSetOption) *ProviderSetAPI {
+	t.Helper()
+
+	ps, err := NewProviderSetAPI(opts...)
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	ps.providers["scripted"] = p
+	return ps
+}
+
+func retryTestRequest() *spec.FetchCompletionRequest {
+	return &spec.FetchCompletionRequest{
+		ModelParam: spec.ModelParam{Name: "m"},
+		Inputs: []spec.InputUnion{{
+			Kind: spec.InputKindInputMessage,
+			InputMessage: &spec.InputOutputContent{
+				Role: spec.RoleUser,
+				Contents: []spec.InputOutputContentItemUnion{{
+					Kind:     spec.ContentItemKindText,
+					TextItem: &spec.ContentItemText{Text: "hi"},
+				}},
+			},
+		}},
+	}
+}
+
+func providerStatusError(status int, header http.Header) error {
+	return sdkutil.NewProviderError(
+		"scripted",
+		errors.New(http.StatusText(status)),
+		sdkutil.ProviderErrorDetails{HTTPStatus: status, Header: header},
+	)
+}
+
+func fastRetryPolicy(maxAttempts int) *spec.RetryPolicy {
+	return &spec.RetryPolicy{
+		MaxAttempts:          maxAttempts,
+		InitialBackoffMillis: 1,
+		MaxBackoffMillis:     2,
+		DisableJitter:        true,
+	}
+}
+
+func TestFetchCompletionRetry(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := providerStatusError(http.StatusTooManyRequests, nil)
+	badRequest := providerStatusError(http.StatusBadRequest, nil)
+
+	tests := []struct {
+		name         string
+		errs         []error
+		stream       []bool
+		setPolicy    *spec.RetryPolicy
+		callPolicy   *spec.RetryPolicy
+		wantCalls    int
+		wantErr      bool
+		wantAttempts string
+	}{
+		{
+			name:      "no policy makes a single attempt",
+			errs:      []error{rateLimited, nil},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "retries retryable errors until success",
+			errs:      []error{rateLimited, rateLimited, nil},
+			setPolicy: fastRetryPolicy(3),
+			wantCalls: 3,
+		},
+		{
+			name:         "stops at max attempts",
+			errs:         []error{rateLimited, rateLimited, rateLimited, nil},
+			setPolicy:    fastRetryPolicy(2),
+			wantCalls:    2,
+			wantErr:      true,
+			wantAttempts: "after 2 attempts",
+		},
+		{
+			name:      "does not retry non retryable errors",
+			errs:      []error{badRequest, nil},
+			setPolicy: fastRetryPolicy(3),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "call policy overrides set policy",
+			errs:      []error{rateLimited, nil},
+			setPolicy: fastRetryPolicy(3),
+			callPolicy: &spec.RetryPolicy{
+				MaxAttempts: 1,
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "never retries after stream events were delivered",
+			errs:      []error{rateLimited, nil},
+			stream:    []bool{true},
+			setPolicy: fastRetryPolicy(3),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "retries stream that failed before any event",
+			errs:      []error{rateLimited, nil},
+			stream:    []bool{false, true},
+			setPolicy: fastRetryPolicy(3),
+			wantCalls: 2,
+		},
+		{
+			name: "custom classifier is honoured",
+			errs: []error{errors.New("boom"), nil},
+			setPolicy: &spec.RetryPolicy{
+				MaxAttempts:          2,
+				InitialBackoffMillis: 1,
+				ShouldRetry:          func(error) bool { return true },
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &scriptedProvider{errs: tc.errs, stream: tc.stream}
+			ps := newScriptedProviderSet(t, p, WithRetryPolicy(tc.setPolicy))
+
+			req := retryTestRequest()
+			req.ModelParam.Stream = true
+			opts := &spec.FetchCompletionOptions{
+				StreamHandler: func(spec.StreamEvent) error { return nil },
+				RetryPolicy:   tc.callPolicy,
+			}
+
+			resp, err := ps.FetchCompletion(t.Context(), "scripted", req, opts)
+			if p.calls != tc.wantCalls {
+				t.Fatalf("calls: got %d want %d", p.calls, tc.wantCalls)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err: got %v wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantAttempts != "" && !strings.Contains(err.Error(), tc.wantAttempts) {
+				t.Fatalf("err %q does not mention %q", err, tc.wantAttempts)
+			}
+			if !tc.wantErr && tc.wantCalls > 1 {
+				if len(resp.Warnings) == 0 || resp.Warnings[len(resp.Warnings)-1].Code != "completion_retried" {
+					t.Fatalf("expected completion_retried warning, got %#v", resp.Warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchCompletionRetryAttemptMetadata(t *testing.T) {
+	t.Parallel()
+
+	rateLimited := providerStatusError(http.StatusTooManyRequests, nil)
+	p := &scriptedProvider{errs: []error{rateLimited, rateLimited, nil}}
+	ps := newScriptedProviderSet(t, p, WithRetryPolicy(fastRetryPolicy(3)))
+
+	if _, err := ps.FetchCompletion(t.Context(), "scripted", retryTestRequest(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(p.attempts))
+	}
+	for i, a := range p.attempts {
+		if a == nil {
+			t.Fatalf("attempt %d: missing attempt metadata", i+1)
+		}
+		if a.Attempt != i+1 || a.MaxAttempts != 3 {
+			t.Fatalf("attempt %d: got %+v", i+1, a)
+		}
+		if len(a.PreviousErrors) != i {
+			t.Fatalf("attempt %d: expected %d previous errors, got %v", i+1, i, a.PreviousErrors)
+		}
+	}
+}
+
+func TestFetchCompletionRetryHonoursElapsedBudget(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("Retry-After", "30")
+	p := &scriptedProvider{errs: []error{providerStatusError(http.StatusTooManyRequests, header), nil}}
+	ps := newScriptedProviderSet(t, p, WithRetryPolicy(&spec.RetryPolicy{
+		MaxAttempts:      3,
+		MaxElapsedMillis: 1000,
+	}))
+
+	start := time.Now()
+	_, err := ps.FetchCompletion(t.Context(), "scripted", retryTestRequest(), nil)
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected provider error when retry-after exceeds the elapsed budget, got %v", err)
+	}
+	if pe.RetryAfter != 30*time.Second {
+		t.Fatalf("expected retry-after to be kept on the error, got %v", pe.RetryAfter)
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected a single call, got %d", p.calls)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("expected no wait when retry-after exceeds the elapsed budget")
+	}
+}
+
+func TestFetchCompletionRetryStopsWhenRetryAfterExceedsMaxBackoff(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("Retry-After", "3600")
+	p := &scriptedProvider{errs: []error{providerStatusError(http.StatusTooManyRequests, header), nil}}
+	ps := newScriptedProviderSet(t, p, WithRetryPolicy(fastRetryPolicy(2)))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	_, err := ps.FetchCompletion(ctx, "scripted", retryTestRequest(), nil)
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected provider error when retry-after exceeds max backoff, got %v", err)
+	}
+	if pe.RetryAfter != time.Hour {
+		t.Fatalf("expected retry-after to be kept on the error, got %v", pe.RetryAfter)
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected a single call, got %d", p.calls)
+	}
+}
+
+func TestFetchCompletionRetryWaitsForRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("retry-after-ms", "50")
+	p := &scriptedProvider{errs: []error{providerStatusError(http.StatusTooManyRequests, header), nil}}
+	ps := newScriptedProviderSet(t, p, WithRetryPolicy(&spec.RetryPolicy{
+		MaxAttempts:          2,
+		InitialBackoffMillis: 1,
+		MaxBackoffMillis:     1000,
+		DisableJitter:        true,
+	}))
+
+	start := time.Now()
+	if _, err := ps.FetchCompletion(t.Context(), "scripted", retryTestRequest(), nil); err != nil {
+		t.Fatalf("expected retry after the retry-after hint, got %v", err)
+	}
+	if p.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", p.calls)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected the retry to wait for retry-after, waited %v", elapsed)
+	}
+}
+
+func TestFetchCompletionRetryStopsOnContextCancel(t *testing.T) {
+	t.Parallel()
+
+	p := &scriptedProvider{errs: []error{providerStatusError(http.StatusServiceUnavailable, nil), nil}}
+	ps := newScriptedProviderSet(t, p, WithRetryPolicy(&spec.RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoffMillis: 60_000,
+	}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := ps.FetchCompletion(ctx, "scripted", retryTestRequest(), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected a single call, got %d", p.calls)
+	}
+}
+
+func TestIsRetryableCompletionError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"overloaded", providerStatusError(529, nil), true},
+		{"rate limited", providerStatusError(http.StatusTooManyRequests, nil), true},
+		{"server error", providerStatusError(http.StatusBadGateway, nil), true},
+		{"bad request", providerStatusError(http.StatusBadRequest, nil), false},
+		{"unauthorized", providerStatusError(http.StatusUnauthorized, nil), false},
+		{"quota exceeded", sdkutil.NewProviderError(
+			"scripted",
+			errors.New("insufficient_quota"),
+			sdkutil.ProviderErrorDetails{HTTPStatus: http.StatusTooManyRequests},
+		), false},
+		{
+			"wrapped provider error",
+			fmt.Errorf("outer: %w", providerStatusError(http.StatusServiceUnavailable, nil)),
+			true,
+		},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"context canceled", context.Canceled, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isRetryableCompletionError(tc.err); got != tc.want {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
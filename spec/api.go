@@ -2,25 +2,98 @@ package spec
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 )
 
 // StreamContentKind enumerates the kinds of streaming events that can be delivered while a completion is in progress.
+//
+// New kinds may be added over time; handlers should ignore kinds they do not recognize.
 type StreamContentKind string
 
 const (
 	StreamContentKindText     StreamContentKind = "text"
 	StreamContentKindThinking StreamContentKind = "thinking"
+
+	// Tool call lifecycle for function and custom tools. Delta events carry argument fragments in provider order;
+	// the end event carries the complete arguments.
+	StreamContentKindToolCallStart StreamContentKind = "toolCallStart"
+	StreamContentKindToolCallDelta StreamContentKind = "toolCallDelta"
+	StreamContentKindToolCallEnd   StreamContentKind = "toolCallEnd"
+
+	// Status changes of a server side web search call.
+	StreamContentKindWebSearchCall StreamContentKind = "webSearchCall"
+
+	// A citation attached to the output text streamed so far.
+	StreamContentKindCitation StreamContentKind = "citation"
+
+	// Boundaries of one output item (message, reasoning, tool call, ...).
+	StreamContentKindOutputItemStart StreamContentKind = "outputItemStart"
+	StreamContentKindOutputItemStop  StreamContentKind = "outputItemStop"
+
+	// Final event of a successful stream, carrying usage and the provider stop reason.
+	StreamContentKindCompleted StreamContentKind = "completed"
 )
 
 type StreamTextChunk struct {
 	Text string `json:"text"`
+	// Logprobs are those of the tokens in Text, when the request asked for them and the provider streams them.
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
 }
 
 type StreamThinkingChunk struct {
 	Text string `json:"text"`
 }
 
+// StreamToolCallChunk is carried by the toolCallStart, toolCallDelta and toolCallEnd kinds.
+type StreamToolCallChunk struct {
+	// OutputIndex is the position of the tool call among the streamed output items.
+	OutputIndex int      `json:"outputIndex"`
+	Type        ToolType `json:"type"`
+
+	// CallID and Name are set on every event of the call when the provider has reported them.
+	CallID string `json:"callID,omitempty"`
+	Name   string `json:"name,omitempty"`
+
+	// ArgumentsDelta is the argument fragment of a toolCallDelta event.
+	ArgumentsDelta string `json:"argumentsDelta,omitempty"`
+
+	// Arguments holds the complete arguments on toolCallEnd.
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// StreamWebSearchCallChunk reports the progress of a server side web search call.
+type StreamWebSearchCallChunk struct {
+	OutputIndex int    `json:"outputIndex"`
+	CallID      string `json:"callID,omitempty"`
+
+	// Status is one of inProgress, searching, completed or failed.
+	Status Status `json:"status"`
+
+	// Query is set when the provider has disclosed the search query.
+	Query string `json:"query,omitempty"`
+}
+
+// StreamCitationChunk carries one citation of the output message at OutputIndex.
+type StreamCitationChunk struct {
+	OutputIndex int      `json:"outputIndex"`
+	Citation    Citation `json:"citation"`
+}
+
+// StreamOutputItemChunk marks the start or stop of an output item. Kind matches the OutputUnion kind the item is
+// returned as in FetchCompletionResponse.Outputs.
+type StreamOutputItemChunk struct {
+	OutputIndex int        `json:"outputIndex"`
+	Kind        OutputKind `json:"kind"`
+	ID          string     `json:"id,omitempty"`
+}
+
+// StreamCompletedChunk is delivered once, after all other events of a successful stream.
+type StreamCompletedChunk struct {
+	Usage      *Usage      `json:"usage,omitempty"`
+	StopReason *StopReason `json:"stopReason,omitempty"`
+}
+
 type StreamEvent struct {
 	Kind StreamContentKind `json:"kind"`
 
@@ -30,8 +103,13 @@ type StreamEvent struct {
 	CompletionKey string       `json:"completionKey,omitempty"`
 
 	// Exactly one of the below will be non-nil depending on Kind.
-	Text     *StreamTextChunk     `json:"text,omitempty"`
-	Thinking *StreamThinkingChunk `json:"thinking,omitempty"`
+	Text          *StreamTextChunk          `json:"text,omitempty"`
+	Thinking      *StreamThinkingChunk      `json:"thinking,omitempty"`
+	ToolCall      *StreamToolCallChunk      `json:"toolCall,omitempty"`
+	WebSearchCall *StreamWebSearchCallChunk `json:"webSearchCall,omitempty"`
+	Citation      *StreamCitationChunk      `json:"citation,omitempty"`
+	OutputItem    *StreamOutputItemChunk    `json:"outputItem,omitempty"`
+	Completed     *StreamCompletedChunk     `json:"completed,omitempty"`
 }
 
 // StreamConfig controls low-level behavior of streaming delivery. All fields are optional; zero values mean "use
@@ -52,6 +130,10 @@ type FetchCompletionOptions struct {
 	// It can be used by the capability resolver to map arbitrary user/model identifiers to capability profiles.
 	CompletionKey string `json:"-"`
 
+	// RequestID is an opaque, runtime-only caller identifier for the call. It is added to every log record of the
+	// call, together with CompletionKey, to correlate library logs with the caller's own.
+	RequestID string `json:"-"`
+
 	// StreamHandler, if non-nil, is invoked with incremental streaming events
 	// when ModelParam.Stream is true. Returning a non-nil error will stop
 	// streaming early and propagate that error back to the caller.
@@ -61,6 +143,160 @@ type FetchCompletionOptions struct {
 	// CapabilityResolver, if non-nil, is used to resolve model capabilities for request validation/normalization.
 	// Else, inbuilt SDK capabilities are enforced.
 	CapabilityResolver ModelCapabilityResolver `json:"-"`
+
+	// RetryPolicy, if non-nil, overrides the ProviderSet level retry policy for this call.
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// Tokenizer, if non-nil, overrides the ProviderSet level tokenizer for this call. It is used to trim inputs to
+	// ModelParam.MaxPromptLength and by CountTokens when the provider cannot count tokens itself.
+	Tokenizer Tokenizer `json:"-"`
+
+	// ContextStrategy, if non-nil, decides which inputs are kept when they exceed ModelParam.MaxPromptLength tokens.
+	// Else, the newest inputs that fit are kept.
+	ContextStrategy ContextStrategy `json:"-"`
+
+	// Pricing, if non-nil, is used to compute FetchCompletionResponse.Cost from the reported usage.
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+
+	// NormalizationMode decides what happens to request fields the selected SDK/model does not support.
+	// Empty means NormalizationModeBestEffort.
+	NormalizationMode NormalizationMode `json:"normalizationMode,omitempty"`
+}
+
+type NormalizationMode string
+
+const (
+	// NormalizationModeBestEffort drops or truncates unsupported fields and reports each change as a warning.
+	NormalizationModeBestEffort NormalizationMode = "bestEffort"
+	// NormalizationModeStrict fails the call with a *CapabilityValidationError listing every unsupported field,
+	// instead of dropping them.
+	NormalizationModeStrict NormalizationMode = "strict"
+	// NormalizationModeCoerce behaves like NormalizationModeBestEffort, but maps an unsupported reasoning level to
+	// the nearest supported one and clamps reasoning token budgets to the allowed range instead of dropping
+	// reasoning.
+	NormalizationModeCoerce NormalizationMode = "coerce"
+)
+
+// ContextStrategy fits the inputs of a request into a token budget. Implementations must not modify the given inputs
+// and should report anything they dropped or changed as warnings.
+type ContextStrategy interface {
+	FitInputs(ctx context.Context, inputs []InputUnion, budget ContextBudget) ([]InputUnion, []Warning, error)
+}
+
+type ContextBudget struct {
+	MaxTokens int
+	// Tokenizer is never nil.
+	Tokenizer Tokenizer
+}
+
+// Tokenizer counts tokens locally. Implementations must be safe for concurrent use.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+type TokenCountSource string
+
+const (
+	// TokenCountSourceProvider means the count comes from the provider's token counting endpoint.
+	TokenCountSourceProvider TokenCountSource = "provider"
+	// TokenCountSourceTokenizer means the count was estimated locally with a Tokenizer.
+	TokenCountSourceTokenizer TokenCountSource = "tokenizer"
+)
+
+// TokenCount is the number of input tokens of a request.
+type TokenCount struct {
+	InputTokens int              `json:"inputTokens"`
+	Source      TokenCountSource `json:"source"`
+
+	// Exact is true when InputTokens is the provider's own count for the whole request, i.e. the number that would
+	// be billed as input for it.
+	Exact bool `json:"exact"`
+
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// CompiledRequest is the provider HTTP request a FetchCompletion call would send, built without sending it.
+type CompiledRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+
+	// Headers are the request headers with credentials masked as "***".
+	Headers http.Header `json:"headers"`
+
+	// Body is the serialized provider JSON body, byte for byte.
+	Body json.RawMessage `json:"body"`
+
+	Warnings              []Warning          `json:"warnings,omitempty"`
+	EffectiveCapabilities *ModelCapabilities `json:"effectiveCapabilities,omitempty"`
+}
+
+// RetryPolicy controls automatic retries of transient provider failures (rate limits, overload, 5xx, network
+// errors). All fields are optional; zero values mean "use library defaults".
+//
+// A call is never retried once any StreamEvent has been delivered to the StreamHandler, or once the caller's context
+// is done.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one. Set to 1 to disable retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// InitialBackoffMillis is the delay before the first retry. It is multiplied by BackoffMultiplier for every
+	// subsequent retry and capped at MaxBackoffMillis.
+	InitialBackoffMillis int     `json:"initialBackoffMillis,omitempty"`
+	MaxBackoffMillis     int     `json:"maxBackoffMillis,omitempty"`
+	BackoffMultiplier    float64 `json:"backoffMultiplier,omitempty"`
+
+	// DisableJitter turns off the randomization applied to computed backoff delays.
+	DisableJitter bool `json:"disableJitter,omitempty"`
+
+	// MaxElapsedMillis bounds the total time spent across all attempts and waits. A retry whose wait would exceed the
+	// remaining budget is not attempted. Zero means "bounded only by the context".
+	MaxElapsedMillis int `json:"maxElapsedMillis,omitempty"`
+
+	// DisableRetryAfter ignores provider "retry-after-ms" / "Retry-After" hints and always uses computed backoff.
+	// An honoured hint replaces the computed backoff; a hint longer than MaxBackoffMillis or than the remaining
+	// MaxElapsedMillis budget stops the retries, and the returned *ProviderError carries it as RetryAfter.
+	DisableRetryAfter bool `json:"disableRetryAfter,omitempty"`
+
+	// ShouldRetry, if non-nil, replaces the inbuilt classification of retryable errors.
+	ShouldRetry func(err error) bool `json:"-"`
+}
+
+// CompletionAttempt describes a single provider call made as part of a (possibly retried) completion.
+type CompletionAttempt struct {
+	// Attempt is 1-based.
+	Attempt     int `json:"attempt"`
+	MaxAttempts int `json:"maxAttempts"`
+
+	// PreviousErrors holds the error messages of earlier failed attempts, oldest first.
+	PreviousErrors []string `json:"previousErrors,omitempty"`
+}
+
+// CompletionTarget identifies one provider/model entry of a fallback chain.
+type CompletionTarget struct {
+	// Index is the 0-based position of the target in the chain.
+	Index         int          `json:"index"`
+	Provider      ProviderName `json:"provider"`
+	Model         ModelName    `json:"model"`
+	ModelPresetID string       `json:"modelPresetID,omitempty"`
+}
+
+type completionAttemptContextKey struct{}
+
+// WithCompletionAttempt attaches attempt metadata to ctx so that providers can surface it to their debugger.
+func WithCompletionAttempt(ctx context.Context, attempt CompletionAttempt) context.Context {
+	return context.WithValue(ctx, completionAttemptContextKey{}, attempt)
+}
+
+// CompletionAttemptFromContext returns the attempt metadata attached via WithCompletionAttempt, or nil.
+func CompletionAttemptFromContext(ctx context.Context) *CompletionAttempt {
+	if ctx == nil {
+		return nil
+	}
+	a, ok := ctx.Value(completionAttemptContextKey{}).(CompletionAttempt)
+	if !ok {
+		return nil
+	}
+	return &a
 }
 
 type Warning struct {
@@ -68,12 +304,59 @@ type Warning struct {
 	Message string `json:"message"`
 }
 
+type StopReasonKind string
+
+const (
+	StopReasonEndTurn               StopReasonKind = "endTurn"
+	StopReasonMaxTokens             StopReasonKind = "maxTokens"
+	StopReasonStopSequence          StopReasonKind = "stopSequence"
+	StopReasonToolUse               StopReasonKind = "toolUse"
+	StopReasonRefusal               StopReasonKind = "refusal"
+	StopReasonContentFilter         StopReasonKind = "contentFilter"
+	StopReasonPauseTurn             StopReasonKind = "pauseTurn"
+	StopReasonContextWindowExceeded StopReasonKind = "contextWindowExceeded"
+	StopReasonOther                 StopReasonKind = "other"
+)
+
+// StopReason is the normalized reason the model stopped generating.
+//
+// Not every provider distinguishes every kind: OpenAI Chat Completions and Google report a stop sequence hit as a
+// normal end of turn, and only Anthropic pauses long server tool turns. The provider's own value is kept in Raw.
+type StopReason struct {
+	Kind StopReasonKind `json:"kind"`
+
+	// Sequence is the stop sequence that was matched, when Kind is stopSequence and the provider reports it.
+	Sequence string `json:"sequence,omitempty"`
+
+	// Raw is the provider's stop / finish reason, e.g. "end_turn", "length", "MAX_TOKENS" or, for OpenAI Responses,
+	// the response status or incomplete reason.
+	Raw string `json:"raw,omitempty"`
+}
+
+// Choice is one of several candidates sampled for the same prompt.
+type Choice struct {
+	Index      int           `json:"index"`
+	Outputs    []OutputUnion `json:"outputs,omitempty"`
+	StopReason *StopReason   `json:"stopReason,omitempty"`
+}
+
 type FetchCompletionResponse struct {
-	Outputs      []OutputUnion `json:"outputs,omitempty"`
-	Usage        *Usage        `json:"usage,omitempty"`
-	Error        *Error        `json:"error,omitempty"`
-	Warnings     []Warning     `json:"warnings,omitempty"`
-	DebugDetails any           `json:"debugDetails,omitempty"`
+	Outputs    []OutputUnion `json:"outputs,omitempty"`
+	StopReason *StopReason   `json:"stopReason,omitempty"`
+
+	// Choices holds every candidate, ordered by index, when ModelParam.Candidates asked for more than one. Outputs and
+	// StopReason are those of the first choice. Usage covers all of them.
+	Choices []Choice `json:"choices,omitempty"`
+
+	Usage        *Usage    `json:"usage,omitempty"`
+	Cost         *Cost     `json:"cost,omitempty"`
+	Error        *Error    `json:"error,omitempty"`
+	Warnings     []Warning `json:"warnings,omitempty"`
+	DebugDetails any       `json:"debugDetails,omitempty"`
+
+	// Target identifies the fallback target that produced this response. Only set by routers that try more than
+	// one provider/model.
+	Target *CompletionTarget `json:"target,omitempty"`
 }
 
 type FetchCompletionRequest struct {
@@ -93,6 +376,9 @@ type CompletionSpanStart struct {
 	// These may be nil and MUST be treated as read-only.
 	Request *FetchCompletionRequest
 	Options *FetchCompletionOptions
+
+	// Attempt is set when the call is made under a RetryPolicy. May be nil.
+	Attempt *CompletionAttempt
 }
 
 type CompletionSpanEnd struct {
@@ -120,6 +406,22 @@ type CompletionSpan interface {
 	End(info *CompletionSpanEnd) any
 }
 
+// CompletionStreamObserver may be implemented by a CompletionSpan to see the stream events of its call, e.g. to
+// measure the time to the first chunk. Each event is observed just before it is passed to the StreamHandler and
+// MUST be treated as read-only.
+type CompletionStreamObserver interface {
+	ObserveStreamEvent(event StreamEvent)
+}
+
+// CompletionRequestInterceptor may be implemented by a CompletionDebugger to inspect and modify the request of a
+// call after capability normalization, just before it is converted into the provider request. It also runs for
+// CountTokens and CompileRequest. Only req, the call's own copy, may be changed; changes are not normalized again.
+// opts is a shallow copy of the call's options and MUST be treated as read-only: changes to it have no effect, and
+// the values it points to are shared with the caller. A non-nil error fails the call.
+type CompletionRequestInterceptor interface {
+	InterceptRequest(ctx context.Context, req *FetchCompletionRequest, opts *FetchCompletionOptions) error
+}
+
 // CompletionDebugger is the long-lived "client" object for a provider.
 //
 // Provider code (e.g., OpenAIResponsesAPI) owns one CompletionDebugger.
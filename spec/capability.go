@@ -68,6 +68,24 @@ type StopSequenceCapabilities struct {
 	MaxSequences            int  `json:"maxSequences"`
 }
 
+// SamplingCapabilities gates the ModelParam sampling fields besides Temperature.
+// A nil SamplingCapabilities supports none of them.
+type SamplingCapabilities struct {
+	SupportsTopP             bool `json:"supportsTopP"`
+	SupportsTopK             bool `json:"supportsTopK"`
+	SupportsSeed             bool `json:"supportsSeed"`
+	SupportsPresencePenalty  bool `json:"supportsPresencePenalty"`
+	SupportsFrequencyPenalty bool `json:"supportsFrequencyPenalty"`
+	SupportsLogitBias        bool `json:"supportsLogitBias"`
+	// MaxCandidates is the largest supported ModelParam.Candidates. 0 and 1 allow a single candidate only.
+	MaxCandidates int `json:"maxCandidates"`
+	// DisallowedWithReasoning drops topP, topK, penalties and logitBias when reasoning is enabled.
+	DisallowedWithReasoning bool `json:"disallowedWithReasoning"`
+	SupportsLogprobs        bool `json:"supportsLogprobs"`
+	// MaxTopLogprobs is the largest supported LogprobsParam.TopAlternatives.
+	MaxTopLogprobs int `json:"maxTopLogprobs"`
+}
+
 type OutputCapabilities struct {
 	SupportedOutputFormats []OutputFormatKind `json:"supportedOutputFormats"`
 	SupportsVerbosity      bool               `json:"supportsVerbosity"`
@@ -115,6 +133,7 @@ type ModelCapabilities struct {
 
 	ReasoningCapabilities    *ReasoningCapabilities    `json:"reasoningCapabilities,omitempty"`
 	StopSequenceCapabilities *StopSequenceCapabilities `json:"stopSequenceCapabilities,omitempty"`
+	SamplingCapabilities     *SamplingCapabilities     `json:"samplingCapabilities,omitempty"`
 	OutputCapabilities       *OutputCapabilities       `json:"outputCapabilities,omitempty"`
 	ToolCapabilities         *ToolCapabilities         `json:"toolCapabilities,omitempty"`
 	CacheCapabilities        *CacheCapabilities        `json:"cacheCapabilities,omitempty"`
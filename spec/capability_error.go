@@ -0,0 +1,62 @@
+package spec
+
+import (
+	"strings"
+)
+
+// CapabilityViolation is a request field rejected by the capabilities of the selected SDK/model.
+type CapabilityViolation struct {
+	// Field is the path of the rejected request field, e.g. "modelParam.reasoning.level" or
+	// "inputs[2].inputMessage.cacheControl.ttl".
+	Field string `json:"field"`
+
+	// CapabilityPath is the path in ModelCapabilities that rejected the field, e.g.
+	// "reasoningCapabilities.supportedReasoningLevels".
+	CapabilityPath string `json:"capabilityPath"`
+
+	// Code is the warning code reported for the same field in NormalizationModeBestEffort.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CapabilityValidationError is returned (wrapped) by FetchCompletion when NormalizationModeStrict is set and the
+// request uses fields the selected SDK/model does not support. Use errors.As to retrieve it:
+//
+//	var cve *spec.CapabilityValidationError
+//	if errors.As(err, &cve) {
+//		for _, v := range cve.Violations { ... }
+//	}
+type CapabilityValidationError struct {
+	ProviderSDKType ProviderSDKType       `json:"providerSDKType"`
+	ModelName       ModelName             `json:"modelName"`
+	Violations      []CapabilityViolation `json:"violations"`
+}
+
+func (e *CapabilityValidationError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var sb strings.Builder
+	sb.WriteString("request violates capabilities")
+	if e.ProviderSDKType != "" {
+		sb.WriteString(" of sdkType=")
+		sb.WriteString(string(e.ProviderSDKType))
+	}
+	if e.ModelName != "" {
+		sb.WriteString(" model=")
+		sb.WriteString(string(e.ModelName))
+	}
+	for i, v := range e.Violations {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(v.Field)
+		sb.WriteString(" (")
+		sb.WriteString(v.CapabilityPath)
+		sb.WriteString("): ")
+		sb.WriteString(v.Message)
+	}
+	return sb.String()
+}
@@ -31,12 +31,31 @@ const (
 	ContentItemKindImage   ContentItemKind = "image"
 	ContentItemKindFile    ContentItemKind = "file"
 	ContentItemKindRefusal ContentItemKind = "refusal"
+	ContentItemKindAudio   ContentItemKind = "audio"
+	ContentItemKindVideo   ContentItemKind = "video"
 )
 
 type ContentItemText struct {
 	Text      string     `json:"text"`
 	Citations []Citation `json:"citations,omitempty"`
 	Signature string     `json:"signature,omitzero"`
+
+	// Logprobs are the log probabilities of the text's tokens, in order, when ModelParam.Logprobs asked for them.
+	// Output only; they are not sent back to providers.
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+}
+
+// TokenLogprob is the natural log probability of one output token.
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	// TopAlternatives are the most likely tokens at this position, most likely first. They may include Token.
+	TopAlternatives []TokenAlternative `json:"topAlternatives,omitempty"`
+}
+
+type TokenAlternative struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
 }
 
 type ContentItemRefusal struct {
@@ -71,6 +90,37 @@ type ContentItemFile struct {
 	CitationConfig    *CitationConfig `json:"citationConfig"`
 }
 
+// ContentItemAudio is audio sent to the model or spoken by it. Adapters use AudioData first, then AudioURL, then
+// FileID.
+type ContentItemAudio struct {
+	// ID identifies generated audio. OpenAI Chat Completions replays assistant audio by this ID.
+	ID        string `json:"id,omitzero"`
+	AudioName string `json:"audioName,omitzero"`
+	AudioMIME string `json:"audioMIME,omitzero"`
+	AudioURL  string `json:"audioURL,omitzero"`
+	// AudioData is base64 encoded.
+	AudioData string `json:"audioData,omitzero"`
+	// FileID references audio uploaded to the provider's file store; for Gemini it is the file's URI.
+	FileID          string  `json:"fileID,omitzero"`
+	DurationSeconds float64 `json:"durationSeconds,omitzero"`
+	// Transcript is the text of generated audio when the provider returns one.
+	Transcript string `json:"transcript,omitzero"`
+}
+
+// ContentItemVideo is video sent to the model or generated by it. Adapters use VideoData first, then VideoURL, then
+// FileID.
+type ContentItemVideo struct {
+	ID        string `json:"id,omitzero"`
+	VideoName string `json:"videoName,omitzero"`
+	VideoMIME string `json:"videoMIME,omitzero"`
+	VideoURL  string `json:"videoURL,omitzero"`
+	// VideoData is base64 encoded.
+	VideoData string `json:"videoData,omitzero"`
+	// FileID references video uploaded to the provider's file store; for Gemini it is the file's URI.
+	FileID          string  `json:"fileID,omitzero"`
+	DurationSeconds float64 `json:"durationSeconds,omitzero"`
+}
+
 type InputOutputContentItemUnion struct {
 	Kind ContentItemKind `json:"kind"`
 
@@ -78,6 +128,8 @@ type InputOutputContentItemUnion struct {
 	RefusalItem *ContentItemRefusal `json:"refusalItem,omitempty"`
 	ImageItem   *ContentItemImage   `json:"imageItem,omitempty"`
 	FileItem    *ContentItemFile    `json:"fileItem,omitempty"`
+	AudioItem   *ContentItemAudio   `json:"audioItem,omitempty"`
+	VideoItem   *ContentItemVideo   `json:"videoItem,omitempty"`
 }
 
 type InputOutputContent struct {
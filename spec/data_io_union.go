@@ -26,6 +26,9 @@ type InputUnion struct {
 	CustomToolOutput    *ToolOutput         `json:"customToolOutput,omitempty"`
 	WebSearchToolCall   *ToolCall           `json:"webSearchToolCall,omitempty"`
 	WebSearchToolOutput *ToolOutput         `json:"webSearchToolOutput,omitempty"`
+
+	// Sticky inputs are never dropped or summarized by context strategies.
+	Sticky bool `json:"sticky,omitempty"`
 }
 
 type OutputKind string
@@ -81,10 +81,32 @@ type OutputFormat struct {
 	JSONSchemaParam *JSONSchemaParam `json:"jsonSchemaParam,omitempty"`
 }
 
+// AudioOutputParam asks for spoken audio output. It is dropped unless the model lists the audioOut modality.
+//
+// Cross-provider notes:
+//   - OpenAI Chat Completions: maps to modalities ["text", "audio"] + audio. Voice defaults to "alloy" and Format
+//     to "wav".
+//   - Google GenerateContent: maps to responseModalities ["AUDIO"] + speechConfig. Format is ignored; Gemini
+//     returns PCM audio.
+type AudioOutputParam struct {
+	// Voice is the provider's voice name, e.g. "alloy" for OpenAI or "Kore" for Gemini.
+	Voice string `json:"voice,omitempty"`
+	// Format is the audio encoding, e.g. "wav" or "mp3".
+	Format string `json:"format,omitempty"`
+}
+
 type OutputParam struct {
 	Format *OutputFormat `json:"format,omitempty"`
 	// Maps to "Verbosity" in OpenAI, "Effort" in Anthropic.
 	Verbosity *OutputVerbosity `json:"verbosity,omitempty"`
+	// Audio asks for spoken output alongside, or for Gemini instead of, text.
+	Audio *AudioOutputParam `json:"audio,omitempty"`
+}
+
+// LogprobsParam asks for token log probabilities.
+type LogprobsParam struct {
+	// TopAlternatives is the number of most likely tokens returned for every position, 0 for the chosen token only.
+	TopAlternatives int `json:"topAlternatives,omitempty"`
 }
 
 type ModelParam struct {
@@ -114,13 +136,71 @@ type ModelParam struct {
 	//   - Anthropic Messages: maps to stop_sequences.
 	StopSequences []string `json:"stopSequences,omitempty"`
 
+	// Sampling parameters besides Temperature. Each is gated by SamplingCapabilities and dropped with a warning
+	// when the SDK/model does not support it.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions: topP, seed, penalties, logitBias and candidates (n).
+	//   - OpenAI Responses: topP only.
+	//   - Anthropic Messages: topP and topK.
+	//   - Google GenerateContent: all except logitBias; candidates maps to candidateCount.
+	//   - Ollama Chat: topP, topK, seed and penalties, as options.
+	//   - Bedrock Converse: topP only; model-specific knobs go in AdditionalParametersRawJSON.
+
+	// TopP is the nucleus sampling probability mass, in [0, 1].
+	TopP *float64 `json:"topP,omitempty"`
+	// TopK samples from the K most likely tokens only. Must be positive.
+	TopK *int `json:"topK,omitempty"`
+	// Seed asks for best-effort deterministic sampling.
+	Seed             *int64   `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	// LogitBias maps token IDs, as decimal strings, to a bias added to the token's logit.
+	LogitBias map[string]int `json:"logitBias,omitempty"`
+	// Candidates is the number of completions to sample for the prompt. 0 and 1 both mean one. More than one is
+	// returned in FetchCompletionResponse.Choices.
+	Candidates int `json:"candidates,omitempty"`
+	// Logprobs asks for the log probability of every output text token, returned in ContentItemText.Logprobs.
+	//   - OpenAI Chat Completions and OpenAI Responses: up to 20 top alternatives.
+	//   - Google GenerateContent: responseLogprobs, with logprobs top alternatives.
+	Logprobs *LogprobsParam `json:"logprobs,omitempty"`
+
+	// AdditionalParametersRawJSON is a JSON object deep-merged into the provider request body, for provider fields
+	// without a typed equivalent. Keys the adapter builds itself, such as the model, messages and tools, are dropped,
+	// and values that replace a built one are reported as warnings.
+	// Cross-provider notes:
+	//   - OpenAI Chat Completions and Responses, Anthropic Messages, Ollama Chat: merged into the JSON body as sent.
+	//   - Google GenerateContent: merged into the wire body, e.g. {"generationConfig":{"seed":7}}.
+	//   - Bedrock Converse: model-specific fields go under additionalModelRequestFields.
 	AdditionalParametersRawJSON *string `json:"additionalParametersRawJSON"`
 }
 
+// Usage is the token usage of a completion. Detail fields are zero or nil when the provider does not report them.
 type Usage struct {
-	InputTokensTotal    int64 `json:"inputTokensTotal"`
-	InputTokensCached   int64 `json:"inputTokensCached"`
+	// InputTokensTotal is InputTokensCached + InputTokensUncached.
+	InputTokensTotal int64 `json:"inputTokensTotal"`
+	// InputTokensCached counts prompt cache reads.
+	InputTokensCached int64 `json:"inputTokensCached"`
+	// InputTokensUncached includes InputTokensCacheWrite.
 	InputTokensUncached int64 `json:"inputTokensUncached"`
-	OutputTokens        int64 `json:"outputTokens"`
-	ReasoningTokens     int64 `json:"reasoningTokens"`
+	// OutputTokens includes ReasoningTokens.
+	OutputTokens    int64 `json:"outputTokens"`
+	ReasoningTokens int64 `json:"reasoningTokens"`
+
+	// InputTokensCacheWrite counts input tokens written to the prompt cache. InputTokensCacheWriteByTTL splits them
+	// by cache TTL where the provider reports that split.
+	//   - Anthropic Messages: cache_creation_input_tokens, split into 5m and 1h.
+	//   - Others: not reported, cache writes are not billed separately.
+	InputTokensCacheWrite      int64                     `json:"inputTokensCacheWrite,omitempty"`
+	InputTokensCacheWriteByTTL map[CacheControlTTL]int64 `json:"inputTokensCacheWriteByTTL,omitempty"`
+
+	// InputTokensByModality splits InputTokensTotal by input modality, e.g. textIn, imageIn, audioIn and videoIn.
+	//   - Google Generate Content: from prompt and tool use prompt token details.
+	//   - OpenAI Chat Completions: audioIn only.
+	InputTokensByModality map[Modality]int64 `json:"inputTokensByModality,omitempty"`
+
+	// ToolUseInputTokens is the part of InputTokensTotal spent on server tool results, e.g. Google Search grounding.
+	ToolUseInputTokens int64 `json:"toolUseInputTokens,omitempty"`
+
+	// WebSearchRequests is the number of server-side web searches run for the completion.
+	WebSearchRequests int64 `json:"webSearchRequests,omitempty"`
 }
@@ -0,0 +1,77 @@
+package spec
+
+import (
+	"maps"
+	"slices"
+)
+
+// PricingCurrencyUSD is the default currency of ModelPricing.
+const PricingCurrencyUSD = "USD"
+
+// ModelPricing holds the prices of a model. Token prices are per million tokens.
+type ModelPricing struct {
+	Currency string `json:"currency"`
+
+	InputPerMTok       float64 `json:"inputPerMTok"`
+	CachedInputPerMTok float64 `json:"cachedInputPerMTok"`
+
+	// CacheWritePerMTok holds the price of writing the prompt cache, by cache TTL.
+	CacheWritePerMTok map[CacheControlTTL]float64 `json:"cacheWritePerMTok,omitempty"`
+
+	OutputPerMTok    float64 `json:"outputPerMTok"`
+	ReasoningPerMTok float64 `json:"reasoningPerMTok"`
+
+	WebSearchPerCall float64 `json:"webSearchPerCall"`
+}
+
+// Cost is the price of a completion, split by usage category.
+type Cost struct {
+	Currency string `json:"currency"`
+
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cachedInput"`
+	CacheWrite  float64 `json:"cacheWrite"`
+	Output      float64 `json:"output"`
+	Reasoning   float64 `json:"reasoning"`
+	WebSearch   float64 `json:"webSearch"`
+
+	Total float64 `json:"total"`
+}
+
+// Cost prices u.
+//
+// Reasoning tokens are part of Usage.OutputTokens and are priced at ReasoningPerMTok instead of OutputPerMTok. Cache
+// writes are part of Usage.InputTokensUncached and are priced by TTL; writes without a reported TTL are priced as 5m
+// writes, and TTLs without a CacheWritePerMTok entry at InputPerMTok.
+func (p ModelPricing) Cost(u Usage) Cost {
+	const perMTok = 1e6
+
+	writePrice := func(ttl CacheControlTTL) float64 {
+		if v, ok := p.CacheWritePerMTok[ttl]; ok {
+			return v
+		}
+		return p.InputPerMTok
+	}
+	cacheWrite := min(u.InputTokensCacheWrite, u.InputTokensUncached)
+	unattributed := cacheWrite
+	var cacheWriteCost float64
+	for _, ttl := range slices.Sorted(maps.Keys(u.InputTokensCacheWriteByTTL)) {
+		n := min(u.InputTokensCacheWriteByTTL[ttl], unattributed)
+		unattributed -= n
+		cacheWriteCost += float64(n) * writePrice(ttl)
+	}
+	cacheWriteCost += float64(unattributed) * writePrice(CacheControlTTL5m)
+
+	reasoning := min(u.ReasoningTokens, u.OutputTokens)
+	c := Cost{
+		Currency:    p.Currency,
+		Input:       float64(u.InputTokensUncached-cacheWrite) * p.InputPerMTok / perMTok,
+		CachedInput: float64(u.InputTokensCached) * p.CachedInputPerMTok / perMTok,
+		CacheWrite:  cacheWriteCost / perMTok,
+		Output:      float64(u.OutputTokens-reasoning) * p.OutputPerMTok / perMTok,
+		Reasoning:   float64(reasoning) * p.ReasoningPerMTok / perMTok,
+		WebSearch:   float64(u.WebSearchRequests) * p.WebSearchPerCall,
+	}
+	c.Total = c.Input + c.CachedInput + c.CacheWrite + c.Output + c.Reasoning + c.WebSearch
+	return c
+}
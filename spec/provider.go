@@ -8,6 +8,8 @@ const (
 
 	DefaultFileDataMIME    = "application/octet-stream"
 	DefaultImageDataMIME   = "image/png"
+	DefaultAudioDataMIME   = "audio/wav"
+	DefaultVideoDataMIME   = "video/mp4"
 	DefaultApplicationJSON = "application/json"
 
 	DefaultContentTypeHeaderKey = "content-type"
@@ -26,6 +28,16 @@ const (
 	DefaultGoogleGenerateContentPrefix = "/"
 	//nolint:gosec // APIKeyHeaderKey is the key string and not the key itself.
 	DefaultGoogleGenerateContentAPIKeyHeaderKey = "x-goog-api-key"
+
+	DefaultOllamaOrigin     = "http://127.0.0.1:11434"
+	DefaultOllamaChatPrefix = "/api/chat"
+
+	DefaultBedrockRuntimeOrigin  = "https://bedrock-runtime.us-east-1.amazonaws.com"
+	DefaultBedrockConversePrefix = "/model"
+
+	// BedrockEnvironmentCredentials is the API key that makes a Bedrock provider sign requests with the
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables, read per request.
+	BedrockEnvironmentCredentials = "aws:env"
 )
 
 var DefaultBaseHeaders = map[string]string{DefaultContentTypeHeaderKey: DefaultContentTypeHeader}
@@ -35,6 +47,12 @@ const (
 	ProviderSDKTypeOpenAIChatCompletions ProviderSDKType = "providerSDKTypeOpenAIChatCompletions"
 	ProviderSDKTypeOpenAIResponses       ProviderSDKType = "providerSDKTypeOpenAIResponses"
 	ProviderSDKTypeGoogleGenerateContent ProviderSDKType = "providerSDKTypeGoogleGenerateContent"
+	ProviderSDKTypeOllamaChat            ProviderSDKType = "providerSDKTypeOllamaChat"
+	ProviderSDKTypeBedrockConverse       ProviderSDKType = "providerSDKTypeBedrockConverse"
+
+	// ProviderSDKTypeFake answers from an in-process fakeprovider.Provider, for tests. It is registered with
+	// ProviderSetAPI.AddCompletionProvider, not AddProvider.
+	ProviderSDKTypeFake ProviderSDKType = "providerSDKTypeFake"
 )
 
 // ProviderParam represents information about a provider.
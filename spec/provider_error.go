@@ -0,0 +1,103 @@
+package spec
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProviderErrorKind is the provider-neutral classification of a failed provider call.
+type ProviderErrorKind string
+
+const (
+	ProviderErrorKindRateLimited           ProviderErrorKind = "rateLimited"
+	ProviderErrorKindQuotaExceeded         ProviderErrorKind = "quotaExceeded"
+	ProviderErrorKindOverloaded            ProviderErrorKind = "overloaded"
+	ProviderErrorKindAuth                  ProviderErrorKind = "auth"
+	ProviderErrorKindInvalidRequest        ProviderErrorKind = "invalidRequest"
+	ProviderErrorKindContextLengthExceeded ProviderErrorKind = "contextLengthExceeded"
+	ProviderErrorKindContentFiltered       ProviderErrorKind = "contentFiltered"
+	ProviderErrorKindTimeout               ProviderErrorKind = "timeout"
+	ProviderErrorKindCancelled             ProviderErrorKind = "cancelled"
+	ProviderErrorKindUnknown               ProviderErrorKind = "unknown"
+)
+
+// ProviderError is returned (wrapped) by FetchCompletion whenever the provider call itself fails. Use errors.As to
+// retrieve it:
+//
+//	var pe *spec.ProviderError
+//	if errors.As(err, &pe) && pe.Kind == spec.ProviderErrorKindRateLimited { ... }
+//
+// The underlying SDK / transport error is available through errors.Unwrap.
+type ProviderError struct {
+	Kind     ProviderErrorKind
+	Provider ProviderName
+
+	// HTTPStatus is the HTTP status code of the failed response, or 0 if no response was received (transport errors,
+	// cancellation) or the failure was reported inside a stream.
+	HTTPStatus int
+
+	// ProviderCode is the raw provider error code/type when available (e.g. "rate_limit_error",
+	// "context_length_exceeded", "RESOURCE_EXHAUSTED").
+	ProviderCode string
+
+	// RequestID is the provider assigned request identifier when available.
+	RequestID string
+
+	// RetryAfter is the server requested delay before retrying, or 0 if none was provided.
+	RetryAfter time.Duration
+
+	Err error
+}
+
+func (e *ProviderError) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	var sb strings.Builder
+	sb.WriteString("provider error")
+	if e.Provider != "" {
+		sb.WriteString(" from ")
+		sb.WriteString(string(e.Provider))
+	}
+	fmt.Fprintf(&sb, " (kind=%s", e.Kind)
+	if e.HTTPStatus != 0 {
+		fmt.Fprintf(&sb, ", status=%d", e.HTTPStatus)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, ", requestID=%s", e.RequestID)
+	}
+	sb.WriteString(")")
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *ProviderError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// IsTransient reports whether the failure is expected to clear up on its own, i.e. a later identical call may
+// succeed. Caller side cancellation and local deadlines are never transient.
+func (e *ProviderError) IsTransient() bool {
+	if e == nil {
+		return false
+	}
+	switch e.Kind {
+	case ProviderErrorKindRateLimited, ProviderErrorKindOverloaded:
+		return true
+	case ProviderErrorKindTimeout:
+		// Only server side timeouts; a local deadline already consumed the caller's budget.
+		return e.HTTPStatus != 0
+	case ProviderErrorKindUnknown:
+		return e.HTTPStatus == http.StatusConflict || e.HTTPStatus >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
@@ -0,0 +1,190 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// CompletionStream is a pull-based view of a streaming completion, returned by
+// ProviderSetAPI.StreamCompletion.
+//
+// Events must be consumed at most once, from a single goroutine. The final
+// normalized response is available from Response once iteration has ended.
+type CompletionStream struct {
+	run func(ctx context.Context, handler spec.StreamHandler) (*spec.FetchCompletionResponse, error)
+	ctx context.Context
+
+	mu       sync.Mutex
+	started  bool
+	finished bool
+	resp     *spec.FetchCompletionResponse
+	err      error
+}
+
+// StreamCompletion returns a streaming completion as a CompletionStream whose
+// events can be ranged over:
+//
+//	stream := ps.StreamCompletion(ctx, "anthropic", req, nil)
+//	for ev, err := range stream.Events() {
+//		...
+//	}
+//	resp, err := stream.Response()
+//
+// The call behaves like FetchCompletion with ModelParam.Stream forced on;
+// opts.StreamHandler is replaced by the iterator. Nothing is sent to the
+// provider until iteration starts. Breaking out of the loop cancels the
+// underlying call and waits for it to return.
+func (ps *ProviderSetAPI) StreamCompletion(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) *CompletionStream {
+	return newCompletionStream(
+		ctx,
+		func(ctx context.Context, handler spec.StreamHandler) (*spec.FetchCompletionResponse, error) {
+			req, callOpts := streamCompletionInputs(fetchCompletionRequest, opts, handler)
+			return ps.FetchCompletion(ctx, provider, req, callOpts)
+		},
+	)
+}
+
+func newCompletionStream(
+	ctx context.Context,
+	run func(ctx context.Context, handler spec.StreamHandler) (*spec.FetchCompletionResponse, error),
+) *CompletionStream {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &CompletionStream{run: run, ctx: ctx}
+}
+
+// streamCompletionInputs returns copies of the request and options with
+// streaming enabled and the given handler installed.
+func streamCompletionInputs(
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+	handler spec.StreamHandler,
+) (*spec.FetchCompletionRequest, *spec.FetchCompletionOptions) {
+	var reqCopy *spec.FetchCompletionRequest
+	if req != nil {
+		r := *req
+		r.ModelParam.Stream = true
+		reqCopy = &r
+	}
+
+	optsCopy := &spec.FetchCompletionOptions{}
+	if opts != nil {
+		*optsCopy = *opts
+	}
+	optsCopy.StreamHandler = handler
+	return reqCopy, optsCopy
+}
+
+// Events returns an iterator over the stream events. A failed call yields a
+// final pair with a zero event and the error; the same error is also returned
+// by Response.
+//
+// Events may only be iterated once; later iterations yield a single error.
+func (s *CompletionStream) Events() iter.Seq2[spec.StreamEvent, error] {
+	return func(yield func(spec.StreamEvent, error) bool) {
+		s.mu.Lock()
+		if s.started {
+			s.mu.Unlock()
+			yield(spec.StreamEvent{}, errors.New("completion stream already consumed"))
+			return
+		}
+		s.started = true
+		s.mu.Unlock()
+
+		resp, err := s.iterate(yield)
+
+		s.mu.Lock()
+		s.resp, s.err, s.finished = resp, err, true
+		s.mu.Unlock()
+	}
+}
+
+// Response returns the final normalized response and error of the call.
+//
+// If Events has not been iterated, Response runs the call to completion,
+// discarding the events. It returns an error if called while iteration is
+// still in progress. When the consumer stopped iterating early the error
+// reports the cancellation.
+func (s *CompletionStream) Response() (*spec.FetchCompletionResponse, error) {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		for _, err := range s.Events() {
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.finished {
+		return nil, errors.New("completion stream still in progress")
+	}
+	return s.resp, s.err
+}
+
+// iterate runs the call in a separate goroutine and hands events to yield one
+// at a time. The provider is blocked while the consumer handles an event, so
+// events are never buffered.
+func (s *CompletionStream) iterate(
+	yield func(spec.StreamEvent, error) bool,
+) (*spec.FetchCompletionResponse, error) {
+	callCtx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	events := make(chan spec.StreamEvent)
+	handler := func(ev spec.StreamEvent) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-callCtx.Done():
+			return context.Cause(callCtx)
+		}
+	}
+
+	type result struct {
+		resp *spec.FetchCompletionResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := s.run(callCtx, handler)
+		done <- result{resp: resp, err: err}
+	}()
+
+	// Once the context is done events are no longer received, so a pending
+	// handler call observes the cancellation rather than delivering more.
+	recv, ctxDone := events, callCtx.Done()
+	for {
+		select {
+		case ev := <-recv:
+			if !yield(ev, nil) {
+				cancel()
+				r := <-done
+				return r.resp, r.err
+			}
+			if callCtx.Err() != nil {
+				recv = nil
+			}
+		case <-ctxDone:
+			recv, ctxDone = nil, nil
+		case r := <-done:
+			if r.err != nil {
+				yield(spec.StreamEvent{}, r.err)
+			}
+			return r.resp, r.err
+		}
+	}
+}
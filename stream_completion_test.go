@@ -0,0 +1,190 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// eventStreamingProvider streams a fixed number of text events and reports how
+// the call ended.
+type eventStreamingProvider struct {
+	scriptedProvider
+
+	events    int
+	err       error
+	gotStream bool
+	ended     chan error
+}
+
+func (p *eventStreamingProvider) FetchCompletion(
+	ctx context.Context,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionResponse, error) {
+	p.gotStream = req.ModelParam.Stream
+	resp := &spec.FetchCompletionResponse{}
+	for i := range p.events {
+		if err := opts.StreamHandler(spec.StreamEvent{
+			Kind: spec.StreamContentKindText,
+			Text: &spec.StreamTextChunk{Text: fmt.Sprintf("chunk-%d", i)},
+		}); err != nil {
+			p.ended <- err
+			return resp, err
+		}
+	}
+	p.ended <- p.err
+	if p.err != nil {
+		return resp, p.err
+	}
+	resp.Outputs = []spec.OutputUnion{{Kind: spec.OutputKindOutputMessage}}
+	return resp, nil
+}
+
+func newEventStreamingProviderSet(t *testing.T, p *eventStreamingProvider) *ProviderSetAPI {
+	t.Helper()
+
+	ps, err := NewProviderSetAPI()
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	ps.providers["scripted"] = p
+	return ps
+}
+
+func TestStreamCompletion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		events      int
+		providerErr error
+		breakAfter  int
+		wantEvents  int
+		wantErr     bool
+		wantOutputs int
+	}{
+		{
+			name:        "yields every event then exposes the response",
+			events:      3,
+			wantEvents:  3,
+			wantOutputs: 1,
+		},
+		{
+			name:        "provider error is yielded last",
+			events:      2,
+			providerErr: providerStatusError(http.StatusBadRequest, nil),
+			wantEvents:  2,
+			wantErr:     true,
+		},
+		{
+			name:       "breaking early cancels the call",
+			events:     5,
+			breakAfter: 2,
+			wantEvents: 2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &eventStreamingProvider{events: tt.events, err: tt.providerErr, ended: make(chan error, 1)}
+			ps := newEventStreamingProviderSet(t, p)
+			stream := ps.StreamCompletion(t.Context(), "scripted", retryTestRequest(), nil)
+
+			gotEvents := 0
+			var iterErr error
+			for ev, err := range stream.Events() {
+				if err != nil {
+					iterErr = err
+					continue
+				}
+				if want := fmt.Sprintf("chunk-%d", gotEvents); ev.Text == nil || ev.Text.Text != want {
+					t.Fatalf("event %d: got %+v, want text %q", gotEvents, ev, want)
+				}
+				gotEvents++
+				if tt.breakAfter > 0 && gotEvents == tt.breakAfter {
+					break
+				}
+			}
+
+			// The provider call must have returned by the time iteration ends.
+			select {
+			case <-p.ended:
+			default:
+				t.Fatal("provider call still running after iteration ended")
+			}
+
+			if gotEvents != tt.wantEvents {
+				t.Fatalf("events: got %d want %d", gotEvents, tt.wantEvents)
+			}
+			if !p.gotStream {
+				t.Fatal("expected ModelParam.Stream to be forced on")
+			}
+			if tt.providerErr != nil && !errors.Is(iterErr, tt.providerErr) {
+				t.Fatalf("iteration error: got %v want %v", iterErr, tt.providerErr)
+			}
+
+			resp, err := stream.Response()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Response err: got %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.breakAfter > 0 && !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected cancellation after early break, got %v", err)
+			}
+			if resp == nil || len(resp.Outputs) != tt.wantOutputs {
+				t.Fatalf("response: got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestStreamCompletionResponseWithoutIteration(t *testing.T) {
+	t.Parallel()
+
+	p := &eventStreamingProvider{events: 2, ended: make(chan error, 1)}
+	ps := newEventStreamingProviderSet(t, p)
+	stream := ps.StreamCompletion(t.Context(), "scripted", retryTestRequest(), nil)
+
+	resp, err := stream.Response()
+	if err != nil {
+		t.Fatalf("Response: %v", err)
+	}
+	if len(resp.Outputs) != 1 {
+		t.Fatalf("response: got %+v", resp)
+	}
+
+	for _, err := range stream.Events() {
+		if err == nil {
+			t.Fatal("expected an error iterating a consumed stream")
+		}
+	}
+}
+
+func TestStreamCompletionContextCancel(t *testing.T) {
+	t.Parallel()
+
+	p := &eventStreamingProvider{events: 3, ended: make(chan error, 1)}
+	ps := newEventStreamingProviderSet(t, p)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	stream := ps.StreamCompletion(ctx, "scripted", retryTestRequest(), nil)
+
+	var lastErr error
+	for _, err := range stream.Events() {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		cancel()
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Fatalf("expected a cancellation error, got %v", lastErr)
+	}
+}
@@ -0,0 +1,123 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/flexigpt/inference-go/internal/logutil"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// CountTokens returns the number of input tokens of a request: system prompt, inputs and tool definitions.
+//
+// Providers with a token counting endpoint (Anthropic, OpenAI Responses, Google) are asked first. If the provider has
+// none, or the endpoint returns an error while the context is still live, the request is counted locally with the
+// configured Tokenizer and a warning explains why. TokenCount.Source and TokenCount.Exact report which one happened.
+//
+// The request is normalized against the model capabilities just like FetchCompletion, so features dropped for the
+// model are not counted. opts may be nil; StreamHandler and RetryPolicy are ignored.
+func (ps *ProviderSetAPI) CountTokens(
+	ctx context.Context,
+	provider spec.ProviderName,
+	fetchCompletionRequest *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.TokenCount, error) {
+	if provider == "" || fetchCompletionRequest == nil || len(fetchCompletionRequest.Inputs) == 0 ||
+		fetchCompletionRequest.ModelParam.Name == "" {
+		return nil, errors.New("got empty count tokens input")
+	}
+
+	ps.mu.RLock()
+	p, exists := ps.providers[provider]
+	ps.mu.RUnlock()
+
+	if !exists {
+		return nil, errors.New("invalid provider")
+	}
+	ctx = logutil.NewContext(ctx, ps.requestLogger(provider, fetchCompletionRequest.ModelParam.Name, opts))
+
+	callOpts := &spec.FetchCompletionOptions{}
+	if opts != nil {
+		*callOpts = *opts
+	}
+	callOpts.StreamHandler = nil
+	callOpts.Tokenizer = ps.resolveTokenizer(opts)
+
+	var warns []spec.Warning
+	if counter, ok := p.(sdkutil.TokenCounter); ok {
+		count, err := counter.CountTokens(ctx, fetchCompletionRequest, callOpts)
+		if err == nil {
+			return count, nil
+		}
+		// Only failures of the endpoint itself fall back; invalid requests fail as they would in FetchCompletion.
+		if !tokenCountEndpointFailed(err) || ctx.Err() != nil {
+			return nil, fmt.Errorf("count tokens failed for provider %s: %w", provider, err)
+		}
+		logutil.WarnContext(ctx, "provider token count failed, estimating locally", "error", err)
+		warns = append(warns, spec.Warning{
+			Code:    "token_count_fallback",
+			Message: fmt.Sprintf("provider token count failed, estimated locally: %v", err),
+		})
+	} else {
+		warns = append(warns, spec.Warning{
+			Code:    "token_count_fallback",
+			Message: "provider has no token counting endpoint, estimated locally",
+		})
+	}
+
+	req, normWarns, err := normalizeForLocalCount(ctx, p, fetchCompletionRequest, callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("count tokens failed for provider %s: %w", provider, err)
+	}
+	return &spec.TokenCount{
+		InputTokens: sdkutil.CountRequestTokens(req, callOpts.Tokenizer),
+		Source:      spec.TokenCountSourceTokenizer,
+		Warnings:    append(normWarns, warns...),
+	}, nil
+}
+
+// tokenCountEndpointFailed reports whether err is a provider failure of the counting endpoint itself, rather than a
+// rejection of the request. A missing endpoint (404 / 405) counts as an endpoint failure.
+func tokenCountEndpointFailed(err error) bool {
+	var pe *spec.ProviderError
+	if !errors.As(err, &pe) {
+		return false
+	}
+	if pe.Kind != spec.ProviderErrorKindInvalidRequest {
+		return true
+	}
+	return pe.HTTPStatus == http.StatusNotFound || pe.HTTPStatus == http.StatusMethodNotAllowed
+}
+
+// normalizeForLocalCount normalizes req against the provider's capabilities the way its adapter would before a
+// completion call, so the local estimate covers what would actually be sent.
+func normalizeForLocalCount(
+	ctx context.Context,
+	p sdkutil.CompletionProvider,
+	req *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.FetchCompletionRequest, []spec.Warning, error) {
+	caps, err := p.GetProviderCapability(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sdkType spec.ProviderSDKType
+	if info := p.GetProviderInfo(ctx); info != nil {
+		sdkType = info.SDKType
+	}
+	nreq, _, warns, err := sdkutil.NormalizeRequestForSDK(ctx, req, opts, sdkType, caps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nreq, warns, nil
+}
+
+func (ps *ProviderSetAPI) resolveTokenizer(opts *spec.FetchCompletionOptions) spec.Tokenizer {
+	if opts != nil && opts.Tokenizer != nil {
+		return opts.Tokenizer
+	}
+	return ps.tokenizer
+}
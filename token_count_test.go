@@ -0,0 +1,224 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/flexigpt/inference-go/contextstrategy"
+	"github.com/flexigpt/inference-go/internal/sdkutil"
+	"github.com/flexigpt/inference-go/spec"
+)
+
+// countingProvider is a scriptedProvider with a token counting endpoint.
+type countingProvider struct {
+	scriptedProvider
+
+	count        int
+	err          error
+	gotTokenizer spec.Tokenizer
+}
+
+func (p *countingProvider) CountTokens(
+	_ context.Context,
+	_ *spec.FetchCompletionRequest,
+	opts *spec.FetchCompletionOptions,
+) (*spec.TokenCount, error) {
+	p.gotTokenizer = opts.Tokenizer
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &spec.TokenCount{InputTokens: p.count, Source: spec.TokenCountSourceProvider, Exact: true}, nil
+}
+
+// charTokenizer counts every non-space byte as a token.
+type charTokenizer struct{}
+
+func (charTokenizer) CountTokens(text string) int {
+	return len(strings.ReplaceAll(text, " ", ""))
+}
+
+func TestCountTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		provider    sdkutil.CompletionProvider
+		optsTok     spec.Tokenizer
+		wantTokens  int
+		wantSource  spec.TokenCountSource
+		wantExact   bool
+		wantWarning string
+		wantErr     bool
+	}{
+		{
+			name:       "uses the provider count",
+			provider:   &countingProvider{count: 42},
+			wantTokens: 42,
+			wantSource: spec.TokenCountSourceProvider,
+			wantExact:  true,
+		},
+		{
+			name:        "falls back to the tokenizer on provider errors",
+			provider:    &countingProvider{err: providerStatusError(http.StatusNotFound, nil)},
+			optsTok:     charTokenizer{},
+			wantTokens:  2,
+			wantSource:  spec.TokenCountSourceTokenizer,
+			wantWarning: "token_count_fallback",
+		},
+		{
+			name:     "fails on invalid request provider errors",
+			provider: &countingProvider{err: providerStatusError(http.StatusBadRequest, nil)},
+			wantErr:  true,
+		},
+		{
+			name:     "fails on request errors",
+			provider: &countingProvider{err: errors.New("invalid tool policy")},
+			wantErr:  true,
+		},
+		{
+			name:        "providers without an endpoint use the tokenizer",
+			provider:    &scriptedProvider{},
+			optsTok:     charTokenizer{},
+			wantTokens:  2,
+			wantSource:  spec.TokenCountSourceTokenizer,
+			wantWarning: "token_count_fallback",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ps, err := NewProviderSetAPI()
+			if err != nil {
+				t.Fatalf("NewProviderSetAPI: %v", err)
+			}
+			ps.providers["scripted"] = tt.provider
+
+			var opts *spec.FetchCompletionOptions
+			if tt.optsTok != nil {
+				opts = &spec.FetchCompletionOptions{Tokenizer: tt.optsTok}
+			}
+			count, err := ps.CountTokens(t.Context(), "scripted", retryTestRequest(), opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err: got %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if count.InputTokens != tt.wantTokens || count.Source != tt.wantSource || count.Exact != tt.wantExact {
+				t.Fatalf("count: got %+v", count)
+			}
+			if tt.wantWarning != "" && (len(count.Warnings) != 1 || count.Warnings[0].Code != tt.wantWarning) {
+				t.Fatalf("warnings: got %+v", count.Warnings)
+			}
+		})
+	}
+}
+
+func TestCountTokensNormalizesLocalCount(t *testing.T) {
+	t.Parallel()
+
+	ps, err := NewProviderSetAPI(WithTokenizer(charTokenizer{}))
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	ps.providers["scripted"] = &scriptedProvider{}
+
+	req := retryTestRequest()
+	req.ToolChoices = []spec.ToolChoice{{Type: spec.ToolTypeWebSearch, ID: "ws", Name: "search"}}
+	count, err := ps.CountTokens(t.Context(), "scripted", req, nil)
+	if err != nil {
+		t.Fatalf("CountTokens: %v", err)
+	}
+	if count.InputTokens != 2 {
+		t.Fatalf("expected the unsupported tool to be excluded, got %d tokens", count.InputTokens)
+	}
+	if len(count.Warnings) != 2 || count.Warnings[0].Code != "toolChoice_dropped_unsupported" {
+		t.Fatalf("warnings: got %+v", count.Warnings)
+	}
+}
+
+func TestCountTokensPassesTokenizerToProvider(t *testing.T) {
+	t.Parallel()
+
+	ps, err := NewProviderSetAPI(WithTokenizer(charTokenizer{}))
+	if err != nil {
+		t.Fatalf("NewProviderSetAPI: %v", err)
+	}
+	p := &countingProvider{count: 1}
+	ps.providers["scripted"] = p
+
+	if _, err := ps.CountTokens(t.Context(), "scripted", retryTestRequest(), nil); err != nil {
+		t.Fatalf("CountTokens: %v", err)
+	}
+	if _, ok := p.gotTokenizer.(charTokenizer); !ok {
+		t.Fatalf("expected the provider set tokenizer, got %T", p.gotTokenizer)
+	}
+}
+
+func TestFetchCompletionTrimsWithTokenizer(t *testing.T) {
+	t.Parallel()
+
+	p := &scriptedProvider{}
+	ps := newScriptedProviderSet(t, p, WithTokenizer(charTokenizer{}))
+
+	req := retryTestRequest()
+	older := req.Inputs[0]
+	older.InputMessage = &spec.InputOutputContent{
+		Role: spec.RoleUser,
+		Contents: []spec.InputOutputContentItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: "a much longer message"},
+		}},
+	}
+	req.Inputs = []spec.InputUnion{older, req.Inputs[0]}
+	// "hi" is 2 tokens for charTokenizer; the older message does not fit.
+	req.ModelParam.MaxPromptLength = 5
+
+	if _, err := ps.FetchCompletion(t.Context(), "scripted", req, nil); err != nil {
+		t.Fatalf("FetchCompletion: %v", err)
+	}
+	if len(p.inputs) != 1 || len(p.inputs[0]) != 1 {
+		t.Fatalf("expected a single input to reach the provider, got %+v", p.inputs)
+	}
+}
+
+func TestFetchCompletionContextStrategy(t *testing.T) {
+	t.Parallel()
+
+	p := &scriptedProvider{}
+	ps := newScriptedProviderSet(t, p, WithTokenizer(charTokenizer{}))
+
+	req := retryTestRequest()
+	pinned := req.Inputs[0]
+	pinned.Sticky = true
+	pinned.InputMessage = &spec.InputOutputContent{
+		Role: spec.RoleUser,
+		Contents: []spec.InputOutputContentItemUnion{{
+			Kind:     spec.ContentItemKindText,
+			TextItem: &spec.ContentItemText{Text: "pin"},
+		}},
+	}
+	older := pinned
+	older.Sticky = false
+	req.Inputs = []spec.InputUnion{pinned, older, req.Inputs[0]}
+	// "pin" + "hi" fit, the non-sticky "pin" does not.
+	req.ModelParam.MaxPromptLength = 5
+
+	resp, err := ps.FetchCompletion(t.Context(), "scripted", req, &spec.FetchCompletionOptions{
+		ContextStrategy: contextstrategy.KeepFirstLast(0, 1),
+	})
+	if err != nil {
+		t.Fatalf("FetchCompletion: %v", err)
+	}
+	if len(p.inputs) != 1 || len(p.inputs[0]) != 2 || !p.inputs[0][0].Sticky {
+		t.Fatalf("expected the sticky and last inputs to reach the provider, got %+v", p.inputs)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Code != contextstrategy.WarningCodeInputsDropped {
+		t.Fatalf("expected a dropped inputs warning, got %+v", resp.Warnings)
+	}
+}